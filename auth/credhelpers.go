@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	bkauth "github.com/moby/buildkit/session/auth"
+	"resenje.org/singleflight"
+)
+
+const (
+	// credentialHelperPrefix is the prefix of the binaries on $PATH that
+	// implement the docker-credential-helpers protocol.
+	credentialHelperPrefix = "docker-credential-"
+
+	// dockerHubServerURL is the key Docker uses for Docker Hub in its config
+	// and credential stores.
+	dockerHubServerURL = "https://index.docker.io/v1/"
+
+	// identityTokenUsername is the username returned by credential helpers
+	// when the secret is an identity token rather than a password.
+	identityTokenUsername = "<token>"
+
+	// DefaultCredentialHelperTTL is how long credentials returned by a
+	// credential helper are cached for. Short-lived tokens (e.g. from
+	// ecr-login or gcloud) are typically valid for much longer than this, so
+	// this mostly avoids re-running the helper for every pull in a session.
+	DefaultCredentialHelperTTL = 5 * time.Minute
+)
+
+// CredentialHelpers retrieves credentials from the credential helpers
+// configured in a Docker config file (credHelpers and credsStore), caching
+// them for a limited amount of time.
+//
+// Hosts the helper has no credential for are cached too, so that anonymous
+// pulls don't run the helper every time.
+type CredentialHelpers struct {
+	config *configfile.ConfigFile
+	ttl    time.Duration
+
+	now func() time.Time
+
+	// helperG deduplicates concurrent helper runs for the same host
+	helperG singleflight.Group[string, *bkauth.CredentialsResponse]
+
+	cache map[string]cachedCredential
+	m     sync.Mutex
+}
+
+type cachedCredential struct {
+	// credential is nil if the helper has no credential for the host
+	credential *bkauth.CredentialsResponse
+	expires    time.Time
+}
+
+// NewCredentialHelpers initializes credential helpers from the given Docker
+// config file, caching credentials for the given duration.
+func NewCredentialHelpers(cfg *configfile.ConfigFile, ttl time.Duration) *CredentialHelpers {
+	return &CredentialHelpers{
+		config: cfg,
+		ttl:    ttl,
+		now:    time.Now,
+		cache:  map[string]cachedCredential{},
+	}
+}
+
+// WithoutCredentialHelpers returns a copy of the given Docker config file
+// without its credHelpers and credsStore, so that an auth provider using it as
+// a fallback to CredentialHelpers only reads the credentials stored in the
+// file itself, instead of running the helpers again and caching their
+// credentials for the whole session.
+func WithoutCredentialHelpers(cfg *configfile.ConfigFile) *configfile.ConfigFile {
+	if cfg == nil {
+		return nil
+	}
+	stripped := *cfg
+	stripped.CredentialHelpers = nil
+	stripped.CredentialsStore = ""
+	return &stripped
+}
+
+// helper returns the name of the credential helper configured for the given
+// host, if any. A helper configured for a specific host in credHelpers takes
+// precedence over the default credsStore.
+func (h *CredentialHelpers) helper(host string) string {
+	if h.config == nil {
+		return ""
+	}
+	if helper, ok := h.config.CredentialHelpers[host]; ok {
+		return helper
+	}
+	if host == defaultDockerDomain {
+		if helper, ok := h.config.CredentialHelpers[dockerHubServerURL]; ok {
+			return helper
+		}
+	}
+	return h.config.CredentialsStore
+}
+
+// Credential returns the credential for the given host from its credential
+// helper. It returns nil if no helper is configured for the host, or if the
+// helper has no credential for it.
+func (h *CredentialHelpers) Credential(ctx context.Context, host string) (*bkauth.CredentialsResponse, error) {
+	helper := h.helper(host)
+	if helper == "" {
+		return nil, nil
+	}
+
+	h.m.Lock()
+	cached, ok := h.cache[host]
+	h.m.Unlock()
+	if ok && h.now().Before(cached.expires) {
+		return cached.credential, nil
+	}
+
+	credential, _, err := h.helperG.Do(ctx, host, func(context.Context) (*bkauth.CredentialsResponse, error) {
+		credential, err := h.run(helper, host)
+		if err != nil {
+			return nil, err
+		}
+		h.m.Lock()
+		h.cache[host] = cachedCredential{
+			credential: credential,
+			expires:    h.now().Add(h.ttl),
+		}
+		h.m.Unlock()
+		return credential, nil
+	})
+	return credential, err
+}
+
+// run gets the credential for host from the given helper binary.
+func (h *CredentialHelpers) run(helper, host string) (*bkauth.CredentialsResponse, error) {
+	serverURL := host
+	if host == defaultDockerDomain {
+		serverURL = dockerHubServerURL
+	}
+	creds, err := client.Get(client.NewShellProgramFunc(credentialHelperPrefix+helper), serverURL)
+	if err != nil {
+		if credentials.IsErrCredentialsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("credential helper %s%s failed for %s: %w", credentialHelperPrefix, helper, host, err)
+	}
+
+	credential := &bkauth.CredentialsResponse{
+		Username: creds.Username,
+		Secret:   creds.Secret,
+	}
+	if creds.Username == identityTokenUsername {
+		credential.Username = ""
+	}
+	return credential, nil
+}
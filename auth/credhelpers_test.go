@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/moby/buildkit/session/auth"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// fakeCredentialHelper installs a docker-credential-<name> script on $PATH
+// that returns the given username and secret for any server URL except ones
+// containing "unknown", and records each server URL it is invoked with.
+// Server URLs containing "slow" block until unblock is called.
+func fakeCredentialHelper(t *testing.T, name, username, secret string) (calls func() []string, unblock func()) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake credential helpers are shell scripts")
+	}
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "calls")
+	unblockPath := filepath.Join(dir, "unblock")
+	script := `#!/bin/sh
+test "$1" = get || exit 1
+read -r url
+echo "$url" >> ` + logPath + `
+case "$url" in
+  *unknown*) echo "credentials not found in native keychain"; exit 1 ;;
+  *slow*) while [ ! -e ` + unblockPath + ` ]; do sleep 0.05; done ;;
+esac
+printf '{"ServerURL":"%s","Username":"` + username + `","Secret":"` + secret + `"}' "$url"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, credentialHelperPrefix+name), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	calls = func() []string {
+		dt, err := os.ReadFile(logPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		require.NoError(t, err)
+		return strings.Fields(string(dt))
+	}
+	unblock = func() {
+		require.NoError(t, os.WriteFile(unblockPath, nil, 0o600))
+	}
+	return calls, unblock
+}
+
+func TestCredentialHelpers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("credHelpers", func(t *testing.T) {
+		calls, _ := fakeCredentialHelper(t, "ecr-login", "AWS", "ecrtoken")
+		helpers := NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialHelpers: map[string]string{
+				"123456789012.dkr.ecr.us-west-1.amazonaws.com": "ecr-login",
+			},
+		}, DefaultCredentialHelperTTL)
+
+		cred, err := helpers.Credential(ctx, "123456789012.dkr.ecr.us-west-1.amazonaws.com")
+		require.NoError(t, err)
+		require.Equal(t, "AWS", cred.Username)
+		require.Equal(t, "ecrtoken", cred.Secret)
+
+		cred, err = helpers.Credential(ctx, "ghcr.io")
+		require.NoError(t, err)
+		require.Nil(t, cred)
+		require.Equal(t, []string{"123456789012.dkr.ecr.us-west-1.amazonaws.com"}, calls())
+	})
+
+	t.Run("credsStore", func(t *testing.T) {
+		calls, _ := fakeCredentialHelper(t, "store", "dagger", "storesecret")
+		helpers := NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialsStore: "store",
+		}, DefaultCredentialHelperTTL)
+
+		cred, err := helpers.Credential(ctx, defaultDockerDomain)
+		require.NoError(t, err)
+		require.Equal(t, "dagger", cred.Username)
+		require.Equal(t, "storesecret", cred.Secret)
+
+		cred, err = helpers.Credential(ctx, "unknown.example.com")
+		require.NoError(t, err)
+		require.Nil(t, cred)
+		require.Equal(t, []string{dockerHubServerURL, "unknown.example.com"}, calls())
+	})
+
+	t.Run("identity token", func(t *testing.T) {
+		_, _ = fakeCredentialHelper(t, "gcloud", identityTokenUsername, "gcrtoken")
+		helpers := NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialHelpers: map[string]string{"gcr.io": "gcloud"},
+		}, DefaultCredentialHelperTTL)
+
+		cred, err := helpers.Credential(ctx, "gcr.io")
+		require.NoError(t, err)
+		require.Empty(t, cred.Username)
+		require.Equal(t, "gcrtoken", cred.Secret)
+	})
+
+	t.Run("missing helper", func(t *testing.T) {
+		helpers := NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialsStore: "does-not-exist",
+		}, DefaultCredentialHelperTTL)
+
+		_, err := helpers.Credential(ctx, "ghcr.io")
+		require.ErrorContains(t, err, "docker-credential-does-not-exist")
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		calls, _ := fakeCredentialHelper(t, "cached", "dagger", "cachedsecret")
+		helpers := NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialsStore: "cached",
+		}, time.Minute)
+		now := time.Now()
+		helpers.now = func() time.Time { return now }
+
+		for range 3 {
+			_, err := helpers.Credential(ctx, "ghcr.io")
+			require.NoError(t, err)
+		}
+		require.Len(t, calls(), 1)
+
+		now = now.Add(2 * time.Minute)
+		_, err := helpers.Credential(ctx, "ghcr.io")
+		require.NoError(t, err)
+		require.Len(t, calls(), 2)
+
+		// hosts without a credential are cached too
+		for range 3 {
+			cred, err := helpers.Credential(ctx, "unknown.example.com")
+			require.NoError(t, err)
+			require.Nil(t, cred)
+		}
+		require.Len(t, calls(), 3)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		calls, unblock := fakeCredentialHelper(t, "concurrent", "dagger", "concurrentsecret")
+		helpers := NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialsStore: "concurrent",
+		}, DefaultCredentialHelperTTL)
+
+		var eg errgroup.Group
+		for range 3 {
+			eg.Go(func() error {
+				_, err := helpers.Credential(ctx, "slow.example.com")
+				return err
+			})
+		}
+
+		// other hosts don't wait for a slow helper run
+		require.Eventually(t, func() bool {
+			return slices.Contains(calls(), "slow.example.com")
+		}, 10*time.Second, 10*time.Millisecond)
+		cred, err := helpers.Credential(ctx, "ghcr.io")
+		require.NoError(t, err)
+		require.Equal(t, "concurrentsecret", cred.Secret)
+
+		unblock()
+		require.NoError(t, eg.Wait())
+		require.Equal(t, []string{"slow.example.com", "ghcr.io"}, calls())
+	})
+
+	t.Run("registry auth provider", func(t *testing.T) {
+		_, _ = fakeCredentialHelper(t, "provider", "helperuser", "helpersecret")
+		registry := NewRegistryAuthProvider().WithCredentialHelpers(NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialsStore: "provider",
+		}, DefaultCredentialHelperTTL))
+
+		res, err := registry.Credentials(ctx, &auth.CredentialsRequest{Host: "registry-1.docker.io"})
+		require.NoError(t, err)
+		require.Equal(t, "helperuser", res.Username)
+		require.Equal(t, "helpersecret", res.Secret)
+
+		// explicitly added credentials take precedence over helpers
+		require.NoError(t, registry.AddCredential(testRegistryAddress, testRegistryUser, testRegistrySecret))
+		res, err = registry.Credentials(ctx, &auth.CredentialsRequest{Host: testRegistryAddress})
+		require.NoError(t, err)
+		require.Equal(t, testRegistryUser, res.Username)
+		require.Equal(t, testRegistrySecret, res.Secret)
+	})
+	t.Run("fallback", func(t *testing.T) {
+		fallback := &fakeAuthServer{}
+		registry := NewRegistryAuthProvider().WithCredentialHelpers(NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialsStore: "does-not-exist",
+		}, DefaultCredentialHelperTTL)).WithFallback(fallback)
+
+		// a failing helper falls back instead of failing the pull
+		res, err := registry.Credentials(ctx, &auth.CredentialsRequest{Host: "ghcr.io"})
+		require.NoError(t, err)
+		require.Equal(t, "fallbackuser", res.Username)
+		require.Equal(t, []string{"ghcr.io"}, fallback.hosts)
+
+		_, err = NewRegistryAuthProvider().WithCredentialHelpers(NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialsStore: "does-not-exist",
+		}, DefaultCredentialHelperTTL)).Credentials(ctx, &auth.CredentialsRequest{Host: "ghcr.io"})
+		require.ErrorContains(t, err, "docker-credential-does-not-exist")
+	})
+
+	t.Run("fetch token", func(t *testing.T) {
+		_, _ = fakeCredentialHelper(t, "token", "helperuser", "helpersecret")
+		var gotUser, gotSecret string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			gotUser, gotSecret = r.Form.Get("username"), r.Form.Get("password")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"registrytoken","expires_in":300}`))
+		}))
+		defer srv.Close()
+
+		fallback := &fakeAuthServer{}
+		registry := NewRegistryAuthProvider().WithCredentialHelpers(NewCredentialHelpers(&configfile.ConfigFile{
+			CredentialHelpers: map[string]string{"ghcr.io": "token"},
+		}, DefaultCredentialHelperTTL)).WithFallback(fallback)
+
+		res, err := registry.FetchToken(ctx, &auth.FetchTokenRequest{
+			ClientID: "buildkit-client",
+			Host:     "ghcr.io",
+			Realm:    srv.URL,
+			Service:  "ghcr.io",
+			Scopes:   []string{"repository:dagger/engine:pull"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "registrytoken", res.Token)
+		require.EqualValues(t, 300, res.ExpiresIn)
+		require.Equal(t, "helperuser", gotUser)
+		require.Equal(t, "helpersecret", gotSecret)
+		require.Empty(t, fallback.hosts)
+
+		// hosts without a helper are still forwarded to the fallback
+		_, err = registry.FetchToken(ctx, &auth.FetchTokenRequest{Host: "docker.io"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"docker.io"}, fallback.hosts)
+	})
+
+	t.Run("fallback config", func(t *testing.T) {
+		cfg := &configfile.ConfigFile{
+			AuthConfigs:       map[string]types.AuthConfig{"ghcr.io": {Username: "fileuser"}},
+			CredentialHelpers: map[string]string{"gcr.io": "gcloud"},
+			CredentialsStore:  "desktop",
+		}
+		stripped := WithoutCredentialHelpers(cfg)
+		require.Empty(t, stripped.CredentialHelpers)
+		require.Empty(t, stripped.CredentialsStore)
+		require.Equal(t, cfg.AuthConfigs, stripped.AuthConfigs)
+		// the original config is left untouched
+		require.Equal(t, "desktop", cfg.CredentialsStore)
+	})
+}
+
+// fakeAuthServer is a fallback auth server that records the hosts it is
+// asked about.
+type fakeAuthServer struct {
+	hosts []string
+	auth.UnimplementedAuthServer
+}
+
+func (s *fakeAuthServer) Credentials(ctx context.Context, req *auth.CredentialsRequest) (*auth.CredentialsResponse, error) {
+	s.hosts = append(s.hosts, req.Host)
+	return &auth.CredentialsResponse{Username: "fallbackuser", Secret: "fallbacksecret"}, nil
+}
+
+func (s *fakeAuthServer) FetchToken(ctx context.Context, req *auth.FetchTokenRequest) (*auth.FetchTokenResponse, error) {
+	s.hosts = append(s.hosts, req.Host)
+	return &auth.FetchTokenResponse{Token: "fallbacktoken"}, nil
+}
@@ -2,11 +2,16 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	authutil "github.com/containerd/containerd/remotes/docker/auth"
+	remoteserrors "github.com/containerd/containerd/remotes/errors"
 	bkauth "github.com/moby/buildkit/session/auth"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -31,6 +36,14 @@ type RegistryAuthProvider struct {
 	// Memory map credential storage.
 	credentials map[string]*bkauth.CredentialsResponse
 
+	// Credential helpers consulted when no credential was added for the
+	// requested host.
+	helpers *CredentialHelpers
+
+	// Auth server that requests are forwarded to when no credential could be
+	// found by this provider.
+	fallback bkauth.AuthServer
+
 	// Mutex to handle concurrency.
 	m sync.RWMutex
 
@@ -42,6 +55,20 @@ func NewRegistryAuthProvider() *RegistryAuthProvider {
 	return &RegistryAuthProvider{credentials: map[string]*bkauth.CredentialsResponse{}}
 }
 
+// WithCredentialHelpers configures the provider to look up credentials from
+// the given credential helpers when none were added for a host.
+func (r *RegistryAuthProvider) WithCredentialHelpers(helpers *CredentialHelpers) *RegistryAuthProvider {
+	r.helpers = helpers
+	return r
+}
+
+// WithFallback configures the provider to forward requests it can't serve
+// itself to the given auth server.
+func (r *RegistryAuthProvider) WithFallback(fallback bkauth.AuthServer) *RegistryAuthProvider {
+	r.fallback = fallback
+	return r
+}
+
 // AddCredential inserts a new credential for the corresponding address.
 // Returns an error if the address does not match the standard registry
 // address: {registry_domain}.{extension}.
@@ -152,11 +179,17 @@ func (r *RegistryAuthProvider) Register(server *grpc.Server) {
 	bkauth.RegisterAuthServer(server, r)
 }
 
-func (r *RegistryAuthProvider) credential(domain string) *bkauth.CredentialsResponse {
-	// Update default DNS of Docker Hub registry to short name.
+// normalizeHost updates the default DNS of Docker Hub registry to its short
+// name.
+func normalizeHost(domain string) string {
 	if domain == "registry-1.docker.io" || domain == "index.docker.io" {
-		domain = defaultDockerDomain
+		return defaultDockerDomain
 	}
+	return domain
+}
+
+func (r *RegistryAuthProvider) credential(domain string) *bkauth.CredentialsResponse {
+	domain = normalizeHost(domain)
 
 	r.m.Lock()
 	defer r.m.Unlock()
@@ -170,15 +203,127 @@ func (r *RegistryAuthProvider) credential(domain string) *bkauth.CredentialsResp
 	return nil
 }
 
+// lookup returns the credential for the given host from the memory map or,
+// failing that, from the credential helpers. It returns nil if neither has a
+// credential for the host.
+//
+// A failing credential helper is only reported when there is no fallback auth
+// server to ask instead.
+func (r *RegistryAuthProvider) lookup(ctx context.Context, host string) (*bkauth.CredentialsResponse, error) {
+	if credential := r.credential(host); credential != nil {
+		return credential, nil
+	}
+	if r.helpers == nil {
+		return nil, nil
+	}
+	credential, err := r.helpers.Credential(ctx, normalizeHost(host))
+	if err != nil {
+		if r.fallback != nil {
+			return nil, nil
+		}
+		return nil, status.Errorf(codes.Unavailable, "%s", err)
+	}
+	return credential, nil
+}
+
 // Credentials retrieves credentials of the requested address.
 // It searches in the memory map for the standardize address.
 //
-// If the address isn't registered in the memory map, it will search
-// on DockerAuthProvider.
+// If the address isn't registered in the memory map, it will search the
+// credential helpers, and then the fallback auth server, if configured.
 func (r *RegistryAuthProvider) Credentials(ctx context.Context, req *bkauth.CredentialsRequest) (*bkauth.CredentialsResponse, error) {
-	memoryCredential := r.credential(req.GetHost())
-	if memoryCredential != nil {
-		return memoryCredential, nil
+	credential, err := r.lookup(ctx, req.GetHost())
+	if err != nil {
+		return nil, err
+	}
+	if credential != nil {
+		return credential, nil
+	}
+	if r.fallback != nil {
+		return r.fallback.Credentials(ctx, req)
 	}
 	return nil, status.Errorf(codes.NotFound, "no credential found for %s", req.GetHost())
 }
+
+// FetchToken fetches a registry token using the same credentials Credentials
+// would return, so that credentials added to the provider or returned by a
+// credential helper are also used when the fallback auth server handles
+// token requests.
+func (r *RegistryAuthProvider) FetchToken(ctx context.Context, req *bkauth.FetchTokenRequest) (*bkauth.FetchTokenResponse, error) {
+	credential, err := r.lookup(ctx, req.GetHost())
+	if err != nil {
+		return nil, err
+	}
+	if credential != nil {
+		return fetchToken(ctx, req, credential)
+	}
+	if r.fallback != nil {
+		return r.fallback.FetchToken(ctx, req)
+	}
+	return r.UnimplementedAuthServer.FetchToken(ctx, req)
+}
+
+// fetchToken fetches a token from the registry's token server with the given
+// credential, as Buildkit's Docker auth provider does.
+func fetchToken(ctx context.Context, req *bkauth.FetchTokenRequest, credential *bkauth.CredentialsResponse) (*bkauth.FetchTokenResponse, error) {
+	to := authutil.TokenOptions{
+		Realm:    req.GetRealm(),
+		Service:  req.GetService(),
+		Scopes:   req.GetScopes(),
+		Username: credential.Username,
+		Secret:   credential.Secret,
+	}
+	client := http.DefaultClient
+
+	if to.Secret == "" {
+		resp, err := authutil.FetchToken(ctx, client, nil, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch anonymous token: %w", err)
+		}
+		return tokenResponse(resp.Token, resp.IssuedAt, resp.ExpiresIn), nil
+	}
+
+	resp, err := authutil.FetchTokenWithOAuth(ctx, client, nil, req.GetClientID(), to)
+	if err != nil {
+		// Registries without support for POST may return 404 (e.g. GCR) or
+		// 401 (e.g. JFrog Artifactory), so retry with GET.
+		var errStatus remoteserrors.ErrUnexpectedStatus
+		if errors.As(err, &errStatus) &&
+			((errStatus.StatusCode == http.StatusMethodNotAllowed && to.Username != "") ||
+				errStatus.StatusCode == http.StatusNotFound ||
+				errStatus.StatusCode == http.StatusUnauthorized) {
+			resp, err := authutil.FetchToken(ctx, client, nil, to)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch oauth token: %w", err)
+			}
+			return tokenResponse(resp.Token, resp.IssuedAt, resp.ExpiresIn), nil
+		}
+		return nil, fmt.Errorf("failed to fetch oauth token: %w", err)
+	}
+	return tokenResponse(resp.AccessToken, resp.IssuedAt, resp.ExpiresIn), nil
+}
+
+func tokenResponse(token string, issuedAt time.Time, expiresIn int) *bkauth.FetchTokenResponse {
+	resp := &bkauth.FetchTokenResponse{
+		Token:     token,
+		ExpiresIn: int64(expiresIn),
+	}
+	if !issuedAt.IsZero() {
+		resp.IssuedAt = issuedAt.Unix()
+	}
+	return resp
+}
+
+func (r *RegistryAuthProvider) GetTokenAuthority(ctx context.Context, req *bkauth.GetTokenAuthorityRequest) (*bkauth.GetTokenAuthorityResponse, error) {
+	if r.fallback != nil {
+		return r.fallback.GetTokenAuthority(ctx, req)
+	}
+	return r.UnimplementedAuthServer.GetTokenAuthority(ctx, req)
+}
+
+func (r *RegistryAuthProvider) VerifyTokenAuthority(ctx context.Context, req *bkauth.VerifyTokenAuthorityRequest) (*bkauth.VerifyTokenAuthorityResponse, error) {
+	if r.fallback != nil {
+		return r.fallback.VerifyTokenAuthority(ctx, req)
+	}
+	return r.UnimplementedAuthServer.VerifyTokenAuthority(ctx, req)
+}
@@ -26,6 +26,7 @@ type FormatTypeFuncs interface {
 	FormatKindScalarBoolean(representation string) string
 	FormatKindScalarDefault(representation string, refName string, input bool) string
 	FormatKindObject(representation string, refName string, input bool) string
+	FormatKindUnion(representation string, refName string) string
 	FormatKindInputObject(representation string, refName string, input bool) string
 	FormatKindEnum(representation string, refName string) string
 }
@@ -222,6 +223,8 @@ func (c *CommonFunctions) formatType(r *introspection.TypeRef, scope string, inp
 			}
 		case introspection.TypeKindObject:
 			return ff.FormatKindObject(representation, ref.Name, input), nil
+		case introspection.TypeKindUnion:
+			return ff.FormatKindUnion(representation, ref.Name), nil
 		case introspection.TypeKindInputObject:
 			return ff.FormatKindInputObject(representation, ref.Name, input), nil
 		case introspection.TypeKindEnum:
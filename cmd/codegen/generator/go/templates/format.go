@@ -61,6 +61,11 @@ func (f *FormatTypeFunc) FormatKindObject(representation string, refName string,
 	return representation
 }
 
+func (f *FormatTypeFunc) FormatKindUnion(representation string, refName string) string {
+	representation += f.scope + formatName(refName)
+	return representation
+}
+
 func (f *FormatTypeFunc) FormatKindInputObject(representation string, refName string, input bool) string {
 	representation += f.scope + formatName(refName)
 	return representation
@@ -8,6 +8,7 @@ import (
 	"maps"
 	"strconv"
 	"strings"
+	"time"
 
 	. "github.com/dave/jennifer/jen" //nolint:stylecheck
 	"github.com/mitchellh/mapstructure"
@@ -28,9 +29,25 @@ func (ps *parseState) parseGoFunc(parentType *types.Named, fn *types.Func) (*fun
 	if err != nil {
 		return nil, fmt.Errorf("failed to find decl for method %s: %w", fn.Name(), err)
 	}
-	spec.doc = funcDecl.Doc.Text()
+	pragmas, doc := parsePragmaComment(funcDecl.Doc.Text())
+	spec.doc = doc
 	spec.sourceMap = ps.sourceMap(funcDecl)
 
+	if v, ok := pragmas["cache"]; ok {
+		spec.cacheTTL, ok = v.(string)
+		if !ok {
+			return nil, fmt.Errorf("cache pragma %q, must be a valid duration string", v)
+		}
+		if _, err := time.ParseDuration(spec.cacheTTL); err != nil {
+			return nil, fmt.Errorf("cache pragma %q, must be a valid duration string: %w", v, err)
+		}
+	}
+	if v, ok := pragmas["cacheKeyArgs"]; ok {
+		if err := mapstructure.Decode(v, &spec.cacheKeyArgs); err != nil {
+			return nil, fmt.Errorf("cacheKeyArgs pragma %q, must be a list of argument names: %w", v, err)
+		}
+	}
+
 	sig, ok := fn.Type().(*types.Signature)
 	if !ok {
 		return nil, fmt.Errorf("expected method to be a func, got %T", fn.Type())
@@ -85,6 +102,10 @@ type funcTypeSpec struct {
 	doc       string
 	sourceMap *sourceMap
 
+	// cacheTTL and cacheKeyArgs are the function's cache policy, if any
+	cacheTTL     string
+	cacheKeyArgs []string
+
 	argSpecs []paramSpec
 
 	returnSpec   ParsedType // nil if void return
@@ -115,6 +136,20 @@ func (spec *funcTypeSpec) TypeDefCode() (*Statement, error) {
 	if spec.sourceMap != nil {
 		fnTypeDefCode = dotLine(fnTypeDefCode, "WithSourceMap").Call(spec.sourceMap.TypeDefCode())
 	}
+	if spec.cacheTTL != "" || len(spec.cacheKeyArgs) > 0 {
+		cacheOptsCode := []Code{}
+		if spec.cacheTTL != "" {
+			cacheOptsCode = append(cacheOptsCode, Id("TTL").Op(":").Lit(spec.cacheTTL))
+		}
+		if len(spec.cacheKeyArgs) > 0 {
+			keyArgs := make([]Code, 0, len(spec.cacheKeyArgs))
+			for _, name := range spec.cacheKeyArgs {
+				keyArgs = append(keyArgs, Lit(name))
+			}
+			cacheOptsCode = append(cacheOptsCode, Id("KeyArgs").Op(":").Index().String().Values(keyArgs...))
+		}
+		fnTypeDefCode = dotLine(fnTypeDefCode, "WithCachePolicy").Call(Id("dagger").Dot("FunctionWithCachePolicyOpts").Values(cacheOptsCode...))
+	}
 
 	for _, argSpec := range spec.argSpecs {
 		if argSpec.isContext {
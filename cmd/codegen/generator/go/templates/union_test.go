@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"go/format"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/cmd/codegen/generator"
+	"github.com/dagger/dagger/cmd/codegen/introspection"
+)
+
+func TestUnionTemplate(t *testing.T) {
+	nonNull := func(ref *introspection.TypeRef) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindNonNull, OfType: ref}
+	}
+	cat := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Cat"}
+	dog := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Dog"}
+	pet := &introspection.Type{
+		Kind:          introspection.TypeKindUnion,
+		Name:          "Pet",
+		Description:   "A pet, either a cat or a dog.",
+		PossibleTypes: []*introspection.Type{cat, dog},
+	}
+	query := &introspection.Type{
+		Kind: introspection.TypeKindObject,
+		Name: "Query",
+		Fields: []*introspection.Field{{
+			Name:    "pet",
+			TypeRef: nonNull(&introspection.TypeRef{Kind: introspection.TypeKindUnion, Name: "Pet"}),
+		}},
+	}
+	schema := &introspection.Schema{
+		Types: introspection.Types{query, pet, cat, dog},
+	}
+	schema.QueryType.Name = "Query"
+	generator.SetSchemaParents(schema)
+
+	funcs := GoTemplateFuncs(context.Background(), schema, "", generator.Config{}, nil, nil, 0)
+	tmpl := Templates(funcs)["dagger.gen.go"]
+
+	var b bytes.Buffer
+	b.WriteString("package dagger\n")
+	require.NoError(t, tmpl.ExecuteTemplate(&b, "_types/union.go.tmpl", pet))
+	require.NoError(t, tmpl.ExecuteTemplate(&b, "_types/object.go.tmpl", query))
+	src, err := format.Source(b.Bytes())
+	require.NoError(t, err)
+
+	for _, expected := range []string{
+		"// A pet, either a cat or a dog.\n//\n// The value is one of: *Cat, *Dog.\n",
+		"type Pet struct {\n\tquery *querybuilder.Selection\n}\n",
+		"func (r *Pet) Typename(ctx context.Context) (string, error) {\n\tq := r.query.Select(\"__typename\")\n",
+		"func (r *Pet) AsCat() *Cat {\n\treturn &Cat{\n\t\tquery: r.query.InlineFragment(\"Cat\"),\n\t}\n}\n",
+		"func (r *Pet) AsDog() *Dog {\n\treturn &Dog{\n\t\tquery: r.query.InlineFragment(\"Dog\"),\n\t}\n}\n",
+		"\tcase \"Cat\":\n\t\treturn r.AsCat(), nil\n\tcase \"Dog\":\n\t\treturn r.AsDog(), nil\n",
+		"func (r *Client) Pet() *Pet {\n\tq := r.query.Select(\"pet\")\n\n\treturn &Pet{\n\t\tquery: q,\n\t}\n}\n",
+	} {
+		require.Contains(t, string(src), expected)
+	}
+}
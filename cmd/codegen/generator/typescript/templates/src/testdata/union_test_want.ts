@@ -0,0 +1,135 @@
+
+
+export class Client extends BaseClient {
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+   constructor(
+    ctx?: Context,
+   ) {
+     super(ctx)
+
+   }
+
+  /**
+   * Get the Raw GraphQL client.
+   */
+  public getGQLClient() {
+    return this._ctx.getGQLClient()
+  }
+
+  /**
+   * The pet of the house.
+   */
+  pet = (): Pet => {
+
+    const ctx = this._ctx.select(
+      "pet",
+    )
+    return new Pet(ctx)
+  }
+}
+
+/**
+ * A pet, either a cat or a dog.
+ *
+ * The value is one of: Cat, Dog.
+ */
+export class Pet extends BaseClient {
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(ctx?: Context) {
+    super(ctx)
+  }
+
+  /**
+   * The name of the concrete type of the value.
+   */
+  typename = async (): Promise<string> => {
+    const ctx = this._ctx.select("__typename")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * Returns the value as a Cat, which it must be, as reported by typename.
+   */
+  asCat = (): Cat => {
+    const ctx = this._ctx.select("... on Cat")
+    return new Cat(ctx)
+  }
+
+  /**
+   * Returns the value as a Dog, which it must be, as reported by typename.
+   */
+  asDog = (): Dog => {
+    const ctx = this._ctx.select("... on Dog")
+    return new Dog(ctx)
+  }
+}
+
+
+export class Cat extends BaseClient {
+  private readonly _lives?: number = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+   constructor(
+    ctx?: Context,
+     _lives?: number,
+   ) {
+     super(ctx)
+
+     this._lives = _lives
+   }
+  lives = async (): Promise<number> => {
+    if (this._lives) {
+      return this._lives
+    }
+
+    const ctx = this._ctx.select(
+      "lives",
+    )
+
+    const response: Awaited<number> = await ctx.execute()
+
+    
+    return response
+  }
+}
+
+
+export class Dog extends BaseClient {
+  private readonly _breed?: string = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+   constructor(
+    ctx?: Context,
+     _breed?: string,
+   ) {
+     super(ctx)
+
+     this._breed = _breed
+   }
+  breed = async (): Promise<string> => {
+    if (this._breed) {
+      return this._breed
+    }
+
+    const ctx = this._ctx.select(
+      "breed",
+    )
+
+    const response: Awaited<string> = await ctx.execute()
+
+    
+    return response
+  }
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnion(t *testing.T) {
+	tmpl := templateHelper(t)
+
+	objects := objectsInit(t, unionJSON)
+
+	var b bytes.Buffer
+	err := tmpl.ExecuteTemplate(&b, "objects", objects)
+	require.NoError(t, err)
+
+	want := updateAndGetFixtures(t, "testdata/union_test_want.ts", b.String())
+	require.Equal(t, want, b.String())
+}
+
+var unionJSON = `
+[
+  {
+    "kind": "OBJECT",
+    "name": "Query",
+    "description": "",
+    "fields": [
+      {
+        "name": "pet",
+        "description": "The pet of the house.",
+        "args": [],
+        "type": {
+          "kind": "NON_NULL",
+          "ofType": {
+            "kind": "UNION",
+            "name": "Pet"
+          }
+        },
+        "isDeprecated": false,
+        "deprecationReason": null
+      }
+    ],
+    "interfaces": []
+  },
+  {
+    "kind": "UNION",
+    "name": "Pet",
+    "description": "A pet, either a cat or a dog.",
+    "fields": null,
+    "interfaces": null,
+    "possibleTypes": [
+      {
+        "kind": "OBJECT",
+        "name": "Cat"
+      },
+      {
+        "kind": "OBJECT",
+        "name": "Dog"
+      }
+    ]
+  },
+  {
+    "kind": "OBJECT",
+    "name": "Cat",
+    "description": "",
+    "fields": [
+      {
+        "name": "lives",
+        "description": "",
+        "args": [],
+        "type": {
+          "kind": "NON_NULL",
+          "ofType": {
+            "kind": "SCALAR",
+            "name": "Int"
+          }
+        },
+        "isDeprecated": false,
+        "deprecationReason": null
+      }
+    ],
+    "interfaces": []
+  },
+  {
+    "kind": "OBJECT",
+    "name": "Dog",
+    "description": "",
+    "fields": [
+      {
+        "name": "breed",
+        "description": "",
+        "args": [],
+        "type": {
+          "kind": "NON_NULL",
+          "ofType": {
+            "kind": "SCALAR",
+            "name": "String"
+          }
+        },
+        "isDeprecated": false,
+        "deprecationReason": null
+      }
+    ],
+    "interfaces": []
+  }
+]
+`
@@ -106,7 +106,9 @@ type Type struct {
 	InputFields []InputValue `json:"inputFields,omitempty"`
 	EnumValues  []EnumValue  `json:"enumValues,omitempty"`
 	Interfaces  []*Type      `json:"interfaces"`
-	Directives  Directives   `json:"directives"`
+	// PossibleTypes are the member types of a union.
+	PossibleTypes []*Type    `json:"possibleTypes,omitempty"`
+	Directives    Directives `json:"directives"`
 }
 
 // Remove all occurrences of a type from the schema, including
@@ -227,6 +229,14 @@ func (r TypeRef) IsObject() bool {
 	return false
 }
 
+func (r TypeRef) IsUnion() bool {
+	ref := r
+	if r.Kind == TypeKindNonNull {
+		ref = *ref.OfType
+	}
+	return ref.Kind == TypeKindUnion
+}
+
 func (r TypeRef) IsList() bool {
 	ref := r
 	if r.Kind == TypeKindNonNull {
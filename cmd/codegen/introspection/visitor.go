@@ -34,6 +34,9 @@ func (v *Visitor) Run() []*Type {
 		{
 			Kind: TypeKindEnum,
 		},
+		{
+			Kind: TypeKindUnion,
+		},
 	}
 
 	var types []*Type
@@ -51,6 +54,11 @@ func (v *Visitor) visit(kind TypeKind, ignore map[string]any) []*Type {
 			if strings.HasPrefix(t.Name, "_") {
 				continue
 			}
+			// subscriptions are served over SSE, which the generated
+			// clients don't speak
+			if sub := v.schema.SubscriptionType; sub != nil && t.Name == sub.Name {
+				continue
+			}
 			if ignore != nil {
 				if _, ok := ignore[t.Name]; ok {
 					continue
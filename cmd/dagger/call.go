@@ -8,9 +8,11 @@ import (
 	"strings"
 
 	"github.com/dagger/dagger/engine/client"
+	"github.com/iancoleman/strcase"
 	"github.com/juju/ansiterm/tabwriter"
 	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
+	"mvdan.cc/sh/v3/syntax"
 )
 
 const (
@@ -50,10 +52,16 @@ var funcListCmd = &cobra.Command{
 
 This is similar to ´dagger call --help´, but only focused on showing the
 available functions.
+
+Use ´--tree´ to print every function that can be reached by chaining calls,
+or ´--browse´ to explore them interactively, with their arguments and
+documentation, and copy a ´dagger call´ command line for the selected function.
 `,
 		"´",
 		"`",
 	),
+	Example: `dagger functions --tree
+dagger functions --browse build`,
 	GroupID: moduleGroup.ID,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return withEngine(cmd.Context(), client.Params{}, func(ctx context.Context, engineClient *client.Client) (rerr error) {
@@ -62,6 +70,7 @@ available functions.
 				return err
 			}
 			o := mod.MainObject.AsFunctionProvider()
+			var path []*modFunction
 			// Walk the hypothetical function pipeline specified by the args
 			for _, field := range cmd.Flags().Args() {
 				// Lookup the next function in the specified pipeline
@@ -82,16 +91,38 @@ available functions.
 					// > and at worst cause json serialization errors due to cyclic references
 					// > (i.e. with* functions on an object that return the object itself).
 					o = mod.GetFunctionProvider(nextType.Name())
+					path = append(path, nextFunc)
 					continue
 				}
 				return fmt.Errorf("function %q returns type %q with no further functions available", field, nextType.Kind)
 			}
 
+			switch {
+			case funcListBrowse:
+				line, err := browseFunctions(mod, path)
+				if line != "" {
+					fmt.Fprintln(cmd.OutOrStdout(), line)
+				}
+				return err
+			case funcListTree:
+				return functionTreeRun(mod, o, cmd.OutOrStdout())
+			}
 			return functionListRun(o, cmd.OutOrStdout())
 		})
 	},
 }
 
+var (
+	funcListTree   bool
+	funcListBrowse bool
+)
+
+func init() {
+	funcListCmd.Flags().BoolVar(&funcListTree, "tree", false, "Print the tree of all functions that can be chained")
+	funcListCmd.Flags().BoolVar(&funcListBrowse, "browse", false, "Browse functions interactively")
+	funcListCmd.MarkFlagsMutuallyExclusive("tree", "browse")
+}
+
 func functionListRun(o functionProvider, writer io.Writer) error {
 	fns, skipped := GetSupportedFunctions(o)
 
@@ -118,3 +149,87 @@ func functionListRun(o functionProvider, writer io.Writer) error {
 	}
 	return tw.Flush()
 }
+
+// functionTreeRun prints the functions of o, and for each one the functions
+// that can be chained after it, following the returned objects.
+//
+// Core types aren't expanded, and each module type is only expanded the first
+// time it's found, to keep the tree finite.
+func functionTreeRun(mod *moduleDef, o functionProvider, writer io.Writer) error {
+	out := termenv.NewOutput(writer)
+	fmt.Fprintln(out, out.String(o.ProviderName()).Bold())
+	seen := map[string]bool{o.ProviderName(): true}
+	writeFunctionTree(out, mod, o, "", seen)
+	return nil
+}
+
+func writeFunctionTree(w *termenv.Output, mod *moduleDef, o functionProvider, indent string, seen map[string]bool) {
+	fns, _ := GetSupportedFunctions(o)
+	sort.Slice(fns, func(i, j int) bool {
+		return fns[i].Name < fns[j].Name
+	})
+	for i, fn := range fns {
+		mod.LoadFunctionTypeDefs(fn)
+
+		branch, next := "├── ", "│   "
+		if i == len(fns)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", indent, branch, functionSignature(w, fn))
+
+		name := fn.ReturnType.Name()
+		if name == "" || seen[name] {
+			continue
+		}
+		fp := mod.GetFunctionProvider(name)
+		if fp == nil || fp.IsCore() {
+			continue
+		}
+		seen[name] = true
+		writeFunctionTree(w, mod, fp, indent+next, seen)
+	}
+}
+
+// functionSignature returns a one line summary of a function with its
+// required arguments and return type.
+func functionSignature(out *termenv.Output, fn *modFunction) string {
+	var sb strings.Builder
+	sb.WriteString(fn.CmdName())
+	for _, arg := range fn.RequiredArgs() {
+		sb.WriteString(" ")
+		sb.WriteString(arg.Usage())
+	}
+	if len(fn.OptionalArgs()) > 0 {
+		sb.WriteString(" [options]")
+	}
+	sb.WriteString(out.String(" → " + fn.ReturnType.String()).Faint().String())
+	return sb.String()
+}
+
+// callCommandLine returns a `dagger call` command line that chains the given
+// functions, with a placeholder for each required argument.
+func callCommandLine(mod *moduleDef, path []*modFunction) string {
+	args := []string{"dagger", "call"}
+	if ref, ok := getExplicitModuleSourceRef(); ok {
+		if quoted, err := syntax.Quote(ref, syntax.LangBash); err == nil {
+			ref = quoted
+		}
+		args = append(args, "-m", ref)
+	}
+	if obj := mod.MainObject.AsObject; obj != nil && obj.Constructor != nil {
+		args = append(args, requiredArgFlags(obj.Constructor)...)
+	}
+	for _, fn := range path {
+		args = append(args, fn.CmdName())
+		args = append(args, requiredArgFlags(fn)...)
+	}
+	return strings.Join(args, " ")
+}
+
+func requiredArgFlags(fn *modFunction) []string {
+	flags := make([]string, 0, len(fn.Args))
+	for _, arg := range fn.RequiredArgs() {
+		flags = append(flags, "--"+arg.FlagName()+"="+strings.ToUpper(strcase.ToSnake(arg.Name)))
+	}
+	return flags
+}
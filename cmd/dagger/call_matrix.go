@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/juju/ansiterm/tabwriter"
+	"github.com/muesli/termenv"
+	"github.com/sourcegraph/conc/pool"
+	"github.com/spf13/cobra"
+
+	"dagger.io/dagger/querybuilder"
+	"dagger.io/dagger/telemetry"
+)
+
+// defaultMatrixParallelism is how many matrix cells are called at once by
+// default, so that large matrices don't flood the engine.
+const defaultMatrixParallelism = 4
+
+// matrixVar is a function argument with the values to call the function
+// with, from a `--matrix name=value1,value2` flag.
+type matrixVar struct {
+	Name   string
+	Values []string
+}
+
+// parseMatrix parses the values of the `--matrix` flags.
+func parseMatrix(specs []string) ([]matrixVar, error) {
+	vars := make([]matrixVar, 0, len(specs))
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		name, values, ok := strings.Cut(spec, "=")
+		name = cliName(strings.TrimPrefix(strings.TrimSpace(name), "--"))
+		if !ok || name == "" || values == "" {
+			return nil, fmt.Errorf("invalid --matrix %q, expected name=value1,value2", spec)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("--matrix %q is set more than once", name)
+		}
+		seen[name] = true
+		vars = append(vars, matrixVar{
+			Name:   name,
+			Values: strings.Split(values, ","),
+		})
+	}
+	return vars, nil
+}
+
+// matrixCell is one combination of values of the matrix variables.
+type matrixCell []matrixValue
+
+type matrixValue struct {
+	Name  string
+	Value string
+}
+
+func (c matrixCell) String() string {
+	parts := make([]string, 0, len(c))
+	for _, v := range c {
+		parts = append(parts, v.Name+"="+v.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// matrixCells returns every combination of the variables' values, varying
+// the last variable fastest.
+func matrixCells(vars []matrixVar) []matrixCell {
+	cells := []matrixCell{nil}
+	for _, v := range vars {
+		next := make([]matrixCell, 0, len(cells)*len(v.Values))
+		for _, cell := range cells {
+			for _, value := range v.Values {
+				c := make(matrixCell, len(cell), len(cell)+1)
+				copy(c, cell)
+				next = append(next, append(c, matrixValue{v.Name, value}))
+			}
+		}
+		cells = next
+	}
+	return cells
+}
+
+// setMatrixArgs sets the function's arguments that are matrix variables to
+// their values in the cell being run.
+func (fc *FuncCommand) setMatrixArgs(c *cobra.Command, fn *modFunction) error {
+	for _, v := range fc.cell {
+		arg, err := fn.GetArg(v.Name)
+		if err != nil {
+			continue
+		}
+		flag := c.Flags().Lookup(arg.FlagName())
+		if flag == nil {
+			// unsupported argument type, which is reported as skipped
+			continue
+		}
+		if flag.Changed {
+			return fmt.Errorf("--%s is set both as a flag and with --matrix", v.Name)
+		}
+		if err := c.Flags().Set(flag.Name, v.Value); err != nil {
+			return fmt.Errorf("--matrix %s=%s: %w", v.Name, v.Value, err)
+		}
+		fc.cellArgs[v.Name] = true
+	}
+	return nil
+}
+
+// runMatrix calls the function chain for every cell in the matrix,
+// concurrently within the same session, up to --matrix-parallelism cells at
+// once, and prints a summary of the
+// results.
+func (fc *FuncCommand) runMatrix(c *cobra.Command, a []string) error {
+	vars, err := parseMatrix(fc.matrix)
+	if err != nil {
+		return err
+	}
+	if outputPath != "" {
+		return fmt.Errorf("--output can't be used with --matrix")
+	}
+	if fc.matrixParallelism < 1 {
+		return fmt.Errorf("--matrix-parallelism must be at least 1, got %d", fc.matrixParallelism)
+	}
+
+	// Errors are reported per cell from now on.
+	fc.showUsage = false
+
+	cells := matrixCells(vars)
+	results := make([]error, len(cells))
+
+	// Loading commands isn't safe to do concurrently since flags that
+	// aren't function arguments are shared, but that's quick compared to
+	// the calls themselves.
+	var loadMu sync.Mutex
+
+	p := pool.New().WithMaxGoroutines(fc.matrixParallelism)
+	for i, cell := range cells {
+		p.Go(func() {
+			results[i] = fc.runMatrixCell(c, a, cell, &loadMu)
+		})
+	}
+	p.Wait()
+
+	var failed int
+	for _, err := range results {
+		if err != nil {
+			failed++
+		}
+	}
+	if err := printMatrixSummary(c.OutOrStdout(), vars, cells, results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d matrix cells failed", failed, len(cells))
+	}
+	return nil
+}
+
+// runMatrixCell calls the function chain with the cell's argument values,
+// in a span of its own.
+func (fc *FuncCommand) runMatrixCell(c *cobra.Command, a []string, cell matrixCell, loadMu *sync.Mutex) (rerr error) {
+	ctx, span := Tracer().Start(c.Context(), cell.String())
+	defer telemetry.End(span, func() error { return rerr })
+
+	stdio := telemetry.SpanStdio(ctx, InstrumentationLibrary)
+	defer stdio.Close()
+
+	cellFc := &FuncCommand{
+		Name:     fc.Name,
+		mod:      fc.mod,
+		c:        fc.c,
+		q:        querybuilder.Query().Client(fc.c.Dagger().GraphQLClient()),
+		cell:     cell,
+		cellArgs: map[string]bool{},
+	}
+
+	// A separate command tree per cell, so each gets its own argument flags.
+	cmd := &cobra.Command{
+		Use:         c.Use,
+		Annotations: map[string]string{},
+	}
+	cmd.SetGlobalNormalizationFunc(c.GlobalNormalizationFunc())
+	// flags of the original command, including inherited ones, are
+	// persistent so that sub-commands accept them too
+	cmd.PersistentFlags().AddFlagSet(c.Flags())
+	cmd.Flags().SetInterspersed(false)
+	cmd.SetOut(stdio.Stdout)
+	cmd.SetErr(stdio.Stderr)
+	cmd.SetContext(ctx)
+
+	loadMu.Lock()
+	leaf, args, err := cellFc.loadCommand(cmd, a)
+	loadMu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, v := range cell {
+		if !cellFc.cellArgs[v.Name] {
+			return fmt.Errorf("--matrix %s doesn't match any function argument", v.Name)
+		}
+	}
+
+	if leaf == cmd {
+		return cellFc.RunE(ctx, fc.mod.MainObject.AsObject.Constructor)(leaf, args)
+	}
+	return leaf.RunE(leaf, args)
+}
+
+func printMatrixSummary(w io.Writer, vars []matrixVar, cells []matrixCell, results []error) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	for _, v := range vars {
+		fmt.Fprintf(tw, "%s\t", termenv.String(v.Name).Bold())
+	}
+	fmt.Fprintln(tw, termenv.String("Result").Bold())
+
+	for i, cell := range cells {
+		for _, v := range cell {
+			fmt.Fprintf(tw, "%s\t", v.Value)
+		}
+		if err := results[i]; err != nil {
+			msg, _, _ := strings.Cut(err.Error(), "\n")
+			fmt.Fprintln(tw, "failed: "+msg)
+		} else {
+			fmt.Fprintln(tw, "ok")
+		}
+	}
+
+	return tw.Flush()
+}
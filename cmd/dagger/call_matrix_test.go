@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"dagger.io/dagger"
+)
+
+func TestMatrix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cells", func(t *testing.T) {
+		t.Parallel()
+		vars, err := parseMatrix([]string{"go=1.22,1.23", "--platform=linux/amd64,linux/arm64", "pkg=./..."})
+		require.NoError(t, err)
+
+		var cells []string
+		for _, cell := range matrixCells(vars) {
+			cells = append(cells, cell.String())
+		}
+		require.Equal(t, []string{
+			"go=1.22 platform=linux/amd64 pkg=./...",
+			"go=1.22 platform=linux/arm64 pkg=./...",
+			"go=1.23 platform=linux/amd64 pkg=./...",
+			"go=1.23 platform=linux/arm64 pkg=./...",
+		}, cells)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := parseMatrix([]string{"go"})
+		require.ErrorContains(t, err, "expected name=value1,value2")
+		_, err = parseMatrix([]string{"go=1.22", "go=1.23"})
+		require.ErrorContains(t, err, "set more than once")
+	})
+
+	t.Run("args", func(t *testing.T) {
+		t.Parallel()
+		fn := &modFunction{
+			Name: "test",
+			Args: []*modFunctionArg{
+				{Name: "goVersion", TypeDef: &modTypeDef{Kind: dagger.TypeDefKindStringKind}},
+				{Name: "race", TypeDef: &modTypeDef{Kind: dagger.TypeDefKindBooleanKind, Optional: true}},
+			},
+		}
+		newCmd := func() *cobra.Command {
+			cmd := &cobra.Command{}
+			for _, arg := range fn.Args {
+				require.NoError(t, arg.AddFlag(cmd.Flags()))
+			}
+			return cmd
+		}
+
+		fc := &FuncCommand{
+			cell:     matrixCell{{"go-version", "1.23"}, {"race", "true"}, {"other", "x"}},
+			cellArgs: map[string]bool{},
+		}
+		cmd := newCmd()
+		require.NoError(t, fc.setMatrixArgs(cmd, fn))
+		v, err := cmd.Flags().GetString("go-version")
+		require.NoError(t, err)
+		require.Equal(t, "1.23", v)
+		race, err := cmd.Flags().GetBool("race")
+		require.NoError(t, err)
+		require.True(t, race)
+		require.Equal(t, map[string]bool{"go-version": true, "race": true}, fc.cellArgs)
+
+		cmd = newCmd()
+		require.NoError(t, cmd.Flags().Set("race", "false"))
+		require.ErrorContains(t, fc.setMatrixArgs(cmd, fn), "--race is set both as a flag and with --matrix")
+	})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/require"
+
+	"dagger.io/dagger"
+)
+
+func testObjectTypeDef(name string) *modTypeDef {
+	return &modTypeDef{
+		Kind:     dagger.TypeDefKindObjectKind,
+		AsObject: &modObject{Name: name},
+	}
+}
+
+// testFunctionTreeModule returns a module where Test.build returns a
+// Builder, which can chain with-source to itself and return a core
+// Container.
+func testFunctionTreeModule() *moduleDef {
+	str := &modTypeDef{Kind: dagger.TypeDefKindStringKind}
+	builder := &modTypeDef{
+		Kind: dagger.TypeDefKindObjectKind,
+		AsObject: &modObject{
+			Name:             "Builder",
+			SourceModuleName: "test",
+			Functions: []*modFunction{
+				{
+					Name:       "withSource",
+					ReturnType: testObjectTypeDef("Builder"),
+					Args: []*modFunctionArg{
+						{Name: "path", TypeDef: str},
+					},
+				},
+				{
+					Name:       "container",
+					ReturnType: testObjectTypeDef("Container"),
+				},
+			},
+		},
+	}
+	main := &modTypeDef{
+		Kind: dagger.TypeDefKindObjectKind,
+		AsObject: &modObject{
+			Name:             "Test",
+			SourceModuleName: "test",
+			Constructor: &modFunction{
+				Args: []*modFunctionArg{
+					{Name: "registryUser", TypeDef: str},
+				},
+			},
+			Functions: []*modFunction{
+				{
+					Name:        "build",
+					Description: "Build the project\n\nMore details.",
+					ReturnType:  testObjectTypeDef("Builder"),
+					Args: []*modFunctionArg{
+						{Name: "target", TypeDef: str, DefaultValue: `"linux"`},
+					},
+				},
+				{
+					Name:       "version",
+					ReturnType: str,
+				},
+			},
+		},
+	}
+	container := &modTypeDef{
+		Kind: dagger.TypeDefKindObjectKind,
+		AsObject: &modObject{
+			Name: "Container",
+			Functions: []*modFunction{
+				{Name: "stdout", ReturnType: str},
+			},
+		},
+	}
+	return &moduleDef{
+		Name:       "test",
+		MainObject: main,
+		Objects:    []*modTypeDef{main, builder, container},
+	}
+}
+
+func TestFunctionTree(t *testing.T) {
+	mod := testFunctionTreeModule()
+
+	var sb strings.Builder
+	err := functionTreeRun(mod, mod.MainObject.AsFunctionProvider(), &sb)
+	require.NoError(t, err)
+	require.Equal(t, `Test
+├── build [options] → Builder
+│   ├── container → Container
+│   └── with-source --path string → Builder
+└── version → string
+`, sb.String())
+}
+
+func TestCallCommandLine(t *testing.T) {
+	mod := testFunctionTreeModule()
+	t.Setenv("DAGGER_MODULE", "github.com/example/test@main")
+
+	build, err := mod.GetObjectFunction("Test", "build")
+	require.NoError(t, err)
+	withSource, err := mod.GetObjectFunction("Builder", "with-source")
+	require.NoError(t, err)
+
+	require.Equal(t,
+		"dagger call -m github.com/example/test@main --registry-user=REGISTRY_USER build with-source --path=PATH",
+		callCommandLine(mod, []*modFunction{build, withSource}),
+	)
+}
+
+func TestFunctionBrowser(t *testing.T) {
+	mod := testFunctionTreeModule()
+	t.Setenv("DAGGER_MODULE", "test")
+
+	build, err := mod.GetObjectFunction("Test", "build")
+	require.NoError(t, err)
+
+	var out strings.Builder
+	b := newFunctionBrowser(mod, []*modFunction{build}, termenv.NewOutput(&out))
+	require.Len(t, b.frames, 2)
+	require.Equal(t, "container", b.selected().CmdName())
+
+	b.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	require.Contains(t, b.View(), "Returns")
+
+	key := func(s string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+		switch s {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		b.Update(msg)
+	}
+
+	// go into the Container returned by container, and back
+	key("enter")
+	require.Len(t, b.frames, 3)
+	require.Equal(t, "Container", b.frame().fp.ProviderName())
+	key("esc")
+	require.Len(t, b.frames, 2)
+
+	key("j")
+	key("c")
+	require.Equal(t, "dagger call -m test --registry-user=REGISTRY_USER build with-source --path=PATH", b.copied)
+	require.Contains(t, out.String(), "\x1b]52;c;")
+
+	// a function that returns a scalar can't be entered
+	key("esc")
+	key("j")
+	key("enter")
+	require.Len(t, b.frames, 1)
+	require.Equal(t, "string has no functions", b.status)
+}
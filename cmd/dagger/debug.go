@@ -1,16 +1,179 @@
 package main
 
 import (
+	"context"
 	"expvar"
+	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"path/filepath"
 	"runtime"
+	"strings"
 
+	"github.com/spf13/cobra"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"golang.org/x/net/trace"
+
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/dagql/dagui"
+	"github.com/dagger/dagger/engine/clientdb"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Debug the Dagger API",
+}
+
+var (
+	graphFormat   string
+	graphClientDB string
 )
 
+var debugGraphCmd = &cobra.Command{
+	Use:   "graph [options] [ID]",
+	Short: "Export the call graph of an ID",
+	Long: `Export the call graph of an ID as Graphviz DOT, Mermaid or JSON.
+
+The ID is read from standard input if not given as an argument.
+
+Each call appears once in the graph, even if the ID refers to it several times.
+With --client-db, calls are annotated with their duration and whether they
+were cached, from the telemetry recorded by the engine for a client.
+`,
+	Example: `dagger query <<< '{container{from(address:"alpine"){id}}}' | jq -r .container.from.id | dagger debug graph --format mermaid`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := readDebugID(cmd, args)
+		if err != nil {
+			return err
+		}
+		dag, err := id.ToProto()
+		if err != nil {
+			return err
+		}
+		var db *dagui.DB
+		if graphClientDB != "" {
+			db, err = loadClientDB(cmd.Context(), graphClientDB)
+			if err != nil {
+				return err
+			}
+		}
+		graph := dagui.NewCallGraph(dag, db)
+		switch graphFormat {
+		case "dot":
+			return graph.WriteDOT(cmd.OutOrStdout())
+		case "mermaid":
+			return graph.WriteMermaid(cmd.OutOrStdout())
+		case "json":
+			return graph.WriteJSON(cmd.OutOrStdout())
+		default:
+			return fmt.Errorf("unknown format %q: must be one of dot, mermaid or json", graphFormat)
+		}
+	},
+}
+
+var debugWhyCmd = &cobra.Command{
+	Use:   "why [options] <ID> <ID>",
+	Short: "Explain why two IDs differ",
+	Long: `Explain why two IDs differ, such as why a call expected to be cached ran
+again.
+
+Prints the earliest call where the IDs diverge, and the field, argument, module
+or content digest that differs. Sensitive arguments are not compared, since
+they don't affect caching.
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		left, err := readDebugID(cmd, args[:1])
+		if err != nil {
+			return err
+		}
+		right, err := readDebugID(cmd, args[1:])
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		diff := call.DiffIDs(left, right)
+		if diff == nil {
+			fmt.Fprintf(out, "The IDs are the same: %s\n", left.Digest())
+			return nil
+		}
+		fmt.Fprintf(out, "The IDs first differ at %s\n\n", diff.Left.Path())
+		if diff.Arg != "" {
+			fmt.Fprintf(out, "Argument %s differs:\n", diff.Arg)
+		} else {
+			fmt.Fprintf(out, "The %s differs:\n", diff.Kind)
+		}
+		fmt.Fprintf(out, "  - %s\n", diff.LeftValue)
+		fmt.Fprintf(out, "  + %s\n", diff.RightValue)
+		fmt.Fprintf(out, "\nDigests: %s != %s\n", diff.Left.Digest(), diff.Right.Digest())
+		return nil
+	},
+}
+
+func init() {
+	debugGraphCmd.Flags().StringVar(&graphFormat, "format", "dot", "Output format: dot, mermaid or json")
+	debugGraphCmd.Flags().StringVar(&graphClientDB, "client-db", "", "Annotate calls with the telemetry in the given client database")
+	debugCmd.AddCommand(debugGraphCmd, debugWhyCmd)
+}
+
+// readDebugID decodes the ID given as an argument, or read from stdin.
+func readDebugID(cmd *cobra.Command, args []string) (*call.ID, error) {
+	var enc string
+	if len(args) > 0 && args[0] != "-" {
+		enc = args[0]
+	} else {
+		bytes, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return nil, err
+		}
+		enc = string(bytes)
+	}
+	var id call.ID
+	if err := id.Decode(strings.TrimSpace(enc)); err != nil {
+		return nil, fmt.Errorf("decode ID: %w", err)
+	}
+	return &id, nil
+}
+
+// loadClientDB loads the spans of the client database at the given path.
+func loadClientDB(ctx context.Context, path string) (*dagui.DB, error) {
+	dbs := clientdb.NewDBs(filepath.Dir(path))
+	sqlDB, err := dbs.Open(strings.TrimSuffix(filepath.Base(path), ".db"))
+	if err != nil {
+		return nil, err
+	}
+	defer sqlDB.Close()
+
+	const batchSize = 1000
+	db := dagui.NewDB()
+	q := clientdb.New(sqlDB)
+	var since int64
+	for {
+		spans, err := q.SelectSpansSince(ctx, clientdb.SelectSpansSinceParams{
+			ID:    since,
+			Limit: batchSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("select spans: %w", err)
+		}
+		if len(spans) == 0 {
+			return db, nil
+		}
+		roSpans := make([]sdktrace.ReadOnlySpan, len(spans))
+		for i, span := range spans {
+			roSpans[i] = span.ReadOnly()
+			since = span.ID
+		}
+		if err := db.ExportSpans(ctx, roSpans); err != nil {
+			return nil, err
+		}
+	}
+}
+
 func setupDebugHandlers(addr string) error {
 	m := http.NewServeMux()
 	m.Handle("/debug/vars", expvar.Handler())
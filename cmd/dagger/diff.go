@@ -0,0 +1,491 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"dagger.io/dagger"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"mvdan.cc/sh/v3/interp"
+
+	"github.com/dagger/dagger/dagql/idtui"
+	"github.com/dagger/dagger/engine/client"
+)
+
+// maxTextDiffSize is the size above which a changed file isn't shown as a
+// unified diff
+const maxTextDiffSize = 1 << 20
+
+var diffJSON bool
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [options] <expression> <expression>",
+	Short: "Compare two directories or containers",
+	Long: `Compare the results of two Dagger Shell expressions that return a Directory or a Container.
+
+Shows the files that were added, removed or changed, with a unified diff for
+changed text files. Containers are also compared by their config, environment
+variables and labels.`,
+	Example: `dagger diff 'host | directory ./before' 'host | directory ./after'
+dagger diff 'container | from alpine:3.21' 'container | from alpine:3.22'
+dagger diff --json 'build --source=.' 'build --source=https://github.com/dagger/hello-dagger'`,
+	GroupID: execGroup.ID,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SetContext(idtui.WithPrintTraceLink(cmd.Context(), true))
+		return withEngine(cmd.Context(), client.Params{}, func(ctx context.Context, engineClient *client.Client) error {
+			h := &shellCallHandler{
+				dag:  engineClient.Dagger(),
+				mode: modeShell,
+			}
+			if err := h.Initialize(ctx); err != nil {
+				return err
+			}
+			code := ".diff"
+			if diffJSON {
+				code += " --json"
+			}
+			code += fmt.Sprintf(" $(%s) $(%s)", args[0], args[1])
+			return h.run(ctx, strings.NewReader(code), "")
+		})
+	},
+}
+
+func init() {
+	diffCmd.Flags().BoolVarP(&diffJSON, "json", "j", false, "Present the differences as JSON")
+}
+
+// Diff compares two Directory or Container objects, given as state tokens
+func (h *shellCallHandler) Diff(ctx context.Context, args []string) error {
+	var asJSON bool
+	if len(args) > 0 && (args[0] == "--json" || args[0] == "-j") {
+		asJSON = true
+		args = args[1:]
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("requires exactly two objects to compare")
+	}
+
+	var types, ids [2]string
+	for i, arg := range args {
+		key := GetStateKey(arg)
+		if key == "" {
+			return fmt.Errorf("argument %d is not an object, use a command substitution instead: $(...)", i+1)
+		}
+		st, err := h.state.Load(key)
+		if err != nil {
+			return err
+		}
+		types[i], ids[i], err = h.objectID(ctx, st)
+		if err != nil {
+			return err
+		}
+	}
+	if types[0] != types[1] {
+		return fmt.Errorf("can't compare %s with %s", types[0], types[1])
+	}
+
+	var result interface{ WriteText(io.Writer) error }
+	var err error
+	switch types[0] {
+	case Directory:
+		result, err = diffDirectories(ctx,
+			h.dag.LoadDirectoryFromID(dagger.DirectoryID(ids[0])),
+			h.dag.LoadDirectoryFromID(dagger.DirectoryID(ids[1])),
+		)
+	case Container:
+		result, err = diffContainers(ctx, h.dag, ids[0], ids[1])
+	default:
+		return fmt.Errorf("can't compare %s, expected a Directory or a Container", types[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	w := interp.HandlerCtx(ctx).Stdout
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(result)
+	}
+	return result.WriteText(w)
+}
+
+// dirDiff is the difference between two directories
+type dirDiff struct {
+	Added   []string   `json:"added"`
+	Removed []string   `json:"removed"`
+	Changed []fileDiff `json:"changed"`
+}
+
+type fileDiff struct {
+	Path string `json:"path"`
+	// Diff is the unified diff, if both versions are text files
+	Diff string `json:"diff,omitempty"`
+}
+
+func (d *dirDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// WriteText writes the list of added (A), removed (D) and changed (M) files,
+// followed by the unified diffs of changed text files.
+func (d *dirDiff) WriteText(w io.Writer) error {
+	status := make(map[string]string)
+	for _, p := range d.Added {
+		status[p] = "A"
+	}
+	for _, p := range d.Removed {
+		status[p] = "D"
+	}
+	for _, f := range d.Changed {
+		status[f.Path] = "M"
+	}
+	for _, p := range slices.Sorted(maps.Keys(status)) {
+		if _, err := fmt.Fprintf(w, "%s %s\n", status[p], p); err != nil {
+			return err
+		}
+	}
+	for _, f := range d.Changed {
+		if f.Diff == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n%s", f.Diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffDirectories compares the files in two directories.
+//
+// Directory.diff narrows down the files that may have changed, which are then
+// compared by their content digest.
+func diffDirectories(ctx context.Context, a, b *dagger.Directory) (*dirDiff, error) {
+	var pathsA, pathsB, newer []string
+	eg, gctx := errgroup.WithContext(ctx)
+	eg.Go(func() (err error) {
+		pathsA, err = a.Glob(gctx, "**/*")
+		return err
+	})
+	eg.Go(func() (err error) {
+		pathsB, err = b.Glob(gctx, "**/*")
+		return err
+	})
+	eg.Go(func() (err error) {
+		newer, err = a.Diff(b).Glob(gctx, "**/*")
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	// directories are listed with a trailing slash
+	isFile := func(p string) bool { return !strings.HasSuffix(p, "/") }
+	inA := make(map[string]bool)
+	for _, p := range pathsA {
+		inA[p] = true
+	}
+	inB := make(map[string]bool)
+	for _, p := range pathsB {
+		inB[p] = true
+	}
+
+	d := &dirDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []fileDiff{},
+	}
+	for _, p := range pathsA {
+		if isFile(p) && !inB[p] {
+			d.Removed = append(d.Removed, p)
+		}
+	}
+
+	var mu sync.Mutex
+	eg, gctx = errgroup.WithContext(ctx)
+	eg.SetLimit(16)
+	for _, p := range newer {
+		if !isFile(p) {
+			continue
+		}
+		if !inA[p] {
+			d.Added = append(d.Added, p)
+			continue
+		}
+		eg.Go(func() error {
+			f, err := diffFile(gctx, p, a.File(p), b.File(p))
+			if err != nil || f == nil {
+				return err
+			}
+			mu.Lock()
+			d.Changed = append(d.Changed, *f)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+	slices.SortFunc(d.Changed, func(x, y fileDiff) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+	return d, nil
+}
+
+// diffFile compares two versions of a file, returning nil if their content
+// is the same
+func diffFile(ctx context.Context, p string, a, b *dagger.File) (*fileDiff, error) {
+	opts := dagger.FileDigestOpts{ExcludeMetadata: true}
+	digestA, err := a.Digest(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	digestB, err := b.Digest(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if digestA == digestB {
+		return nil, nil
+	}
+
+	f := &fileDiff{Path: p}
+	contents := make([]string, 2)
+	for i, file := range []*dagger.File{a, b} {
+		size, err := file.Size(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxTextDiffSize {
+			return f, nil
+		}
+		contents[i], err = file.Contents(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !isText(contents[i]) {
+			return f, nil
+		}
+	}
+	f.Diff, err = unifiedDiff(p, contents[0], contents[1])
+	return f, err
+}
+
+func isText(s string) bool {
+	return utf8.ValidString(s) && !strings.ContainsRune(s, 0)
+}
+
+func unifiedDiff(p, a, b string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: "a/" + p,
+		ToFile:   "b/" + p,
+		Context:  3,
+	})
+}
+
+// splitLines splits s into lines that all end with a newline, as expected by
+// difflib
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// containerDiff is the difference between two containers
+type containerDiff struct {
+	Config []valueChange `json:"config"`
+	Env    []valueChange `json:"env"`
+	Labels []valueChange `json:"labels"`
+	Files  *dirDiff      `json:"files"`
+}
+
+// valueChange is a named value that was added (Old is nil), removed (New is
+// nil) or changed
+type valueChange struct {
+	Name string  `json:"name"`
+	Old  *string `json:"old"`
+	New  *string `json:"new"`
+}
+
+// WriteText writes each section with differences, with removed values
+// prefixed by "-" and added values by "+".
+func (d *containerDiff) WriteText(w io.Writer) error {
+	var sections []string
+	for _, section := range []struct {
+		title   string
+		changes []valueChange
+	}{
+		{"Config", d.Config},
+		{"Env", d.Env},
+		{"Labels", d.Labels},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString(section.title + ":\n")
+		for _, c := range section.changes {
+			if c.Old != nil {
+				fmt.Fprintf(&sb, "- %s=%s\n", c.Name, *c.Old)
+			}
+			if c.New != nil {
+				fmt.Fprintf(&sb, "+ %s=%s\n", c.Name, *c.New)
+			}
+		}
+		sections = append(sections, sb.String())
+	}
+	if !d.Files.IsEmpty() {
+		var sb strings.Builder
+		sb.WriteString("Files:\n")
+		if err := d.Files.WriteText(&sb); err != nil {
+			return err
+		}
+		sections = append(sections, sb.String())
+	}
+	_, err := io.WriteString(w, strings.Join(sections, "\n"))
+	return err
+}
+
+// diffValues compares two sets of named values
+func diffValues(a, b map[string]string) []valueChange {
+	changes := []valueChange{}
+	names := slices.Sorted(maps.Keys(a))
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		old, inA := a[name]
+		val, inB := b[name]
+		c := valueChange{Name: name}
+		if inA {
+			c.Old = &old
+		}
+		if inB {
+			c.New = &val
+		}
+		if inA && inB && old == val {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+const containerConfigQuery = `
+query ContainerConfig($id: ContainerID!) {
+  container: loadContainerFromID(id: $id) {
+    platform
+    entrypoint
+    defaultArgs
+    workdir
+    user
+    envVariables { name value }
+    labels { name value }
+  }
+}
+`
+
+type containerConfig struct {
+	Platform     string
+	Entrypoint   []string
+	DefaultArgs  []string
+	Workdir      string
+	User         string
+	EnvVariables []struct{ Name, Value string }
+	Labels       []struct{ Name, Value string }
+}
+
+func (c containerConfig) config() map[string]string {
+	list := func(l []string) string {
+		b, _ := json.Marshal(l)
+		return string(b)
+	}
+	return map[string]string{
+		"platform":    c.Platform,
+		"entrypoint":  list(c.Entrypoint),
+		"defaultArgs": list(c.DefaultArgs),
+		"workdir":     c.Workdir,
+		"user":        c.User,
+	}
+}
+
+func (c containerConfig) env() map[string]string {
+	m := make(map[string]string, len(c.EnvVariables))
+	for _, v := range c.EnvVariables {
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+func (c containerConfig) labels() map[string]string {
+	m := make(map[string]string, len(c.Labels))
+	for _, v := range c.Labels {
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+// diffContainers compares the config and the root filesystem of two
+// containers
+func diffContainers(ctx context.Context, dag *dagger.Client, idA, idB string) (*containerDiff, error) {
+	var configs [2]containerConfig
+	var files *dirDiff
+
+	eg, gctx := errgroup.WithContext(ctx)
+	for i, id := range []string{idA, idB} {
+		eg.Go(func() error {
+			var res struct {
+				Container containerConfig
+			}
+			err := dag.Do(gctx, &dagger.Request{
+				Query:     containerConfigQuery,
+				Variables: map[string]any{"id": id},
+			}, &dagger.Response{
+				Data: &res,
+			})
+			if err != nil {
+				return fmt.Errorf("query container config: %w", err)
+			}
+			configs[i] = res.Container
+			return nil
+		})
+	}
+	eg.Go(func() (err error) {
+		files, err = diffDirectories(gctx,
+			dag.LoadContainerFromID(dagger.ContainerID(idA)).Rootfs(),
+			dag.LoadContainerFromID(dagger.ContainerID(idB)).Rootfs(),
+		)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return &containerDiff{
+		Config: diffValues(configs[0].config(), configs[1].config()),
+		Env:    diffValues(configs[0].env(), configs[1].env()),
+		Labels: diffValues(configs[0].labels(), configs[1].labels()),
+		Files:  files,
+	}, nil
+}
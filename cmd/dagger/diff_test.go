@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffValues(t *testing.T) {
+	t.Parallel()
+
+	changes := diffValues(
+		map[string]string{"PATH": "/bin", "FOO": "1", "OLD": "x"},
+		map[string]string{"PATH": "/bin", "FOO": "2", "NEW": "y"},
+	)
+	var lines []string
+	for _, c := range changes {
+		line := c.Name + ":"
+		if c.Old != nil {
+			line += " -" + *c.Old
+		}
+		if c.New != nil {
+			line += " +" + *c.New
+		}
+		lines = append(lines, line)
+	}
+	require.Equal(t, []string{"FOO: -1 +2", "NEW: +y", "OLD: -x"}, lines)
+	require.Empty(t, diffValues(map[string]string{"A": "1"}, map[string]string{"A": "1"}))
+}
+
+func TestDirDiffText(t *testing.T) {
+	t.Parallel()
+
+	patch, err := unifiedDiff("main.go", "package main\n", "package app\n")
+	require.NoError(t, err)
+
+	d := &dirDiff{
+		Added:   []string{"b.txt"},
+		Removed: []string{"c/d.txt"},
+		Changed: []fileDiff{{Path: "a.bin"}, {Path: "main.go", Diff: patch}},
+	}
+	var sb strings.Builder
+	require.NoError(t, d.WriteText(&sb))
+	require.Equal(t, `M a.bin
+A b.txt
+D c/d.txt
+M main.go
+
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package main
++package app
+`, sb.String())
+}
@@ -105,6 +105,8 @@ func withEngine(
 
 		params.DisableHostRW = disableHostRW
 		params.AllowedLLMModules = allowedLLMModules
+		params.MaxQueryDepth = queryMaxDepth
+		params.MaxQueryCost = queryMaxCost
 
 		params.CloudURLCallback = Frontend.SetCloudURL
 
@@ -153,6 +155,9 @@ func initEngineTelemetry(ctx context.Context) (context.Context, func(error)) {
 		telemetryCfg.LiveLogExporters = append(telemetryCfg.LiveLogExporters, logs)
 		telemetryCfg.LiveMetricExporters = append(telemetryCfg.LiveMetricExporters, metrics)
 	}
+	if junitReport != nil {
+		telemetryCfg.LiveTraceExporters = append(telemetryCfg.LiveTraceExporters, junitReport)
+	}
 	ctx = telemetry.Init(ctx, telemetryCfg)
 
 	// Set the full command string as the name of the root span.
@@ -341,6 +341,7 @@ func (v *directoryValue) Get(ctx context.Context, dag *dagger.Client, modSrc *da
 
 	return dag.Host().Directory(path, dagger.HostDirectoryOpts{
 		Exclude: modArg.Ignore,
+		NoCache: hostReload(ctx),
 	}).Sync(ctx)
 }
 
@@ -430,7 +431,9 @@ func (v *fileValue) Get(ctx context.Context, dag *dagger.Client, _ *dagger.Modul
 		return nil, err
 	}
 
-	return dag.Host().File(path).Sync(ctx)
+	return dag.Host().File(path, dagger.HostFileOpts{
+		NoCache: hostReload(ctx),
+	}).Sync(ctx)
 }
 
 // secretValue is a pflag.Value that builds a dagger.Secret from a name and a
@@ -965,11 +968,9 @@ func (r *modFunctionArg) AddFlag(flags *pflag.FlagSet) error {
 			return nil
 		}
 
-		// TODO: default to JSON?
-		return &UnsupportedFlagError{
-			Name: name,
-			Type: fmt.Sprintf("%q object", objName),
-		}
+		// an ID, or the constructor's arguments as JSON
+		flags.Var(newJSONValue(r.TypeDef, r.DefaultValue), name, usage)
+		return nil
 
 	case dagger.TypeDefKindInputKind:
 		inputName := r.TypeDef.AsInput.Name
@@ -979,11 +980,8 @@ func (r *modFunctionArg) AddFlag(flags *pflag.FlagSet) error {
 			return nil
 		}
 
-		// TODO: default to JSON?
-		return &UnsupportedFlagError{
-			Name: name,
-			Type: fmt.Sprintf("%q input", inputName),
-		}
+		flags.Var(newJSONValue(r.TypeDef, r.DefaultValue), name, usage)
+		return nil
 
 	case dagger.TypeDefKindListKind:
 		elementType := r.TypeDef.AsList.ElementTypeDef
@@ -1055,11 +1053,8 @@ func (r *modFunctionArg) AddFlag(flags *pflag.FlagSet) error {
 				return nil
 			}
 
-			// TODO: default to JSON?
-			return &UnsupportedFlagError{
-				Name: name,
-				Type: fmt.Sprintf("list of %q objects", objName),
-			}
+			flags.Var(newJSONValue(r.TypeDef, r.DefaultValue), name, usage)
+			return nil
 
 		case dagger.TypeDefKindInputKind:
 			inputName := elementType.AsInput.Name
@@ -1073,17 +1068,12 @@ func (r *modFunctionArg) AddFlag(flags *pflag.FlagSet) error {
 				return nil
 			}
 
-			// TODO: default to JSON?
-			return &UnsupportedFlagError{
-				Name: name,
-				Type: fmt.Sprintf("list of %q inputs", inputName),
-			}
+			flags.Var(newJSONValue(r.TypeDef, r.DefaultValue), name, usage)
+			return nil
 
 		case dagger.TypeDefKindListKind:
-			return &UnsupportedFlagError{
-				Name: name,
-				Type: "list of lists",
-			}
+			flags.Var(newJSONValue(r.TypeDef, r.DefaultValue), name, usage)
+			return nil
 		}
 	}
 
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"dagger.io/dagger"
+	"github.com/dagger/dagger/engine/client/secretprovider"
+)
+
+// completionTimeout limits how long to wait for the engine when completing
+// a value, so that a slow remote doesn't block the prompt.
+const completionTimeout = 5 * time.Second
+
+// secretSchemes are the secret providers supported in Secret arguments.
+var secretSchemes = func() []string {
+	var schemes []string
+	for _, scheme := range secretprovider.Schemes() {
+		schemes = append(schemes, scheme+"://")
+	}
+	return schemes
+}()
+
+// argCompleter suggests values for function arguments, asking the engine for
+// the ones that depend on it.
+//
+// Results from the engine are cached, since the same completion is usually
+// requested several times while typing a value.
+type argCompleter struct {
+	dag *dagger.Client
+
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+func newArgCompleter(dag *dagger.Client) *argCompleter {
+	return &argCompleter{
+		dag:   dag,
+		cache: map[string][]string{},
+	}
+}
+
+// Complete returns the possible values for an argument that start with
+// prefix. For lists, it completes a single element.
+func (c *argCompleter) Complete(ctx context.Context, arg *modFunctionArg, prefix string) []string {
+	typeDef := arg.TypeDef
+	if typeDef.AsList != nil {
+		typeDef = typeDef.AsList.ElementTypeDef
+	}
+
+	var values []string
+	switch typeDef.Kind {
+	case dagger.TypeDefKindBooleanKind:
+		values = []string{"true", "false"}
+	case dagger.TypeDefKindEnumKind:
+		values = typeDef.AsEnum.ValueNames()
+	case dagger.TypeDefKindScalarKind:
+		if typeDef.AsScalar.Name == Platform {
+			values = c.platforms(ctx)
+		}
+	case dagger.TypeDefKindObjectKind:
+		switch typeDef.AsObject.Name {
+		case Directory, GitRepository:
+			values = hostPathCompletions(prefix, true)
+		case File:
+			values = hostPathCompletions(prefix, false)
+		case Secret:
+			values = secretCompletions(prefix)
+		case GitRef:
+			values = c.gitRefs(ctx, prefix)
+		}
+	}
+
+	matches := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) && !slices.Contains(matches, v) {
+			matches = append(matches, v)
+		}
+	}
+	return matches
+}
+
+// cached returns the values for key, calling fn with a timeout if they
+// haven't been loaded yet. Errors aren't cached so they can be retried.
+func (c *argCompleter) cached(ctx context.Context, key string, fn func(context.Context) ([]string, error)) []string {
+	c.mu.Lock()
+	values, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok {
+		return values
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+	values, err := fn(ctx)
+	if err != nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	c.cache[key] = values
+	c.mu.Unlock()
+	return values
+}
+
+// platforms returns the engine's default platform followed by the other
+// platforms it can run containers for
+func (c *argCompleter) platforms(ctx context.Context) []string {
+	values := []string{"current"}
+	return append(values, c.cached(ctx, "platform", func(ctx context.Context) ([]string, error) {
+		platform, err := c.dag.DefaultPlatform(ctx)
+		if err != nil {
+			return nil, err
+		}
+		enabled, err := c.dag.Engine().Platforms(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values := []string{string(platform)}
+		for _, p := range enabled {
+			if p != platform {
+				values = append(values, string(p))
+			}
+		}
+		return values, nil
+	})...)
+}
+
+// gitRefs completes a `<repository>#<ref>` address with the repository's
+// branches and tags, or the repository itself with local directories.
+func (c *argCompleter) gitRefs(ctx context.Context, prefix string) []string {
+	address, _, ok := strings.Cut(prefix, "#")
+	if !ok {
+		return hostPathCompletions(prefix, true)
+	}
+	refs := c.cached(ctx, "git:"+address, func(ctx context.Context) ([]string, error) {
+		if gitURL, err := parseGitURL(address); err == nil {
+			repo := c.dag.Git(gitURL.Remote())
+			branches, err := repo.Branches(ctx)
+			if err != nil {
+				return nil, err
+			}
+			tags, err := repo.Tags(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return append(branches, tags...), nil
+		}
+		path, err := getLocalPath(address)
+		if err != nil {
+			return nil, err
+		}
+		return localGitRefs(ctx, path)
+	})
+	values := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		values = append(values, address+"#"+ref)
+	}
+	return values
+}
+
+// localGitRefs returns the branches and tags of a local repository, read
+// with git on the host rather than by uploading the directory to the engine.
+func localGitRefs(ctx context.Context, path string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", path, "for-each-ref",
+		"--format=%(refname:lstrip=2)", "refs/heads", "refs/tags").Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// secretCompletions completes the scheme of a secret provider, and the
+// values that can be found locally for the `env://` and `file://` providers
+func secretCompletions(prefix string) []string {
+	switch {
+	case strings.HasPrefix(prefix, "env://"):
+		var values []string
+		for _, env := range os.Environ() {
+			name, _, _ := strings.Cut(env, "=")
+			values = append(values, "env://"+name)
+		}
+		slices.Sort(values)
+		return values
+	case strings.HasPrefix(prefix, "file://"):
+		var values []string
+		for _, path := range hostPathCompletions(strings.TrimPrefix(prefix, "file://"), false) {
+			values = append(values, "file://"+path)
+		}
+		return values
+	}
+	return secretSchemes
+}
+
+// hostPathCompletions returns the local paths in the directory of prefix,
+// with a trailing slash for directories so that they can be completed
+// further. Hidden files are only included if prefix is for one.
+func hostPathCompletions(prefix string, dirsOnly bool) []string {
+	dir, base := filepath.Split(prefix)
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	if strings.HasPrefix(readDir, "~") {
+		if path, err := getLocalPath(readDir); err == nil {
+			readDir = path
+		}
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+	values := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(readDir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		switch {
+		case isDir:
+			values = append(values, dir+name+"/")
+		case !dirsOnly:
+			values = append(values, dir+name)
+		}
+	}
+	return values
+}
+
+// completeFunctionChain returns the completions for the last word of a
+// `dagger call` command line, after following the chain of functions in
+// args: function names, their argument flags, or the value of an argument.
+//
+// The values of flags in cmdFlags, which belong to the command itself, are
+// skipped.
+func completeFunctionChain(
+	ctx context.Context,
+	mod *moduleDef,
+	values *argCompleter,
+	cmdFlags *pflag.FlagSet,
+	args []string,
+	toComplete string,
+) ([]cobra.Completion, cobra.ShellCompDirective) {
+	fn := mod.MainObject.AsObject.Constructor
+	fp := mod.MainObject.AsFunctionProvider()
+	if fn != nil {
+		mod.LoadFunctionTypeDefs(fn)
+	}
+
+	// valueArg is set when the last word is a flag that expects a value
+	var valueArg *modFunctionArg
+	for i := 0; i < len(args); i++ {
+		word := args[i]
+		valueArg = nil
+		if word == "--" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(word, "-"); ok {
+			name = strings.TrimPrefix(name, "-")
+			if strings.Contains(name, "=") {
+				continue
+			}
+			var flag *pflag.Flag
+			if len(name) == 1 {
+				flag = cmdFlags.ShorthandLookup(name)
+			} else {
+				flag = cmdFlags.Lookup(name)
+			}
+			if flag != nil {
+				if flag.NoOptDefVal == "" {
+					i++
+				}
+				continue
+			}
+			if fn == nil {
+				continue
+			}
+			arg, err := fn.GetArg(cliName(name))
+			if err != nil || arg.TypeDef.Kind == dagger.TypeDefKindBooleanKind {
+				continue
+			}
+			if i == len(args)-1 {
+				valueArg = arg
+			}
+			i++
+			continue
+		}
+		if fp == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		next, err := GetSupportedFunction(mod, fp, word)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		fn = next
+		fp = mod.GetFunctionProvider(next.ReturnType.Name())
+	}
+
+	// `--flag=value`
+	var flagPrefix string
+	if valueArg == nil && fn != nil && strings.HasPrefix(toComplete, "--") {
+		if name, value, ok := strings.Cut(strings.TrimPrefix(toComplete, "--"), "="); ok {
+			if arg, err := fn.GetArg(cliName(name)); err == nil {
+				valueArg = arg
+				flagPrefix = "--" + name + "="
+				toComplete = value
+			}
+		}
+	}
+
+	var completions []cobra.Completion
+	directive := cobra.ShellCompDirectiveNoFileComp
+	switch {
+	case valueArg != nil:
+		for _, value := range values.Complete(ctx, valueArg, toComplete) {
+			if strings.HasSuffix(value, "/") {
+				// let the path be completed further
+				directive |= cobra.ShellCompDirectiveNoSpace
+			}
+			completions = append(completions, flagPrefix+value)
+		}
+		if usage := valueArg.JSONUsage(); usage != "" {
+			// shown by shells that support it, since JSON can't be completed
+			completions = cobra.AppendActiveHelp(completions, usage)
+		}
+	case strings.HasPrefix(toComplete, "-"):
+		if fn == nil {
+			break
+		}
+		for _, arg := range fn.SupportedArgs() {
+			flag := "--" + arg.FlagName()
+			if strings.HasPrefix(flag, toComplete) {
+				desc := arg.Short()
+				if usage := arg.JSONUsage(); usage != "" {
+					desc = strings.TrimSpace(desc + " (" + usage + ")")
+				}
+				completions = append(completions, cobra.CompletionWithDesc(flag, desc))
+			}
+		}
+	case fp != nil:
+		fns, _ := GetSupportedFunctions(fp)
+		sort.Slice(fns, func(i, j int) bool {
+			return fns[i].Name < fns[j].Name
+		})
+		for _, fn := range fns {
+			if strings.HasPrefix(fn.CmdName(), toComplete) {
+				completions = append(completions, cobra.CompletionWithDesc(fn.CmdName(), fn.Short()))
+			}
+		}
+	}
+	return completions, directive
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"dagger.io/dagger"
+)
+
+func TestHostPathCompletions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "src"), 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), nil, 0o600))
+	prefix := dir + string(filepath.Separator)
+
+	require.ElementsMatch(t,
+		[]string{prefix + "src/", prefix + "main.go"},
+		hostPathCompletions(prefix, false),
+	)
+	require.Equal(t, []string{prefix + "src/"}, hostPathCompletions(prefix, true))
+	require.Contains(t, hostPathCompletions(prefix+".", true), prefix+".git/")
+	require.Empty(t, hostPathCompletions(filepath.Join(dir, "missing")+"/", false))
+}
+
+func TestSecretCompletions(t *testing.T) {
+	t.Setenv("TEST_COMPLETION_TOKEN", "secret")
+
+	require.Equal(t, secretSchemes, secretCompletions("o"))
+	require.Subset(t, secretSchemes, []string{"env://", "file://", "cmd://", "op://", "vault://", "libsecret://"})
+	require.Contains(t, secretCompletions("env://TEST_"), "env://TEST_COMPLETION_TOKEN")
+}
+
+func TestLocalGitRefs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@dagger.io"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "--initial-branch=main")
+	git("commit", "--allow-empty", "-m", "init")
+	git("branch", "feature/x")
+	git("tag", "v1.0.0")
+
+	refs, err := localGitRefs(context.Background(), dir)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"feature/x", "main", "v1.0.0"}, refs)
+
+	_, err = localGitRefs(context.Background(), filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+// testCompletionModule returns a module with a `deploy` function that has
+// arguments of different types, after a `--token` constructor argument.
+func testCompletionModule() *moduleDef {
+	str := &modTypeDef{Kind: dagger.TypeDefKindStringKind}
+	env := &modTypeDef{
+		Kind: dagger.TypeDefKindEnumKind,
+		AsEnum: &modEnum{
+			Name: "Env",
+			Members: []*modEnumMember{
+				{Name: "STAGING"},
+				{Name: "PRODUCTION"},
+			},
+		},
+	}
+	main := &modTypeDef{
+		Kind: dagger.TypeDefKindObjectKind,
+		AsObject: &modObject{
+			Name:             "Test",
+			SourceModuleName: "test",
+			Constructor: &modFunction{
+				ReturnType: testObjectTypeDef("Test"),
+				Args: []*modFunctionArg{
+					{Name: "token", TypeDef: str},
+				},
+			},
+			Functions: []*modFunction{
+				{
+					Name:        "deploy",
+					Description: "Deploy the app",
+					ReturnType:  str,
+					Args: []*modFunctionArg{
+						{Name: "env", TypeDef: env},
+						{Name: "dryRun", TypeDef: &modTypeDef{Kind: dagger.TypeDefKindBooleanKind, Optional: true}},
+						{Name: "source", TypeDef: testObjectTypeDef(Directory)},
+						{
+							Name:        "targets",
+							Description: "Hosts to deploy to, by region",
+							TypeDef: &modTypeDef{
+								Kind:     dagger.TypeDefKindListKind,
+								Optional: true,
+								AsList: &modList{ElementTypeDef: &modTypeDef{
+									Kind:   dagger.TypeDefKindListKind,
+									AsList: &modList{ElementTypeDef: str},
+								}},
+							},
+						},
+					},
+				},
+				{
+					Name:       "debug",
+					ReturnType: str,
+				},
+			},
+		},
+	}
+	return &moduleDef{
+		Name:       "test",
+		MainObject: main,
+		Objects:    []*modTypeDef{main},
+		Enums:      []*modTypeDef{env},
+	}
+}
+
+func TestCompleteFunctionChain(t *testing.T) {
+	t.Parallel()
+
+	mod := testCompletionModule()
+	values := newArgCompleter(nil)
+	flags := pflag.NewFlagSet("call", pflag.ContinueOnError)
+	flags.StringP("output", "o", "", "")
+	flags.BoolP("json", "j", false, "")
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "app"), 0o755))
+
+	for _, tc := range []struct {
+		name        string
+		args        []string
+		toComplete  string
+		completions []cobra.Completion
+		directive   cobra.ShellCompDirective
+	}{
+		{
+			name:        "functions",
+			args:        []string{"--token", "abc", "-j"},
+			toComplete:  "de",
+			completions: []cobra.Completion{"debug\t-", "deploy\tDeploy the app"},
+		},
+		{
+			name:        "arguments",
+			args:        []string{"-o", "out.txt", "deploy"},
+			toComplete:  "--d",
+			completions: []cobra.Completion{"--dry-run\t"},
+		},
+		{
+			name:        "enum value",
+			args:        []string{"deploy", "--dry-run", "--env"},
+			toComplete:  "PR",
+			completions: []cobra.Completion{"PRODUCTION"},
+		},
+		{
+			name:        "enum value with equal sign",
+			args:        []string{"deploy"},
+			toComplete:  "--env=S",
+			completions: []cobra.Completion{"--env=STAGING"},
+		},
+		{
+			name:        "directory value",
+			args:        []string{"deploy", "--source"},
+			toComplete:  dir + "/a",
+			completions: []cobra.Completion{dir + "/app/"},
+			directive:   cobra.ShellCompDirectiveNoSpace,
+		},
+		{
+			name:        "json argument",
+			args:        []string{"deploy"},
+			toComplete:  "--t",
+			completions: []cobra.Completion{"--targets\tHosts to deploy to, by region (JSON: [[string]], or @file.json)"},
+		},
+		{
+			name:        "json value",
+			args:        []string{"deploy", "--targets"},
+			toComplete:  "",
+			completions: []cobra.Completion{"_activeHelp_ JSON: [[string]], or @file.json"},
+		},
+		{
+			name:       "after a scalar",
+			args:       []string{"deploy", "--env", "STAGING"},
+			toComplete: "",
+		},
+		{
+			name:       "unknown function",
+			args:       []string{"build"},
+			toComplete: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, directive := completeFunctionChain(context.Background(), mod, values, flags, tc.args, tc.toComplete)
+			require.Equal(t, tc.completions, completions)
+			require.Equal(t, cobra.ShellCompDirectiveNoFileComp|tc.directive, directive)
+		})
+	}
+}
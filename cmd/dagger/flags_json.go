@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/querybuilder"
+)
+
+// jsonValue is a pflag.Value for arguments of types that don't have a plain
+// flag value, such as input objects, module objects and lists of lists. The
+// value is JSON, or the path to a JSON file prefixed with "@".
+//
+// Objects may also be given as an ID, or, if they have a constructor, as a
+// JSON object of the constructor's arguments.
+type jsonValue struct {
+	typeDef *modTypeDef
+	value   string
+	data    any
+}
+
+func newJSONValue(typeDef *modTypeDef, defaultValue dagger.JSON) *jsonValue {
+	v := &jsonValue{typeDef: typeDef}
+	if defaultValue != "" {
+		// only used for displaying the default
+		v.value = string(defaultValue)
+	}
+	return v
+}
+
+func (v *jsonValue) Type() string {
+	return v.typeDef.String()
+}
+
+func (v *jsonValue) String() string {
+	return v.value
+}
+
+func (v *jsonValue) Set(s string) error {
+	raw := s
+	if path, ok := strings.CutPrefix(s, "@"); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read JSON file: %w", err)
+		}
+		raw = string(content)
+	}
+
+	data, err := decodeJSON(raw)
+	if err != nil {
+		if v.typeDef.Kind != dagger.TypeDefKindObjectKind && v.typeDef.Kind != dagger.TypeDefKindInterfaceKind {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		// not JSON, so it should be an ID
+		data = strings.TrimSpace(raw)
+	}
+
+	v.value = s
+	v.data = data
+	return nil
+}
+
+// decodeJSON decodes a single JSON value, keeping numbers as json.Number so
+// they can be converted to the expected type.
+func decodeJSON(raw string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return data, nil
+}
+
+func (v *jsonValue) Get(ctx context.Context, dag *dagger.Client, modSrc *dagger.ModuleSource, modArg *modFunctionArg) (any, error) {
+	if v.data == nil {
+		return nil, fmt.Errorf("expected %s, got null", v.typeDef)
+	}
+	return jsonArgValue(ctx, dag, modSrc, modArg, v.typeDef, v.data)
+}
+
+// jsonArgValue converts a decoded JSON value to a value for the query builder,
+// according to the expected type.
+func jsonArgValue(
+	ctx context.Context,
+	dag *dagger.Client,
+	modSrc *dagger.ModuleSource,
+	modArg *modFunctionArg,
+	typeDef *modTypeDef,
+	val any,
+) (any, error) {
+	switch typeDef.Kind {
+	case dagger.TypeDefKindStringKind:
+		if s, ok := val.(string); ok {
+			return s, nil
+		}
+
+	case dagger.TypeDefKindIntegerKind:
+		if n, ok := val.(json.Number); ok {
+			i, err := n.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("expected int, got %s", n)
+			}
+			return int(i), nil
+		}
+
+	case dagger.TypeDefKindFloatKind:
+		if n, ok := val.(json.Number); ok {
+			return n.Float64()
+		}
+
+	case dagger.TypeDefKindBooleanKind:
+		if b, ok := val.(bool); ok {
+			return b, nil
+		}
+
+	case dagger.TypeDefKindScalarKind:
+		if s, ok := val.(string); ok {
+			return s, nil
+		}
+		// e.g. the JSON scalar, which is passed encoded
+		b, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case dagger.TypeDefKindEnumKind:
+		if s, ok := val.(string); ok {
+			if names := typeDef.AsEnum.ValueNames(); len(names) > 0 && !slices.Contains(names, s) {
+				return nil, fmt.Errorf("value should be one of %s", strings.Join(names, ","))
+			}
+			return s, nil
+		}
+
+	case dagger.TypeDefKindListKind:
+		if elems, ok := val.([]any); ok {
+			res := make([]any, 0, len(elems))
+			for i, elem := range elems {
+				if elem == nil {
+					if typeDef.AsList.ElementTypeDef.Optional {
+						res = append(res, nil)
+						continue
+					}
+					return nil, fmt.Errorf("[%d]: expected %s, got null", i, typeDef.AsList.ElementTypeDef)
+				}
+				v, err := jsonArgValue(ctx, dag, modSrc, modArg, typeDef.AsList.ElementTypeDef, elem)
+				if err != nil {
+					return nil, fmt.Errorf("[%d]: %w", i, err)
+				}
+				res = append(res, v)
+			}
+			return res, nil
+		}
+
+	case dagger.TypeDefKindInputKind:
+		if obj, ok := val.(map[string]any); ok {
+			return jsonFieldValues(ctx, dag, modSrc, modArg, inputFieldArgs(typeDef.AsInput), obj)
+		}
+
+	case dagger.TypeDefKindObjectKind:
+		obj := typeDef.AsObject
+		switch val := val.(type) {
+		case string:
+			if flag := GetCustomFlagValue(obj.Name); flag != nil {
+				if err := flag.Set(val); err != nil {
+					return nil, err
+				}
+				return flag.Get(ctx, dag, modSrc, modArg)
+			}
+			// an ID, which doesn't need to be sent with its type
+			return val, nil
+		case map[string]any:
+			return constructJSONObject(ctx, dag, modSrc, modArg, obj, val)
+		}
+
+	case dagger.TypeDefKindInterfaceKind:
+		if id, ok := val.(string); ok {
+			return id, nil
+		}
+	}
+
+	return nil, fmt.Errorf("expected %s, got %s", jsonShape(typeDef, 0), jsonKind(val))
+}
+
+// constructJSONObject calls the object's constructor with the given arguments
+// and returns the ID of the result.
+func constructJSONObject(
+	ctx context.Context,
+	dag *dagger.Client,
+	modSrc *dagger.ModuleSource,
+	modArg *modFunctionArg,
+	obj *modObject,
+	args map[string]any,
+) (string, error) {
+	ctor := obj.Constructor
+	if ctor == nil || ctor.Name == "" {
+		return "", fmt.Errorf("%s has no constructor, so it must be given as an ID", obj.Name)
+	}
+	vals, err := jsonFieldValues(ctx, dag, modSrc, modArg, ctor.Args, args)
+	if err != nil {
+		return "", err
+	}
+
+	q := querybuilder.Query().Client(dag.GraphQLClient()).Select(ctor.Name)
+	for _, arg := range ctor.Args {
+		if v, ok := vals[arg.Name]; ok {
+			q = q.Arg(arg.Name, v)
+		}
+	}
+	var id string
+	if err := q.Select("id").Bind(&id).Execute(ctx); err != nil {
+		return "", fmt.Errorf("construct %s: %w", obj.Name, err)
+	}
+	return id, nil
+}
+
+// jsonFieldValues converts the fields of a JSON object to values for the
+// given arguments, which may be named as in the API or as flags.
+func jsonFieldValues(
+	ctx context.Context,
+	dag *dagger.Client,
+	modSrc *dagger.ModuleSource,
+	modArg *modFunctionArg,
+	args []*modFunctionArg,
+	obj map[string]any,
+) (map[string]any, error) {
+	res := make(map[string]any, len(obj))
+	for key, val := range obj {
+		i := slices.IndexFunc(args, func(arg *modFunctionArg) bool {
+			return arg.Name == key || arg.FlagName() == key
+		})
+		if i == -1 {
+			names := make([]string, 0, len(args))
+			for _, arg := range args {
+				names = append(names, arg.Name)
+			}
+			return nil, fmt.Errorf("unknown field %q, expected one of: %s", key, strings.Join(names, ", "))
+		}
+		arg := args[i]
+		if val == nil {
+			if !arg.TypeDef.Optional {
+				return nil, fmt.Errorf("%s: expected %s, got null", arg.Name, arg.TypeDef)
+			}
+			// leave it unset
+			continue
+		}
+		v, err := jsonArgValue(ctx, dag, modSrc, modArg, arg.TypeDef, val)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg.Name, err)
+		}
+		res[arg.Name] = v
+	}
+	return res, nil
+}
+
+// inputFieldArgs returns the fields of an input object as arguments, so they
+// can be handled like a constructor's.
+func inputFieldArgs(input *modInput) []*modFunctionArg {
+	args := make([]*modFunctionArg, 0, len(input.Fields))
+	for _, f := range input.Fields {
+		args = append(args, &modFunctionArg{
+			Name:        f.Name,
+			Description: f.Description,
+			TypeDef:     f.TypeDef,
+		})
+	}
+	return args
+}
+
+func jsonKind(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
+
+// maxJSONShapeDepth limits how deeply nested types are described, since
+// constructors may take the objects they construct.
+const maxJSONShapeDepth = 3
+
+// jsonShape describes the JSON expected for a value of the given type, such
+// as `{"name": string, "value"?: string}`.
+func jsonShape(typeDef *modTypeDef, depth int) string {
+	switch typeDef.Kind {
+	case dagger.TypeDefKindEnumKind:
+		if names := typeDef.AsEnum.ValueNames(); len(names) > 0 {
+			quoted := make([]string, 0, len(names))
+			for _, name := range names {
+				quoted = append(quoted, strconv.Quote(name))
+			}
+			return strings.Join(quoted, "|")
+		}
+	case dagger.TypeDefKindListKind:
+		return "[" + jsonShape(typeDef.AsList.ElementTypeDef, depth) + "]"
+	case dagger.TypeDefKindInputKind:
+		if depth < maxJSONShapeDepth {
+			return jsonArgsShape(inputFieldArgs(typeDef.AsInput), depth+1)
+		}
+		return typeDef.AsInput.Name
+	case dagger.TypeDefKindObjectKind:
+		shape := typeDef.AsObject.Name + " ID"
+		if ctor := typeDef.AsObject.Constructor; ctor != nil && ctor.Name != "" && depth < maxJSONShapeDepth {
+			shape += " | " + jsonArgsShape(ctor.Args, depth+1)
+		}
+		return shape
+	case dagger.TypeDefKindInterfaceKind:
+		return typeDef.AsInterface.Name + " ID"
+	}
+	return typeDef.String()
+}
+
+func jsonArgsShape(args []*modFunctionArg, depth int) string {
+	fields := make([]string, 0, len(args))
+	for _, arg := range args {
+		key := strconv.Quote(arg.Name)
+		if !arg.IsRequired() {
+			key += "?"
+		}
+		fields = append(fields, key+": "+jsonShape(arg.TypeDef, depth))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// JSONShape returns the JSON expected for the argument's value if its flag
+// takes JSON, or an empty string otherwise.
+func (r *modFunctionArg) JSONShape() string {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := r.AddFlag(flags); err != nil {
+		return ""
+	}
+	if _, ok := flags.Lookup(r.FlagName()).Value.(*jsonValue); !ok {
+		return ""
+	}
+	return jsonShape(r.TypeDef, 0)
+}
+
+// JSONUsage describes the value expected for the argument if its flag takes
+// JSON, or returns an empty string otherwise.
+func (r *modFunctionArg) JSONUsage() string {
+	shape := r.JSONShape()
+	if shape == "" {
+		return ""
+	}
+	return fmt.Sprintf("JSON: %s, or @file.json", shape)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"dagger.io/dagger"
+)
+
+func TestJSONValue(t *testing.T) {
+	t.Parallel()
+
+	str := &modTypeDef{Kind: dagger.TypeDefKindStringKind}
+	optInt := &modTypeDef{Kind: dagger.TypeDefKindIntegerKind, Optional: true}
+	input := &modTypeDef{
+		Kind: dagger.TypeDefKindInputKind,
+		AsInput: &modInput{
+			Name: "BuildArg",
+			Fields: []*modField{
+				{Name: "name", TypeDef: str},
+				{Name: "retries", TypeDef: optInt},
+			},
+		},
+	}
+	listOfLists := &modTypeDef{
+		Kind: dagger.TypeDefKindListKind,
+		AsList: &modList{ElementTypeDef: &modTypeDef{
+			Kind:   dagger.TypeDefKindListKind,
+			AsList: &modList{ElementTypeDef: optInt},
+		}},
+	}
+	strs := &modTypeDef{
+		Kind:   dagger.TypeDefKindListKind,
+		AsList: &modList{ElementTypeDef: str},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		typeDef *modTypeDef
+		input   string
+		want    any
+		wantErr string
+	}{
+		{
+			name:    "input",
+			typeDef: input,
+			input:   `{"name": "foo", "retries": 3}`,
+			want:    map[string]any{"name": "foo", "retries": 3},
+		},
+		{
+			name:    "input with null optional field",
+			typeDef: input,
+			input:   `{"name": "foo", "retries": null}`,
+			want:    map[string]any{"name": "foo"},
+		},
+		{
+			name:    "input with unknown field",
+			typeDef: input,
+			input:   `{"nme": "foo"}`,
+			wantErr: `unknown field "nme", expected one of: name, retries`,
+		},
+		{
+			name:    "list of lists",
+			typeDef: listOfLists,
+			input:   `[[1, 2], [3]]`,
+			want:    []any{[]any{1, 2}, []any{3}},
+		},
+		{
+			name:    "list of lists with wrong type",
+			typeDef: listOfLists,
+			input:   `[[1, "2"]]`,
+			wantErr: `[0]: [1]: expected int, got string`,
+		},
+		{
+			name:    "list of lists with null optional element",
+			typeDef: listOfLists,
+			input:   `[[1, null]]`,
+			want:    []any{[]any{1, nil}},
+		},
+		{
+			name:    "list with null required element",
+			typeDef: strs,
+			input:   `["a", null]`,
+			wantErr: `[1]: expected string, got null`,
+		},
+		{
+			name:    "invalid",
+			typeDef: listOfLists,
+			input:   `[[1`,
+			wantErr: `invalid JSON`,
+		},
+		{
+			name:    "trailing data",
+			typeDef: listOfLists,
+			input:   `[[1]] [[2]]`,
+			wantErr: `invalid JSON: unexpected data after JSON value`,
+		},
+		{
+			name:    "trailing garbage",
+			typeDef: input,
+			input:   `{"name": "foo"}x`,
+			wantErr: `invalid JSON`,
+		},
+		{
+			name:    "trailing whitespace",
+			typeDef: input,
+			input:   "{\"name\": \"foo\"}\n",
+			want:    map[string]any{"name": "foo"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			v := newJSONValue(tc.typeDef, "")
+			err := v.Set(tc.input)
+			if err == nil {
+				var got any
+				got, err = v.Get(context.Background(), nil, nil, nil)
+				if err == nil {
+					require.Empty(t, tc.wantErr)
+					require.Equal(t, tc.want, got)
+					return
+				}
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "arg.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"name": "foo"}`), 0o600))
+
+		v := newJSONValue(input, "")
+		require.NoError(t, v.Set("@"+path))
+		got, err := v.Get(context.Background(), nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"name": "foo"}, got)
+	})
+
+	t.Run("shape", func(t *testing.T) {
+		t.Parallel()
+		require.Equal(t, `{"name": string, "retries"?: int}`, jsonShape(input, 0))
+		require.Equal(t, `[[int]]`, jsonShape(listOfLists, 0))
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"dagger.io/dagger/querybuilder"
 	"dagger.io/dagger/telemetry"
 	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/dagql/dagui"
 	"github.com/dagger/dagger/dagql/idtui"
 	"github.com/dagger/dagger/engine/client"
 	"github.com/dagger/dagger/engine/client/pathutil"
@@ -104,6 +105,23 @@ type FuncCommand struct {
 	// arguments rather than a debug level log.
 	warnSkipped bool
 
+	// watch is set by the `--watch` flag to run the command again when its
+	// local inputs change.
+	watch bool
+
+	// matrix is set by the `--matrix` flag to call the function chain for
+	// every combination of the given argument values.
+	matrix []string
+
+	// matrixParallelism is set by the `--matrix-parallelism` flag to limit
+	// how many matrix cells are called at once.
+	matrixParallelism int
+
+	// cell has the argument values for the matrix cell being run, and
+	// cellArgs which of them were set.
+	cell     matrixCell
+	cellArgs map[string]bool
+
 	q   *querybuilder.Selection
 	c   *client.Client
 	ctx context.Context
@@ -126,6 +144,12 @@ func (fc *FuncCommand) Command() *cobra.Command {
 			DisableFlagParsing:    true,
 			DisableFlagsInUseLine: true,
 
+			// Shell completion parses the flags before calling
+			// ValidArgsFunction, including the module's which aren't
+			// known yet.
+			FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+			ValidArgsFunction:  fc.complete,
+
 			PreRunE: func(c *cobra.Command, a []string) error {
 				// Recover what DisableFlagParsing disabled.
 				// In PreRunE it's, already past the --help check and
@@ -169,40 +193,53 @@ func (fc *FuncCommand) Command() *cobra.Command {
 					c.SetContext(idtui.WithPrintTraceLink(c.Context(), true))
 				}
 
-				return withEngine(c.Context(), client.Params{}, func(ctx context.Context, engineClient *client.Client) (rerr error) {
+				var params client.Params
+				var watcher *hostWatcher
+				if fc.watch && !fc.needsHelp {
+					watcher = newHostWatcher()
+					params.OnLocalSync = watcher.Observe
+				}
+
+				if junitPath != "" && !fc.needsHelp {
+					junitReport = dagui.NewDB()
+				}
+
+				err := withEngine(c.Context(), params, func(ctx context.Context, engineClient *client.Client) (rerr error) {
 					fc.c = engineClient
-					fc.q = querybuilder.Query().Client(engineClient.Dagger().GraphQLClient())
-
-					// withEngine changes the context.
-					c.SetContext(ctx)
-
-					if err := fc.execute(c, a); err != nil {
-						// We've already handled printing the error in `fc.execute`
-						// because we want to show the usage for the right sub-command.
-						// Returning ExitError here will prevent the error from being printed
-						// twice on main().
-
-						// Return the same ExecError exit code.
-						var ex *dagger.ExecError
-						if errors.As(err, &ex) {
-							tty := !silent && (hasTTY && progress == "auto" || progress == "tty")
-							// Only the pretty frontend prints the stderr of
-							// the exec error in the final render
-							if !tty && ex.Stdout != "" {
-								c.PrintErrln("Stdout:")
-								c.PrintErrln(ex.Stdout)
-							}
-							if !tty && ex.Stderr != "" {
-								c.PrintErrln("Stderr:")
-								c.PrintErrln(ex.Stderr)
-							}
-							return ExitError{Code: ex.ExitCode, Original: err}
-						}
-						return ExitError{Code: 1, Original: err}
+
+					if watcher == nil {
+						// withEngine changes the context.
+						c.SetContext(ctx)
+						return fc.run(c, a)
 					}
 
-					return nil
+					// Reuse the session for every run so that results that
+					// don't depend on what changed are cached.
+					first := true
+					return watcher.Run(ctx, func(ctx context.Context) error {
+						if !first {
+							fc.reset()
+							if err := c.PreRunE(c, a); err != nil {
+								return err
+							}
+						}
+						first = false
+						c.SetContext(ctx)
+						return fc.run(c, a)
+					})
 				})
+
+				// Write the report after telemetry is flushed, even if the
+				// call failed.
+				if junitReport != nil {
+					if jerr := writeJUnitReport(); jerr != nil {
+						if err == nil {
+							return fmt.Errorf("write JUnit report: %w", jerr)
+						}
+						fmt.Fprintln(stderr, "failed to write JUnit report:", jerr)
+					}
+				}
+				return err
 			},
 		}
 
@@ -223,10 +260,106 @@ func (fc *FuncCommand) Command() *cobra.Command {
 		fc.cmd.PersistentFlags().StringVarP(&outputPath, "output", "o", "", "Save the result to a local file or directory")
 
 		fc.cmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Present result as JSON")
+
+		fc.cmd.PersistentFlags().StringVar(&junitPath, "junit", "", "Write a JUnit XML report of the function calls to a file")
+
+		fc.cmd.PersistentFlags().BoolVar(&fc.watch, "watch", false, "Run again when local files loaded as arguments or the module's source change")
+
+		fc.cmd.PersistentFlags().StringArrayVar(&fc.matrix, "matrix", nil, "Call for every combination of argument values, e.g. --matrix go=1.22,1.23 (can be repeated)")
+
+		fc.cmd.PersistentFlags().IntVar(&fc.matrixParallelism, "matrix-parallelism", defaultMatrixParallelism, "Maximum number of matrix combinations to call at once")
 	}
 	return fc.cmd
 }
 
+// complete returns the shell completions for a command line, loading the
+// module to complete its functions, arguments and their values.
+func (fc *FuncCommand) complete(c *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var completions []cobra.Completion
+	directive := cobra.ShellCompDirectiveNoFileComp
+
+	// withEngine sends the command's output to telemetry, but completions
+	// are printed after it returns.
+	root := c.Root()
+	defer root.SetOut(root.OutOrStdout())
+	defer root.SetErr(root.ErrOrStderr())
+
+	// The context isn't set on the command when completing, only the root.
+	err := withEngine(c.Root().Context(), client.Params{}, func(ctx context.Context, engineClient *client.Client) error {
+		var mod *moduleDef
+		var err error
+		if fc.DisableModuleLoad || moduleNoURL {
+			mod, err = initializeCore(ctx, engineClient.Dagger())
+		} else {
+			mod, err = initializeDefaultModule(ctx, engineClient.Dagger())
+		}
+		if err != nil {
+			return err
+		}
+		completions, directive = completeFunctionChain(ctx, mod, newArgCompleter(engineClient.Dagger()), c.Flags(), args, toComplete)
+		return nil
+	})
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completions, directive
+}
+
+// run executes the command with a new query, returning an ExitError on
+// failure.
+func (fc *FuncCommand) run(c *cobra.Command, a []string) error {
+	fc.q = querybuilder.Query().Client(fc.c.Dagger().GraphQLClient())
+
+	if err := fc.execute(c, a); err != nil {
+		// We've already handled printing the error in `fc.execute`
+		// because we want to show the usage for the right sub-command.
+		// Returning ExitError here will prevent the error from being printed
+		// twice on main().
+
+		// Return the same ExecError exit code.
+		var ex *dagger.ExecError
+		if errors.As(err, &ex) {
+			tty := !silent && (hasTTY && progress == "auto" || progress == "tty")
+			// Only the pretty frontend prints the stderr of
+			// the exec error in the final render
+			if !tty && ex.Stdout != "" {
+				c.PrintErrln("Stdout:")
+				c.PrintErrln(ex.Stdout)
+			}
+			if !tty && ex.Stderr != "" {
+				c.PrintErrln("Stderr:")
+				c.PrintErrln(ex.Stderr)
+			}
+			return ExitError{Code: ex.ExitCode, Original: err}
+		}
+		return ExitError{Code: 1, Original: err}
+	}
+
+	return nil
+}
+
+// reset removes the flags and sub-commands added from the module's
+// functions, so the command can be loaded again with a reloaded module.
+func (fc *FuncCommand) reset() {
+	c := fc.cmd
+
+	persistent := c.PersistentFlags()
+	c.ResetCommands()
+	c.ResetFlags()
+	c.PersistentFlags().AddFlagSet(persistent)
+	c.SetGlobalNormalizationFunc(c.GlobalNormalizationFunc())
+	c.InitDefaultHelpFlag()
+
+	c.Use = fc.Name
+	delete(c.Annotations, skippedOptsAnnotation)
+	delete(c.Annotations, skippedCmdsAnnotation)
+
+	fc.needsHelp = false
+	fc.showUsage = false
+	fc.warnSkipped = false
+}
+
 func (fc *FuncCommand) Help(cmd *cobra.Command) error {
 	var args []any
 	// We need to store these in annotations because during traversal all
@@ -296,6 +429,10 @@ func (fc *FuncCommand) execute(c *cobra.Command, a []string) (rerr error) {
 	// are more likely to be from wrong CLI usage.
 	fc.showUsage = true
 
+	if len(fc.matrix) > 0 && !fc.needsHelp {
+		return fc.runMatrix(c, a)
+	}
+
 	cmd, flags, err := fc.loadCommand(c, a)
 	if err != nil {
 		return err
@@ -371,6 +508,10 @@ func (fc *FuncCommand) cobraBuilder(ctx context.Context, fn *modFunction) func(*
 			return c.FlagErrorFunc()(c, err)
 		}
 
+		if err := fc.setMatrixArgs(c, fn); err != nil {
+			return err
+		}
+
 		fc.addSubCommands(ctx, c, fn.ReturnType)
 
 		if fc.needsHelp {
@@ -452,7 +593,9 @@ func (fc *FuncCommand) addSubCommands(ctx context.Context, cmd *cobra.Command, t
 		return
 	}
 
-	cmd.AddGroup(funcGroup)
+	if !cmd.ContainsGroup(funcGroup.ID) {
+		cmd.AddGroup(funcGroup)
+	}
 
 	fns, skipped := GetSupportedFunctions(fnProvider)
 
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// browseFunctions runs an interactive browser for the functions in a module,
+// starting from the object returned by the given chain of functions.
+//
+// It returns the last `dagger call` command line that was copied, if any.
+func browseFunctions(mod *moduleDef, path []*modFunction) (string, error) {
+	var copied string
+	err := withTerminal(func(stdin io.Reader, stdout, _ io.Writer) error {
+		b := newFunctionBrowser(mod, path, termenv.NewOutput(stdout))
+		m, err := tea.NewProgram(b,
+			tea.WithInput(stdin),
+			tea.WithOutput(stdout),
+			tea.WithAltScreen(),
+		).Run()
+		if err != nil {
+			return err
+		}
+		copied = m.(*functionBrowser).copied
+		return nil
+	})
+	return copied, err
+}
+
+// functionBrowser is a TUI to navigate the functions of a module, going
+// into the type returned by a function to see the ones that can be chained.
+type functionBrowser struct {
+	mod *moduleDef
+	out *termenv.Output
+
+	// frames has the functions of each type in the chain, the last one
+	// being the current one
+	frames []*browserFrame
+
+	width  int
+	height int
+
+	// status is a message shown in the footer until the next key press
+	status string
+
+	// copied is the last command line copied to the clipboard
+	copied string
+}
+
+type browserFrame struct {
+	fp     functionProvider
+	fns    []*modFunction
+	cursor int
+	offset int
+}
+
+var _ tea.Model = (*functionBrowser)(nil)
+
+func newFunctionBrowser(mod *moduleDef, path []*modFunction, out *termenv.Output) *functionBrowser {
+	b := &functionBrowser{
+		mod: mod,
+		out: out,
+	}
+	b.push(mod.MainObject.AsFunctionProvider())
+	for _, fn := range path {
+		frame := b.frame()
+		for i, f := range frame.fns {
+			if f == fn {
+				frame.cursor = i
+			}
+		}
+		b.push(mod.GetFunctionProvider(fn.ReturnType.Name()))
+	}
+	return b
+}
+
+func (b *functionBrowser) push(fp functionProvider) {
+	fns, _ := GetSupportedFunctions(fp)
+	sort.Slice(fns, func(i, j int) bool {
+		return fns[i].Name < fns[j].Name
+	})
+	for _, fn := range fns {
+		b.mod.LoadFunctionTypeDefs(fn)
+	}
+	b.frames = append(b.frames, &browserFrame{fp: fp, fns: fns})
+}
+
+func (b *functionBrowser) frame() *browserFrame {
+	return b.frames[len(b.frames)-1]
+}
+
+// selected returns the function under the cursor, if any
+func (b *functionBrowser) selected() *modFunction {
+	frame := b.frame()
+	if frame.cursor < len(frame.fns) {
+		return frame.fns[frame.cursor]
+	}
+	return nil
+}
+
+// path returns the chain of functions leading to the one under the cursor
+func (b *functionBrowser) path() []*modFunction {
+	path := make([]*modFunction, 0, len(b.frames))
+	for _, frame := range b.frames {
+		if frame.cursor < len(frame.fns) {
+			path = append(path, frame.fns[frame.cursor])
+		}
+	}
+	return path
+}
+
+// returnProvider returns the type with functions that fn returns, if any
+func (b *functionBrowser) returnProvider(fn *modFunction) functionProvider {
+	name := fn.ReturnType.Name()
+	if name == "" {
+		return nil
+	}
+	fp := b.mod.GetFunctionProvider(name)
+	if fp == nil {
+		return nil
+	}
+	if fns, _ := GetSupportedFunctions(fp); len(fns) == 0 {
+		return nil
+	}
+	return fp
+}
+
+func (b *functionBrowser) Init() tea.Cmd {
+	return nil
+}
+
+func (b *functionBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.width, b.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		b.status = ""
+		frame := b.frame()
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return b, tea.Quit
+		case "up", "k":
+			if frame.cursor > 0 {
+				frame.cursor--
+			}
+		case "down", "j":
+			if frame.cursor < len(frame.fns)-1 {
+				frame.cursor++
+			}
+		case "home", "g":
+			frame.cursor = 0
+		case "end", "G":
+			frame.cursor = max(len(frame.fns)-1, 0)
+		case "enter", "right", "l":
+			fn := b.selected()
+			if fn == nil {
+				break
+			}
+			if fp := b.returnProvider(fn); fp != nil {
+				b.push(fp)
+			} else {
+				b.status = fmt.Sprintf("%s has no functions", fn.ReturnType.String())
+			}
+		case "esc", "backspace", "left", "h":
+			if len(b.frames) > 1 {
+				b.frames = b.frames[:len(b.frames)-1]
+			}
+		case "c", "y":
+			line := callCommandLine(b.mod, b.path())
+			b.out.Copy(line)
+			b.copied = line
+			b.status = "Copied: " + line
+		}
+	}
+	return b, nil
+}
+
+var (
+	browserTitleStyle    = lipgloss.NewStyle().Bold(true)
+	browserSelectedStyle = lipgloss.NewStyle().Reverse(true)
+	browserFaintStyle    = lipgloss.NewStyle().Faint(true)
+	browserPaneStyle     = lipgloss.NewStyle().PaddingRight(2)
+)
+
+func (b *functionBrowser) View() string {
+	if b.width == 0 {
+		return ""
+	}
+	frame := b.frame()
+
+	header := browserTitleStyle.Render(callCommandLine(b.mod, b.path())) +
+		browserFaintStyle.Render("  "+frame.fp.ProviderName())
+	footer := browserFaintStyle.Render("↑/↓ select · enter go to return type · esc back · c copy command · q quit")
+	if b.status != "" {
+		footer = b.status
+	}
+	height := max(b.height-3, 1)
+
+	list := b.renderList(frame, height)
+	listWidth := lipgloss.Width(list) + browserPaneStyle.GetPaddingRight()
+	details := lipgloss.NewStyle().
+		Width(max(b.width-listWidth, 1)).
+		MaxHeight(height).
+		Render(b.renderDetails(b.selected()))
+
+	body := lipgloss.NewStyle().Height(height).MaxHeight(height).Render(
+		lipgloss.JoinHorizontal(lipgloss.Top, browserPaneStyle.Render(list), details),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", body, footer)
+}
+
+// renderList renders the functions of the current type, scrolled to keep
+// the cursor visible
+func (b *functionBrowser) renderList(frame *browserFrame, height int) string {
+	if len(frame.fns) == 0 {
+		return browserFaintStyle.Render("no functions")
+	}
+	if frame.cursor < frame.offset {
+		frame.offset = frame.cursor
+	}
+	if frame.cursor >= frame.offset+height {
+		frame.offset = frame.cursor - height + 1
+	}
+
+	width := 0
+	for _, fn := range frame.fns {
+		width = max(width, len(fn.CmdName()))
+	}
+	width = min(width+2, max(b.width/3, 10))
+
+	lines := make([]string, 0, height)
+	for i := frame.offset; i < len(frame.fns) && i < frame.offset+height; i++ {
+		fn := frame.fns[i]
+		name := fn.CmdName()
+		if b.returnProvider(fn) != nil {
+			name += " ›"
+		}
+		style := lipgloss.NewStyle().Width(width).MaxWidth(width)
+		if i == frame.cursor {
+			style = style.Inherit(browserSelectedStyle)
+		}
+		lines = append(lines, style.Render(name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderDetails renders the documentation of a function, with its
+// arguments and return type
+func (b *functionBrowser) renderDetails(fn *modFunction) string {
+	if fn == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(browserTitleStyle.Render(fn.CmdName()))
+	sb.WriteString(browserFaintStyle.Render(" → " + fn.ReturnType.String()))
+	sb.WriteString("\n")
+	if fn.Description != "" {
+		sb.WriteString("\n")
+		sb.WriteString(fn.Description)
+		sb.WriteString("\n")
+	}
+
+	if args := fn.SupportedArgs(); len(args) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(browserTitleStyle.Render("Arguments"))
+		sb.WriteString("\n")
+		for _, arg := range args {
+			sb.WriteString("  " + arg.Usage())
+			if arg.IsRequired() {
+				sb.WriteString(browserFaintStyle.Render(" (required)"))
+			}
+			sb.WriteString("\n")
+			if long := arg.Long(); long != "" {
+				for _, line := range strings.Split(long, "\n") {
+					sb.WriteString("      " + line + "\n")
+				}
+			}
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(browserTitleStyle.Render("Returns"))
+	sb.WriteString("\n  ")
+	sb.WriteString(fn.ReturnType.Short())
+	sb.WriteString("\n")
+	return sb.String()
+}
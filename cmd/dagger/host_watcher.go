@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	gofs "io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tonistiigi/fsutil"
+	"go.opentelemetry.io/otel/trace"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/querybuilder"
+	"github.com/dagger/dagger/engine/client"
+	"github.com/dagger/dagger/engine/slog"
+)
+
+// hostWatchInterval is how often watched host paths are checked for changes.
+//
+// There's no portable way to be notified of changes to arbitrary trees, and
+// the paths are filtered the same way as when they're synced, so polling
+// keeps what's watched the same as what's loaded.
+const hostWatchInterval = 500 * time.Millisecond
+
+// hostWatcher records the host paths that the engine loads while running a
+// command, so the command can be run again when any of them change.
+type hostWatcher struct {
+	mu        sync.Mutex
+	recording bool
+
+	// paths has the fingerprint of each path when it was loaded
+	paths map[string]watchedPath
+
+	// exports are paths written by the engine, which are ignored
+	exports []string
+}
+
+type watchedPath struct {
+	client.LocalSync
+	fingerprint string
+}
+
+func newHostWatcher() *hostWatcher {
+	return &hostWatcher{
+		paths: map[string]watchedPath{},
+	}
+}
+
+// Observe records a host path synced by the engine, if recording.
+func (w *hostWatcher) Observe(sync client.LocalSync) {
+	w.mu.Lock()
+	if !w.recording {
+		w.mu.Unlock()
+		return
+	}
+	if sync.Export {
+		w.exports = append(w.exports, sync.Path)
+		// paths loaded earlier need to skip the export too
+		var affected []watchedPath
+		for _, p := range w.paths {
+			if isUnderPath(sync.Path, p.Path) {
+				affected = append(affected, p)
+			}
+		}
+		w.mu.Unlock()
+		for _, p := range affected {
+			w.Observe(p.LocalSync)
+		}
+		return
+	}
+	exports := slices.Clone(w.exports)
+	w.mu.Unlock()
+
+	fp := fingerprintHostPath(sync, exports)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paths[watchedPathKey(sync)] = watchedPath{sync, fp}
+}
+
+// Record starts recording the paths loaded from now on, forgetting the
+// ones that were recorded before.
+func (w *hostWatcher) Record() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.recording = true
+	w.paths = map[string]watchedPath{}
+	w.exports = nil
+}
+
+// Stop stops recording paths.
+func (w *hostWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.recording = false
+}
+
+// Len returns the number of recorded paths.
+func (w *hostWatcher) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.paths)
+}
+
+// Changed returns the first recorded path that changed since it was loaded.
+func (w *hostWatcher) Changed() (string, bool) {
+	w.mu.Lock()
+	paths := make([]watchedPath, 0, len(w.paths))
+	for _, p := range w.paths {
+		paths = append(paths, p)
+	}
+	exports := slices.Clone(w.exports)
+	w.mu.Unlock()
+
+	slices.SortFunc(paths, func(a, b watchedPath) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	for _, p := range paths {
+		if fingerprintHostPath(p.LocalSync, exports) != p.fingerprint {
+			return p.Path, true
+		}
+	}
+	return "", false
+}
+
+// Wait blocks until a recorded path changes, returning it.
+func (w *hostWatcher) Wait(ctx context.Context) (string, error) {
+	ticker := time.NewTicker(hostWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", context.Cause(ctx)
+		case <-ticker.C:
+			if path, ok := w.Changed(); ok {
+				return path, nil
+			}
+		}
+	}
+}
+
+// Run calls run, and then again each time a host path loaded by the last
+// run changes, until ctx is done. A run that's still in progress when a
+// change is detected is canceled first.
+//
+// Runs after the first reload host paths, since the engine would otherwise
+// return what it loaded before for the same client.
+func (w *hostWatcher) Run(ctx context.Context, run func(context.Context) error) error {
+	defer w.Stop()
+
+	var changed string
+	for {
+		runCtx, cancel := context.WithCancel(ctx)
+		var span trace.Span
+		if changed != "" {
+			runCtx = withHostReload(runCtx)
+			runCtx, span = Tracer().Start(runCtx, "re-run: "+relHostPath(changed)+" changed")
+		}
+
+		w.Record()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			// errors are reported by the run itself, so keep watching
+			_ = run(runCtx)
+			if runCtx.Err() == nil {
+				slog.Info("watching for changes", "paths", w.Len())
+			}
+		}()
+
+		var err error
+		changed, err = w.Wait(ctx)
+		cancel()
+		<-done
+		if span != nil {
+			span.End()
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				// interrupted
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func watchedPathKey(sync client.LocalSync) string {
+	return strings.Join([]string{
+		sync.Path,
+		strings.Join(sync.IncludePatterns, ","),
+		strings.Join(sync.ExcludePatterns, ","),
+		strings.Join(sync.FollowPaths, ","),
+	}, "\x00")
+}
+
+// fingerprintHostPath hashes the metadata of a host path, and of the files
+// under it if it's a directory, applying the same filters as the sync.
+// Exported paths are skipped, so that writing the result of a run doesn't
+// trigger another.
+func fingerprintHostPath(sync client.LocalSync, exports []string) string {
+	h := sha256.New()
+	exported := func(path string) bool {
+		return slices.ContainsFunc(exports, func(export string) bool {
+			return isUnderPath(path, export)
+		})
+	}
+
+	st, err := os.Stat(sync.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return "missing"
+	case err != nil:
+		return "error: " + err.Error()
+	case !st.IsDir():
+		if exported(sync.Path) {
+			return ""
+		}
+		writeFileInfo(h, "", st)
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	fs, err := fsutil.NewFS(sync.Path)
+	if err == nil {
+		fs, err = fsutil.NewFilterFS(fs, &fsutil.FilterOpt{
+			IncludePatterns: sync.IncludePatterns,
+			ExcludePatterns: sync.ExcludePatterns,
+			FollowPaths:     sync.FollowPaths,
+		})
+	}
+	if err == nil {
+		err = fs.Walk(context.Background(), "", func(path string, entry gofs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if exported(filepath.Join(sync.Path, path)) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			writeFileInfo(h, path, info)
+			return nil
+		})
+	}
+	if err != nil {
+		// files may be removed while walking, which is a change anyway
+		fmt.Fprintf(h, "error: %s\n", err)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeFileInfo(h io.Writer, path string, info gofs.FileInfo) {
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\n", path, info.Mode(), info.Size(), info.ModTime().UnixNano())
+}
+
+// isUnderPath returns true if path is dir or under it.
+func isUnderPath(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// relHostPath returns path relative to the working directory if it's under
+// it, for display.
+func relHostPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return path
+	}
+	return rel
+}
+
+type hostReloadKey struct{}
+
+// withHostReload marks a context so that host directories, files and local
+// modules are loaded again rather than reused from earlier in the session.
+func withHostReload(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hostReloadKey{}, true)
+}
+
+func hostReload(ctx context.Context) bool {
+	reload, _ := ctx.Value(hostReloadKey{}).(bool)
+	return reload
+}
+
+// loadModuleSource returns the module source for ref, reloading a local
+// module's files if the context asks for it.
+func loadModuleSource(ctx context.Context, dag *dagger.Client, ref string) (*dagger.ModuleSource, error) {
+	if !hostReload(ctx) {
+		return dag.ModuleSource(ref), nil
+	}
+	var id dagger.ModuleSourceID
+	err := querybuilder.Query().Client(dag.GraphQLClient()).
+		Select("moduleSource").
+		Arg("refString", ref).
+		Arg("noCache", true).
+		Select("id").
+		Bind(&id).
+		Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dag.LoadModuleSourceFromID(id), nil
+}
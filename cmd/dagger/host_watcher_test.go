@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/engine/client"
+)
+
+func TestHostWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		// make sure the change is visible even on coarse mtimes
+		later := time.Now().Add(time.Duration(len(content)+1) * time.Second)
+		require.NoError(t, os.Chtimes(path, later, later))
+	}
+	write("main.go", "package main")
+	write("node_modules/dep.js", "")
+	write("dist/out", "")
+
+	w := newHostWatcher()
+
+	// not recording yet
+	w.Observe(client.LocalSync{Path: dir})
+	require.Zero(t, w.Len())
+
+	w.Record()
+	w.Observe(client.LocalSync{Path: dir, ExcludePatterns: []string{"node_modules"}})
+	w.Observe(client.LocalSync{Path: filepath.Join(dir, "dist"), Export: true})
+	require.Equal(t, 1, w.Len())
+
+	_, changed := w.Changed()
+	require.False(t, changed)
+
+	write("node_modules/dep.js", "excluded")
+	_, changed = w.Changed()
+	require.False(t, changed)
+
+	write("dist/out", "exported")
+	_, changed = w.Changed()
+	require.False(t, changed)
+
+	write("main.go", "package main // changed")
+	path, changed := w.Changed()
+	require.True(t, changed)
+	require.Equal(t, dir, path)
+
+	// recording again starts from the current state
+	w.Record()
+	w.Observe(client.LocalSync{Path: filepath.Join(dir, "main.go")})
+	_, changed = w.Changed()
+	require.False(t, changed)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "main.go")))
+	_, changed = w.Changed()
+	require.True(t, changed)
+}
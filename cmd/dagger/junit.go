@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/dagger/dagger/dagql/dagui"
+	"github.com/dagger/dagger/dagql/idtui"
+)
+
+var (
+	// junitPath is the parsed value of the `--junit` flag.
+	junitPath string
+
+	// junitReport collects the spans for the JUnit report, if one was
+	// requested.
+	junitReport *dagui.DB
+)
+
+// writeJUnitReport writes the function calls collected in junitReport to
+// junitPath.
+func writeJUnitReport() error {
+	var buf bytes.Buffer
+	if err := idtui.WriteJUnit(&buf, junitReport, spanName(os.Args)); err != nil {
+		return err
+	}
+	return writeOutputFile(junitPath, &buf)
+}
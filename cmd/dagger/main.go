@@ -147,11 +147,14 @@ func init() {
 		funcListCmd,
 		callCoreCmd.Command(),
 		callModCmd.Command(),
+		diffCmd,
 		sessionCmd(),
 		newGenCmd(),
 		shellCmd,
+		terminalCmd,
 		clientCmd,
 		mcpCmd,
+		debugCmd,
 	)
 
 	rootCmd.AddGroup(moduleGroup)
@@ -332,7 +335,7 @@ func installGlobalFlags(flags *pflag.FlagSet) {
 	flags.CountVarP(&quiet, "quiet", "q", "Reduce verbosity (show progress, but clean up at the end)")
 	flags.BoolVarP(&silent, "silent", "s", silent, "Do not show progress at all")
 	flags.BoolVarP(&debug, "debug", "d", debug, "Show debug logs and full verbosity")
-	flags.StringVar(&progress, "progress", "auto", "Progress output format (auto, plain, tty, dots)")
+	flags.StringVar(&progress, "progress", "auto", "Progress output format (auto, plain, tty, dots, jsonl)")
 	flags.BoolVarP(&interactive, "interactive", "i", false, "Spawn a terminal on container exec failure")
 	flags.StringVar(&interactiveCommand, "interactive-command", "/bin/sh", "Change the default command for interactive mode")
 	flags.BoolVarP(&web, "web", "w", false, "Open trace URL in a web browser")
@@ -432,6 +435,10 @@ var opts dagui.FrontendOpts
 
 func main() {
 	parseGlobalFlags()
+	if len(os.Args) > 1 && (os.Args[1] == cobra.ShellCompRequestCmd || os.Args[1] == cobra.ShellCompNoDescRequestCmd) {
+		// progress would get mixed with the completions
+		silent = true
+	}
 	opts.Verbosity += dagui.ShowCompletedVerbosity // keep progress by default
 	opts.Verbosity += verbose                      // raise verbosity with -v
 	opts.Verbosity -= quiet                        // lower verbosity with -q
@@ -471,6 +478,8 @@ func main() {
 		Frontend = idtui.NewDots(stderr)
 	case "report":
 		Frontend = idtui.NewReporter(stderr)
+	case "jsonl":
+		Frontend = idtui.NewJSONL(stderr)
 	default:
 		fmt.Fprintf(stderr, "unknown progress type %q\n", progress)
 		os.Exit(1)
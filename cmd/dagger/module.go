@@ -120,6 +120,7 @@ func init() {
 	moduleAddFlags(mcpCmd, mcpCmd.PersistentFlags(), true)
 
 	moduleAddFlags(shellCmd, shellCmd.PersistentFlags(), true)
+	moduleAddFlags(diffCmd, diffCmd.PersistentFlags(), true)
 	shellAddFlags(shellCmd)
 	moduleAddFlags(rootCmd, rootCmd.Flags(), true)
 	shellAddFlags(rootCmd)
@@ -44,7 +44,11 @@ func initializeDefaultModule(ctx context.Context, dag *dagger.Client) (*moduleDe
 	if modRef == "" {
 		modRef = moduleURLDefault
 	}
-	return initializeModule(ctx, dag, modRef, dag.ModuleSource(modRef))
+	modSrc, err := loadModuleSource(ctx, dag, modRef)
+	if err != nil {
+		return nil, err
+	}
+	return initializeModule(ctx, dag, modRef, modSrc)
 }
 
 // initializeModule loads the module at the given source ref
@@ -1035,6 +1039,15 @@ func (r *modFunctionArg) Long() string {
 		fmt.Fprintf(sb, "(possible values: %s)", names)
 	}
 
+	if usage := r.JSONUsage(); usage != "" {
+		if multiline {
+			sb.WriteString("\n\n")
+		} else if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		fmt.Fprintf(sb, "(%s)", usage)
+	}
+
 	return sb.String()
 }
 
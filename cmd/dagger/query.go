@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -20,6 +21,9 @@ var (
 	queryFile          string
 	queryVarsInput     []string
 	queryVarsJSONInput string
+	queryExplainCost   bool
+	queryMaxDepth      int
+	queryMaxCost       int
 )
 
 var queryCmd = &cobra.Command{
@@ -60,6 +64,9 @@ EOF
 }
 
 func Query(ctx context.Context, engineClient *client.Client, _ *dagger.Module, cmd *cobra.Command, args []string) (rerr error) {
+	if queryExplainCost {
+		return explainQueryCost(ctx, engineClient, cmd, args)
+	}
 	res, err := runQuery(ctx, engineClient, args)
 	if err != nil {
 		return err
@@ -77,9 +84,9 @@ func runQuery(
 	engineClient *client.Client,
 	args []string,
 ) (map[string]any, error) {
-	var operation string
-	if len(args) > 0 {
-		operation = args[0]
+	operations, operation, err := readQuery(args)
+	if err != nil {
+		return nil, err
 	}
 
 	vars := make(map[string]any)
@@ -91,26 +98,80 @@ func runQuery(
 		vars = getKVInput(queryVarsInput)
 	}
 
+	res := make(map[string]any)
+	err = engineClient.Do(ctx, operations, operation, vars, &res)
+	return res, err
+}
+
+// readQuery returns the query document and the name of the operation to run.
+func readQuery(args []string) (operations string, operation string, _ error) {
+	if len(args) > 0 {
+		operation = args[0]
+	}
+
 	// Use the provided query file if specified
 	// Otherwise, if stdin is a pipe or other non-tty thing, read from it.
-	var operations string
 	if queryFile != "" {
 		inBytes, err := os.ReadFile(queryFile)
 		if err != nil {
-			return nil, err
+			return "", "", err
 		}
 		operations = string(inBytes)
 	} else if !term.IsTerminal(int(os.Stdin.Fd())) {
 		inBytes, err := io.ReadAll(os.Stdin)
 		if err != nil {
-			return nil, err
+			return "", "", err
 		}
 		operations = string(inBytes)
 	}
+	return operations, operation, nil
+}
 
-	res := make(map[string]any)
-	err := engineClient.Do(ctx, operations, operation, vars, &res)
-	return res, err
+// explainQueryCost prints the engine's estimate of the cost of the query
+// instead of running it.
+func explainQueryCost(ctx context.Context, engineClient *client.Client, cmd *cobra.Command, args []string) error {
+	operations, operation, err := readQuery(args)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		QueryCost struct {
+			Depth    int
+			Cost     int
+			MaxDepth int
+			MaxCost  int
+		} `json:"__queryCost"`
+	}
+	err = engineClient.Do(ctx,
+		`query QueryCost($query: String!, $operationName: String!) {
+			__queryCost(query: $query, operationName: $operationName) {
+				depth
+				cost
+				maxDepth
+				maxCost
+			}
+		}`,
+		"QueryCost",
+		map[string]any{
+			"query":         operations,
+			"operationName": operation,
+		},
+		&res,
+	)
+	if err != nil {
+		return err
+	}
+	limit := func(n int) string {
+		if n == 0 {
+			return "unlimited"
+		}
+		return strconv.Itoa(n)
+	}
+	cost := res.QueryCost
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Depth: %d (limit: %s)\n", cost.Depth, limit(cost.MaxDepth))
+	fmt.Fprintf(out, "Cost:  %d (limit: %s)\n", cost.Cost, limit(cost.MaxCost))
+	return nil
 }
 
 func getKVInput(kvs []string) map[string]any {
@@ -126,5 +187,8 @@ func init() {
 	queryCmd.Flags().StringVar(&queryFile, "doc", "", "Read query from file (defaults to reading from stdin)")
 	queryCmd.Flags().StringSliceVar(&queryVarsInput, "var", nil, "List of query variables, in key=value format")
 	queryCmd.Flags().StringVar(&queryVarsJSONInput, "var-json", "", "Query variables in JSON format (overrides --var)")
+	queryCmd.Flags().BoolVar(&queryExplainCost, "explain-cost", false, "Print the estimated cost of the query instead of running it")
+	queryCmd.Flags().IntVar(&queryMaxDepth, "max-depth", 0, "Reject queries nested deeper than this, in addition to the engine's limit")
+	queryCmd.Flags().IntVar(&queryMaxCost, "max-cost", 0, "Reject queries with a higher estimated cost, in addition to the engine's limit")
 	queryCmd.MarkFlagFilename("doc", "graphql", "gql")
 }
@@ -45,12 +45,17 @@ var shellCmd = &cobra.Command{
 	Short: "Run an interactive dagger shell",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SetContext(idtui.WithPrintTraceLink(cmd.Context(), true))
-		return withEngine(cmd.Context(), client.Params{}, func(ctx context.Context, engineClient *client.Client) error {
+		watcher := newHostWatcher()
+		params := client.Params{
+			OnLocalSync: watcher.Observe,
+		}
+		return withEngine(cmd.Context(), params, func(ctx context.Context, engineClient *client.Client) error {
 			dag := engineClient.Dagger()
 			handler := &shellCallHandler{
 				dag:      dag,
 				llmModel: llmModel,
 				mode:     modeShell,
+				watcher:  watcher,
 			}
 
 			err := handler.RunAll(ctx, args)
@@ -126,6 +131,18 @@ type shellCallHandler struct {
 
 	// cancel interrupts the entire shell session
 	cancel func()
+
+	// watcher records the host paths loaded by `.watch` runs
+	watcher *hostWatcher
+
+	// tryErr is the error from the last `.try`, until handled by `.catch`
+	tryErr error
+
+	// session has the commands that ran successfully in interactive mode
+	session []string
+
+	// argValues completes the values of function arguments
+	argValues *argCompleter
 }
 
 // Debug prints to stderr internal command handler state and workflow that
@@ -256,6 +273,53 @@ func (h *shellCallHandler) Eval(ctx context.Context, code string) error {
 	return h.run(ctx, strings.NewReader(code), "")
 }
 
+// Watch runs code in a subshell, and again whenever the host paths it loaded
+// or the current module's source change, until interrupted.
+func (h *shellCallHandler) Watch(ctx context.Context, code string) error {
+	if h.watcher == nil {
+		return fmt.Errorf("watching is not supported in this session")
+	}
+	file, err := parseShell(strings.NewReader(code), "")
+	if err != nil {
+		return err
+	}
+	hctx := interp.HandlerCtx(ctx)
+
+	def := h.GetDef(nil)
+	return h.watcher.Run(ctx, func(ctx context.Context) error {
+		if def.SourceKind == dagger.ModuleSourceKindLocalSource {
+			// The module was loaded before watching started, so load it
+			// again on every run to pick up changes to its source.
+			if err := h.reloadModule(ctx, def); err != nil {
+				fmt.Fprintln(hctx.Stderr, "Error:", err)
+				return err
+			}
+		}
+		r := h.runner.Subshell()
+		interp.StdIO(nil, hctx.Stdout, hctx.Stderr)(r)
+		err := r.Run(ctx, file)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintln(hctx.Stderr, "Error:", err)
+		}
+		return err
+	})
+}
+
+// reloadModule loads a local module again from the host, replacing its
+// definition.
+func (h *shellCallHandler) reloadModule(ctx context.Context, def *moduleDef) error {
+	src, err := loadModuleSource(withHostReload(ctx), h.dag, def.SourceRoot)
+	if err != nil {
+		return err
+	}
+	newDef, err := initializeModule(ctx, h.dag, def.SourceRoot, src)
+	if err != nil {
+		return fmt.Errorf("failed to reload module: %w", err)
+	}
+	h.modDefs.Store(def.SourceDigest, newDef)
+	return nil
+}
+
 // run parses code and executes the interpreter's Runner
 func (h *shellCallHandler) run(ctx context.Context, reader io.Reader, name string) error {
 	file, err := parseShell(reader, name)
@@ -393,7 +457,11 @@ func (h *shellCallHandler) Handle(ctx context.Context, line string) (rerr error)
 	}
 
 	// Run shell command
-	return h.run(ctx, strings.NewReader(line), "")
+	if err := h.run(ctx, strings.NewReader(line), ""); err != nil {
+		return err
+	}
+	h.recordCommand(line)
+	return nil
 }
 
 func (h *shellCallHandler) Prompt(ctx context.Context, out idtui.TermOutput, fg termenv.Color) (string, tea.Cmd) {
@@ -515,6 +515,211 @@ Example:
 				return nil
 			},
 		},
+		&ShellCommand{
+			Use: ".watch <command>",
+			Description: `Run a command again whenever the local files it loads change
+
+The command, usually a quoted pipeline, runs in a subshell. It runs again each
+time one of the host directories or files it loaded changes, or the source of
+the current module if it's local. A run that's still in progress when a change
+is detected is canceled. Interrupt to stop watching.
+
+Example:
+
+  .watch 'container | from alpine | with-directory /src $(host | directory .) | with-exec ls /src | stdout'
+`,
+			Args:               ExactArgs(1),
+			State:              NoState,
+			NoResolveStateArgs: true,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				return h.Watch(ctx, args[0])
+			},
+		},
+		&ShellCommand{
+			Use: ".try <command> [.catch <handler>]",
+			Description: `Run a command without failing the shell on error
+
+The command, usually a quoted pipeline, runs in a subshell. If it fails, the
+error is kept to be handled by the next .catch, instead of exiting the shell.
+The handler can be given directly after the command.
+
+Example:
+
+  .try 'container | from alpine | with-exec false | stdout' .catch '.echo "failed with exit code $EXIT_CODE"'
+`,
+			Args: func(args []string) error {
+				switch {
+				case len(args) == 1:
+					return nil
+				case len(args) == 3 && args[1] == ".catch":
+					return nil
+				}
+				return fmt.Errorf("requires a command and an optional .catch handler")
+			},
+			State:              NoState,
+			NoResolveStateArgs: true,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				if err := h.Try(ctx, args[0]); err != nil {
+					return err
+				}
+				if len(args) == 3 {
+					return h.Catch(ctx, args[2])
+				}
+				return nil
+			},
+		},
+		&ShellCommand{
+			Use: ".catch <handler>",
+			Description: `Handle the error from the last .try
+
+The handler runs in a subshell only if the last .try failed, with the following
+variables set:
+
+  ERROR       the error message
+  EXIT_CODE   the exit code
+  STDOUT      the standard output, if an exec failed
+  STDERR      the standard error, if an exec failed
+
+Example:
+
+  .try 'container | from alpine | with-exec sh -c "echo oops >&2; exit 3" | stdout'
+  .catch '.echo "exit code $EXIT_CODE: $STDERR"'
+`,
+			Args:               ExactArgs(1),
+			State:              NoState,
+			NoResolveStateArgs: true,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				return h.Catch(ctx, args[0])
+			},
+		},
+		&ShellCommand{
+			Use: ".items",
+			Description: `Split a list result into its items, one per line
+
+Each object in the list is loaded from its own ID, so it can be used in a loop
+without selecting it again. Lists of other types print one value per line.
+
+Example:
+
+  for port in $(container | from nginx | exposed-ports | .items); do
+    $port | port
+  done
+`,
+			Args:  NoArgs,
+			State: RequiredState,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, st *ShellState) error {
+				return h.Items(ctx, st)
+			},
+		},
+		&ShellCommand{
+			Use: ".functions [name...]",
+			Description: `Print the source of user-defined shell functions
+
+Without arguments, prints all the functions defined in the session. Redirect
+the output to a ` + shellLibraryExt + ` file to share them as a library that can be
+loaded with .source.
+
+Example:
+
+  alpine() { container | from alpine; }
+  .functions alpine > lib` + shellLibraryExt + `
+`,
+			State: NoState,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				src, err := h.FunctionsSource(args...)
+				if err != nil {
+					return err
+				}
+				if src == "" {
+					return nil
+				}
+				return h.Print(ctx, src)
+			},
+		},
+		&ShellCommand{
+			Use: ".source <file>",
+			Description: `Run the commands in a file in the current shell
+
+Functions and variables defined in the file remain available afterwards, which
+makes it useful to load a library of functions, conventionally with a
+` + shellLibraryExt + ` extension.
+
+Example:
+
+  .source lib` + shellLibraryExt + `
+  alpine | with-exec echo hello | stdout
+`,
+			Args:  ExactArgs(1),
+			State: NoState,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				return h.Source(ctx, args[0])
+			},
+		},
+		&ShellCommand{
+			Use: ".diff [--json] <object> <object>",
+			Description: `Compare two directories or containers
+
+Shows the files that were added (A), removed (D) or changed (M), with a unified
+diff for changed text files. Containers are also compared by their config,
+environment variables and labels. Use --json for a machine-readable output.
+
+Example:
+
+  .diff $(host | directory ./before) $(host | directory ./after)
+  .diff --json $(container | from alpine:3.21) $(container | from alpine:3.22)
+`,
+			State:              NoState,
+			NoResolveStateArgs: true,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				return h.Diff(ctx, args)
+			},
+		},
+		&ShellCommand{
+			Use: ".save <name>",
+			Description: `Save a variable to load it in a later session
+
+Objects are saved by ID, so they don't need to be built again when loaded with
+.load, as long as they're still in the cache.
+
+Example:
+
+  ctr=$(container | from alpine | with-exec apk add git)
+  .save ctr
+`,
+			Args:  ExactArgs(1),
+			State: NoState,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				return h.SaveVar(ctx, args[0])
+			},
+		},
+		&ShellCommand{
+			Use: ".load <name>",
+			Description: `Load a variable saved with .save in a previous session
+
+Example:
+
+  .load ctr
+  $ctr | with-exec git --version | stdout
+`,
+			Args:  ExactArgs(1),
+			State: NoState,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				return h.LoadVar(ctx, args[0])
+			},
+		},
+		&ShellCommand{
+			Use: ".export-script <file>",
+			Description: `Write the commands run in this session to a script
+
+Only the commands that succeeded are included. The script can be run again
+with 'dagger <file>'.
+`,
+			Args:  ExactArgs(1),
+			State: NoState,
+			Run: func(ctx context.Context, cmd *ShellCommand, args []string, _ *ShellState) error {
+				return h.ExportScript(ctx, args[0])
+			},
+		},
 		&ShellCommand{
 			Use: ".exit [code]",
 			Description: `Exit the shell with an optional status code
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"strings"
 
 	"github.com/vito/bubbline/computil"
 	"github.com/vito/bubbline/editline"
 	"mvdan.cc/sh/v3/syntax"
+
+	"dagger.io/dagger"
 )
 
 // shellAutoComplete is a wrapper for the shell call handler
@@ -109,8 +112,18 @@ func (h *shellAutoComplete) Do(entireInput [][]rune, row, col int) (msg string,
 	if shctx == nil {
 		return "", nil
 	}
-
-	completions := shctx.completions(inprogressPrefix)
+	var completions []string
+	if shctx.ModArg != nil {
+		// describe the JSON expected for the value being typed, and suggest
+		// the values that can be found
+		msg = shctx.ModArg.JSONShape()
+		completions = h.argCompleter().Complete(context.Background(), shctx.ModArg, inprogressPrefix)
+		if len(completions) == 0 {
+			return msg, nil
+		}
+	} else {
+		completions = shctx.completions(inprogressPrefix)
+	}
 	var matches []string
 	suggested := map[string]struct{}{}
 	for _, c := range completions {
@@ -122,7 +135,7 @@ func (h *shellAutoComplete) Do(entireInput [][]rune, row, col int) (msg string,
 			suggested[c] = struct{}{}
 		}
 	}
-	return "", editline.SimpleWordsCompletion(
+	return msg, editline.SimpleWordsCompletion(
 		matches,
 		"completion",
 		col,
@@ -154,12 +167,29 @@ func (h *shellAutoComplete) dispatchCall(previous *CompletionContext, call *synt
 
 	args := make([]string, 0, len(call.Args))
 	for _, arg := range call.Args {
+		if arg.End().Offset() > cursor {
+			// the in-progress word
+			break
+		}
 		args = append(args, arg.Lit())
 	}
 	if len(args) == 0 {
 		return previous
 	}
-	return previous.lookupField(args[0], args[1:])
+	next := previous.lookupField(args[0], args[1:])
+	if next != nil && next.ModFunction != nil && len(args) > 1 {
+		// completing the value of a flag
+		if flag, ok := strings.CutPrefix(args[len(args)-1], "--"); ok {
+			if arg, err := next.ModFunction.GetArg(flag); err == nil && arg.TypeDef.Kind != dagger.TypeDefKindBooleanKind {
+				next = &CompletionContext{
+					Completer:   next.Completer,
+					ModFunction: next.ModFunction,
+					ModArg:      arg,
+				}
+			}
+		}
+	}
+	return next
 }
 
 func (h *shellAutoComplete) dispatchPipe(previous *CompletionContext, pipe *syntax.BinaryCmd, cursor uint) *CompletionContext {
@@ -210,6 +240,9 @@ type CompletionContext struct {
 	// ModFunc indicates the completions should be performed on the arguments
 	// for a function call.
 	ModFunction *modFunction
+	// ModArg indicates the completions should be performed on the value of
+	// a function argument.
+	ModArg *modFunctionArg
 
 	root bool
 }
@@ -406,3 +439,14 @@ func (ctx *CompletionContext) stdlibCmd(name string) *ShellCommand {
 	}
 	return nil
 }
+
+// argCompleter returns the completer for argument values, which is kept for
+// the whole session to cache what it gets from the engine
+func (h *shellCallHandler) argCompleter() *argCompleter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.argValues == nil {
+		h.argValues = newArgCompleter(h.dag)
+	}
+	return h.argValues
+}
@@ -484,6 +484,14 @@ func (h *shellCallHandler) functionCall(ctx context.Context, st *ShellState, nam
 		return st, fmt.Errorf("function %q: %w", fn.CmdName(), err)
 	}
 
+	// When re-running with `.watch`, functions that load from the host,
+	// like `host | directory`, need to skip the cache to see changes.
+	if _, err := fn.GetArg("no-cache"); err == nil && hostReload(ctx) {
+		if _, ok := argValues["noCache"]; !ok {
+			argValues["noCache"] = true
+		}
+	}
+
 	newSt := st.WithCall(fn, argValues)
 
 	return &newSt, nil
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mvdan.cc/sh/v3/interp"
+	"mvdan.cc/sh/v3/syntax"
+
+	"dagger.io/dagger"
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// shellLibraryExt is the conventional file extension for a library of shell
+// functions, loaded with `.source`.
+const shellLibraryExt = ".dagger-shell"
+
+// Try runs code in a subshell and records its error instead of failing, so
+// that it can be handled by a following `.catch`.
+func (h *shellCallHandler) Try(ctx context.Context, code string) error {
+	err := h.runSubshell(ctx, code, nil)
+	if ctx.Err() != nil {
+		// Don't swallow interrupts.
+		return err
+	}
+	h.mu.Lock()
+	h.tryErr = err
+	h.mu.Unlock()
+	return nil
+}
+
+// Catch runs code in a subshell if the last `.try` failed, with variables
+// describing the error.
+func (h *shellCallHandler) Catch(ctx context.Context, code string) error {
+	h.mu.Lock()
+	tryErr := h.tryErr
+	h.tryErr = nil
+	h.mu.Unlock()
+
+	if tryErr == nil {
+		return nil
+	}
+	return h.runSubshell(ctx, code, tryErrorVars(tryErr))
+}
+
+// runSubshell parses and runs code in a subshell of the current runner, with
+// the given variables set.
+func (h *shellCallHandler) runSubshell(ctx context.Context, code string, vars map[string]string) error {
+	file, err := parseShell(strings.NewReader(code), "")
+	if err != nil {
+		return err
+	}
+	hctx := interp.HandlerCtx(ctx)
+	r := h.runner.Subshell()
+	interp.StdIO(nil, hctx.Stdout, hctx.Stderr)(r)
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	assigns := make([]*syntax.Stmt, 0, len(names))
+	for _, name := range names {
+		assigns = append(assigns, &syntax.Stmt{
+			Cmd: &syntax.CallExpr{
+				Assigns: []*syntax.Assign{{
+					Name: &syntax.Lit{Value: name},
+					Value: &syntax.Word{Parts: []syntax.WordPart{
+						&syntax.SglQuoted{Value: vars[name]},
+					}},
+				}},
+			},
+		})
+	}
+	file.Stmts = append(assigns, file.Stmts...)
+
+	return r.Run(ctx, file)
+}
+
+// tryErrorVars returns the variables available to a `.catch` handler
+func tryErrorVars(err error) map[string]string {
+	vars := map[string]string{
+		"ERROR":     err.Error(),
+		"EXIT_CODE": "1",
+	}
+	var status interp.ExitStatus
+	if errors.As(err, &status) {
+		vars["EXIT_CODE"] = strconv.Itoa(int(status))
+	}
+	var exe *dagger.ExecError
+	if errors.As(err, &exe) {
+		vars["EXIT_CODE"] = strconv.Itoa(exe.ExitCode)
+		vars["STDOUT"] = exe.Stdout
+		vars["STDERR"] = exe.Stderr
+	}
+	return vars
+}
+
+// Items writes a state for each object in a list result, one per line, so
+// that they can be iterated on. Lists of scalars are printed instead, with
+// one value per line.
+//
+// Each object is loaded from its own ID, which includes its index in the
+// list, so it doesn't need to be selected again from the parent.
+func (h *shellCallHandler) Items(ctx context.Context, st *ShellState) error {
+	if st.IsEmpty() {
+		return fmt.Errorf("expected a list")
+	}
+	def := h.GetDef(st)
+	fn, err := st.GetDef(def)
+	if err != nil {
+		return err
+	}
+	if fn.ReturnType.AsList == nil {
+		return fmt.Errorf("expected a list, got %s", fn.ReturnType.Long())
+	}
+	w := interp.HandlerCtx(ctx).Stdout
+
+	if fn.ReturnType.AsFunctionProvider() == nil {
+		var values []any
+		if err := makeRequest(ctx, st.QueryBuilder(h.dag), &values); err != nil {
+			return err
+		}
+		for _, v := range values {
+			fmt.Fprintln(w, v)
+		}
+		return nil
+	}
+
+	var ids []string
+	if err := makeRequest(ctx, st.QueryBuilder(h.dag).Select("id"), &ids); err != nil {
+		return err
+	}
+	tokens := make([]string, 0, len(ids))
+	for _, encoded := range ids {
+		var id call.ID
+		if err := id.Decode(encoded); err != nil {
+			return fmt.Errorf("failed to decode ID: %w", err)
+		}
+		typeName := id.Type().ToAST().Name()
+		key := h.state.Store(ShellState{
+			ModDigest: st.ModDigest,
+			Calls: []FunctionCall{{
+				Object:       "Query",
+				Name:         "load" + typeName + "FromID",
+				Arguments:    map[string]any{"id": encoded},
+				ReturnObject: typeName,
+			}},
+		})
+		tokens = append(tokens, newStateToken(key))
+	}
+	if len(tokens) > 0 {
+		_, err = fmt.Fprintln(w, strings.Join(tokens, "\n"))
+	}
+	return err
+}
+
+// FunctionsSource returns the source of user-defined shell functions, or
+// only the given ones, in a form that can be loaded with `.source`.
+func (h *shellCallHandler) FunctionsSource(names ...string) (string, error) {
+	funcs := h.runner.Funcs
+	if len(names) == 0 {
+		for name := range funcs {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+	}
+
+	var sb strings.Builder
+	printer := syntax.NewPrinter(syntax.Indent(4))
+	for _, name := range names {
+		body, ok := funcs[name]
+		if !ok {
+			return "", fmt.Errorf("function %q not defined", name)
+		}
+		decl := &syntax.Stmt{Cmd: &syntax.FuncDecl{
+			Name: &syntax.Lit{Value: name},
+			Body: body,
+		}}
+		// Print it in a single line and parse it again to drop what
+		// parseShell added to command substitutions, without changing the
+		// function's definition.
+		var src strings.Builder
+		if err := syntax.NewPrinter(syntax.SingleLine(true)).Print(&src, decl); err != nil {
+			return "", err
+		}
+		file, err := syntax.NewParser(syntax.Variant(syntax.LangPOSIX)).Parse(strings.NewReader(src.String()), "")
+		if err != nil {
+			return "", err
+		}
+		syntax.Walk(file, func(node syntax.Node) bool {
+			if node, ok := node.(*syntax.CmdSubst); ok {
+				if len(node.Stmts) > 0 && isClosedStdinExec(node.Stmts[0]) {
+					node.Stmts = node.Stmts[1:]
+				}
+			}
+			return true
+		})
+		if err := printer.Print(&sb, file); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+// isClosedStdinExec returns true if stmt is the `exec <&-` statement added
+// by parseShell to command substitutions
+func isClosedStdinExec(stmt *syntax.Stmt) bool {
+	ce, ok := stmt.Cmd.(*syntax.CallExpr)
+	if !ok || len(ce.Args) != 1 || len(stmt.Redirs) != 1 {
+		return false
+	}
+	return ce.Args[0].Lit() == shellInterpBuiltinPrefix+"exec" &&
+		stmt.Redirs[0].Op == syntax.DplIn &&
+		stmt.Redirs[0].Word.Lit() == "-"
+}
+
+// Source runs the commands in a file in the current shell, so that the
+// functions and variables it defines remain available.
+func (h *shellCallHandler) Source(ctx context.Context, path string) error {
+	hctx := interp.HandlerCtx(ctx)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(hctx.Dir, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	file, err := parseShell(f, path)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	if err := syntax.NewPrinter().Print(&sb, file); err != nil {
+		return err
+	}
+	return hctx.Builtin(ctx, []string{"eval", sb.String()})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"mvdan.cc/sh/v3/interp"
+)
+
+func TestShellFunctionsSource(t *testing.T) {
+	t.Parallel()
+
+	file, err := parseShell(strings.NewReader(`
+alpine() { container | from alpine; }
+build() {
+    alpine | with-directory /src $(host | directory "$1") | with-exec make
+}
+`), "")
+	require.NoError(t, err)
+
+	r, err := interp.New()
+	require.NoError(t, err)
+	require.NoError(t, r.Run(context.Background(), file))
+	h := &shellCallHandler{runner: r}
+
+	src, err := h.FunctionsSource()
+	require.NoError(t, err)
+	require.Equal(t, `alpine() { container | from alpine; }
+build() { alpine | with-directory /src $(host | directory "$1") | with-exec make; }`, src)
+
+	src, err = h.FunctionsSource("alpine")
+	require.NoError(t, err)
+	require.Equal(t, "alpine() { container | from alpine; }", src)
+
+	_, err = h.FunctionsSource("test")
+	require.ErrorContains(t, err, `function "test" not defined`)
+}
+
+func TestTryErrorVars(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, map[string]string{
+		"ERROR":     "oops",
+		"EXIT_CODE": "1",
+	}, tryErrorVars(fmt.Errorf("oops")))
+
+	require.Equal(t, map[string]string{
+		"ERROR":     "exit status 2",
+		"EXIT_CODE": "2",
+	}, tryErrorVars(interp.ExitStatus(2)))
+
+	require.Equal(t, map[string]string{
+		"ERROR":     "oops",
+		"EXIT_CODE": "3",
+	}, tryErrorVars(&HandlerError{Err: fmt.Errorf("oops"), ExitCode: 3}))
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"mvdan.cc/sh/v3/interp"
+	"mvdan.cc/sh/v3/syntax"
+
+	"github.com/dagger/dagger/dagql/idtui"
+	"github.com/dagger/dagger/engine/slog"
+)
+
+// shellDataDir is where the shell persists data between sessions
+var shellDataDir = filepath.Join(xdg.DataHome, "dagger", "shell")
+
+// dataDir returns the directory where the shell persists the history and
+// saved variables of the module loaded when the shell started, so that each
+// module gets its own.
+//
+// Without a module, the data is shared in the top-level directory.
+func (h *shellCallHandler) dataDir() string {
+	def, err := h.GetModuleDef(nil)
+	if err != nil {
+		return shellDataDir
+	}
+	sum := sha256.Sum256([]byte(def.SourceRoot))
+	return filepath.Join(shellDataDir, "modules", hex.EncodeToString(sum[:8]))
+}
+
+// HistoryFile returns the path of the history file for the module loaded when
+// the shell started.
+//
+// Without a module, the default history file is used. A module's history
+// starts as a copy of the default one, so that it isn't lost when moving to
+// per-module history.
+func (h *shellCallHandler) HistoryFile() string {
+	if _, err := h.GetModuleDef(nil); err != nil {
+		return ""
+	}
+	path := filepath.Join(h.dataDir(), "history")
+	if err := migrateHistory(idtui.DefaultHistoryFile, path); err != nil {
+		slog.Warn("failed to migrate shell history", "error", err)
+	}
+	return path
+}
+
+// migrateHistory copies the history file at src to dst, unless dst already
+// exists or there's no history to copy.
+func migrateHistory(src, dst string) error {
+	if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
+
+// savedVar is a variable persisted with `.save`
+type savedVar struct {
+	// Type is the object type name, if the value is an object
+	Type string `json:"type,omitempty"`
+
+	// ID is the encoded object ID, if the value is an object
+	ID string `json:"id,omitempty"`
+
+	// Value is the value of any other variable
+	Value string `json:"value,omitempty"`
+}
+
+func (h *shellCallHandler) savedVarPath(name string) string {
+	return filepath.Join(h.dataDir(), "vars", name+".json")
+}
+
+// SaveVar persists the value of a variable to disk so that it can be loaded
+// again in a later session with `.load`.
+//
+// Objects are saved as IDs, which the API can load in a new session.
+func (h *shellCallHandler) SaveVar(ctx context.Context, name string) error {
+	if !syntax.ValidName(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	vr := interp.HandlerCtx(ctx).Env.Get(name)
+	if !vr.IsSet() {
+		return fmt.Errorf("variable %q not set", name)
+	}
+	value := vr.String()
+
+	saved := savedVar{Value: value}
+
+	if key := GetStateKey(value); key != "" {
+		st, err := h.state.Load(key)
+		if err != nil {
+			return err
+		}
+		saved.Value = ""
+		saved.Type, saved.ID, err = h.objectID(ctx, st)
+		if err != nil {
+			return fmt.Errorf("save %q: %w", name, err)
+		}
+	} else if HasState(value) {
+		return fmt.Errorf("save %q: can't save a value with embedded objects", name)
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := h.savedVarPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// objectID returns the type name and ID of the object a state refers to
+func (h *shellCallHandler) objectID(ctx context.Context, st *ShellState) (string, string, error) {
+	if st.IsCommandRoot() {
+		return "", "", fmt.Errorf("expected an object, got %q", st.Cmd)
+	}
+	def := h.GetDef(st)
+	var err error
+	if def.HasModule() && st.IsEmpty() {
+		st, err = h.constructorCall(ctx, def, st, nil)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	fn, err := st.Function().GetDef(def)
+	if err != nil {
+		return "", "", err
+	}
+	if fn.ReturnType.AsList != nil || fn.ReturnType.AsFunctionProvider() == nil {
+		return "", "", fmt.Errorf("expected an object, got %s", fn.ReturnType.Long())
+	}
+	var id string
+	if err := makeRequest(ctx, st.QueryBuilder(h.dag).Select("id"), &id); err != nil {
+		return "", "", err
+	}
+	return fn.ReturnType.Name(), id, nil
+}
+
+// LoadVar sets a variable in the current shell with the value persisted with
+// `.save`
+func (h *shellCallHandler) LoadVar(ctx context.Context, name string) error {
+	if !syntax.ValidName(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	data, err := os.ReadFile(h.savedVarPath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("variable %q not saved", name)
+		}
+		return err
+	}
+	var saved savedVar
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("load %q: %w", name, err)
+	}
+
+	value := saved.Value
+	if saved.ID != "" {
+		key := h.state.Store(ShellState{
+			Calls: []FunctionCall{{
+				Object:       "Query",
+				Name:         "load" + saved.Type + "FromID",
+				Arguments:    map[string]any{"id": saved.ID},
+				ReturnObject: saved.Type,
+			}},
+		})
+		value = newStateToken(key)
+	}
+	quoted, err := syntax.Quote(value, syntax.LangPOSIX)
+	if err != nil {
+		return err
+	}
+	return interp.HandlerCtx(ctx).Builtin(ctx, []string{"eval", name + "=" + quoted})
+}
+
+// recordCommand adds a command that ran successfully in the interactive
+// shell to the session, for `.export-script`
+func (h *shellCallHandler) recordCommand(line string) {
+	if fields := strings.Fields(line); len(fields) > 0 && fields[0] == ".export-script" {
+		return
+	}
+	h.mu.Lock()
+	h.session = append(h.session, line)
+	h.mu.Unlock()
+}
+
+// ExportScript writes the commands that ran successfully in the session as a
+// script that can be run again with `dagger`.
+func (h *shellCallHandler) ExportScript(ctx context.Context, path string) error {
+	hctx := interp.HandlerCtx(ctx)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(hctx.Dir, path)
+	}
+
+	h.mu.RLock()
+	lines := h.session
+	h.mu.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env dagger\n")
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0o755) //nolint:gosec
+}
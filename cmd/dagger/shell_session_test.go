@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateHistory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "histfile")
+	dst := filepath.Join(dir, "modules", "abc", "history")
+
+	// nothing to migrate
+	require.NoError(t, migrateHistory(legacy, dst))
+	require.NoFileExists(t, dst)
+
+	require.NoError(t, os.WriteFile(legacy, []byte("container\n"), 0o600))
+	require.NoError(t, migrateHistory(legacy, dst))
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "container\n", string(data))
+
+	// the module's own history is kept
+	require.NoError(t, os.WriteFile(dst, []byte("directory\n"), 0o600))
+	require.NoError(t, migrateHistory(legacy, dst))
+	data, err = os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "directory\n", string(data))
+}
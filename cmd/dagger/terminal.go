@@ -3,9 +3,14 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
 	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/dagger/dagger/engine/session/terminal"
 )
 
 var terminalMu sync.Mutex
@@ -49,3 +54,36 @@ func (ts *terminalSession) SetStderr(w io.Writer) {
 func (ts *terminalSession) Run() error {
 	return ts.fn(ts.stdin, ts.stdout, ts.stderr)
 }
+
+var (
+	terminalReplayPath  string
+	terminalReplaySpeed float64
+	terminalIdleLimit   time.Duration
+)
+
+var terminalCmd = &cobra.Command{
+	Use:   "terminal --replay <file>",
+	Short: "Play back a recorded terminal session",
+	Long: `Play back a terminal session recorded with the record argument of Container.terminal.
+
+Recordings are in the asciicast v2 format, so they can also be played with
+other tools, such as asciinema.`,
+	Example: `dagger -c 'container | from alpine | terminal --record | file /terminal.cast | export session.cast'
+dagger terminal --replay session.cast --speed 2`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		f, err := os.Open(terminalReplayPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return terminal.Replay(cmd.Context(), f, cmd.OutOrStdout(), terminalReplaySpeed, terminalIdleLimit)
+	},
+}
+
+func init() {
+	terminalCmd.Flags().StringVar(&terminalReplayPath, "replay", "", "Path to an asciicast v2 recording to play back")
+	terminalCmd.Flags().Float64Var(&terminalReplaySpeed, "speed", 1, "Playback speed multiplier")
+	terminalCmd.Flags().DurationVar(&terminalIdleLimit, "idle-time-limit", 0, "Shorten pauses longer than this duration")
+	terminalCmd.MarkFlagRequired("replay")
+}
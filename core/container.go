@@ -19,7 +19,6 @@ import (
 	"dagger.io/dagger/telemetry"
 	"github.com/containerd/containerd/content"
 	"github.com/containerd/containerd/images"
-	"github.com/containerd/containerd/pkg/transfer/archive"
 	"github.com/containerd/platforms"
 	"github.com/distribution/reference"
 	bkcache "github.com/moby/buildkit/cache"
@@ -1570,7 +1569,28 @@ func (container *Container) Export(
 func (container *Container) Import(
 	ctx context.Context,
 	tarball io.Reader,
-	tag string,
+	sel ImageSelector,
+) (*Container, error) {
+	return container.importIndex(ctx, func(ctx context.Context, store content.Store) (specs.Descriptor, error) {
+		return importImageArchive(ctx, store, tarball)
+	}, sel)
+}
+
+// ImportLayout imports the container from an OCI image layout directory.
+func (container *Container) ImportLayout(
+	ctx context.Context,
+	dir *Directory,
+	sel ImageSelector,
+) (*Container, error) {
+	return container.importIndex(ctx, func(ctx context.Context, store content.Store) (specs.Descriptor, error) {
+		return importImageLayout(ctx, store, dir)
+	}, sel)
+}
+
+func (container *Container) importIndex(
+	ctx context.Context,
+	load func(context.Context, content.Store) (specs.Descriptor, error),
+	sel ImageSelector,
 ) (*Container, error) {
 	query, err := CurrentQuery(ctx)
 	if err != nil {
@@ -1589,14 +1609,12 @@ func (container *Container) Import(
 			return nil, err
 		}
 
-		stream := archive.NewImageImportStream(tarball, "")
-
-		desc, err := stream.Import(ctx, store)
+		desc, err := load(ctx, store)
 		if err != nil {
-			return nil, fmt.Errorf("image archive import: %w", err)
+			return nil, err
 		}
 
-		return resolveIndex(ctx, store, desc, container.Platform.Spec(), tag)
+		return resolveIndex(ctx, store, desc, container.Platform.Spec(), sel)
 	}
 	defer func() {
 		if release != nil {
@@ -1608,6 +1626,10 @@ func (container *Container) Import(
 	if err != nil {
 		return nil, fmt.Errorf("recover: %w", err)
 	}
+	if manifestDesc.Platform != nil && !platforms.Only(container.Platform.Spec()).Match(*manifestDesc.Platform) {
+		// the manifest was selected by digest for another platform
+		container.Platform = Platform(platforms.Normalize(*manifestDesc.Platform))
+	}
 
 	return container.FromInternal(ctx, *manifestDesc)
 }
@@ -1771,6 +1793,10 @@ type ContainerAsServiceArgs struct {
 	// Grant the process all root capabilities
 	InsecureRootCapabilities bool `default:"false"`
 
+	// Run the process in a user namespace, mapping root in the container to
+	// an unprivileged user on the host
+	UserNamespace bool `default:"false"`
+
 	// Expand the environment variables in args
 	Expand bool `default:"false"`
 
@@ -1809,6 +1835,7 @@ func (container *Container) AsService(ctx context.Context, args ContainerAsServi
 		Args:                          cmdargs,
 		ExperimentalPrivilegedNesting: args.ExperimentalPrivilegedNesting,
 		InsecureRootCapabilities:      args.InsecureRootCapabilities,
+		UserNamespace:                 args.UserNamespace,
 		NoInit:                        args.NoInit,
 	}, nil
 }
@@ -1881,44 +1908,36 @@ func (BuildArg) TypeDescription() string {
 const ociTagAnnotation = "org.opencontainers.image.ref.name"
 
 func ResolveIndex(ctx context.Context, store content.Store, desc specs.Descriptor, platform specs.Platform, tag string) (*specs.Descriptor, error) {
-	return resolveIndex(ctx, store, desc, platform, tag)
+	return resolveIndex(ctx, store, desc, platform, ImageSelector{Tag: tag})
 }
 
-func resolveIndex(ctx context.Context, store content.Store, desc specs.Descriptor, platform specs.Platform, tag string) (*specs.Descriptor, error) {
-	if desc.MediaType != specs.MediaTypeImageIndex {
-		return nil, fmt.Errorf("expected index, got %s", desc.MediaType)
-	}
-
-	indexBlob, err := content.ReadBlob(ctx, store, desc)
-	if err != nil {
-		return nil, fmt.Errorf("read index blob: %w", err)
-	}
-
-	var idx specs.Index
-	err = json.Unmarshal(indexBlob, &idx)
+func resolveIndex(ctx context.Context, store content.Store, desc specs.Descriptor, platform specs.Platform, sel ImageSelector) (*specs.Descriptor, error) {
+	idx, err := readIndex(ctx, store, desc)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshal index: %w", err)
+		return nil, err
 	}
 
 	matcher := platforms.Only(platform)
 
 	for _, m := range idx.Manifests {
-		if m.Platform != nil {
+		// a manifest selected by its digest is imported regardless of the
+		// container's platform
+		if m.Platform != nil && (sel.Digest == "" || m.Digest != sel.Digest) {
 			if !matcher.Match(*m.Platform) {
 				// incompatible
 				continue
 			}
 		}
 
-		if tag != "" {
-			if m.Annotations == nil {
-				continue
-			}
-
-			manifestTag, found := m.Annotations[ociTagAnnotation]
-			if !found || manifestTag != tag {
-				continue
+		if !sel.Matches(m) {
+			if sel.Digest != "" && isIndexMediaType(m.MediaType) {
+				// the digest may select a manifest in a multi-platform index
+				desc, err := resolveIndex(ctx, store, m, platform, ImageSelector{Digest: sel.Digest})
+				if err == nil {
+					return desc, nil
+				}
 			}
+			continue
 		}
 
 		switch m.MediaType {
@@ -1928,14 +1947,38 @@ func resolveIndex(ctx context.Context, store content.Store, desc specs.Descripto
 
 		case specs.MediaTypeImageIndex, // OCI
 			images.MediaTypeDockerSchema2ManifestList: // Docker
-			return resolveIndex(ctx, store, m, platform, tag)
+			// the selector applies to this index as a whole, so only the
+			// platform is used to pick a manifest from it
+			return resolveIndex(ctx, store, m, platform, ImageSelector{})
 
 		default:
 			return nil, fmt.Errorf("expected manifest or index, got %s", m.MediaType)
 		}
 	}
 
-	return nil, fmt.Errorf("no manifest for platform %s and tag %s", platforms.Format(platform), tag)
+	return nil, fmt.Errorf("no manifest for platform %s and %s", platforms.Format(platform), sel)
+}
+
+func isIndexMediaType(mediaType string) bool {
+	return mediaType == specs.MediaTypeImageIndex || mediaType == images.MediaTypeDockerSchema2ManifestList
+}
+
+func readIndex(ctx context.Context, store content.Store, desc specs.Descriptor) (*specs.Index, error) {
+	if !isIndexMediaType(desc.MediaType) {
+		return nil, fmt.Errorf("expected index, got %s", desc.MediaType)
+	}
+
+	indexBlob, err := content.ReadBlob(ctx, store, desc)
+	if err != nil {
+		return nil, fmt.Errorf("read index blob: %w", err)
+	}
+
+	var idx specs.Index
+	err = json.Unmarshal(indexBlob, &idx)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal index: %w", err)
+	}
+	return &idx, nil
 }
 
 type ImageLayerCompression string
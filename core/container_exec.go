@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"runtime"
 	"slices"
@@ -66,6 +67,10 @@ type ContainerExecOpts struct {
 	// Grant the process all root capabilities
 	InsecureRootCapabilities bool `default:"false"`
 
+	// Run the process in a user namespace, mapping root in the container to
+	// an unprivileged user on the host
+	UserNamespace bool `default:"false"`
+
 	// Expand the environment variables in args
 	Expand bool `default:"false"`
 
@@ -127,6 +132,9 @@ func (container *Container) execMeta(ctx context.Context, opts ContainerExecOpts
 	if opts.NoInit {
 		execMD.NoInit = true
 	}
+	if opts.UserNamespace {
+		execMD.UserNamespace = true
+	}
 
 	if execMD.EncodedModuleID != "" {
 		modID := new(call.ID)
@@ -446,6 +454,94 @@ func (container *Container) Stdout(ctx context.Context) (string, error) {
 	return container.metaFileContents(ctx, buildkit.MetaMountStdoutPath)
 }
 
+// StdoutStream streams the stdout of the container's last exec as it runs,
+// given the ID of the call that started it. If the exec doesn't run (e.g. it
+// was cached) or was already running, the output not seen live is sent once
+// the exec is done. Only execs started by the client's session are streamed
+// live.
+func (container *Container) StdoutStream(ctx context.Context, execID *call.ID) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if container.Meta == nil {
+			yield("", ErrNoCommand)
+			return
+		}
+		query, err := CurrentQuery(ctx)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		bk, err := query.Buildkit(ctx)
+		if err != nil {
+			yield("", fmt.Errorf("failed to get buildkit client: %w", err))
+			return
+		}
+
+		clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		// subscribe before evaluating, so the start of the output isn't missed
+		sub := bk.SubscribeExecStdout(clientMetadata.SessionID, execID.Digest())
+		defer sub.Close()
+
+		type result struct {
+			stdout string
+			err    error
+		}
+		done := make(chan result, 1)
+		go func() {
+			stdout, err := container.Stdout(ctx)
+			done <- result{stdout, err}
+		}()
+
+		var streamed strings.Builder
+		var lostAny bool
+		send := func(chunk []byte, lost int) bool {
+			if len(chunk) > 0 {
+				streamed.Write(chunk)
+				if !yield(string(chunk), nil) {
+					return false
+				}
+			}
+			if lost > 0 {
+				// the client didn't keep up with the output; tell it what it
+				// missed rather than silently skipping it
+				lostAny = true
+				return yield(fmt.Sprintf("\n[%d bytes of output lost: the client is reading too slowly]\n", lost), nil)
+			}
+			return true
+		}
+		for {
+			select {
+			case <-sub.Ready():
+				if !send(sub.Take()) {
+					return
+				}
+			case res := <-done:
+				if res.err != nil {
+					yield("", res.err)
+					return
+				}
+				if !send(sub.Take()) {
+					return
+				}
+				if lostAny {
+					return
+				}
+				if rest, ok := strings.CutPrefix(res.stdout, streamed.String()); ok {
+					send([]byte(rest), 0)
+				}
+				return
+			case <-ctx.Done():
+				yield("", context.Cause(ctx))
+				return
+			}
+		}
+	}
+}
+
 func (container *Container) Stderr(ctx context.Context) (string, error) {
 	return container.metaFileContents(ctx, buildkit.MetaMountStderrPath)
 }
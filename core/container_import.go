@@ -0,0 +1,280 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/pkg/transfer/archive"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ImageSelector selects an image from the index of an image archive or
+// layout. The zero value selects the first image matching the platform.
+type ImageSelector struct {
+	// Tag matches the org.opencontainers.image.ref.name annotation.
+	Tag string
+	// Digest matches the digest of the manifest or index descriptor.
+	Digest digest.Digest
+	// Annotations must all be set to the given values on the descriptor.
+	Annotations []ImageAnnotation
+}
+
+func (sel ImageSelector) Matches(desc specs.Descriptor) bool {
+	if sel.Tag != "" && desc.Annotations[ociTagAnnotation] != sel.Tag {
+		return false
+	}
+	if sel.Digest != "" && desc.Digest != sel.Digest {
+		return false
+	}
+	for _, annotation := range sel.Annotations {
+		value, ok := desc.Annotations[annotation.Name]
+		if !ok || value != annotation.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func (sel ImageSelector) String() string {
+	var parts []string
+	if sel.Tag != "" {
+		parts = append(parts, fmt.Sprintf("tag %s", sel.Tag))
+	}
+	if sel.Digest != "" {
+		parts = append(parts, fmt.Sprintf("digest %s", sel.Digest))
+	}
+	for _, annotation := range sel.Annotations {
+		parts = append(parts, fmt.Sprintf("annotation %s=%s", annotation.Name, annotation.Value))
+	}
+	if len(parts) == 0 {
+		return "no selector"
+	}
+	return strings.Join(parts, ", ")
+}
+
+type ImageAnnotation struct {
+	Name  string `field:"true" doc:"The annotation name."`
+	Value string `field:"true" doc:"The annotation value."`
+}
+
+func (ImageAnnotation) TypeName() string {
+	return "ImageAnnotation"
+}
+
+func (ImageAnnotation) TypeDescription() string {
+	return "Key value object that represents an OCI annotation used to select an image."
+}
+
+// ImageDescriptor describes an image found in an image archive or layout.
+type ImageDescriptor struct {
+	Digest    string `field:"true" doc:"The digest of the image manifest."`
+	MediaType string `field:"true" doc:"The media type of the image manifest."`
+	Platform  string `field:"true" doc:"The platform of the image, if known."`
+	Tag       string `field:"true" doc:"The tag of the image, if any."`
+
+	// IndexDigest is the digest of the index descriptor the manifest was
+	// found under, for multi-platform images.
+	IndexDigest string `field:"true" doc:"The digest of the multi-platform index containing the image manifest, if any."`
+
+	Annotations map[string]string
+}
+
+func (*ImageDescriptor) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "ImageDescriptor",
+		NonNull:   true,
+	}
+}
+
+func (*ImageDescriptor) TypeDescription() string {
+	return "An image found in an OCI tarball, Docker archive or OCI layout directory."
+}
+
+// ImageArchiveDescriptors lists the images in an OCI tarball or Docker
+// archive.
+func ImageArchiveDescriptors(ctx context.Context, tarball io.Reader) ([]*ImageDescriptor, error) {
+	return listImages(ctx, func(ctx context.Context, store content.Store) (specs.Descriptor, error) {
+		return importImageArchive(ctx, store, tarball)
+	})
+}
+
+// ImageLayoutDescriptors lists the images in an OCI layout directory.
+func ImageLayoutDescriptors(ctx context.Context, dir *Directory) ([]*ImageDescriptor, error) {
+	return listImages(ctx, func(ctx context.Context, store content.Store) (specs.Descriptor, error) {
+		return importImageLayout(ctx, store, dir)
+	})
+}
+
+func listImages(ctx context.Context, load func(context.Context, content.Store) (specs.Descriptor, error)) ([]*ImageDescriptor, error) {
+	query, err := CurrentQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	store := query.OCIStore()
+
+	ctx, release, err := leaseutil.WithLease(ctx, query.LeaseManager(), leaseutil.MakeTemporary)
+	if err != nil {
+		return nil, err
+	}
+	defer release(ctx)
+
+	desc, err := load(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return indexImages(ctx, store, desc, nil)
+}
+
+func indexImages(ctx context.Context, store content.Store, desc specs.Descriptor, parent *specs.Descriptor) ([]*ImageDescriptor, error) {
+	idx, err := readIndex(ctx, store, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	var descs []*ImageDescriptor
+	for _, m := range idx.Manifests {
+		switch m.MediaType {
+		case specs.MediaTypeImageManifest, // OCI
+			images.MediaTypeDockerSchema2Manifest: // Docker
+			// attestations and other non-image manifests are listed with an
+			// unknown platform in multi-platform indexes; skip them
+			if parent != nil && m.Platform != nil && m.Platform.OS == "unknown" {
+				continue
+			}
+
+			img := &ImageDescriptor{
+				Digest:      m.Digest.String(),
+				MediaType:   m.MediaType,
+				Annotations: map[string]string{},
+			}
+			if parent != nil {
+				img.IndexDigest = parent.Digest.String()
+				maps.Copy(img.Annotations, parent.Annotations)
+			}
+			maps.Copy(img.Annotations, m.Annotations)
+			img.Tag = img.Annotations[ociTagAnnotation]
+			if m.Platform != nil {
+				img.Platform = platforms.Format(*m.Platform)
+			}
+			descs = append(descs, img)
+
+		case specs.MediaTypeImageIndex, // OCI
+			images.MediaTypeDockerSchema2ManifestList: // Docker
+			nested, err := indexImages(ctx, store, m, &m)
+			if err != nil {
+				return nil, err
+			}
+			descs = append(descs, nested...)
+		}
+	}
+	return descs, nil
+}
+
+// importImageArchive imports an OCI tarball or Docker archive into the
+// store, returning the descriptor of its index.
+func importImageArchive(ctx context.Context, store content.Store, tarball io.Reader) (specs.Descriptor, error) {
+	stream := archive.NewImageImportStream(tarball, "")
+
+	desc, err := stream.Import(ctx, store)
+	if err != nil {
+		return specs.Descriptor{}, fmt.Errorf("image archive import: %w", err)
+	}
+	return desc, nil
+}
+
+// importImageLayout imports the blobs of an OCI layout directory into the
+// store, returning the descriptor of its index.
+func importImageLayout(ctx context.Context, store content.Store, dir *Directory) (desc specs.Descriptor, rerr error) {
+	rerr = dir.Mount(ctx, func(root string) error {
+		indexBlob, err := os.ReadFile(filepath.Join(root, specs.ImageIndexFile))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("not an OCI layout: missing %s", specs.ImageIndexFile)
+			}
+			return err
+		}
+
+		blobsDir := filepath.Join(root, specs.ImageBlobsDir)
+		err = filepath.WalkDir(blobsDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(blobsDir, path)
+			if err != nil {
+				return err
+			}
+			algo, encoded, ok := strings.Cut(filepath.ToSlash(rel), "/")
+			if !ok {
+				return nil
+			}
+			dgst := digest.NewDigestFromEncoded(digest.Algorithm(algo), encoded)
+			if err := dgst.Validate(); err != nil {
+				// not a blob, e.g. a stray file
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return content.WriteBlob(ctx, store, "oci-layout-"+dgst.String(), f, specs.Descriptor{
+				Digest: dgst,
+				Size:   info.Size(),
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("import layout blobs: %w", err)
+		}
+
+		var idx specs.Index
+		if err := json.Unmarshal(indexBlob, &idx); err != nil {
+			return fmt.Errorf("unmarshal %s: %w", specs.ImageIndexFile, err)
+		}
+		mediaType := idx.MediaType
+		if mediaType == "" {
+			mediaType = specs.MediaTypeImageIndex
+		}
+		desc = specs.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(indexBlob),
+			Size:      int64(len(indexBlob)),
+		}
+		return content.WriteBlob(ctx, store, "oci-layout-"+desc.Digest.String(), bytes.NewReader(indexBlob), desc)
+	})
+	return desc, rerr
+}
+
+// SortedAnnotations returns the annotations of the image sorted by name, so
+// that they're listed in a stable order.
+func (img *ImageDescriptor) SortedAnnotations() []ImageAnnotation {
+	annotations := make([]ImageAnnotation, 0, len(img.Annotations))
+	for name, value := range img.Annotations {
+		annotations = append(annotations, ImageAnnotation{Name: name, Value: value})
+	}
+	slices.SortFunc(annotations, func(a, b ImageAnnotation) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return annotations
+}
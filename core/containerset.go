@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/engine/buildkit"
+)
+
+// ContainerSet is a set of containers built from the same chain of operations,
+// one per platform.
+type ContainerSet struct {
+	Containers dagql.ObjectResultArray[*Container] `field:"true" doc:"The containers in the set, one per platform, in the order the platforms were requested."`
+}
+
+func (*ContainerSet) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "ContainerSet",
+		NonNull:   true,
+	}
+}
+
+func (*ContainerSet) TypeDescription() string {
+	return dagql.FormatDescription(
+		`A set of containers built from the same pipeline for multiple platforms.`,
+		`Operations applied to the set, such as withExec, are applied to each platform in parallel.`)
+}
+
+// Platforms returns the platform of each container in the set, in order.
+func (set *ContainerSet) Platforms() []Platform {
+	platforms := make([]Platform, 0, len(set.Containers))
+	for _, ctr := range set.Containers {
+		platforms = append(platforms, ctr.Self().Platform)
+	}
+	return platforms
+}
+
+// Container returns the container in the set built for the given platform.
+func (set *ContainerSet) Container(platform Platform) (dagql.ObjectResult[*Container], bool) {
+	for _, ctr := range set.Containers {
+		if ctr.Self().Platform.Format() == platform.Format() {
+			return ctr, true
+		}
+	}
+	return dagql.ObjectResult[*Container]{}, false
+}
+
+var _ Evaluatable = (*ContainerSet)(nil)
+
+// Evaluate evaluates every container in the set in parallel. Unlike most
+// multi-step operations, it does not stop at the first failure: the error
+// returned reports every platform that failed.
+func (set *ContainerSet) Evaluate(ctx context.Context) (*buildkit.Result, error) {
+	return nil, set.forEach(ctx, func(ctx context.Context, ctr *Container) error {
+		_, err := ctr.Evaluate(ctx)
+		return err
+	})
+}
+
+// Publish publishes every container in the set as a single multi-platform
+// image index.
+func (set *ContainerSet) Publish(
+	ctx context.Context,
+	ref string,
+	forcedCompression ImageLayerCompression,
+	mediaTypes ImageMediaTypes,
+) (string, error) {
+	if len(set.Containers) == 0 {
+		return "", errors.New("no containers to publish")
+	}
+	// evaluate first so that failures are reported per platform, rather than
+	// as a single opaque export error
+	if _, err := set.Evaluate(ctx); err != nil {
+		return "", err
+	}
+	variants := make([]*Container, 0, len(set.Containers)-1)
+	for _, ctr := range set.Containers[1:] {
+		variants = append(variants, ctr.Self())
+	}
+	return set.Containers[0].Self().Publish(ctx, ref, variants, forcedCompression, mediaTypes)
+}
+
+// Map returns a new set with fn applied to each container in parallel. As
+// with Evaluate, the error returned reports every platform that failed.
+func (set *ContainerSet) Map(
+	ctx context.Context,
+	fn func(context.Context, dagql.ObjectResult[*Container]) (dagql.ObjectResult[*Container], error),
+) (*ContainerSet, error) {
+	mapped := &ContainerSet{
+		Containers: make(dagql.ObjectResultArray[*Container], len(set.Containers)),
+	}
+	errs := make([]error, len(set.Containers))
+	var wg sync.WaitGroup
+	for i, ctr := range set.Containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := fn(ctx, ctr)
+			if err != nil {
+				errs[i] = &PlatformError{Platform: ctr.Self().Platform, Err: err}
+				return
+			}
+			mapped.Containers[i] = res
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return mapped, nil
+}
+
+func (set *ContainerSet) forEach(ctx context.Context, fn func(context.Context, *Container) error) error {
+	errs := make([]error, len(set.Containers))
+	var wg sync.WaitGroup
+	for i, ctr := range set.Containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx, ctr.Self()); err != nil {
+				errs[i] = &PlatformError{Platform: ctr.Self().Platform, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// PlatformError is returned when an operation fails for a single platform of a
+// ContainerSet.
+type PlatformError struct {
+	Platform Platform
+	Err      error
+}
+
+func (e *PlatformError) Error() string {
+	return fmt.Sprintf("platform %s: %s", e.Platform.Format(), e.Err)
+}
+
+func (e *PlatformError) Unwrap() error {
+	return e.Err
+}
+
+// NewContainerSet loads the pipeline that produced the given container once
+// for each of the given platforms, in parallel.
+//
+// The pipeline must originate from Query.container; its platform argument is
+// replaced for each platform and every subsequent call is replayed on top of
+// it, so that e.g. Container.from pulls the image variant matching each
+// platform.
+func NewContainerSet(ctx context.Context, srv *dagql.Server, id *call.ID, platforms []Platform) (*ContainerSet, error) {
+	if len(platforms) == 0 {
+		return nil, errors.New("at least one platform must be specified")
+	}
+	seen := map[string]struct{}{}
+	for _, platform := range platforms {
+		if _, ok := seen[platform.Format()]; ok {
+			return nil, fmt.Errorf("duplicate platform %q", platform.Format())
+		}
+		seen[platform.Format()] = struct{}{}
+	}
+
+	set := &ContainerSet{
+		Containers: make(dagql.ObjectResultArray[*Container], len(platforms)),
+	}
+	errs := make([]error, len(platforms))
+	var wg sync.WaitGroup
+	for i, platform := range platforms {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			platformID, err := containerIDForPlatform(id, platform)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			ctr, err := dagql.NewID[*Container](platformID).Load(ctx, srv)
+			if err != nil {
+				errs[i] = &PlatformError{Platform: platform, Err: err}
+				return
+			}
+			set.Containers[i] = ctr
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// containerIDForPlatform rewrites the root Query.container call of the given
+// ID to use the given platform, replaying every call on top of it.
+func containerIDForPlatform(id *call.ID, platform Platform) (*call.ID, error) {
+	if id == nil {
+		return nil, errors.New("cannot retarget an empty ID")
+	}
+	if id.Receiver() == nil {
+		if id.Field() != "container" {
+			return nil, fmt.Errorf("cannot change the platform of a pipeline starting from %q, only from %q", id.Field(), "container")
+		}
+		return id.WithArgument(call.NewArgument("platform", platform.ToLiteral(), false)), nil
+	}
+	receiver, err := containerIDForPlatform(id.Receiver(), platform)
+	if err != nil {
+		return nil, err
+	}
+	return receiver.Append(
+		id.Type().ToAST(),
+		id.Field(),
+		id.View(),
+		id.Module(),
+		int(id.Nth()),
+		"", // content digests no longer apply to the new receiver
+		id.Args()...,
+	), nil
+}
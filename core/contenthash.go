@@ -2,7 +2,11 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
 	"strings"
 
 	bkcontenthash "github.com/moby/buildkit/cache/contenthash"
@@ -12,6 +16,7 @@ import (
 	"github.com/moby/buildkit/util/bklog"
 	bkworker "github.com/moby/buildkit/worker"
 	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"resenje.org/singleflight"
 
 	"dagger.io/dagger/telemetry"
@@ -22,6 +27,300 @@ import (
 
 var checksumG singleflight.Group[string, digest.Digest]
 
+// ContentHasher is implemented by objects that can be identified by a hash of
+// their content rather than by the calls that produced them.
+type ContentHasher interface {
+	dagql.Typed
+
+	// ContentHash evaluates the object and returns a hash of its content, or
+	// an empty digest if it has no content to hash.
+	ContentHash(ctx context.Context) (digest.Digest, error)
+}
+
+var _ ContentHasher = (*Directory)(nil)
+var _ ContentHasher = (*File)(nil)
+
+// DefinitionHasher is implemented by objects that can be identified by the
+// LLB definition that produces them, without evaluating it.
+type DefinitionHasher interface {
+	dagql.Typed
+
+	// DefinitionHash returns a hash of the object's definition and
+	// configuration, or an empty digest if its definition doesn't pin the
+	// content it produces.
+	DefinitionHash() (digest.Digest, error)
+}
+
+var _ DefinitionHasher = (*Directory)(nil)
+var _ DefinitionHasher = (*File)(nil)
+var _ DefinitionHasher = (*Container)(nil)
+
+// errUnpinned is returned by definitionDigest for definitions that can
+// produce different content over time.
+var errUnpinned = errors.New("definition is not pinned to its content")
+
+// definitionDigest returns the digest of the last op of def, which covers all
+// of the ops it depends on.
+//
+// Local sources are identified by their path rather than their content, and
+// git and http sources may resolve to different content over time, so
+// definitions that include them return errUnpinned.
+func definitionDigest(def *pb.Definition) (digest.Digest, error) {
+	if def == nil || len(def.Def) == 0 {
+		return digest.FromString("scratch"), nil
+	}
+	dag, err := buildkit.DefToDAG(def)
+	if err != nil {
+		return "", err
+	}
+	err = dag.Walk(func(op *buildkit.OpDAG) error {
+		if _, ok := op.AsLocal(); ok {
+			return errUnpinned
+		}
+		if _, ok := op.AsGit(); ok {
+			return errUnpinned
+		}
+		if _, ok := op.AsHTTP(); ok {
+			return errUnpinned
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(def.Def[len(def.Def)-1]), nil
+}
+
+// unpinnedHash returns an empty digest for errUnpinned, and err otherwise.
+func unpinnedHash(err error) (digest.Digest, error) {
+	if errors.Is(err, errUnpinned) {
+		return "", nil
+	}
+	return "", err
+}
+
+func servicesHashInputs(services ServiceBindings) []string {
+	var inputs []string
+	for _, bnd := range services {
+		inputs = append(inputs,
+			bnd.Service.ID().Digest().String(),
+			bnd.Hostname,
+			bnd.Aliases.String(),
+		)
+	}
+	return inputs
+}
+
+// MakeContentHashed returns an updated instance of the given result that has
+// its dagql ID digest set to a content hash of the result. This allows
+// results with the same content to be deduplicated in dagql's cache, even if
+// they were produced by different calls, and calls on them to share cache
+// hits.
+func MakeContentHashed[T ContentHasher](
+	ctx context.Context,
+	inst dagql.ObjectResult[T],
+) (dagql.ObjectResult[T], error) {
+	dgst, err := inst.Self().ContentHash(ctx)
+	if err != nil {
+		return inst, err
+	}
+	if dgst == "" {
+		return inst, nil
+	}
+	return inst.WithObjectDigest(dgst), nil
+}
+
+func (dir *Directory) ContentHash(ctx context.Context) (digest.Digest, error) {
+	if dir.LLB == nil {
+		return "", nil
+	}
+	bk, err := contentHashBuildkit(ctx)
+	if err != nil {
+		return "", err
+	}
+	// NOTE: this must match GetContentHashFromDirectory, since host directories
+	// are content hashed that way
+	return dir.contentHash(ctx, bk)
+}
+
+func (dir *Directory) contentHash(ctx context.Context, bk *buildkit.Client) (digest.Digest, error) {
+	st, err := dir.State()
+	if err != nil {
+		return "", fmt.Errorf("failed to get state: %w", err)
+	}
+	def, err := st.Marshal(ctx, llb.Platform(dir.Platform.Spec()))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal state: %w", err)
+	}
+	dgst, err := GetContentHashFromDef(ctx, bk, def.ToPB(), dir.Dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return dgst, nil
+}
+
+// DefinitionHash hashes the directory's definition along with its path,
+// platform and service dependencies.
+func (dir *Directory) DefinitionHash() (digest.Digest, error) {
+	dgst, err := definitionDigest(dir.LLB)
+	if err != nil {
+		return unpinnedHash(err)
+	}
+	inputs := []string{"directory", dgst.String(), dir.Dir, dir.Platform.Format()}
+	inputs = append(inputs, servicesHashInputs(dir.Services)...)
+	return dagql.HashFrom(inputs...), nil
+}
+
+func (file *File) ContentHash(ctx context.Context) (digest.Digest, error) {
+	if file.LLB == nil {
+		return "", nil
+	}
+	bk, err := contentHashBuildkit(ctx)
+	if err != nil {
+		return "", err
+	}
+	dgst, err := GetContentHashFromDef(ctx, bk, file.LLB, file.File)
+	if err != nil {
+		return "", fmt.Errorf("failed to get content hash: %w", err)
+	}
+	// the file's name is part of its identity (see File.name)
+	return dagql.HashFrom("file", path.Base(file.File), dgst.String()), nil
+}
+
+// DefinitionHash hashes the file's definition along with its path, platform
+// and service dependencies.
+func (file *File) DefinitionHash() (digest.Digest, error) {
+	dgst, err := definitionDigest(file.LLB)
+	if err != nil {
+		return unpinnedHash(err)
+	}
+	inputs := []string{"file", dgst.String(), file.File, file.Platform.Format()}
+	inputs = append(inputs, servicesHashInputs(file.Services)...)
+	return dagql.HashFrom(inputs...), nil
+}
+
+// DefinitionHash hashes the definitions of the container's rootfs, mounts and
+// /dagger metadata (exit code, stdout, etc.) along with the rest of its
+// configuration. Secrets, sockets and services are identified by their IDs.
+func (container *Container) DefinitionHash() (digest.Digest, error) {
+	defHash := func(def *pb.Definition) (string, error) {
+		if def == nil {
+			return "", nil
+		}
+		dgst, err := definitionDigest(def)
+		if err != nil {
+			return "", err
+		}
+		return dgst.String(), nil
+	}
+
+	inputs := []string{"container"}
+
+	fsHash, err := defHash(container.FS)
+	if err != nil {
+		return unpinnedHash(err)
+	}
+	metaHash, err := defHash(container.Meta)
+	if err != nil {
+		return unpinnedHash(err)
+	}
+	inputs = append(inputs, fsHash, metaHash)
+
+	for _, mnt := range container.Mounts {
+		srcHash, err := defHash(mnt.Source)
+		if err != nil {
+			return unpinnedHash(err)
+		}
+		mntJSON, err := json.Marshal(struct {
+			Source           string
+			SourcePath       string
+			Target           string
+			CacheVolumeID    string
+			CacheSharingMode CacheSharingMode
+			Tmpfs            bool
+			Size             int
+			Readonly         bool
+		}{
+			Source:           srcHash,
+			SourcePath:       mnt.SourcePath,
+			Target:           mnt.Target,
+			CacheVolumeID:    mnt.CacheVolumeID,
+			CacheSharingMode: mnt.CacheSharingMode,
+			Tmpfs:            mnt.Tmpfs,
+			Size:             mnt.Size,
+			Readonly:         mnt.Readonly,
+		})
+		if err != nil {
+			return "", err
+		}
+		inputs = append(inputs, string(mntJSON))
+	}
+
+	for _, secret := range container.Secrets {
+		secretJSON, err := json.Marshal(struct {
+			Secret    string
+			EnvName   string
+			MountPath string
+			Owner     *Ownership
+			Mode      fs.FileMode
+		}{
+			Secret:    secret.Secret.ID().Digest().String(),
+			EnvName:   secret.EnvName,
+			MountPath: secret.MountPath,
+			Owner:     secret.Owner,
+			Mode:      secret.Mode,
+		})
+		if err != nil {
+			return "", err
+		}
+		inputs = append(inputs, string(secretJSON))
+	}
+
+	inputs = append(inputs, servicesHashInputs(container.Services)...)
+
+	cfgJSON, err := json.Marshal(struct {
+		Config             specs.ImageConfig
+		EnabledGPUs        []string
+		Platform           Platform
+		Annotations        []ContainerAnnotation
+		Sockets            []ContainerSocket
+		ImageRef           string
+		Ports              []Port
+		DefaultTerminalCmd DefaultTerminalCmdOpts
+		SystemEnvNames     []string
+		DefaultArgs        bool
+	}{
+		Config:             container.Config,
+		EnabledGPUs:        container.EnabledGPUs,
+		Platform:           container.Platform,
+		Annotations:        container.Annotations,
+		Sockets:            container.Sockets,
+		ImageRef:           container.ImageRef,
+		Ports:              container.Ports,
+		DefaultTerminalCmd: container.DefaultTerminalCmd,
+		SystemEnvNames:     container.SystemEnvNames,
+		DefaultArgs:        container.DefaultArgs,
+	})
+	if err != nil {
+		return "", err
+	}
+	inputs = append(inputs, string(cfgJSON))
+
+	return dagql.HashFrom(inputs...), nil
+}
+
+func contentHashBuildkit(ctx context.Context) (*buildkit.Client, error) {
+	query, err := CurrentQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+	return bk, nil
+}
+
 // MakeDirectoryContentHashed returns an updated instance of the given Directory that
 // has it's dagql ID digest set to a content hash of the directory. This allows all
 // directory instances with the same content to be deduplicated in dagql's cache.
@@ -46,21 +345,7 @@ func GetContentHashFromDirectory(
 	if dirInst.Self() == nil {
 		return "", fmt.Errorf("directory instance is nil")
 	}
-
-	st, err := dirInst.Self().State()
-	if err != nil {
-		return "", fmt.Errorf("failed to get state: %w", err)
-	}
-	def, err := st.Marshal(ctx, llb.Platform(dirInst.Self().Platform.Spec()))
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal state: %w", err)
-	}
-	dgst, err := GetContentHashFromDef(ctx, bk, def.ToPB(), dirInst.Self().Dir)
-	if err != nil {
-		return "", fmt.Errorf("failed to get content hash: %w", err)
-	}
-
-	return dgst, nil
+	return dirInst.Self().contentHash(ctx, bk)
 }
 
 func GetContentHashFromDef(
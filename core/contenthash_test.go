@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoryDefinitionHash(t *testing.T) {
+	ctx := context.Background()
+	platform := Platform{OS: "linux", Architecture: "amd64"}
+
+	def := func(st llb.State) *pb.Definition {
+		t.Helper()
+		def, err := st.Marshal(ctx, llb.Platform(platform.Spec()))
+		require.NoError(t, err)
+		return def.ToPB()
+	}
+	hash := func(st llb.State, dir string) string {
+		t.Helper()
+		dgst, err := (&Directory{LLB: def(st), Dir: dir, Platform: platform}).DefinitionHash()
+		require.NoError(t, err)
+		return dgst.String()
+	}
+
+	const ref = "docker.io/library/alpine@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	image := llb.Image(ref)
+	withFile := image.File(llb.Mkfile("/foo", 0o644, []byte("bar")))
+	renamed := llb.Image(ref, llb.WithCustomName("pull alpine")).File(llb.Mkfile("/foo", 0o644, []byte("bar")))
+
+	require.NotEmpty(t, hash(withFile, "/"))
+	// the same definition hashes the same, whatever its metadata
+	require.Equal(t, hash(withFile, "/"), hash(renamed, "/"))
+	require.NotEqual(t, hash(withFile, "/"), hash(image, "/"))
+	require.NotEqual(t, hash(withFile, "/"), hash(withFile, "/foo"))
+
+	// sources that aren't pinned to their content can't be identified by their
+	// definition
+	require.Empty(t, hash(llb.Local("src"), "/"))
+	require.Empty(t, hash(image.File(llb.Copy(llb.Local("src"), "/", "/src")), "/"))
+	require.Empty(t, hash(llb.Git("https://github.com/dagger/dagger", "main"), "/"))
+	require.Empty(t, hash(llb.HTTP("https://example.com/foo"), "/"))
+}
@@ -29,9 +29,11 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"dagger.io/dagger"
+	"dagger.io/dagger/querybuilder"
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/core/schema"
 	"github.com/dagger/dagger/engine/buildkit"
+	"github.com/dagger/dagger/engine/config"
 	"github.com/dagger/dagger/engine/distconsts"
 	"github.com/dagger/dagger/internal/testutil"
 	"github.com/dagger/testctx"
@@ -212,6 +214,52 @@ func (ContainerSuite) TestExecStdoutStderr(ctx context.Context, t *testctx.T) {
 	})
 }
 
+func (ContainerSuite) TestExecStdoutStream(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	stdoutStream := func(args ...string) *querybuilder.Selection {
+		return c.QueryBuilder().
+			Select("containerStdoutStream").
+			Arg("container", c.Container().From(alpineImage).WithExec(args))
+	}
+
+	t.Run("streams output", func(ctx context.Context, t *testctx.T) {
+		values, errs := querybuilder.Subscribe[string](ctx,
+			stdoutStream("sh", "-c", "echo hello; sleep 1; echo "+identity.NewID()))
+		var chunks []string
+		for chunk := range values {
+			chunks = append(chunks, chunk)
+		}
+		require.NoError(t, <-errs)
+		require.GreaterOrEqual(t, len(chunks), 2)
+		require.Equal(t, "hello\n", chunks[0])
+	})
+
+	t.Run("cached output", func(ctx context.Context, t *testctx.T) {
+		_, err := c.Container().
+			From(alpineImage).
+			WithExec([]string{"echo", "cached"}).
+			Sync(ctx)
+		require.NoError(t, err)
+
+		values, errs := querybuilder.Subscribe[string](ctx, stdoutStream("echo", "cached"))
+		var out strings.Builder
+		for chunk := range values {
+			out.WriteString(chunk)
+		}
+		require.NoError(t, <-errs)
+		require.Equal(t, "cached\n", out.String())
+	})
+
+	t.Run("not in queries", func(ctx context.Context, t *testctx.T) {
+		err := c.QueryBuilder().
+			Select("container").
+			Select("stdoutStream").
+			Execute(ctx)
+		requireErrOut(t, err, `Cannot query field "stdoutStream"`)
+	})
+}
+
 func (ContainerSuite) TestExecCombinedOutput(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
@@ -3135,6 +3183,113 @@ func (ContainerSuite) TestMultiPlatformImport(ctx context.Context, t *testctx.T)
 	}
 }
 
+func (ContainerSuite) TestImportSelectImage(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	variants := make([]*dagger.Container, 0, len(platformToUname))
+	for platform := range platformToUname {
+		variants = append(variants, c.Container(dagger.ContainerOpts{Platform: platform}).From(alpineImage))
+	}
+	tarball := c.Container().AsTarball(dagger.ContainerAsTarballOpts{
+		PlatformVariants: variants,
+	})
+	layout := c.Container().
+		From(alpineImage).
+		WithMountedFile("/image.tar", tarball).
+		WithWorkdir("/layout").
+		WithExec([]string{"tar", "-xf", "/image.tar"}).
+		Directory("/layout")
+
+	tarballID, err := tarball.ID(ctx)
+	require.NoError(t, err)
+	layoutID, err := layout.ID(ctx)
+	require.NoError(t, err)
+
+	type imageDescriptor struct {
+		Digest      string
+		Platform    dagger.Platform
+		IndexDigest string
+	}
+	res, err := testutil.QueryWithClient[struct {
+		LoadFileFromID struct {
+			ImageDescriptors []imageDescriptor
+		}
+		LoadDirectoryFromID struct {
+			ImageDescriptors []imageDescriptor
+		}
+	}](c, t, `query Test($file: FileID!, $dir: DirectoryID!) {
+		loadFileFromID(id: $file) {
+			imageDescriptors {
+				digest
+				platform
+				indexDigest
+			}
+		}
+		loadDirectoryFromID(id: $dir) {
+			imageDescriptors {
+				digest
+				platform
+				indexDigest
+			}
+		}
+	}`, &testutil.QueryOptions{Variables: map[string]any{
+		"file": tarballID,
+		"dir":  layoutID,
+	}})
+	require.NoError(t, err)
+
+	descs := res.LoadFileFromID.ImageDescriptors
+	require.Len(t, descs, len(platformToUname))
+	require.ElementsMatch(t, descs, res.LoadDirectoryFromID.ImageDescriptors)
+	for _, desc := range descs {
+		require.Contains(t, platformToUname, desc.Platform)
+		require.NotEmpty(t, desc.IndexDigest)
+	}
+
+	for _, desc := range descs {
+		for _, field := range []string{"import(source: $file, digest: $digest)", "importLayout(source: $dir, digest: $digest)"} {
+			// the selected manifest is imported regardless of the container's
+			// platform
+			res, err := testutil.QueryWithClient[struct {
+				Container map[string]struct {
+					Platform dagger.Platform
+				}
+			}](c, t, `query Test($file: FileID!, $dir: DirectoryID!, $digest: String!) {
+				container {
+					imported: `+field+` {
+						platform
+					}
+				}
+			}`, &testutil.QueryOptions{Variables: map[string]any{
+				"file":   tarballID,
+				"dir":    layoutID,
+				"digest": desc.Digest,
+			}})
+			require.NoError(t, err)
+			require.Equal(t, desc.Platform, res.Container["imported"].Platform)
+		}
+	}
+
+	t.Run("no match", func(ctx context.Context, t *testctx.T) {
+		_, err := testutil.QueryWithClient[struct {
+			Container struct {
+				ImportLayout struct {
+					Platform dagger.Platform
+				}
+			}
+		}](c, t, `query Test($dir: DirectoryID!) {
+			container {
+				importLayout(source: $dir, annotations: [{name: "org.opencontainers.image.ref.name", value: "nope"}]) {
+					platform
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: map[string]any{
+			"dir": layoutID,
+		}})
+		requireErrOut(t, err, "annotation org.opencontainers.image.ref.name=nope")
+	})
+}
+
 func (ContainerSuite) TestWithDirectoryToMount(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
@@ -3421,6 +3576,116 @@ func (ContainerSuite) TestInsecureRootCapabilitesWithService(ctx context.Context
 	require.Equal(t, fmt.Sprintf("%s-from-outside\n%s-from-inside\n", randID, randID), out)
 }
 
+func (ContainerSuite) TestUserNamespace(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	ctrID, err := c.Container().From(alpineImage).
+		WithMountedCache("/cache", c.CacheVolume("userns-"+identity.NewID())).
+		ID(ctx)
+	require.NoError(t, err)
+
+	res, err := testutil.QueryWithClient[struct {
+		LoadContainerFromID struct {
+			UIDMap struct {
+				Stdout string
+			}
+			Written struct {
+				WithExec struct {
+					Stdout string
+				}
+			}
+		}
+	}](c, t, `query Test($id: ContainerID!) {
+		loadContainerFromID(id: $id) {
+			uidMap: withExec(args: ["cat", "/proc/self/uid_map"], userNamespace: true) {
+				stdout
+			}
+			written: withExec(args: ["sh", "-c", "id -u > /root-file && id -u > /cache/root-file && chown 1234:1234 /root-file"], userNamespace: true) {
+				withExec(args: ["stat", "-c", "%u %g", "/root-file", "/cache/root-file"]) {
+					stdout
+				}
+			}
+		}
+	}`, &testutil.QueryOptions{Variables: map[string]any{
+		"id": ctrID,
+	}})
+	require.NoError(t, err)
+
+	// root in the container maps to an unprivileged host user
+	require.Equal(t, uidMapFields(config.DefaultUserNamespaceRange), strings.Fields(res.LoadContainerFromID.UIDMap.Stdout))
+
+	// files written in the user namespace have the same ownership on disk as
+	// they have from inside it
+	require.Equal(t, "1234 1234\n0 0\n", res.LoadContainerFromID.Written.WithExec.Stdout)
+
+	t.Run("insecure root capabilities", func(ctx context.Context, t *testctx.T) {
+		_, err := testutil.QueryWithClient[struct {
+			LoadContainerFromID struct {
+				WithExec struct {
+					Stdout string
+				}
+			}
+		}](c, t, `query Test($id: ContainerID!) {
+			loadContainerFromID(id: $id) {
+				withExec(args: ["true"], userNamespace: true, insecureRootCapabilities: true) {
+					stdout
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: map[string]any{
+			"id": ctrID,
+		}})
+		requireErrOut(t, err, "insecureRootCapabilities cannot be used in a user namespace")
+	})
+
+	t.Run("configured range", func(ctx context.Context, t *testctx.T) {
+		idRange := config.IDRange{Start: 200000, Size: 1000}
+		engine := devEngineContainer(c, engineWithConfig(ctx, t, func(ctx context.Context, t *testctx.T, cfg config.Config) config.Config {
+			if cfg.Security == nil {
+				cfg.Security = &config.Security{}
+			}
+			cfg.Security.UserNamespaceRange = &idRange
+			return cfg
+		}))
+		engineSvc, err := c.Host().Tunnel(devEngineContainerAsService(engine)).Start(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { engineSvc.Stop(ctx) })
+
+		endpoint, err := engineSvc.Endpoint(ctx, dagger.ServiceEndpointOpts{Scheme: "tcp"})
+		require.NoError(t, err)
+		c2, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+		require.NoError(t, err)
+		t.Cleanup(func() { c2.Close() })
+
+		res, err := testutil.QueryWithClient[struct {
+			Container struct {
+				From struct {
+					WithExec struct {
+						Stdout string
+					}
+				}
+			}
+		}](c2, t, `query Test($image: String!) {
+			container {
+				from(address: $image) {
+					withExec(args: ["cat", "/proc/self/uid_map"], userNamespace: true) {
+						stdout
+					}
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: map[string]any{
+			"image": alpineImage,
+		}})
+		require.NoError(t, err)
+		require.Equal(t, uidMapFields(idRange), strings.Fields(res.Container.From.WithExec.Stdout))
+	})
+}
+
+// uidMapFields returns the fields of /proc/self/uid_map in a user namespace
+// mapped to the given range of host IDs.
+func uidMapFields(idRange config.IDRange) []string {
+	return []string{"0", fmt.Sprint(idRange.Start), fmt.Sprint(idRange.Size)}
+}
+
 func (ContainerSuite) TestWithMountedFileOwner(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
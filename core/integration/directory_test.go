@@ -14,6 +14,7 @@ import (
 
 	"dagger.io/dagger"
 	"github.com/dagger/dagger/core"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/internal/testutil"
 	"github.com/dagger/testctx"
 )
@@ -951,6 +952,28 @@ func (DirectorySuite) TestSync(ctx context.Context, t *testctx.T) {
 		require.NoError(t, err)
 		require.Equal(t, []string{"foo"}, entries)
 	})
+
+	t.Run("identifies by content", func(ctx context.Context, t *testctx.T) {
+		syncedDigest := func(dir *dagger.Directory) string {
+			synced, err := dir.Sync(ctx)
+			require.NoError(t, err)
+			encoded, err := synced.ID(ctx)
+			require.NoError(t, err)
+			var id call.ID
+			require.NoError(t, id.Decode(string(encoded)))
+			return id.Digest().String()
+		}
+
+		dgst1 := syncedDigest(c.Directory().WithNewFile("foo", "bar"))
+		dgst2 := syncedDigest(c.Directory().
+			WithNewFile("baz", "qux").
+			WithNewFile("foo", "bar").
+			WithoutFile("baz"))
+		require.Equal(t, dgst1, dgst2)
+
+		dgst3 := syncedDigest(c.Directory().WithNewFile("foo", "other"))
+		require.NotEqual(t, dgst1, dgst3)
+	})
 }
 
 func (DirectorySuite) TestGlob(ctx context.Context, t *testctx.T) {
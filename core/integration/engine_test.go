@@ -883,3 +883,73 @@ func (EngineSuite) TestPrometheusMetrics(ctx context.Context, t *testctx.T) {
 	clientCancel()
 	require.NoError(t, eg.Wait(), "error from client exec")
 }
+
+func (EngineSuite) TestRegistryConfig(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	newRegistry := func(name string) *dagger.Service {
+		return c.Container().From("registry:2").
+			WithMountedCache("/var/lib/registry/", c.CacheVolume(name+"-"+identity.NewID())).
+			WithExposedPort(5000, dagger.ContainerWithExposedPortOpts{Protocol: dagger.NetworkProtocolTcp}).
+			AsService(dagger.ContainerAsServiceOpts{UseEntrypoint: true})
+	}
+
+	plainHTTP := true
+	engine := devEngineContainer(c,
+		func(ctr *dagger.Container) *dagger.Container {
+			return ctr.
+				WithServiceBinding("privateregistry", newRegistry("engine-registry-config")).
+				WithServiceBinding("registrymirror", newRegistry("engine-registry-config-mirror"))
+		},
+		engineWithConfig(ctx, t, func(ctx context.Context, t *testctx.T, cfg config.Config) config.Config {
+			cfg.Registries = map[string]config.RegistryConfig{
+				"privateregistry:5000": {
+					Mirrors:               []string{"registrymirror:5000"},
+					PlainHTTP:             &plainHTTP,
+					MaxConcurrentRequests: 2,
+				},
+				"registrymirror:5000": {
+					PlainHTTP: &plainHTTP,
+				},
+			}
+			return cfg
+		}),
+	)
+	engineSvc, err := c.Host().Tunnel(devEngineContainerAsService(engine)).Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { engineSvc.Stop(ctx) })
+
+	endpoint, err := engineSvc.Endpoint(ctx, dagger.ServiceEndpointOpts{Scheme: "tcp"})
+	require.NoError(t, err)
+
+	c2, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+	require.NoError(t, err)
+	t.Cleanup(func() { c2.Close() })
+
+	publish := func(ref, contents string) {
+		_, err := c2.Container().
+			From(alpineImage).
+			WithNewFile("/hello", contents).
+			Publish(ctx, ref)
+		require.NoError(t, err)
+	}
+
+	t.Run("fallback from mirror", func(ctx context.Context, t *testctx.T) {
+		// pushes aren't redirected, so the mirror doesn't have the image
+		ref := "privateregistry:5000/registry-config:latest"
+		publish(ref, "world")
+
+		out, err := c2.Container().From(ref).File("/hello").Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "world", out)
+	})
+
+	t.Run("pull from mirror", func(ctx context.Context, t *testctx.T) {
+		// only the mirror has the image
+		publish("registrymirror:5000/registry-config-mirrored:latest", "mirrored")
+
+		out, err := c2.Container().From("privateregistry:5000/registry-config-mirrored:latest").File("/hello").Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "mirrored", out)
+	})
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -1260,3 +1261,43 @@ ctr=$(container)
 		require.Contains(t, out, "Container@xxh3:")
 	})
 }
+
+func (ShellSuite) TestDiff(ctx context.Context, t *testctx.T) {
+	script := `
+a=$(directory | with-new-file same.txt same | with-new-file old.txt old | with-new-file main.go "package main")
+b=$(directory | with-new-file same.txt same | with-new-file new.txt new | with-new-file main.go "package app")
+.diff %s $a $b
+`
+
+	t.Run("text", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+		out, err := daggerCliBase(t, c).
+			With(daggerShellNoMod(fmt.Sprintf(script, ""))).
+			Stdout(ctx)
+		require.NoError(t, err)
+		require.Contains(t, out, "M main.go\nA new.txt\nD old.txt\n")
+		require.Contains(t, out, "-package main")
+		require.Contains(t, out, "+package app")
+		require.NotContains(t, out, "same.txt")
+	})
+
+	t.Run("json", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+		out, err := daggerCliBase(t, c).
+			With(daggerShellNoMod(fmt.Sprintf(script, "--json"))).
+			Stdout(ctx)
+		require.NoError(t, err)
+		var res struct {
+			Added   []string
+			Removed []string
+			Changed []struct {
+				Path string
+			}
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &res))
+		require.Equal(t, []string{"new.txt"}, res.Added)
+		require.Equal(t, []string{"old.txt"}, res.Removed)
+		require.Len(t, res.Changed, 1)
+		require.Equal(t, "main.go", res.Changed[0].Path)
+	})
+}
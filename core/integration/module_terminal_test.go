@@ -395,6 +395,86 @@ type Test struct {
 		require.NoError(t, err)
 	})
 
+	t.Run("record", func(ctx context.Context, t *testctx.T) {
+		modDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(modDir, "main.go"), fmt.Appendf(nil, `package main
+import (
+	"context"
+	"dagger/test/internal/dagger"
+)
+
+func New(ctx context.Context) *Test {
+	return &Test{
+		Ctr: dag.Container().
+			From("%s").
+			WithWorkdir("/coolworkdir"),
+	}
+}
+
+type Test struct {
+	Ctr *dagger.Container
+}
+`, alpineImage), 0644)
+		require.NoError(t, err)
+
+		_, err = hostDaggerExec(ctx, t, modDir, "init", "--source=.", "--name=test", "--sdk=go")
+		require.NoError(t, err)
+
+		// cache the module load itself so there's less to wait for in the shell invocation below
+		_, err = hostDaggerExec(ctx, t, modDir, "functions")
+		require.NoError(t, err)
+
+		console, err := newTUIConsole(t, 60*time.Second)
+		require.NoError(t, err)
+		defer console.Close()
+
+		tty := console.Tty()
+
+		err = pty.Setsize(tty, &pty.Winsize{Rows: 6, Cols: 16})
+		require.NoError(t, err)
+
+		castPath := filepath.Join(t.TempDir(), "session.cast")
+		cmd := hostDaggerCommand(ctx, t, modDir, "call", "ctr", "terminal", "--record",
+			"file", "--path=/terminal.cast", "export", "--path="+castPath)
+		cmd.Stdin = tty
+		cmd.Stdout = tty
+		cmd.Stderr = tty
+
+		err = cmd.Start()
+		require.NoError(t, err)
+
+		prompt := fmt.Sprintf("/coolworkdir%s $ ", resetSeq)
+
+		_, err = console.ExpectString(prompt)
+		require.NoError(t, err)
+
+		_, err = console.SendLine("echo recorded-$((40+2))")
+		require.NoError(t, err)
+
+		_, err = console.ExpectString("recorded-42\r\n")
+		require.NoError(t, err)
+
+		_, err = console.ExpectString(prompt)
+		require.NoError(t, err)
+
+		_, err = console.SendLine("exit")
+		require.NoError(t, err)
+
+		go console.ExpectEOF()
+
+		err = cmd.Wait()
+		require.NoError(t, err)
+
+		cast, err := os.ReadFile(castPath)
+		require.NoError(t, err)
+		require.Contains(t, string(cast), `"version":2`)
+		require.Contains(t, string(cast), "recorded-42")
+
+		out, err := hostDaggerExec(ctx, t, modDir, "terminal", "--replay", castPath, "--speed", "100")
+		require.NoError(t, err)
+		require.Contains(t, string(out), "recorded-42")
+	})
+
 	t.Run("nested client", func(ctx context.Context, t *testctx.T) {
 		modDir := t.TempDir()
 		err := os.WriteFile(filepath.Join(modDir, "main.go"), []byte(`package main
@@ -5805,6 +5805,202 @@ func (m *Test) ReadFile(
 	})
 }
 
+func (ModuleSuite) TestContentHashedArgs(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	modGen := modInit(t, c, "go", `package main
+
+import (
+	"crypto/rand"
+
+	"dagger/test/internal/dagger"
+)
+
+type Test struct{}
+
+func (m *Test) Stamp(dir *dagger.Directory) string {
+	return rand.Text()
+}
+`)
+	require.NoError(t, modGen.Directory(".").AsModule().Serve(ctx))
+
+	stamp := func(dir *dagger.Directory) string {
+		id, err := dir.ID(ctx)
+		require.NoError(t, err)
+		res, err := testutil.QueryWithClient[struct {
+			Test struct {
+				Stamp string
+			}
+		}](c, t, `query($dir: DirectoryID!) {test{stamp(dir: $dir)}}`, &testutil.QueryOptions{
+			Variables: map[string]any{"dir": id},
+		})
+		require.NoError(t, err)
+		return res.Test.Stamp
+	}
+
+	t.Run("synced args are identified by content", func(ctx context.Context, t *testctx.T) {
+		synced := func(dir *dagger.Directory) *dagger.Directory {
+			dir, err := dir.Sync(ctx)
+			require.NoError(t, err)
+			return dir
+		}
+
+		stamp1 := stamp(synced(c.Directory().WithNewFile("foo", "bar")))
+		// different calls, same content
+		stamp2 := stamp(synced(c.Directory().
+			WithNewFile("baz", "qux").
+			WithNewFile("foo", "bar").
+			WithoutFile("baz")))
+		require.Equal(t, stamp1, stamp2)
+
+		stamp3 := stamp(synced(c.Directory().WithNewFile("foo", "other")))
+		require.NotEqual(t, stamp1, stamp3)
+	})
+
+	t.Run("args are identified by content", func(ctx context.Context, t *testctx.T) {
+		build := func(script string) *dagger.Directory {
+			return c.Container().From(alpineImage).
+				WithExec([]string{"sh", "-c", script}).
+				Directory("/out")
+		}
+
+		// different builds, same output: only whitespace differs upstream
+		stamp1 := stamp(build("mkdir /out && echo hello > /out/greeting"))
+		stamp2 := stamp(build("mkdir  /out &&  echo hello > /out/greeting"))
+		require.Equal(t, stamp1, stamp2)
+
+		stamp3 := stamp(build("mkdir /out && echo bye > /out/greeting"))
+		require.NotEqual(t, stamp1, stamp3)
+	})
+}
+
+func (ModuleSuite) TestFunctionCachePolicy(ctx context.Context, t *testctx.T) {
+	type testCase struct {
+		sdk    string
+		source string
+	}
+
+	for _, tc := range []testCase{
+		{
+			sdk: "go",
+			source: `package main
+
+import (
+	"crypto/rand"
+)
+
+type Test struct{}
+
+// +cacheKeyArgs=["repo"]
+func (m *Test) Release(repo string, logLevel string) string {
+	return rand.Text()
+}
+
+// +cache="1h"
+func (m *Test) Stamp(name string) string {
+	return rand.Text()
+}
+`,
+		},
+		{
+			sdk: "python",
+			source: `import secrets
+
+import dagger
+
+
+@dagger.object_type
+class Test:
+    @dagger.function(cache_key_args=["repo"])
+    def release(self, repo: str, log_level: str) -> str:
+        return secrets.token_hex()
+
+    @dagger.function(cache="1h")
+    def stamp(self, name: str) -> str:
+        return secrets.token_hex()
+`,
+		},
+		{
+			sdk: "typescript",
+			source: `
+import { object, func } from "@dagger.io/dagger"
+
+@object()
+export class Test {
+	@func({ cacheKeyArgs: ["repo"] })
+	release(repo: string, logLevel: string): string {
+		return crypto.randomUUID()
+	}
+
+	@func({ cache: "1h" })
+	stamp(name: string): string {
+		return crypto.randomUUID()
+	}
+}
+`,
+		},
+	} {
+		serve := func(ctx context.Context, t *testctx.T) *dagger.Client {
+			c := connect(ctx, t)
+			require.NoError(t, modInit(t, c, tc.sdk, tc.source).Directory(".").AsModule().Serve(ctx))
+			return c
+		}
+		call := func(t *testctx.T, c *dagger.Client, query string) string {
+			res, err := testutil.QueryWithClient[struct {
+				Test map[string]string
+			}](c, t, `{test{`+query+`}}`, nil)
+			require.NoError(t, err)
+			require.Len(t, res.Test, 1)
+			for _, v := range res.Test {
+				return v
+			}
+			return ""
+		}
+
+		t.Run(tc.sdk+" key args identify the result", func(ctx context.Context, t *testctx.T) {
+			c := serve(ctx, t)
+
+			release1 := call(t, c, `release(repo: "foo", logLevel: "debug")`)
+			release2 := call(t, c, `release(repo: "foo", logLevel: "info")`)
+			require.Equal(t, release1, release2)
+
+			release3 := call(t, c, `release(repo: "bar", logLevel: "debug")`)
+			require.NotEqual(t, release1, release3)
+		})
+
+		t.Run(tc.sdk+" ttl reuses results across sessions", func(ctx context.Context, t *testctx.T) {
+			c1 := serve(ctx, t)
+			c2 := serve(ctx, t)
+
+			stamp1 := call(t, c1, `stamp(name: "foo")`)
+			stamp2 := call(t, c2, `stamp(name: "foo")`)
+			require.Equal(t, stamp1, stamp2)
+
+			stamp3 := call(t, c2, `stamp(name: "bar")`)
+			require.NotEqual(t, stamp1, stamp3)
+		})
+	}
+}
+
+func (ModuleSuite) TestFunctionCachePolicyInvalid(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	_, err := goGitBase(t, c).
+		With(daggerExec("init", "--source=.", "--name=test", "--sdk=go")).
+		WithNewFile("main.go", `package main
+
+type Test struct{}
+
+// +cache="soon"
+func (m *Test) Stamp() string {
+	return ""
+}
+`).
+		With(daggerCall("stamp")).
+		Sync(ctx)
+	requireErrOut(t, err, `cache pragma "soon", must be a valid duration string`)
+}
+
 func (ModuleSuite) TestLargeErrors(ctx context.Context, t *testctx.T) {
 	modDir := t.TempDir()
 
@@ -6,6 +6,7 @@ import (
 
 	"dagger.io/dagger"
 
+	"github.com/dagger/dagger/internal/testutil"
 	"github.com/dagger/testctx"
 	"github.com/stretchr/testify/require"
 )
@@ -57,13 +58,10 @@ func testOwnership(
 	} {
 		example := example
 		t.Run(example.name, func(ctx context.Context, t *testctx.T) {
-			withOwner := addContent(ctr, example.name, example.owner)
+			withOwner := addContent(ctr, example.name, example.owner).
+				WithUser("root") // go back to root so we can see 0400 files
 			output, err := withOwner.
-				WithUser("root"). // go back to root so we can see 0400 files
-				WithExec([]string{
-					"sh", "-exc",
-					"find * | xargs stat -c '%U %G'", // stat recursively
-				}).
+				WithExec(statOwnershipArgs).
 				Stdout(ctx)
 			require.NoError(t, err)
 			for line := range strings.SplitSeq(output, "\n") {
@@ -73,6 +71,39 @@ func testOwnership(
 
 				require.Equal(t, example.output, line)
 			}
+
+			// ownership is the same from inside a user namespace
+			usernsOutput := statOwnershipInUserNamespace(ctx, t, c, withOwner)
+			require.Equal(t, output, usernsOutput)
 		})
 	}
 }
+
+var statOwnershipArgs = []string{
+	"sh", "-exc",
+	"find * | xargs stat -c '%U %G'", // stat recursively
+}
+
+func statOwnershipInUserNamespace(ctx context.Context, t *testctx.T, c *dagger.Client, ctr *dagger.Container) string {
+	ctrID, err := ctr.ID(ctx)
+	require.NoError(t, err)
+
+	res, err := testutil.QueryWithClient[struct {
+		LoadContainerFromID struct {
+			WithExec struct {
+				Stdout string
+			}
+		}
+	}](c, t, `query Test($id: ContainerID!, $args: [String!]!) {
+		loadContainerFromID(id: $id) {
+			withExec(args: $args, userNamespace: true) {
+				stdout
+			}
+		}
+	}`, &testutil.QueryOptions{Variables: map[string]any{
+		"id":   ctrID,
+		"args": statOwnershipArgs,
+	}})
+	require.NoError(t, err)
+	return res.LoadContainerFromID.WithExec.Stdout
+}
@@ -13,6 +13,8 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"dagger.io/dagger"
+
+	"github.com/dagger/dagger/internal/testutil"
 )
 
 type PlatformSuite struct{}
@@ -208,3 +210,177 @@ func (PlatformSuite) TestWindows(ctx context.Context, t *testctx.T) {
 	require.NoError(t, err)
 	require.Equal(t, []string{"License.txt", "ProgramData/", "Users/", "Windows/"}, ents)
 }
+
+func (PlatformSuite) TestContainerSet(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	ctrID, err := c.Container().
+		From(alpineImage).
+		WithExec([]string{"sh", "-c", "uname -m > /uname"}).
+		ID(ctx)
+	require.NoError(t, err)
+
+	platforms := make([]dagger.Platform, 0, len(platformToUname))
+	for platform := range platformToUname {
+		platforms = append(platforms, platform)
+	}
+
+	res, err := testutil.QueryWithClient[struct {
+		LoadContainerFromID struct {
+			ForPlatforms struct {
+				Platforms  []dagger.Platform
+				Containers []struct {
+					Platform dagger.Platform
+					File     struct {
+						Contents string
+					}
+				}
+			}
+		}
+	}](c, t, `query Test($id: ContainerID!, $platforms: [Platform!]!) {
+		loadContainerFromID(id: $id) {
+			forPlatforms(platforms: $platforms) {
+				platforms
+				containers {
+					platform
+					file(path: "/uname") {
+						contents
+					}
+				}
+			}
+		}
+	}`, &testutil.QueryOptions{Variables: map[string]any{
+		"id":        ctrID,
+		"platforms": platforms,
+	}})
+	require.NoError(t, err)
+
+	set := res.LoadContainerFromID.ForPlatforms
+	require.Equal(t, platforms, set.Platforms)
+	require.Len(t, set.Containers, len(platforms))
+	for i, ctr := range set.Containers {
+		require.Equal(t, platforms[i], ctr.Platform)
+		require.Equal(t, platformToUname[ctr.Platform], strings.TrimSpace(ctr.File.Contents))
+	}
+
+	t.Run("publish", func(ctx context.Context, t *testctx.T) {
+		testRef := registryRef("platform-container-set")
+		_, err := testutil.QueryWithClient[struct {
+			LoadContainerFromID struct {
+				ForPlatforms struct {
+					Publish string
+				}
+			}
+		}](c, t, `query Test($id: ContainerID!, $platforms: [Platform!]!, $ref: String!) {
+			loadContainerFromID(id: $id) {
+				forPlatforms(platforms: $platforms) {
+					publish(address: $ref)
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: map[string]any{
+			"id":        ctrID,
+			"platforms": platforms,
+			"ref":       testRef,
+		}})
+		require.NoError(t, err)
+
+		for platform, uname := range platformToUname {
+			output, err := c.Container(dagger.ContainerOpts{Platform: platform}).
+				From(testRef).
+				File("/uname").
+				Contents(ctx)
+			require.NoError(t, err)
+			require.Equal(t, uname, strings.TrimSpace(output))
+		}
+	})
+
+	t.Run("operations", func(ctx context.Context, t *testctx.T) {
+		res, err := testutil.QueryWithClient[struct {
+			LoadContainerFromID struct {
+				ForPlatforms struct {
+					WithEnvVariable struct {
+						WithExec struct {
+							Containers []struct {
+								Platform dagger.Platform
+								Stdout   string
+							}
+						}
+					}
+				}
+			}
+		}](c, t, `query Test($id: ContainerID!, $platforms: [Platform!]!) {
+			loadContainerFromID(id: $id) {
+				forPlatforms(platforms: $platforms) {
+					withEnvVariable(name: "GREETING", value: "hello") {
+						withExec(args: ["sh", "-c", "echo $GREETING $(cat /uname)"]) {
+							containers {
+								platform
+								stdout
+							}
+						}
+					}
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: map[string]any{
+			"id":        ctrID,
+			"platforms": platforms,
+		}})
+		require.NoError(t, err)
+
+		ctrs := res.LoadContainerFromID.ForPlatforms.WithEnvVariable.WithExec.Containers
+		require.Len(t, ctrs, len(platforms))
+		for i, ctr := range ctrs {
+			require.Equal(t, platforms[i], ctr.Platform)
+			require.Equal(t, "hello "+platformToUname[ctr.Platform], strings.TrimSpace(ctr.Stdout))
+		}
+	})
+
+	t.Run("per-platform failures", func(ctx context.Context, t *testctx.T) {
+		failID, err := c.Container().
+			From(alpineImage).
+			WithExec([]string{"sh", "-c", `test "$(uname -m)" = x86_64`}).
+			ID(ctx)
+		require.NoError(t, err)
+
+		_, err = testutil.QueryWithClient[struct {
+			LoadContainerFromID struct {
+				ForPlatforms struct {
+					Sync string
+				}
+			}
+		}](c, t, `query Test($id: ContainerID!) {
+			loadContainerFromID(id: $id) {
+				forPlatforms(platforms: ["linux/amd64", "linux/arm64", "linux/s390x"]) {
+					sync
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: map[string]any{
+			"id": failID,
+		}})
+		requireErrOut(t, err, "platform linux/arm64")
+		requireErrOut(t, err, "platform linux/s390x")
+		require.NotContains(t, err.Error(), "platform linux/amd64")
+	})
+
+	t.Run("must start from container", func(ctx context.Context, t *testctx.T) {
+		dirCtrID, err := c.Directory().DockerBuild().ID(ctx)
+		require.NoError(t, err)
+
+		_, err = testutil.QueryWithClient[struct {
+			LoadContainerFromID struct {
+				ForPlatforms struct {
+					Platforms []dagger.Platform
+				}
+			}
+		}](c, t, `query Test($id: ContainerID!) {
+			loadContainerFromID(id: $id) {
+				forPlatforms(platforms: ["linux/amd64"]) {
+					platforms
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: map[string]any{
+			"id": dirCtrID,
+		}})
+		requireErrOut(t, err, `cannot change the platform of a pipeline starting from "directory"`)
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 	"slices"
 	"strconv"
@@ -608,6 +610,71 @@ func (llm *LLM) LastReply(ctx context.Context) (string, error) {
 	return reply, nil
 }
 
+// Stream syncs the LLM, streaming its replies as they are received. If the LLM
+// was already synced, its last reply is sent instead.
+func (llm *LLM) Stream(ctx context.Context) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		stream := llmStream{
+			chunks: make(chan string),
+			closed: make(chan struct{}),
+		}
+		// stop streaming when done, but let the LLM finish syncing
+		defer close(stream.closed)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- llm.Sync(context.WithValue(ctx, llmStreamKey{}, stream))
+		}()
+
+		streamed := false
+		for {
+			select {
+			case chunk := <-stream.chunks:
+				streamed = true
+				if !yield(chunk, nil) {
+					return
+				}
+			case err := <-done:
+				if err != nil {
+					yield("", err)
+					return
+				}
+				if !streamed {
+					reply, err := llm.LastReply(ctx)
+					yield(reply, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+type llmStreamKey struct{}
+
+// llmStream receives LLM replies as they are received, until closed.
+type llmStream struct {
+	chunks chan string
+	closed chan struct{}
+}
+
+func (stream llmStream) Write(p []byte) (int, error) {
+	select {
+	case stream.chunks <- string(p):
+	case <-stream.closed:
+	}
+	return len(p), nil
+}
+
+// llmReplyWriter returns a writer for LLM replies that writes to w, and to
+// the stream of the current LLM.Stream call, if any.
+func llmReplyWriter(ctx context.Context, w io.Writer) io.Writer {
+	stream, ok := ctx.Value(llmStreamKey{}).(llmStream)
+	if !ok {
+		return w
+	}
+	return io.MultiWriter(w, stream)
+}
+
 func (llm *LLM) messagesWithSystemPrompt() []*ModelMessage {
 	if llm.disableDefaultSystemPrompt {
 		return llm.messages
@@ -76,8 +76,8 @@ func (c *AnthropicClient) SendQuery(ctx context.Context, history []*ModelMessage
 	stdio := telemetry.SpanStdio(ctx, InstrumentationLibrary)
 	defer stdio.Close()
 
-	markdownW := telemetry.NewWriter(ctx, InstrumentationLibrary,
-		log.String(telemetry.ContentTypeAttr, "text/markdown"))
+	markdownW := llmReplyWriter(ctx, telemetry.NewWriter(ctx, InstrumentationLibrary,
+		log.String(telemetry.ContentTypeAttr, "text/markdown")))
 
 	m := telemetry.Meter(ctx, InstrumentationLibrary)
 	attrs := []attribute.KeyValue{
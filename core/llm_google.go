@@ -287,7 +287,7 @@ func (c *GenaiClient) SendQuery(ctx context.Context, history []*ModelMessage, to
 
 	content, toolCalls, tokenUsage, err := c.processStreamResponse(
 		stream,
-		stdio.Stdout,
+		llmReplyWriter(ctx, stdio.Stdout),
 		tokenHandler,
 	)
 	if err != nil {
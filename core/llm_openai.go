@@ -231,7 +231,7 @@ func (c *OpenAIClient) queryWithStreaming(
 
 		if len(res.Choices) > 0 {
 			if content := res.Choices[0].Delta.Content; content != "" {
-				fmt.Fprint(stdio.Stdout, content)
+				fmt.Fprint(llmReplyWriter(ctx, stdio.Stdout), content)
 			}
 		}
 	}
@@ -265,7 +265,7 @@ func (c *OpenAIClient) queryWithoutStreaming(
 
 	if len(compl.Choices) > 0 {
 		if content := compl.Choices[0].Message.Content; content != "" {
-			fmt.Fprint(stdio.Stdout, content)
+			fmt.Fprint(llmReplyWriter(ctx, stdio.Stdout), content)
 		}
 	}
 
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"dagger.io/dagger/telemetry"
 	bkgw "github.com/moby/buildkit/frontend/gateway/client"
@@ -204,10 +206,35 @@ func (fn *ModuleFunction) CacheConfigForCall(
 	view call.View,
 	inputCfg dagql.CacheConfig,
 ) (*dagql.CacheConfig, error) {
+	// Directory and File args are identified by their content, and Container
+	// args by their definition, rather than the calls that produced them, so
+	// that different calls that produce the same result share the call's cache
+	contentArgs, err := fn.contentHashedArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(contentArgs) > 0 {
+		id := dagql.CurrentID(ctx)
+		for _, idArg := range id.Args() {
+			if input, ok := contentArgs[idArg.Name()]; ok {
+				id = id.WithArgument(idArg.WithValue(input.ToLiteral()))
+			}
+		}
+		ctx = dagql.ContextWithID(ctx, id)
+	}
+	if len(fn.metadata.CacheKeyArgs) > 0 {
+		// only the key args identify the result, so that calls differing only
+		// in other args share it
+		ctx = dagql.ContextWithID(ctx, dagql.IDWithArgs(dagql.CurrentID(ctx), fn.metadata.CacheKeyArgs...))
+	}
+
 	cacheCfg, err := fn.mod.CacheConfigForCall(ctx, parent, args, view, inputCfg)
 	if err != nil {
 		return nil, err
 	}
+	if len(contentArgs) > 0 {
+		cacheCfg.UpdatedArgs = contentArgs
+	}
 
 	dgstInputs := []string{cacheCfg.Digest.String()}
 
@@ -224,7 +251,9 @@ func (fn *ModuleFunction) CacheConfigForCall(
 		ctxArgs = append(ctxArgs, argMetadata)
 	}
 	if len(ctxArgs) > 0 {
-		cacheCfg.UpdatedArgs = make(map[string]dagql.Input)
+		if cacheCfg.UpdatedArgs == nil {
+			cacheCfg.UpdatedArgs = make(map[string]dagql.Input)
+		}
 		var mu sync.Mutex
 		type argInput struct {
 			name string
@@ -256,14 +285,142 @@ func (fn *ModuleFunction) CacheConfigForCall(
 		}
 
 		for _, arg := range ctxArgVals {
+			if !fn.isCacheKeyArg(arg.name) {
+				continue
+			}
 			dgstInputs = append(dgstInputs, arg.name, arg.val.ID().Digest().String())
 		}
 	}
 
-	cacheCfg.Digest = dagql.HashFrom(dgstInputs...)
+	ttl, err := fn.metadata.CachePolicyTTL()
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache TTL: %w", err)
+	}
+	cacheCfg.Digest = dagql.TTLDigest(dagql.HashFrom(dgstInputs...), ttl, time.Now())
 	return cacheCfg, nil
 }
 
+// isCacheKeyArg returns whether the arg with the given original name identifies
+// the function's result in the cache.
+func (fn *ModuleFunction) isCacheKeyArg(originalName string) bool {
+	if len(fn.metadata.CacheKeyArgs) == 0 {
+		return true
+	}
+	for _, arg := range fn.metadata.Args {
+		if arg.OriginalName == originalName {
+			return slices.Contains(fn.metadata.CacheKeyArgs, arg.Name)
+		}
+	}
+	return false
+}
+
+// contentHashedArgs returns the cache key args whose values include
+// Directory, File or Container IDs, with those IDs replaced by ones identified
+// by their content. Directory and File args are evaluated to hash their
+// content, unless they're already identified by it (e.g. synced or host
+// directories).
+//
+// Containers aren't evaluated, since that would mean checksumming their whole
+// rootfs and mounts; they're identified by their LLB definition instead, so
+// identical containers built through different definitions still miss the
+// cache.
+func (fn *ModuleFunction) contentHashedArgs(ctx context.Context, args map[string]dagql.Input) (map[string]dagql.Input, error) {
+	srv := dagql.CurrentDagqlServer(ctx)
+	var mu sync.Mutex
+	updated := map[string]dagql.Input{}
+	eg, ctx := errgroup.WithContext(ctx)
+	for name, input := range args {
+		if len(fn.metadata.CacheKeyArgs) > 0 && !slices.Contains(fn.metadata.CacheKeyArgs, name) {
+			continue
+		}
+		eg.Go(func() error {
+			newInput, changed, err := contentHashedInput(ctx, srv, input)
+			if err != nil {
+				return fmt.Errorf("failed to hash content of arg %q: %w", name, err)
+			}
+			if changed {
+				mu.Lock()
+				updated[name] = newInput
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func contentHashedInput(ctx context.Context, srv *dagql.Server, input dagql.Input) (dagql.Input, bool, error) {
+	switch x := input.(type) {
+	case dagql.DynamicOptional:
+		if !x.Valid {
+			return input, false, nil
+		}
+		value, changed, err := contentHashedInput(ctx, srv, x.Value)
+		if err != nil || !changed {
+			return input, false, err
+		}
+		x.Value = value
+		return x, true, nil
+
+	case dagql.DynamicArrayInput:
+		values := make([]dagql.Input, len(x.Values))
+		var anyChanged bool
+		for i, elem := range x.Values {
+			value, changed, err := contentHashedInput(ctx, srv, elem)
+			if err != nil {
+				return nil, false, err
+			}
+			values[i] = value
+			anyChanged = anyChanged || changed
+		}
+		if !anyChanged {
+			return input, false, nil
+		}
+		x.Values = values
+		return x, true, nil
+
+	case dagql.IDType:
+		id := x.ID()
+		switch id.Type().NamedType() {
+		case "Directory", "File", "Container":
+		default:
+			return input, false, nil
+		}
+		if id.Digest() != id.WithDigest("").Digest() {
+			// already identified by its content
+			return input, false, nil
+		}
+		obj, err := srv.Load(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		var dgst digest.Digest
+		switch hasher := obj.Unwrap().(type) {
+		case ContentHasher:
+			dgst, err = hasher.ContentHash(ctx)
+		case DefinitionHasher:
+			dgst, err = hasher.DefinitionHash()
+		default:
+			return input, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if dgst == "" || dgst == id.Digest() {
+			return input, false, nil
+		}
+		value, err := x.Decoder().DecodeInput(id.WithDigest(dgst))
+		if err != nil {
+			return nil, false, err
+		}
+		return value, true, nil
+	}
+	return input, false, nil
+}
+
 func (fn *ModuleFunction) Call(ctx context.Context, opts *CallOpts) (t dagql.AnyResult, rerr error) { //nolint: gocyclo
 	mod := fn.mod
 
@@ -292,10 +449,11 @@ func (fn *ModuleFunction) Call(ctx context.Context, opts *CallOpts) (t dagql.Any
 	}
 
 	var cacheMixins []string
-	if !opts.Cache {
+	if !opts.Cache && fn.metadata.CacheTTL == "" {
 		// Scope the exec cache key to the current session ID. It will be
 		// cached in the context of the session but invalidated across
-		// different sessions.
+		// different sessions. Functions with a cache TTL are instead scoped
+		// to the TTL's window, which is mixed into the call digest.
 		cacheMixins = append(cacheMixins, clientMetadata.SessionID)
 	}
 	if !opts.SkipCallDigestCacheKey {
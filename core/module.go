@@ -55,6 +55,9 @@ type Module struct {
 	// The module's enumerations
 	EnumDefs []*TypeDef `field:"true" name:"enums" doc:"Enumerations served by this module."`
 
+	// The module's unions
+	UnionDefs []*TypeDef `field:"true" name:"unions" doc:"Unions served by this module."`
+
 	// ResultID is the ID of the initialized module.
 	ResultID *call.ID
 }
@@ -184,13 +187,21 @@ func (mod *Module) Install(ctx context.Context, dag *dagql.Server) error {
 		enum.Install(dag)
 	}
 
+	for _, def := range mod.UnionDefs {
+		unionDef := def.AsUnion.Value
+
+		slog.ExtraDebug("installing union", "name", mod.Name(), "union", unionDef.Name, "members", len(unionDef.Members))
+
+		dag.InstallUnion(unionDef.ToUnionSpec())
+	}
+
 	return nil
 }
 
 func (mod *Module) TypeDefs(ctx context.Context, dag *dagql.Server) ([]*TypeDef, error) {
 	// TODO: use dag arg to reflect dynamic updates (if/when we support that)
 
-	typeDefs := make([]*TypeDef, 0, len(mod.ObjectDefs)+len(mod.InterfaceDefs)+len(mod.EnumDefs))
+	typeDefs := make([]*TypeDef, 0, len(mod.ObjectDefs)+len(mod.InterfaceDefs)+len(mod.EnumDefs)+len(mod.UnionDefs))
 
 	for _, def := range mod.ObjectDefs {
 		typeDef := def.Clone()
@@ -216,6 +227,14 @@ func (mod *Module) TypeDefs(ctx context.Context, dag *dagql.Server) ([]*TypeDef,
 		typeDefs = append(typeDefs, typeDef)
 	}
 
+	for _, def := range mod.UnionDefs {
+		typeDef := def.Clone()
+		if typeDef.AsUnion.Valid {
+			typeDef.AsUnion.Value.SourceModuleName = mod.Name()
+		}
+		typeDefs = append(typeDefs, typeDef)
+	}
+
 	return typeDefs, nil
 }
 
@@ -283,6 +302,12 @@ func (mod *Module) ModTypeFor(ctx context.Context, typeDef *TypeDef, checkDirect
 			return modType, ok, err
 		}
 		modType, ok = mod.modTypeForEnum(typeDef)
+	case TypeDefKindUnion:
+		modType, ok, err = mod.modTypeFromDeps(ctx, typeDef, checkDirectDeps)
+		if ok || err != nil {
+			return modType, ok, err
+		}
+		modType, ok = mod.modTypeForUnion(typeDef)
 	default:
 		return nil, false, fmt.Errorf("unexpected type def kind %s", typeDef.Kind)
 	}
@@ -377,6 +402,20 @@ func (mod *Module) modTypeForEnum(typeDef *TypeDef) (ModType, bool) {
 	return nil, false
 }
 
+func (mod *Module) modTypeForUnion(typeDef *TypeDef) (ModType, bool) {
+	for _, union := range mod.UnionDefs {
+		if union.AsUnion.Value.Name == typeDef.AsUnion.Value.Name {
+			return &ModuleUnionType{
+				typeDef: union.AsUnion.Value,
+				mod:     mod,
+			}, true
+		}
+	}
+
+	slog.ExtraDebug("module did not find union", "mod", mod.Name(), "union", typeDef.AsUnion.Value.Name)
+	return nil, false
+}
+
 // verify the typedef is has no reserved names
 func (mod *Module) validateTypeDef(ctx context.Context, typeDef *TypeDef) error {
 	switch typeDef.Kind {
@@ -386,6 +425,8 @@ func (mod *Module) validateTypeDef(ctx context.Context, typeDef *TypeDef) error
 		return mod.validateObjectTypeDef(ctx, typeDef)
 	case TypeDefKindInterface:
 		return mod.validateInterfaceTypeDef(ctx, typeDef)
+	case TypeDefKindUnion:
+		return mod.validateUnionTypeDef(ctx, typeDef)
 	}
 	return nil
 }
@@ -454,6 +495,13 @@ func (mod *Module) validateObjectTypeDef(ctx context.Context, typeDef *TypeDef)
 		}
 
 		for _, arg := range fn.Args {
+			if arg.TypeDef.Underlying().Kind == TypeDefKindUnion {
+				return fmt.Errorf("object %q function %q arg %q cannot be a union: unions can only be returned",
+					obj.OriginalName,
+					fn.OriginalName,
+					arg.OriginalName,
+				)
+			}
 			argType, ok, err := mod.Deps.ModTypeFor(ctx, arg.TypeDef)
 			if err != nil {
 				return fmt.Errorf("failed to get mod type for type def: %w", err)
@@ -473,6 +521,23 @@ func (mod *Module) validateObjectTypeDef(ctx context.Context, typeDef *TypeDef)
 				return err
 			}
 		}
+
+		if _, err := fn.CachePolicyTTL(); err != nil {
+			return fmt.Errorf("object %q function %q has an invalid cache TTL: %w",
+				obj.OriginalName,
+				fn.OriginalName,
+				err,
+			)
+		}
+		for _, keyArg := range fn.CacheKeyArgs {
+			if _, ok := fn.LookupArg(keyArg); !ok {
+				return fmt.Errorf("object %q function %q cache policy references unknown arg %q",
+					obj.OriginalName,
+					fn.OriginalName,
+					keyArg,
+				)
+			}
+		}
 	}
 	return nil
 }
@@ -500,6 +565,13 @@ func (mod *Module) validateInterfaceTypeDef(ctx context.Context, typeDef *TypeDe
 		}
 
 		for _, arg := range fn.Args {
+			if arg.TypeDef.Underlying().Kind == TypeDefKindUnion {
+				return fmt.Errorf("interface %q function %q arg %q cannot be a union: unions can only be returned",
+					iface.OriginalName,
+					fn.OriginalName,
+					arg.OriginalName,
+				)
+			}
 			if err := mod.validateTypeDef(ctx, arg.TypeDef); err != nil {
 				return err
 			}
@@ -508,6 +580,43 @@ func (mod *Module) validateInterfaceTypeDef(ctx context.Context, typeDef *TypeDe
 	return nil
 }
 
+func (mod *Module) validateUnionTypeDef(ctx context.Context, typeDef *TypeDef) error {
+	union := typeDef.AsUnion.Value
+
+	// check whether this is a pre-existing union from core or another module
+	modType, ok, err := mod.Deps.ModTypeFor(ctx, typeDef)
+	if err != nil {
+		return fmt.Errorf("failed to get mod type for type def: %w", err)
+	}
+	if ok {
+		if sourceMod := modType.SourceMod(); sourceMod != nil && sourceMod != mod {
+			// already validated, skip
+			return nil
+		}
+	}
+	for _, member := range union.Members {
+		if member.Kind != TypeDefKindObject {
+			return fmt.Errorf("union %q member must be an object, got %s", union.OriginalName, member.Kind)
+		}
+		memberType, ok, err := mod.Deps.ModTypeFor(ctx, member)
+		if err != nil {
+			return fmt.Errorf("failed to get mod type for type def: %w", err)
+		}
+		if ok {
+			// members can be core types and local types, but not types from
+			// other modules
+			if sourceMod := memberType.SourceMod(); sourceMod != nil && sourceMod.Name() != ModuleName && sourceMod != mod {
+				return fmt.Errorf("union %q member %q cannot reference external type from dependency module %q",
+					union.OriginalName,
+					member.AsObject.Value.OriginalName,
+					sourceMod.Name(),
+				)
+			}
+		}
+	}
+	return nil
+}
+
 // prefix the given typedef (and any recursively referenced typedefs) with this
 // module's name/path for any objects
 func (mod *Module) namespaceTypeDef(ctx context.Context, modPath string, typeDef *TypeDef) error {
@@ -595,6 +704,24 @@ func (mod *Module) namespaceTypeDef(ctx context.Context, modPath string, typeDef
 		for _, value := range enum.Members {
 			value.SourceMap = mod.namespaceSourceMap(modPath, value.SourceMap)
 		}
+	case TypeDefKindUnion:
+		union := typeDef.AsUnion.Value
+
+		// only namespace unions defined in this module
+		_, ok, err := mod.Deps.ModTypeFor(ctx, typeDef)
+		if err != nil {
+			return fmt.Errorf("failed to get mod type for type def: %w", err)
+		}
+		if !ok {
+			union.Name = namespaceObject(union.OriginalName, mod.Name(), mod.OriginalName)
+			union.SourceMap = mod.namespaceSourceMap(modPath, union.SourceMap)
+		}
+
+		for _, member := range union.Members {
+			if err := mod.namespaceTypeDef(ctx, modPath, member); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -768,6 +895,11 @@ func (mod Module) Clone() *Module {
 		cp.EnumDefs[i] = def.Clone()
 	}
 
+	cp.UnionDefs = make([]*TypeDef, len(mod.UnionDefs))
+	for i, def := range mod.UnionDefs {
+		cp.UnionDefs[i] = def.Clone()
+	}
+
 	if cp.SDKConfig != nil {
 		cp.SDKConfig = cp.SDKConfig.Clone()
 	}
@@ -781,6 +913,7 @@ func (mod Module) CloneWithoutDefs() *Module {
 	cp.EnumDefs = []*TypeDef{}
 	cp.ObjectDefs = []*TypeDef{}
 	cp.InterfaceDefs = []*TypeDef{}
+	cp.UnionDefs = []*TypeDef{}
 
 	return cp
 }
@@ -871,6 +1004,33 @@ func (mod *Module) WithEnum(ctx context.Context, def *TypeDef) (*Module, error)
 	return mod, nil
 }
 
+func (mod *Module) WithUnion(ctx context.Context, def *TypeDef) (*Module, error) {
+	mod = mod.Clone()
+	if !def.AsUnion.Valid {
+		return nil, fmt.Errorf("expected union type def, got %s: %+v", def.Kind, def)
+	}
+
+	// skip validation+namespacing for module objects being constructed by SDK with* calls
+	// they will be validated when merged into the real final module
+
+	if mod.Deps != nil {
+		if err := mod.validateTypeDef(ctx, def); err != nil {
+			return nil, fmt.Errorf("failed to validate type def: %w", err)
+		}
+	}
+	if mod.NameField != "" {
+		def = def.Clone()
+		modPath := mod.modulePath()
+		if err := mod.namespaceTypeDef(ctx, modPath, def); err != nil {
+			return nil, fmt.Errorf("failed to namespace type def: %w", err)
+		}
+	}
+
+	mod.UnionDefs = append(mod.UnionDefs, def)
+
+	return mod, nil
+}
+
 type CurrentModule struct {
 	Module *Module
 }
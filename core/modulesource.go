@@ -469,6 +469,10 @@ func (src *ModuleSource) LoadContext(
 
 type LocalModuleSource struct {
 	ContextDirectoryPath string
+
+	// NoCache is set when the source was loaded with noCache, so that its
+	// context directory and local dependencies are also reloaded from the host.
+	NoCache bool
 }
 
 func (src LocalModuleSource) Clone() *LocalModuleSource {
@@ -625,6 +629,7 @@ func ResolveDepToSource(
 				Args: []dagql.NamedInput{
 					{Name: "refString", Value: dagql.String(depPath)},
 					{Name: "disableFindUp", Value: dagql.Boolean(true)},
+					{Name: "noCache", Value: dagql.Boolean(parentSrc.Local.NoCache)},
 				},
 			}}
 			if depName != "" {
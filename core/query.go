@@ -88,6 +88,9 @@ type Server interface {
 	// The default platform for the engine as a whole
 	Platform() Platform
 
+	// The platforms the engine can run containers for, natively or with emulation
+	Platforms() []Platform
+
 	// The content store for the engine as a whole
 	OCIStore() content.Store
 
@@ -67,6 +67,7 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 			),
 
 		dagql.NodeFunc("from", s.from).
+			Cost(10).
 			Doc(`Download a container image, and apply it to the container state. All previous state will be lost.`).
 			Args(
 				dagql.Arg("address").Doc(
@@ -435,6 +436,7 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 			),
 
 		dagql.NodeFuncWithCacheKey("withExec", s.withExec, s.withExecCacheKey).
+			Cost(10).
 			View(AllVersion).
 			Doc(`Execute a command in the container, and return a new snapshot of the container state after execution.`).
 			Args(
@@ -469,6 +471,9 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 				dagql.Arg("insecureRootCapabilities").Doc(
 					`Execute the command with all root capabilities. Like --privileged in Docker`,
 					`DANGER: this grants the command full access to the host system. Only use when 1) you trust the command being executed and 2) you specifically need this level of access.`),
+				dagql.Arg("userNamespace").Doc(
+					`Execute the command in a user namespace, so that root in the container maps to an unprivileged user on the host.`,
+					`Files keep their ownership as seen from the container. Cannot be combined with insecureRootCapabilities.`),
 				dagql.Arg("expand").Doc(
 					`Replace "${VAR}" or "$VAR" in the args according to the current `+
 						`environment variables defined in the container (e.g. "/$VAR/foo").`),
@@ -486,6 +491,11 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 			View(BeforeVersion("v0.12.0")).
 			Extend(),
 
+		dagql.NodeFunc("stdoutStream", s.stdoutStream).
+			Doc(`The standard output stream of the last executed command, delivered as it is produced.`,
+				`Can only be selected in a subscription. Returns an error if no command was executed.`,
+				`If the client reads the stream too slowly, output is dropped and replaced by a note saying how many bytes were lost.`),
+
 		dagql.Func("stderr", s.stderr).
 			View(AllVersion).
 			Doc(`The buffered standard error stream of the last executed command`,
@@ -499,6 +509,7 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 				`Returns an error if no command was executed`),
 
 		dagql.Func("exitCode", s.exitCode).
+			Persist().
 			Doc(`The exit code of the last executed command`,
 				`Returns an error if no command was executed`),
 
@@ -526,6 +537,7 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 			),
 
 		dagql.Func("publish", s.publish).
+			Cost(10).
 			DoNotCache("side effect on an external system (OCI registry)").
 			Doc(`Package the container state as an OCI image, and publish it to a registry`,
 				`Returns the fully qualified address of the published image, with digest`).
@@ -554,6 +566,15 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 		dagql.Func("platform", s.platform).
 			Doc(`The platform this container executes and publishes as.`),
 
+		dagql.NodeFunc("forPlatforms", s.forPlatforms).
+			Doc(`Replay the pipeline that built this container for each of the given platforms, in parallel.`,
+				`The pipeline must start from "container"; its platform is replaced
+				for each platform, and every subsequent operation is applied on top of
+				it. Non-native platforms are executed using emulation.`).
+			Args(
+				dagql.Arg("platforms").Doc(`The platforms to build the container for.`),
+			),
+
 		dagql.Func("export", s.export).
 			View(AllVersion).
 			DoNotCache("Writes to the local host.").
@@ -628,10 +649,23 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 			),
 
 		dagql.Func("import", s.import_).
-			Doc(`Reads the container from an OCI tarball.`).
+			Doc(`Reads the container from an OCI tarball or Docker archive.`,
+				`Use File.imageDescriptors to list the images available in the archive.`).
 			Args(
 				dagql.Arg("source").Doc(`File to read the container from.`),
 				dagql.Arg("tag").Doc(`Identifies the tag to import from the archive, if the archive bundles multiple tags.`),
+				dagql.Arg("digest").Doc(`Identifies the image manifest or index to import from the archive by its digest.`),
+				dagql.Arg("annotations").Doc(`Identifies the image to import from the archive by the annotations of its descriptor.`),
+			),
+
+		dagql.Func("importLayout", s.importLayout).
+			Doc(`Reads the container from an OCI image layout directory.`,
+				`Use Directory.imageDescriptors to list the images available in the layout.`).
+			Args(
+				dagql.Arg("source").Doc(`Directory containing the OCI image layout (with an "index.json" at its root).`),
+				dagql.Arg("tag").Doc(`Identifies the tag to import from the layout, if the layout bundles multiple tags.`),
+				dagql.Arg("digest").Doc(`Identifies the image manifest or index to import from the layout by its digest.`),
+				dagql.Arg("annotations").Doc(`Identifies the image to import from the layout by the annotations of its descriptor.`),
 			),
 
 		dagql.Func("withRegistryAuth", s.withRegistryAuth).
@@ -711,7 +745,7 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 
 		dagql.NodeFunc("terminal", s.terminal).
 			View(AfterVersion("v0.12.0")).
-			DoNotCache("Only creates a temporary container for the user to interact with and then returns original parent.").
+			DoNotCache("Each session is interactive, so it must run every time it's called, even for the same container.").
 			Doc(`Opens an interactive terminal for this container using its configured default terminal command if not overridden by args (or sh as a fallback default).`).
 			Args(
 				dagql.Arg("cmd").Doc(`If set, override the container's default terminal command and invoke these command arguments instead.`),
@@ -723,6 +757,10 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 				"--privileged" flag. Containerization does not provide any security
 				guarantees when using this option. It should only be used when
 				absolutely necessary and only with trusted commands.`),
+				dagql.Arg("record").Doc(
+					`If set, record the session as an asciicast v2 file at "`+core.TerminalRecordingPath+`" in the returned container.`,
+					`The returned container is identified by the contents of the recording, so loading it again doesn't open another terminal.`,
+					`It can be played back with "dagger terminal --replay".`),
 			),
 		dagql.NodeFunc("terminal", s.terminalLegacy).
 			View(BeforeVersion("v0.12.0")).
@@ -1027,6 +1065,16 @@ func (s *containerSchema) stdout(ctx context.Context, parent *core.Container, _
 	return parent.Stdout(ctx)
 }
 
+func (s *containerSchema) stdoutStream(ctx context.Context, parent dagql.ObjectResult[*core.Container], _ struct{}) (dagql.Stream[dagql.String], error) {
+	return dagql.NewStream(func(yield func(dagql.String, error) bool) {
+		for chunk, err := range parent.Self().StdoutStream(ctx, parent.ID()) {
+			if !yield(dagql.NewString(chunk), err) {
+				return
+			}
+		}
+	}), nil
+}
+
 //nolint:dupl
 func (s *containerSchema) stdoutLegacy(ctx context.Context, parent dagql.ObjectResult[*core.Container], _ struct{}) (string, error) {
 	srv, err := core.CurrentDagqlServer(ctx)
@@ -2047,6 +2095,18 @@ func (s *containerSchema) platform(ctx context.Context, parent *core.Container,
 	return parent.Platform, nil
 }
 
+type containerForPlatformsArgs struct {
+	Platforms []core.Platform
+}
+
+func (s *containerSchema) forPlatforms(ctx context.Context, parent dagql.ObjectResult[*core.Container], args containerForPlatformsArgs) (*core.ContainerSet, error) {
+	srv, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server: %w", err)
+	}
+	return core.NewContainerSet(ctx, srv, parent.ID(), args.Platforms)
+}
+
 type containerExportArgs struct {
 	Path              string
 	PlatformVariants  []core.ContainerID `default:"[]"`
@@ -2392,9 +2452,30 @@ func (s *containerSchema) exportImage(
 	return core.Void{}, errors.New("invalid load config")
 }
 
+type ImageSelectorArgs struct {
+	Tag         string                                    `default:""`
+	Digest      string                                    `default:""`
+	Annotations []dagql.InputObject[core.ImageAnnotation] `default:"[]"`
+}
+
+func (args ImageSelectorArgs) selector() (core.ImageSelector, error) {
+	sel := core.ImageSelector{
+		Tag:         args.Tag,
+		Annotations: collectInputsSlice(args.Annotations),
+	}
+	if args.Digest != "" {
+		dgst, err := digest.Parse(args.Digest)
+		if err != nil {
+			return sel, fmt.Errorf("invalid digest %q: %w", args.Digest, err)
+		}
+		sel.Digest = dgst
+	}
+	return sel, nil
+}
+
 type containerImportArgs struct {
 	Source core.FileID
-	Tag    string `default:""`
+	ImageSelectorArgs
 }
 
 func (s *containerSchema) import_(ctx context.Context, parent *core.Container, args containerImportArgs) (*core.Container, error) {
@@ -2404,6 +2485,11 @@ func (s *containerSchema) import_(ctx context.Context, parent *core.Container, a
 		slog.ExtraDebug("done importing container", "source", args.Source.Display(), "tag", args.Tag, "took", start)
 	}()
 
+	sel, err := args.selector()
+	if err != nil {
+		return nil, err
+	}
+
 	srv, err := core.CurrentDagqlServer(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get server: %w", err)
@@ -2420,7 +2506,31 @@ func (s *containerSchema) import_(ctx context.Context, parent *core.Container, a
 	}
 	defer r.Close()
 
-	return parent.Import(ctx, r, args.Tag)
+	return parent.Import(ctx, r, sel)
+}
+
+type containerImportLayoutArgs struct {
+	Source core.DirectoryID
+	ImageSelectorArgs
+}
+
+func (s *containerSchema) importLayout(ctx context.Context, parent *core.Container, args containerImportLayoutArgs) (*core.Container, error) {
+	sel, err := args.selector()
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server: %w", err)
+	}
+
+	source, err := args.Source.Load(ctx, srv)
+	if err != nil {
+		return nil, err
+	}
+
+	return parent.ImportLayout(ctx, source.Self(), sel)
 }
 
 type containerWithRegistryAuthArgs struct {
@@ -2581,6 +2691,9 @@ func (s *containerSchema) withDefaultTerminalCmd(
 
 type containerTerminalArgs struct {
 	core.TerminalArgs
+
+	// Record the session in the returned container
+	Record bool `default:"false"`
 }
 
 func (s *containerSchema) terminal(
@@ -2605,18 +2718,46 @@ func (s *containerSchema) terminal(
 		args.Cmd = []string{"sh"}
 	}
 
-	err := ctr.Self().Terminal(ctx, ctr.ID(), &args.TerminalArgs)
+	if !args.Record {
+		err := ctr.Self().Terminal(ctx, ctr.ID(), &args.TerminalArgs)
+		if err != nil {
+			return res, err
+		}
+
+		return ctr, nil
+	}
+
+	recording, err := ctr.Self().TerminalRecording(ctx, ctr.ID(), &args.TerminalArgs)
 	if err != nil {
 		return res, err
 	}
-
-	return ctr, nil
+	srv, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return res, fmt.Errorf("failed to get server: %w", err)
+	}
+	// select the recording onto the parent rather than returning a result for
+	// the current ID, so the result's ID is content-addressed and doesn't call
+	// terminal again when it's loaded
+	err = srv.Select(ctx, ctr, &res,
+		dagql.Selector{
+			Field: "withNewFile",
+			Args: []dagql.NamedInput{
+				{Name: "path", Value: dagql.String(core.TerminalRecordingPath)},
+				{Name: "contents", Value: dagql.String(recording)},
+				{Name: "permissions", Value: dagql.Int(0o644)},
+			},
+		},
+	)
+	if err != nil {
+		return res, fmt.Errorf("failed to add terminal recording: %w", err)
+	}
+	return res, nil
 }
 
 func (s *containerSchema) terminalLegacy(
 	ctx context.Context,
 	ctr dagql.ObjectResult[*core.Container],
-	args containerTerminalArgs,
+	args core.TerminalArgs,
 ) (*core.TerminalLegacy, error) {
 	srv, err := core.CurrentDagqlServer(ctx)
 	if err != nil {
@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dagger/dagger/core"
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
+)
+
+type containerSetSchema struct{}
+
+var _ SchemaResolvers = &containerSetSchema{}
+
+func (s *containerSetSchema) Install(srv *dagql.Server) {
+	dagql.Fields[*core.ContainerSet]{
+		Syncer[*core.ContainerSet]().
+			Doc(`Forces evaluation of every container in the set, in parallel.`,
+				`Failures are reported for every platform that failed, not only the first one.`),
+
+		dagql.Func("platforms", s.platforms).
+			Doc(`The platforms of the containers in the set.`),
+
+		dagql.Func("container", s.container).
+			Doc(`Retrieve the container built for the given platform.`).
+			Args(
+				dagql.Arg("platform").Doc(`The platform of the container to retrieve.`),
+			),
+
+		dagql.Func("publish", s.publish).
+			DoNotCache("side effect on an external system (OCI registry)").
+			Doc(`Package every container in the set as a single multi-platform OCI image, and publish it to a registry`,
+				`Returns the fully qualified address of the published image index, with digest`).
+			Args(
+				dagql.Arg("address").Doc(
+					`The OCI address to publish to`,
+					`Same format as "docker push". Example: "registry.example.com/user/repo:tag"`),
+				dagql.Arg("forcedCompression").Doc(
+					`Force each layer of the published image to use
+					the specified compression algorithm.`,
+					`If this is unset, then if a layer already has a compressed blob in the
+					engine's cache, that will be used (this can result in a mix of
+					compression algorithms for different layers). If this is unset and a
+					layer has no compressed blob in the engine's cache, then it will be
+					compressed using Gzip.`),
+				dagql.Arg("mediaTypes").Doc(
+					`Use the specified media types for the published image's layers.`,
+					`Defaults to "OCI", which is compatible with most recent
+				registries, but "Docker" may be needed for older registries without OCI
+				support.`),
+			),
+	}.Install(srv)
+
+	s.installContainerOps(srv)
+}
+
+// containerSetOps are the Container operations that can be applied to every
+// container of a set at once.
+var containerSetOps = []string{
+	"withExec",
+	"withEnvVariable",
+	"withoutEnvVariable",
+	"withWorkdir",
+	"withUser",
+	"withLabel",
+	"withEntrypoint",
+	"withDefaultArgs",
+	"withDirectory",
+	"withFile",
+	"withNewFile",
+	"withMountedCache",
+	"withMountedDirectory",
+}
+
+// installContainerOps installs a field for each of containerSetOps, with the
+// same arguments as the Container field, which applies it to each container.
+func (s *containerSetSchema) installContainerOps(srv *dagql.Server) {
+	ctrType, ok := srv.ObjectType("Container")
+	if !ok {
+		panic("Container must be installed before ContainerSet")
+	}
+	var fields dagql.Fields[*core.ContainerSet]
+	for _, name := range containerSetOps {
+		spec, ok := ctrType.FieldSpec(name, srv.View)
+		if !ok {
+			panic(fmt.Sprintf("Container.%s not found", name))
+		}
+		spec.Type = &core.ContainerSet{}
+		spec.Description = dagql.FormatDescription(
+			fmt.Sprintf("Apply Container.%s to every container in the set, in parallel.", name),
+			spec.Description)
+		fields = append(fields, dagql.Field[*core.ContainerSet]{
+			Spec: &spec,
+			Func: func(ctx context.Context, self dagql.ObjectResult[*core.ContainerSet], args map[string]dagql.Input, view call.View) (dagql.AnyResult, error) {
+				var inputs []dagql.NamedInput
+				for _, arg := range spec.Args.Inputs(view) {
+					if val, ok := args[arg.Name]; ok {
+						inputs = append(inputs, dagql.NamedInput{Name: arg.Name, Value: val})
+					}
+				}
+				srv, err := core.CurrentDagqlServer(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get server: %w", err)
+				}
+				set, err := self.Self().Map(ctx, func(ctx context.Context, ctr dagql.ObjectResult[*core.Container]) (res dagql.ObjectResult[*core.Container], _ error) {
+					err := srv.Select(ctx, ctr, &res, dagql.Selector{Field: name, Args: inputs, View: view})
+					return res, err
+				})
+				if err != nil {
+					return nil, err
+				}
+				return dagql.NewResultForCurrentID(ctx, set)
+			},
+		})
+	}
+	fields.Install(srv)
+}
+
+func (s *containerSetSchema) platforms(ctx context.Context, parent *core.ContainerSet, _ struct{}) (dagql.Array[core.Platform], error) {
+	return parent.Platforms(), nil
+}
+
+type containerSetContainerArgs struct {
+	Platform core.Platform
+}
+
+func (s *containerSetSchema) container(ctx context.Context, parent *core.ContainerSet, args containerSetContainerArgs) (dagql.ObjectResult[*core.Container], error) {
+	ctr, ok := parent.Container(args.Platform)
+	if !ok {
+		return ctr, fmt.Errorf("no container for platform %q in set", args.Platform.Format())
+	}
+	return ctr, nil
+}
+
+type containerSetPublishArgs struct {
+	Address           dagql.String
+	ForcedCompression dagql.Optional[core.ImageLayerCompression]
+	MediaTypes        core.ImageMediaTypes `default:"OCI"`
+}
+
+func (s *containerSetSchema) publish(ctx context.Context, parent *core.ContainerSet, args containerSetPublishArgs) (dagql.String, error) {
+	ref, err := parent.Publish(
+		ctx,
+		args.Address.String(),
+		args.ForcedCompression.Value,
+		args.MediaTypes,
+	)
+	if err != nil {
+		return "", err
+	}
+	return dagql.NewString(ref), nil
+}
@@ -49,6 +49,7 @@ func (m *CoreMod) Install(ctx context.Context, dag *dagql.Server) error {
 		&fileSchema{},
 		&gitSchema{},
 		&containerSchema{},
+		&containerSetSchema{},
 		&cacheSchema{},
 		&secretSchema{},
 		&serviceSchema{},
@@ -162,6 +163,10 @@ func (m *CoreMod) ModTypeFor(ctx context.Context, typeDef *core.TypeDef, checkDi
 		// core does not yet define any interfaces
 		return nil, false, nil
 
+	case core.TypeDefKindUnion:
+		// core does not define any unions
+		return nil, false, nil
+
 	default:
 		return nil, false, fmt.Errorf("unexpected type def kind %s", typeDef.Kind)
 	}
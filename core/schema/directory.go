@@ -69,11 +69,15 @@ func (s *directorySchema) Install(srv *dagql.Server) {
 				return args
 			})()...),
 		dagql.Func("digest", s.digest).
+			Persist().
 			Doc(
 				`Return the directory's digest.
 				The format of the digest is not guaranteed to be stable between releases of Dagger.
 				It is guaranteed to be stable between invocations of the same Dagger engine.`,
 			),
+		dagql.Func("imageDescriptors", s.imageDescriptors).
+			Doc(`Lists the images in an OCI image layout directory.`,
+				`Use with Container.importLayout to pick the image to import.`),
 		dagql.Func("file", s.file).
 			Doc(`Retrieve a file at the given path.`).
 			Args(
@@ -164,6 +168,7 @@ func (s *directorySchema) Install(srv *dagql.Server) {
 			View(BeforeVersion("v0.12.0")).
 			Extend(),
 		dagql.NodeFunc("dockerBuild", s.dockerBuild).
+			Cost(20).
 			Doc(`Use Dockerfile compatibility to build a container from this directory. Only use this function for Dockerfile compatibility. Otherwise use the native Container type directly, it is feature-complete and supports all Dockerfile features.`).
 			Args(
 				dagql.Arg("dockerfile").Doc(`Path to the Dockerfile to use (e.g., "frontend.Dockerfile").`),
@@ -397,6 +402,10 @@ func (s *directorySchema) digest(ctx context.Context, parent *core.Directory, ar
 	return dagql.NewString(digest), nil
 }
 
+func (s *directorySchema) imageDescriptors(ctx context.Context, parent *core.Directory, args struct{}) (dagql.Array[*core.ImageDescriptor], error) {
+	return core.ImageLayoutDescriptors(ctx, parent)
+}
+
 type dirFileArgs struct {
 	Path string
 }
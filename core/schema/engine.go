@@ -22,6 +22,8 @@ func (s *engineSchema) Install(srv *dagql.Server) {
 	dagql.Fields[*core.Engine]{
 		dagql.Func("localCache", s.localCache).
 			Doc("The local (on-disk) cache for the Dagger engine"),
+		dagql.Func("platforms", s.platforms).
+			Doc("The platforms the engine can run containers for, natively or with emulation"),
 	}.Install(srv)
 
 	dagql.Fields[*core.EngineCache]{
@@ -47,6 +49,14 @@ func (s *engineSchema) engine(ctx context.Context, parent *core.Query, args stru
 	return &core.Engine{}, nil
 }
 
+func (s *engineSchema) platforms(ctx context.Context, parent *core.Engine, args struct{}) (dagql.Array[core.Platform], error) {
+	query, err := core.CurrentQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.Platforms(), nil
+}
+
 func (s *engineSchema) localCache(ctx context.Context, parent *core.Engine, args struct{}) (*core.EngineCache, error) {
 	query, err := core.CurrentQuery(ctx)
 	if err != nil {
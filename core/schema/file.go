@@ -29,16 +29,19 @@ func (s *fileSchema) Install(srv *dagql.Server) {
 		Syncer[*core.File]().
 			Doc(`Force evaluation in the engine.`),
 		dagql.Func("contents", s.contents).
+			Persist().
 			Doc(`Retrieves the contents of the file.`).
 			Args(
 				dagql.Arg("offsetLines").Doc(`Start reading after this line`),
 				dagql.Arg("limitLines").Doc(`Maximum number of lines to read`),
 			),
 		dagql.Func("size", s.size).
+			Persist().
 			Doc(`Retrieves the size of the file, in bytes.`),
 		dagql.Func("name", s.name).
 			Doc(`Retrieves the name of the file.`),
 		dagql.Func("digest", s.digest).
+			Persist().
 			Doc(
 				`Return the file's digest.
 				The format of the digest is not guaranteed to be stable between releases of Dagger.
@@ -47,6 +50,9 @@ func (s *fileSchema) Install(srv *dagql.Server) {
 			Args(
 				dagql.Arg("excludeMetadata").Doc(`If true, exclude metadata from the digest.`),
 			),
+		dagql.Func("imageDescriptors", s.imageDescriptors).
+			Doc(`Lists the images in an OCI tarball or Docker archive.`,
+				`Use with Container.import to pick the image to import.`),
 		dagql.Func("withName", s.withName).
 			Doc(`Retrieves this file with its name set to the given name.`).
 			Args(
@@ -130,6 +136,16 @@ func (s *fileSchema) name(ctx context.Context, file *core.File, args struct{}) (
 	return dagql.NewString(filepath.Base(file.File)), nil
 }
 
+func (s *fileSchema) imageDescriptors(ctx context.Context, file *core.File, args struct{}) (dagql.Array[*core.ImageDescriptor], error) {
+	r, err := file.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return core.ImageArchiveDescriptors(ctx, r)
+}
+
 type fileDigestArgs struct {
 	ExcludeMetadata bool `default:"false"`
 }
@@ -137,8 +137,10 @@ func (s *gitSchema) Install(srv *dagql.Server) {
 					Doc("This option should be passed to `git` instead.").Deprecated(),
 			),
 		dagql.NodeFunc("commit", DagOpWrapper(srv, s.fetchCommit)).
+			Persist().
 			Doc(`The resolved commit id at this ref.`),
 		dagql.NodeFunc("ref", DagOpWrapper(srv, s.fetchRef)).
+			Persist().
 			Doc(`The resolved ref name at this ref.`),
 		dagql.NodeFunc("commonAncestor", s.commonAncestor).
 			Doc(`Find the best common ancestor between this ref and another ref.`).
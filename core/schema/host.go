@@ -560,7 +560,7 @@ func (s *hostSchema) containerImage(ctx context.Context, parent dagql.ObjectResu
 		defer src.Close()
 
 		ctr := core.NewContainer(query.Platform())
-		ctr, err := ctr.Import(ctx, src, "")
+		ctr, err := ctr.Import(ctx, src, core.ImageSelector{})
 		if err != nil {
 			return inst, err
 		}
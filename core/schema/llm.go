@@ -79,6 +79,9 @@ func (s llmSchema) Install(srv *dagql.Server) {
 			return dagql.NewResultForCurrentID(ctx, id)
 		}).
 			Doc("synchronize LLM state"),
+		dagql.Func("stream", s.stream).
+			Doc("synchronize LLM state, streaming replies as they are received",
+				"Can only be selected in a subscription."),
 		dagql.Func("loop", s.loop).
 			// Deprecated("use sync").
 			Doc("synchronize LLM state"),
@@ -170,6 +173,16 @@ func (s *llmSchema) loop(ctx context.Context, llm *core.LLM, args struct{}) (*co
 	return llm, llm.Sync(ctx)
 }
 
+func (s *llmSchema) stream(ctx context.Context, llm *core.LLM, args struct{}) (dagql.Stream[dagql.String], error) {
+	return dagql.NewStream(func(yield func(dagql.String, error) bool) {
+		for chunk, err := range llm.Stream(ctx) {
+			if !yield(dagql.NewString(chunk), err) {
+				return
+			}
+		}
+	}), nil
+}
+
 func (s *llmSchema) attempt(_ context.Context, llm *core.LLM, _ struct {
 	Number int
 }) (*core.LLM, error) {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/core/sdk"
@@ -92,6 +93,9 @@ func (s *moduleSchema) Install(dag *dagql.Server) {
 		dagql.Func("withEnum", s.moduleWithEnum).
 			Doc(`This module plus the given Enum type and associated values`),
 
+		dagql.Func("withUnion", s.moduleWithUnion).
+			Doc(`This module plus the given Union type`),
+
 		dagql.Func("serve", s.moduleServe).
 			DoNotCache(`Mutates the calling session's global schema.`).
 			Doc(`Serve a module's API in the current session.`,
@@ -136,6 +140,15 @@ func (s *moduleSchema) Install(dag *dagql.Server) {
 				dagql.Arg("sourceMap").Doc(`The source map for the function definition.`),
 			),
 
+		dagql.Func("withCachePolicy", s.functionWithCachePolicy).
+			Doc(`Returns the function with the given cache policy.`,
+				`By default, a function's result is cached for the rest of the session
+				for the same receiver and arguments.`).
+			Args(
+				dagql.Arg("ttl").Doc(`Reuse the result across sessions for at most this duration (e.g. "1h").`),
+				dagql.Arg("keyArgs").Doc(`Identify the result by only these arguments, ignoring the others (e.g. a log level).`),
+			),
+
 		dagql.Func("withArg", s.functionWithArg).
 			Doc(`Returns the function with the provided argument`).
 			Args(
@@ -220,6 +233,22 @@ func (s *moduleSchema) Install(dag *dagql.Server) {
 				dagql.Arg("description").Doc(`A doc string for the member, if any`),
 				dagql.Arg("sourceMap").Doc(`The source map for the enum member definition.`),
 			),
+
+		dagql.Func("withUnion", s.typeDefWithUnion).
+			Doc(`Returns a TypeDef of kind Union with the provided name.`,
+				`Note that a union's members may be omitted if the intent is only to refer to a union.
+				This is how functions are able to return their own, or any other circular reference.`).
+			Args(
+				dagql.Arg("name").Doc(`The name of the union`),
+				dagql.Arg("description").Doc(`A doc string for the union, if any`),
+				dagql.Arg("sourceMap").Doc(`The source map for the union definition.`),
+			),
+
+		dagql.Func("withUnionMember", s.typeDefWithUnionMember).
+			Doc(`Adds an object type as a member of a Union TypeDef, failing if the type is not a union.`).
+			Args(
+				dagql.Arg("typeDef").Doc(`The object type of the member`),
+			),
 	}.Install(dag)
 
 	dagql.Fields[*core.ObjectTypeDef]{}.Install(dag)
@@ -234,6 +263,7 @@ func (s *moduleSchema) Install(dag *dagql.Server) {
 		}).Deprecated("use members instead"),
 	}.Install(dag)
 	dagql.Fields[*core.EnumMemberTypeDef]{}.Install(dag)
+	dagql.Fields[*core.UnionTypeDef]{}.Install(dag)
 }
 
 func (s *moduleSchema) typeDef(ctx context.Context, _ *core.Query, args struct{}) (*core.TypeDef, error) {
@@ -408,6 +438,36 @@ func (s *moduleSchema) typeDefWithEnumMember(ctx context.Context, def *core.Type
 	return def.WithEnumMember(args.Name, args.Value, args.Description, sourceMap)
 }
 
+func (s *moduleSchema) typeDefWithUnion(ctx context.Context, def *core.TypeDef, args struct {
+	Name        string
+	Description string `default:""`
+	SourceMap   dagql.Optional[core.SourceMapID]
+}) (*core.TypeDef, error) {
+	if args.Name == "" {
+		return nil, fmt.Errorf("union type def must have a name")
+	}
+	sourceMap, err := s.loadSourceMap(ctx, args.SourceMap)
+	if err != nil {
+		return nil, err
+	}
+	return def.WithUnion(args.Name, args.Description, sourceMap), nil
+}
+
+func (s *moduleSchema) typeDefWithUnionMember(ctx context.Context, def *core.TypeDef, args struct {
+	TypeDef core.TypeDefID
+}) (*core.TypeDef, error) {
+	dag, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dag server: %w", err)
+	}
+
+	memberType, err := args.TypeDef.Load(ctx, dag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode member type: %w", err)
+	}
+	return def.WithUnionMember(memberType.Self())
+}
+
 func supportEnumMembers(ctx context.Context) bool {
 	return core.Supports(ctx, "v0.18.11")
 }
@@ -465,6 +525,24 @@ func (s *moduleSchema) functionWithDescription(ctx context.Context, fn *core.Fun
 	return fn.WithDescription(args.Description), nil
 }
 
+func (s *moduleSchema) functionWithCachePolicy(ctx context.Context, fn *core.Function, args struct {
+	TTL     string   `name:"ttl" default:""`
+	KeyArgs []string `default:"[]"`
+}) (*core.Function, error) {
+	var ttl time.Duration
+	if args.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(args.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache TTL: %w", err)
+		}
+		if ttl <= 0 {
+			return nil, fmt.Errorf("cache TTL must be positive, got %s", args.TTL)
+		}
+	}
+	return fn.WithCachePolicy(ttl, args.KeyArgs), nil
+}
+
 func (s *moduleSchema) functionWithArg(ctx context.Context, fn *core.Function, args struct {
 	Name         string
 	TypeDef      core.TypeDefID
@@ -678,6 +756,22 @@ func (s *moduleSchema) moduleWithEnum(ctx context.Context, mod *core.Module, arg
 	return mod.WithEnum(ctx, def.Self())
 }
 
+func (s *moduleSchema) moduleWithUnion(ctx context.Context, mod *core.Module, args struct {
+	Union core.TypeDefID
+}) (_ *core.Module, rerr error) {
+	dag, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dag server: %w", err)
+	}
+
+	def, err := args.Union.Load(ctx, dag)
+	if err != nil {
+		return nil, err
+	}
+
+	return mod.WithUnion(ctx, def.Self())
+}
+
 func (s *moduleSchema) currentModuleName(
 	ctx context.Context,
 	curMod *core.CurrentModule,
@@ -58,7 +58,7 @@ var _ SchemaResolvers = &moduleSourceSchema{}
 
 func (s *moduleSourceSchema) Install(dag *dagql.Server) {
 	dagql.Fields[*core.Query]{
-		dagql.NodeFuncWithCacheKey("moduleSource", s.moduleSource, dagql.CachePerClient).
+		dagql.NodeFuncWithCacheKey("moduleSource", s.moduleSource, dagql.CacheAsRequested).
 			Doc(`Create a new module source instance from a source ref string`).
 			Args(
 				dagql.Arg("refString").Doc(`The string ref representation of the module source`),
@@ -66,6 +66,7 @@ func (s *moduleSourceSchema) Install(dag *dagql.Server) {
 				dagql.Arg("disableFindUp").Doc(`If true, do not attempt to find dagger.json in a parent directory of the provided path. Only relevant for local module sources.`),
 				dagql.Arg("allowNotExists").Doc(`If true, do not error out if the provided ref string is a local path and does not exist yet. Useful when initializing new modules in directories that don't exist yet.`),
 				dagql.Arg("requireKind").Doc(`If set, error out if the ref string is not of the provided requireKind.`),
+				dagql.Arg("noCache").Doc(`If true, a local module source and its local dependencies will always be reloaded from the host.`),
 			),
 	}.Install(dag)
 
@@ -233,6 +234,7 @@ type moduleSourceArgs struct {
 	DisableFindUp  bool   `default:"false"`
 	AllowNotExists bool   `default:"false"`
 	RequireKind    dagql.Optional[core.ModuleSourceKind]
+	HostDirCacheConfig
 }
 
 func (s *moduleSourceSchema) moduleSource(
@@ -255,7 +257,7 @@ func (s *moduleSourceSchema) moduleSource(
 
 	switch parsedRef.Kind {
 	case core.ModuleSourceKindLocal:
-		inst, err = s.localModuleSource(ctx, query, bk, parsedRef.Local.ModPath, !args.DisableFindUp, args.AllowNotExists)
+		inst, err = s.localModuleSource(ctx, query, bk, parsedRef.Local.ModPath, !args.DisableFindUp, args.AllowNotExists, args.NoCache)
 		if err != nil {
 			return inst, err
 		}
@@ -289,6 +291,10 @@ func (s *moduleSourceSchema) localModuleSource(
 
 	// if true, tolerate the localPath not existing on the filesystem (for dagger init on directories that don't exist yet)
 	allowNotExists bool,
+
+	// if true, reload the context directory and local dependencies from the host rather than
+	// reusing what this client loaded before
+	noCache bool,
 ) (inst dagql.Result[*core.ModuleSource], err error) {
 	if localPath == "" {
 		localPath = "."
@@ -347,7 +353,7 @@ func (s *moduleSourceSchema) localModuleSource(
 				switch parsedRef.Kind {
 				case core.ModuleSourceKindLocal:
 					depModPath := filepath.Join(defaultFindUpSourceRootDir, namedDep.Source)
-					return s.localModuleSource(ctx, query, bk, depModPath, false, allowNotExists)
+					return s.localModuleSource(ctx, query, bk, depModPath, false, allowNotExists, noCache)
 				case core.ModuleSourceKindGit:
 					return s.gitModuleSource(ctx, query, parsedRef.Git, namedDep.Pin, false)
 				}
@@ -419,6 +425,7 @@ func (s *moduleSourceSchema) localModuleSource(
 		Kind:              core.ModuleSourceKindLocal,
 		Local: &core.LocalModuleSource{
 			ContextDirectoryPath: contextDirPath,
+			NoCache:              noCache,
 		},
 	}
 
@@ -943,6 +950,7 @@ func (s *moduleSourceSchema) loadModuleSourceContext(
 				Args: []dagql.NamedInput{
 					{Name: "path", Value: dagql.String(src.Local.ContextDirectoryPath)},
 					{Name: "include", Value: dagql.ArrayInput[dagql.String](dagql.NewStringArray(fullIncludePaths...))},
+					{Name: "noCache", Value: dagql.Boolean(src.Local.NoCache)},
 				},
 			},
 		)
@@ -2201,6 +2209,20 @@ func (s *moduleSourceSchema) moduleSourceGeneratedContextDirectory(
 	return genDirInst, nil
 }
 
+// persistedModuleDefType is the type name of module definitions in the
+// persistent cache, to be changed whenever their encoding does.
+const persistedModuleDefType = "ModuleDef/1"
+
+// persistedModuleDef is the definition of a module returned by its SDK, as
+// kept in the persistent cache.
+type persistedModuleDef struct {
+	Description   string          `json:"description"`
+	ObjectDefs    []*core.TypeDef `json:"objects"`
+	InterfaceDefs []*core.TypeDef `json:"interfaces"`
+	EnumDefs      []*core.TypeDef `json:"enums"`
+	UnionDefs     []*core.TypeDef `json:"unions"`
+}
+
 func (s *moduleSourceSchema) runModuleDefInSDK(ctx context.Context, src, srcInstContentHashed dagql.ObjectResult[*core.ModuleSource], mod *core.Module) (*core.Module, error) {
 	dag, err := core.CurrentDagqlServer(ctx)
 	if err != nil {
@@ -2242,8 +2264,11 @@ func (s *moduleSourceSchema) runModuleDefInSDK(ctx context.Context, src, srcInst
 
 	modName := src.Self().ModuleName
 
-	var initialized *core.Module
-	err = (func() (rerr error) {
+	// the definition only depends on the module's source (including its
+	// dependencies and SDK) and the engine binary loading it, so it's kept
+	// across engine restarts rather than asking the SDK again
+	modDefKey := dagql.HashFrom(srcInstContentHashed.ID().Digest().String(), "modDef", engine.BinaryDigest())
+	initialized, err := dagql.GetOrInitializePersisted(ctx, dag.Cache, modDefKey, persistedModuleDefType, func(ctx context.Context) (_ *persistedModuleDef, rerr error) {
 		ctx, span := core.Tracer(ctx).Start(ctx, "asModule getModDef", telemetry.Internal())
 		defer telemetry.End(span, func() error { return rerr })
 		getModDefFn, err := core.NewModFunction(
@@ -2256,7 +2281,7 @@ func (s *moduleSourceSchema) runModuleDefInSDK(ctx context.Context, src, srcInst
 				AsObject: dagql.NonNull(core.NewObjectTypeDef("Module", "")),
 			}))
 		if err != nil {
-			return fmt.Errorf("failed to create module definition function for module %q: %w", modName, err)
+			return nil, fmt.Errorf("failed to create module definition function for module %q: %w", modName, err)
 		}
 		result, err := getModDefFn.Call(ctx, &core.CallOpts{
 			Cache:          true,
@@ -2269,24 +2294,29 @@ func (s *moduleSourceSchema) runModuleDefInSDK(ctx context.Context, src, srcInst
 			SkipCallDigestCacheKey: true,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to call module %q to get functions: %w", modName, err)
+			return nil, fmt.Errorf("failed to call module %q to get functions: %w", modName, err)
 		}
 		if postCallRes, ok := dagql.UnwrapAs[dagql.PostCallable](result); ok {
 			postCall := postCallRes.GetPostCall()
 			if postCall != nil {
 				if err := postCall(ctx); err != nil {
-					return fmt.Errorf("failed to run post-call for module %q: %w", modName, err)
+					return nil, fmt.Errorf("failed to run post-call for module %q: %w", modName, err)
 				}
 			}
 		}
 
 		resultInst, ok := result.(dagql.Result[*core.Module])
 		if !ok {
-			return fmt.Errorf("expected Module result, got %T", result)
-		}
-		initialized = resultInst.Self()
-		return nil
-	})()
+			return nil, fmt.Errorf("expected Module result, got %T", result)
+		}
+		return &persistedModuleDef{
+			Description:   resultInst.Self().Description,
+			ObjectDefs:    resultInst.Self().ObjectDefs,
+			InterfaceDefs: resultInst.Self().InterfaceDefs,
+			EnumDefs:      resultInst.Self().EnumDefs,
+			UnionDefs:     resultInst.Self().UnionDefs,
+		}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -2311,6 +2341,12 @@ func (s *moduleSourceSchema) runModuleDefInSDK(ctx context.Context, src, srcInst
 			return nil, fmt.Errorf("failed to add enum to module %q: %w", modName, err)
 		}
 	}
+	for _, union := range initialized.UnionDefs {
+		mod, err = mod.WithUnion(ctx, union)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add union to module %q: %w", modName, err)
+		}
+	}
 	err = mod.Patch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to patch module %q: %w", modName, err)
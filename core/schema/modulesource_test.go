@@ -2,9 +2,11 @@ package schema
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/dagger/dagger/core"
+	"github.com/dagger/dagger/dagql"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +61,52 @@ func TestModuleLocaLSource(t *testing.T) {
 		})
 	}
 }
+
+func TestPersistedModuleDefRoundTrip(t *testing.T) {
+	str := &core.TypeDef{Kind: core.TypeDefKindString}
+	obj := core.NewObjectTypeDef("Test", "A test module")
+	obj.Functions = []*core.Function{
+		{
+			Name:         "hello",
+			Description:  "Say hello",
+			ReturnType:   str,
+			OriginalName: "Hello",
+			CacheTTL:     "1h",
+			Args: []*core.FunctionArg{
+				{
+					Name:         "name",
+					TypeDef:      str.Clone().WithOptional(true),
+					DefaultValue: core.JSON(`"world"`),
+					OriginalName: "name",
+				},
+			},
+		},
+	}
+	obj.SourceMap = dagql.NonNull(&core.SourceMap{Module: "test", Filename: "main.go", Line: 3, Column: 6})
+	objDef := &core.TypeDef{
+		Kind:     core.TypeDefKindObject,
+		AsObject: dagql.NonNull(obj),
+	}
+	union, err := (&core.TypeDef{}).WithUnion("Result", "A test union", nil).WithUnionMember(objDef)
+	require.NoError(t, err)
+	def := &persistedModuleDef{
+		Description: "A test module",
+		ObjectDefs:  []*core.TypeDef{objDef},
+		EnumDefs: []*core.TypeDef{{
+			Kind: core.TypeDefKindEnum,
+			AsEnum: dagql.NonNull(&core.EnumTypeDef{
+				Name: "Level",
+				Members: []*core.EnumMemberTypeDef{
+					{Name: "LOW", Value: "low", OriginalName: "Low"},
+				},
+			}),
+		}},
+		UnionDefs: []*core.TypeDef{union},
+	}
+
+	data, err := json.Marshal(def)
+	require.NoError(t, err)
+	var decoded *persistedModuleDef
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, def, decoded)
+}
@@ -9,6 +9,7 @@ import (
 	codegenintrospection "github.com/dagger/dagger/cmd/codegen/introspection"
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/dagql/introspection"
 	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/sources/blob"
@@ -33,12 +34,34 @@ func (s *querySchema) Install(srv *dagql.Server) {
 			Args(
 				dagql.Arg("hiddenTypes").Doc("Types to hide from the schema JSON file."),
 			),
+
+		// Used by `dagger query --explain-cost`; hidden like the rest of
+		// introspection.
+		dagql.Func("__queryCost", s.queryCost).
+			DoNotCache("Depends on the current schema and the client's limits.").
+			Doc("Estimate the cost of a query without executing it.").
+			Args(
+				dagql.Arg("query").Doc("The GraphQL query to estimate."),
+				dagql.Arg("operationName").Doc("The operation to estimate, if the query contains several."),
+			),
+
+		// Used by `dagger debug why`; hidden like the rest of introspection.
+		dagql.Func("__diffIDs", s.diffIDs).
+			Doc("Explain why two IDs differ, returning the first difference between them, or null if they are the same.").
+			Args(
+				dagql.Arg("left").Doc("The first encoded ID."),
+				dagql.Arg("right").Doc("The second encoded ID."),
+			),
 	}.Install(srv)
 
+	dagql.Fields[*dagql.QueryCost]{}.Install(srv)
+	dagql.Fields[*dagql.IDDiff]{}.Install(srv)
+
 	srv.InstallScalar(core.JSON{})
 	srv.InstallScalar(core.Void{})
 
 	core.NetworkProtocols.Install(srv)
+	core.ServiceEventTypes.Install(srv)
 	core.ImageLayerCompressions.Install(srv)
 	core.ImageMediaTypesEnum.Install(srv)
 	core.CacheSharingModes.Install(srv)
@@ -49,6 +72,7 @@ func (s *querySchema) Install(srv *dagql.Server) {
 	dagql.MustInputSpec(PipelineLabel{}).Install(srv)
 	dagql.MustInputSpec(core.PortForward{}).Install(srv)
 	dagql.MustInputSpec(core.BuildArg{}).Install(srv)
+	dagql.MustInputSpec(core.ImageAnnotation{}).Install(srv)
 
 	dagql.Fields[EnvVariable]{}.Install(srv)
 
@@ -56,6 +80,17 @@ func (s *querySchema) Install(srv *dagql.Server) {
 
 	dagql.Fields[Label]{}.Install(srv)
 
+	dagql.Fields[*core.ImageDescriptor]{
+		dagql.Func("annotations", func(ctx context.Context, img *core.ImageDescriptor, args struct{}) (dagql.Array[Label], error) {
+			annotations := img.SortedAnnotations()
+			labels := make([]Label, 0, len(annotations))
+			for _, annotation := range annotations {
+				labels = append(labels, Label{Name: annotation.Name, Value: annotation.Value})
+			}
+			return labels, nil
+		}).Doc(`The annotations of the image descriptor, including those inherited from its multi-platform index.`),
+	}.Install(srv)
+
 	dagql.Fields[*core.Query]{
 		dagql.Func("pipeline", s.pipeline).
 			View(BeforeVersion("v0.13.0")).
@@ -86,6 +121,42 @@ func (s *querySchema) version(_ context.Context, _ *core.Query, args struct{}) (
 	return engine.Version, nil
 }
 
+type queryCostArgs struct {
+	Query         string
+	OperationName string `default:""`
+}
+
+func (s *querySchema) queryCost(ctx context.Context, _ *core.Query, args queryCostArgs) (*dagql.QueryCost, error) {
+	cost, err := dagql.CurrentDagqlServer(ctx).EstimateCost(args.Query, args.OperationName)
+	if err != nil {
+		return nil, err
+	}
+	limits := dagql.CostLimitsFromContext(ctx)
+	cost.MaxDepth = limits.MaxDepth
+	cost.MaxCost = limits.MaxCost
+	return cost, nil
+}
+
+type diffIDsArgs struct {
+	Left  string
+	Right string
+}
+
+func (s *querySchema) diffIDs(ctx context.Context, _ *core.Query, args diffIDsArgs) (dagql.Nullable[*dagql.IDDiff], error) {
+	var left, right call.ID
+	if err := left.Decode(args.Left); err != nil {
+		return dagql.Null[*dagql.IDDiff](), fmt.Errorf("decode left ID: %w", err)
+	}
+	if err := right.Decode(args.Right); err != nil {
+		return dagql.Null[*dagql.IDDiff](), fmt.Errorf("decode right ID: %w", err)
+	}
+	diff := dagql.NewIDDiff(&left, &right)
+	if diff == nil {
+		return dagql.Null[*dagql.IDDiff](), nil
+	}
+	return dagql.NonNull(diff), nil
+}
+
 type schemaJSONArgs struct {
 	HiddenTypes []string `default:"[]"`
 }
@@ -39,6 +39,10 @@ func (s *serviceSchema) Install(srv *dagql.Server) {
 					"--privileged" flag. Containerization does not provide any security
 					guarantees when using this option. It should only be used when
 					absolutely necessary and only with trusted commands.`),
+				dagql.Arg("userNamespace").Doc(
+					`Run the command in a user namespace, so that root in the container
+					maps to an unprivileged user on the host. Cannot be combined with
+					insecureRootCapabilities.`),
 				dagql.Arg("expand").Doc(
 					`Replace "${VAR}" or "$VAR" in the args according to the current `+
 						`environment variables defined in the container (e.g. "/$VAR/foo").`),
@@ -82,6 +86,10 @@ func (s *serviceSchema) Install(srv *dagql.Server) {
 					"--privileged" flag. Containerization does not provide any security
 					guarantees when using this option. It should only be used when
 					absolutely necessary and only with trusted commands.`),
+				dagql.Arg("userNamespace").Doc(
+					`Run the command in a user namespace, so that root in the container
+					maps to an unprivileged user on the host. Cannot be combined with
+					insecureRootCapabilities.`),
 				dagql.Arg("expand").Doc(
 					`Replace "${VAR}" or "$VAR" in the args according to the current `+
 						`environment variables defined in the container (e.g. "/$VAR/foo").`),
@@ -141,6 +149,10 @@ func (s *serviceSchema) Install(srv *dagql.Server) {
 				dagql.Arg("kill").Doc(`Immediately kill the service without waiting for a graceful exit`),
 			),
 
+		dagql.NodeFunc("events", s.events).
+			Doc(`The changes in state of the service, starting with its current state.`,
+				`Can only be selected in a subscription.`),
+
 		dagql.NodeFunc("terminal", s.terminal).
 			DoNotCache("Imperatively mutates runtime state."),
 	}.Install(srv)
@@ -198,6 +210,7 @@ func (s *serviceSchema) containerAsServiceLegacy(ctx context.Context, parent dag
 		UseEntrypoint:                 withExecArgs.UseEntrypoint,
 		ExperimentalPrivilegedNesting: withExecArgs.ExperimentalPrivilegedNesting,
 		InsecureRootCapabilities:      withExecArgs.InsecureRootCapabilities,
+		UserNamespace:                 withExecArgs.UserNamespace,
 		NoInit:                        withExecArgs.NoInit,
 	})
 	if err != nil {
@@ -255,6 +268,12 @@ func (s *serviceSchema) containerUp(ctx context.Context, ctr dagql.ObjectResult[
 			Value: dagql.Boolean(true),
 		})
 	}
+	if args.UserNamespace {
+		inputs = append(inputs, dagql.NamedInput{
+			Name:  "userNamespace",
+			Value: dagql.Boolean(true),
+		})
+	}
 	if args.Expand {
 		inputs = append(inputs, dagql.NamedInput{
 			Name:  "expand",
@@ -366,6 +385,14 @@ func (s *serviceSchema) stop(ctx context.Context, parent dagql.ObjectResult[*cor
 	return dagql.NewResultForCurrentID(ctx, id)
 }
 
+func (s *serviceSchema) events(ctx context.Context, parent dagql.ObjectResult[*core.Service], _ struct{}) (dagql.Stream[core.ServiceEventType], error) {
+	events, err := parent.Self().Events(ctx, parent.ID())
+	if err != nil {
+		return dagql.Stream[core.ServiceEventType]{}, err
+	}
+	return dagql.NewStream(events), nil
+}
+
 type serviceTerminalArgs struct {
 	core.ExecTerminalArgs
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/opencontainers/go-digest"
+
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/dagql/introspection"
@@ -21,6 +23,22 @@ func Syncer[T core.Evaluatable]() dagql.Field[T] {
 			return res, err
 		}
 		id := dagql.NewID[T](self.ID())
+		// now that it's evaluated, identify it by its content (or its definition,
+		// for containers) so that calls on the returned ID share cache hits with
+		// any other identical result
+		var dgst digest.Digest
+		switch hasher := any(self.Self()).(type) {
+		case core.ContentHasher:
+			dgst, err = hasher.ContentHash(ctx)
+		case core.DefinitionHasher:
+			dgst, err = hasher.DefinitionHash()
+		}
+		if err != nil {
+			return res, err
+		}
+		if dgst != "" {
+			id = dagql.NewID[T](self.ID().WithDigest(dgst))
+		}
 		return dagql.NewResultForCurrentID(ctx, id)
 	})
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"log/slog"
 	"net"
 	"runtime"
@@ -55,6 +56,7 @@ type Service struct {
 	Args                          []string
 	ExperimentalPrivilegedNesting bool
 	InsecureRootCapabilities      bool
+	UserNamespace                 bool
 	NoInit                        bool
 	ExecMD                        *buildkit.ExecutionMetadata
 	ExecMeta                      *executor.Meta
@@ -252,6 +254,20 @@ func (svc *Service) Stop(ctx context.Context, id *call.ID, kill bool) error {
 	return svcs.Stop(ctx, id, kill, svc.TunnelUpstream.Self() != nil)
 }
 
+// Events returns the changes in state of the service, starting with its
+// current state, until ctx is canceled.
+func (svc *Service) Events(ctx context.Context, id *call.ID) (iter.Seq2[ServiceEventType, error], error) {
+	query, err := CurrentQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	svcs, err := query.Services(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svcs.Watch(ctx, id, svc.TunnelUpstream.Self() != nil)
+}
+
 type ServiceIO struct {
 	Stdin       io.ReadCloser
 	Stdout      io.WriteCloser
@@ -334,6 +350,7 @@ func (svc *Service) startContainer(
 	if execMD == nil {
 		execMD, err = ctr.execMeta(ctx, ContainerExecOpts{
 			ExperimentalPrivilegedNesting: svc.ExperimentalPrivilegedNesting,
+			UserNamespace:                 svc.UserNamespace,
 			NoInit:                        svc.NoInit,
 		}, nil)
 		if err != nil {
@@ -3,14 +3,17 @@ package core
 import (
 	"context"
 	"fmt"
+	"iter"
 	"sync"
 	"time"
 
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
+	"github.com/vektah/gqlparser/v2/ast"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/slog"
@@ -34,6 +37,7 @@ type Services struct {
 	starting map[ServiceKey]*sync.WaitGroup
 	running  map[ServiceKey]*RunningService
 	bindings map[ServiceKey]int
+	watchers map[ServiceKey]map[chan ServiceEventType]struct{}
 	l        sync.Mutex
 }
 
@@ -84,6 +88,7 @@ func NewServices() *Services {
 		starting: map[ServiceKey]*sync.WaitGroup{},
 		running:  map[ServiceKey]*RunningService{},
 		bindings: map[ServiceKey]int{},
+		watchers: map[ServiceKey]map[chan ServiceEventType]struct{}{},
 	}
 }
 
@@ -179,6 +184,8 @@ dance:
 		}
 	}
 
+	ss.notify(key, ServiceEventStarting)
+
 	svcCtx, stop := context.WithCancelCause(context.WithoutCancel(ctx))
 
 	running, err := svc.Start(svcCtx, id, nil)
@@ -187,6 +194,7 @@ dance:
 		ss.l.Lock()
 		delete(ss.starting, key)
 		ss.l.Unlock()
+		ss.notify(key, ServiceEventFailed)
 		return nil, err
 	}
 	running.Key = key
@@ -196,6 +204,7 @@ dance:
 	ss.running[key] = running
 	ss.bindings[key] = 1
 	ss.l.Unlock()
+	ss.notify(key, ServiceEventRunning)
 
 	_ = stop // leave it running
 
@@ -359,6 +368,7 @@ func (ss *Services) stop(ctx context.Context, running *RunningService, force boo
 	delete(ss.bindings, running.Key)
 	delete(ss.running, running.Key)
 	ss.l.Unlock()
+	ss.notify(running.Key, ServiceEventStopped)
 
 	return nil
 }
@@ -380,5 +390,117 @@ func (ss *Services) stopGraceful(ctx context.Context, running *RunningService, t
 	delete(ss.bindings, running.Key)
 	delete(ss.running, running.Key)
 	ss.l.Unlock()
+	ss.notify(running.Key, ServiceEventStopped)
 	return nil
 }
+
+// ServiceEventType is a change in the state of a service.
+type ServiceEventType string
+
+var ServiceEventTypes = dagql.NewEnum[ServiceEventType]()
+
+var (
+	ServiceEventStarting = ServiceEventTypes.Register("STARTING",
+		"The service is starting.")
+	ServiceEventRunning = ServiceEventTypes.Register("RUNNING",
+		"The service started and is running.")
+	ServiceEventFailed = ServiceEventTypes.Register("FAILED",
+		"The service failed to start.")
+	ServiceEventStopped = ServiceEventTypes.Register("STOPPED",
+		"The service is not running.")
+)
+
+func (ServiceEventType) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "ServiceEventType",
+		NonNull:   true,
+	}
+}
+
+func (ServiceEventType) TypeDescription() string {
+	return "A change in the state of a service."
+}
+
+func (ServiceEventType) Decoder() dagql.InputDecoder {
+	return ServiceEventTypes
+}
+
+func (ev ServiceEventType) ToLiteral() call.Literal {
+	return ServiceEventTypes.Literal(ev)
+}
+
+// serviceEventBuffer is the number of events buffered for each watcher; a
+// watcher that falls further behind misses events.
+const serviceEventBuffer = 16
+
+// Watch returns the changes in state of the given service, starting with its
+// current state, until ctx is canceled.
+func (ss *Services) Watch(ctx context.Context, id *call.ID, clientSpecific bool) (iter.Seq2[ServiceEventType, error], error) {
+	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ServiceKey{
+		Digest:    id.Digest(),
+		SessionID: clientMetadata.SessionID,
+	}
+	if clientSpecific {
+		key.ClientID = clientMetadata.ClientID
+	}
+
+	return func(yield func(ServiceEventType, error) bool) {
+		events := make(chan ServiceEventType, serviceEventBuffer)
+
+		ss.l.Lock()
+		if ss.watchers[key] == nil {
+			ss.watchers[key] = map[chan ServiceEventType]struct{}{}
+		}
+		ss.watchers[key][events] = struct{}{}
+		_, isStarting := ss.starting[key]
+		_, isRunning := ss.running[key]
+		ss.l.Unlock()
+
+		defer func() {
+			ss.l.Lock()
+			delete(ss.watchers[key], events)
+			if len(ss.watchers[key]) == 0 {
+				delete(ss.watchers, key)
+			}
+			ss.l.Unlock()
+		}()
+
+		current := ServiceEventStopped
+		switch {
+		case isRunning:
+			current = ServiceEventRunning
+		case isStarting:
+			current = ServiceEventStarting
+		}
+		if !yield(current, nil) {
+			return
+		}
+
+		for {
+			select {
+			case ev := <-events:
+				if !yield(ev, nil) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}, nil
+}
+
+func (ss *Services) notify(key ServiceKey, ev ServiceEventType) {
+	ss.l.Lock()
+	defer ss.l.Unlock()
+	for events := range ss.watchers[key] {
+		select {
+		case events <- ev:
+		default:
+		}
+	}
+}
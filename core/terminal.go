@@ -1,10 +1,12 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 
+	bkgw "github.com/moby/buildkit/frontend/gateway/client"
 	bkgwpb "github.com/moby/buildkit/frontend/gateway/pb"
 	"github.com/muesli/termenv"
 	"golang.org/x/sync/errgroup"
@@ -14,6 +16,7 @@ import (
 	"github.com/dagger/dagger/dagql/idtui"
 	"github.com/dagger/dagger/engine/buildkit"
 	"github.com/dagger/dagger/engine/distconsts"
+	"github.com/dagger/dagger/engine/session/terminal"
 )
 
 const (
@@ -39,7 +42,29 @@ func (container *Container) Terminal(
 	svcID *call.ID,
 	args *TerminalArgs,
 ) error {
-	return container.terminal(ctx, svcID, args, nil)
+	return container.terminal(ctx, svcID, args, nil, nil)
+}
+
+// TerminalRecordingPath is where Container.terminal puts the recording of a
+// session in the returned container when it's asked to record it.
+const TerminalRecordingPath = "/terminal.cast"
+
+// TerminalRecording opens an interactive terminal like Terminal, and returns
+// a recording of the session in the asciicast v2 format.
+func (container *Container) TerminalRecording(
+	ctx context.Context,
+	svcID *call.ID,
+	args *TerminalArgs,
+) ([]byte, error) {
+	rec := terminal.NewRecorder()
+	if err := container.terminal(ctx, svcID, args, nil, rec); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := rec.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to encode terminal recording: %w", err)
+	}
+	return buf.Bytes(), nil
 }
 
 func (container *Container) TerminalError(
@@ -47,7 +72,7 @@ func (container *Container) TerminalError(
 	svcID *call.ID,
 	richErr *buildkit.RichError,
 ) error {
-	return container.terminal(ctx, svcID, nil, richErr)
+	return container.terminal(ctx, svcID, nil, richErr, nil)
 }
 
 func (container *Container) terminal(
@@ -55,6 +80,7 @@ func (container *Container) terminal(
 	svcID *call.ID,
 	args *TerminalArgs,
 	richErr *buildkit.RichError,
+	rec *terminal.Recorder,
 ) error {
 	container = container.Clone()
 
@@ -65,7 +91,7 @@ func (container *Container) terminal(
 		return fmt.Errorf("failed to evaluate container: %w", err)
 	}
 
-	term, output, err := prepTerminal(ctx, svcID, richErr)
+	term, output, err := prepTerminal(ctx, svcID, richErr, rec)
 	if err != nil {
 		return err
 	}
@@ -162,7 +188,7 @@ func (*Service) Terminal(
 	svc dagql.ObjectResult[*Service],
 	args *ExecTerminalArgs,
 ) error {
-	term, output, err := prepTerminal(ctx, svc.ID(), nil)
+	term, output, err := prepTerminal(ctx, svc.ID(), nil, nil)
 	if err != nil {
 		return err
 	}
@@ -197,7 +223,7 @@ func (*Service) Terminal(
 	})
 }
 
-func prepTerminal(ctx context.Context, svcID *call.ID, richErr *buildkit.RichError) (*buildkit.TerminalClient, *termenv.Output, error) {
+func prepTerminal(ctx context.Context, svcID *call.ID, richErr *buildkit.RichError, rec *terminal.Recorder) (*buildkit.TerminalClient, *termenv.Output, error) {
 	query, err := CurrentQuery(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get current query: %w", err)
@@ -211,6 +237,9 @@ func prepTerminal(ctx context.Context, svcID *call.ID, richErr *buildkit.RichErr
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open terminal: %w", err)
 	}
+	if rec != nil {
+		term = recordTerminal(term, rec)
+	}
 
 	output := idtui.NewOutput(term.Stderr)
 	if richErr == nil {
@@ -239,6 +268,25 @@ func prepTerminal(ctx context.Context, svcID *call.ID, richErr *buildkit.RichErr
 	return term, output, nil
 }
 
+// recordTerminal returns a copy of term that records its output and size
+// changes with rec
+func recordTerminal(term *buildkit.TerminalClient, rec *terminal.Recorder) *buildkit.TerminalClient {
+	cp := *term
+	cp.Stdout = rec.Writer(term.Stdout)
+	cp.Stderr = rec.Writer(term.Stderr)
+
+	resizeCh := make(chan bkgw.WinSize, 1)
+	go func() {
+		defer close(resizeCh)
+		for size := range term.ResizeCh {
+			rec.Resize(int(size.Cols), int(size.Rows))
+			resizeCh <- size
+		}
+	}()
+	cp.ResizeCh = resizeCh
+	return &cp
+}
+
 // prepTerminalEnv creates a custom shell prompt `dagger:<cwd>$`
 func prepTerminalEnv(output *termenv.Output, env []string) []string {
 	env = append(env, fmt.Sprintf("PS1=%s %s $ ",
@@ -8,7 +8,9 @@ import (
 	"iter"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/iancoleman/strcase"
 	"github.com/vektah/gqlparser/v2/ast"
@@ -26,6 +28,9 @@ type Function struct {
 
 	SourceMap dagql.Nullable[*SourceMap] `field:"true" doc:"The location of this function declaration."`
 
+	CacheTTL     string   `field:"true" name:"cacheTTL" doc:"How long the function's result may be reused across sessions (e.g. \"1h\"), if set by its cache policy."`
+	CacheKeyArgs []string `field:"true" doc:"The arguments that identify the function's result in the cache, if not all of them."`
+
 	// Below are not in public API
 
 	// OriginalName of the parent object
@@ -70,6 +75,7 @@ func (fn Function) Clone() *Function {
 	if fn.SourceMap.Valid {
 		cp.SourceMap.Value = fn.SourceMap.Value.Clone()
 	}
+	cp.CacheKeyArgs = slices.Clone(fn.CacheKeyArgs)
 	return &cp
 }
 
@@ -156,6 +162,31 @@ func (fn *Function) WithSourceMap(sourceMap *SourceMap) *Function {
 	return fn
 }
 
+// WithCachePolicy sets how the function's results are cached. A non-zero ttl
+// caches results across sessions for at most that long, and keyArgs, if set,
+// limits the arguments identifying a result to the given ones.
+func (fn *Function) WithCachePolicy(ttl time.Duration, keyArgs []string) *Function {
+	fn = fn.Clone()
+	fn.CacheTTL = ""
+	if ttl > 0 {
+		fn.CacheTTL = ttl.String()
+	}
+	fn.CacheKeyArgs = make([]string, 0, len(keyArgs))
+	for _, name := range keyArgs {
+		fn.CacheKeyArgs = append(fn.CacheKeyArgs, strcase.ToLowerCamel(name))
+	}
+	return fn
+}
+
+// CachePolicyTTL returns the duration the function's results may be reused
+// across sessions, or zero if they are cached per session.
+func (fn *Function) CachePolicyTTL() (time.Duration, error) {
+	if fn.CacheTTL == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(fn.CacheTTL)
+}
+
 func (fn *Function) IsSubtypeOf(otherFn *Function) bool {
 	if fn == nil || otherFn == nil {
 		return false
@@ -327,6 +358,7 @@ type TypeDef struct {
 	AsInput     dagql.Nullable[*InputTypeDef]     `field:"true" doc:"If kind is INPUT, the input-specific type definition. If kind is not INPUT, this will be null."`
 	AsScalar    dagql.Nullable[*ScalarTypeDef]    `field:"true" doc:"If kind is SCALAR, the scalar-specific type definition. If kind is not SCALAR, this will be null."`
 	AsEnum      dagql.Nullable[*EnumTypeDef]      `field:"true" doc:"If kind is ENUM, the enum-specific type definition. If kind is not ENUM, this will be null."`
+	AsUnion     dagql.Nullable[*UnionTypeDef]     `field:"true" doc:"If kind is UNION, the union-specific type definition. If kind is not UNION, this will be null."`
 }
 
 func (typeDef TypeDef) Clone() *TypeDef {
@@ -349,6 +381,9 @@ func (typeDef TypeDef) Clone() *TypeDef {
 	if typeDef.AsEnum.Valid {
 		cp.AsEnum.Value = typeDef.AsEnum.Value.Clone()
 	}
+	if typeDef.AsUnion.Valid {
+		cp.AsUnion.Value = typeDef.AsUnion.Value.Clone()
+	}
 	return &cp
 }
 
@@ -384,6 +419,8 @@ func (typeDef *TypeDef) ToTyped() dagql.Typed {
 		typed = &ModuleObject{TypeDef: typeDef.AsObject.Value}
 	case TypeDefKindInterface:
 		typed = &InterfaceAnnotatedValue{TypeDef: typeDef.AsInterface.Value}
+	case TypeDefKindUnion:
+		typed = dagql.UnionValue{Union: typeDef.AsUnion.Value.Name}
 	case TypeDefKindVoid:
 		typed = Void{}
 	case TypeDefKindInput:
@@ -420,6 +457,10 @@ func (typeDef *TypeDef) ToInput() dagql.Input {
 		typed = DynamicID{typeName: typeDef.AsObject.Value.Name}
 	case TypeDefKindInterface:
 		typed = DynamicID{typeName: typeDef.AsInterface.Value.Name}
+	case TypeDefKindUnion:
+		// GraphQL unions are output types only, and module validation rejects
+		// them as arguments, so this is only used to pass a member's ID back
+		typed = DynamicID{typeName: typeDef.AsUnion.Value.Name}
 	case TypeDefKindVoid:
 		typed = Void{}
 	default:
@@ -536,6 +577,30 @@ func (typeDef *TypeDef) WithEnum(name, desc string, sourceMap *SourceMap) *TypeD
 	return typeDef
 }
 
+func (typeDef *TypeDef) WithUnion(name, desc string, sourceMap *SourceMap) *TypeDef {
+	typeDef = typeDef.WithKind(TypeDefKindUnion)
+	typeDef.AsUnion = dagql.NonNull(NewUnionTypeDef(name, desc, sourceMap))
+	return typeDef
+}
+
+func (typeDef *TypeDef) WithUnionMember(member *TypeDef) (*TypeDef, error) {
+	if !typeDef.AsUnion.Valid {
+		return nil, fmt.Errorf("cannot add member to non-union type: %s", typeDef.Kind)
+	}
+	if member.Kind != TypeDefKindObject || member.Optional {
+		return nil, fmt.Errorf("union %q members must be non-optional objects, got %s", typeDef.AsUnion.Value.Name, member.Kind)
+	}
+	for _, existing := range typeDef.AsUnion.Value.Members {
+		if existing.AsObject.Value.Name == member.AsObject.Value.Name {
+			return nil, fmt.Errorf("union %q member %q is already defined", typeDef.AsUnion.Value.Name, member.AsObject.Value.Name)
+		}
+	}
+
+	typeDef = typeDef.Clone()
+	typeDef.AsUnion.Value.Members = append(typeDef.AsUnion.Value.Members, member.Clone())
+	return typeDef, nil
+}
+
 func (typeDef *TypeDef) WithEnumValue(name, value, desc string, sourceMap *SourceMap) (*TypeDef, error) {
 	if !typeDef.AsEnum.Valid {
 		return nil, fmt.Errorf("cannot add value to non-enum type: %s", typeDef.Kind)
@@ -619,6 +684,8 @@ func (typeDef *TypeDef) IsSubtypeOf(otherDef *TypeDef) bool {
 			return typeDef.AsObject.Value.Name == otherDef.AsObject.Value.Name
 		case TypeDefKindInterface:
 			return typeDef.AsObject.Value.IsSubtypeOf(otherDef.AsInterface.Value)
+		case TypeDefKindUnion:
+			return otherDef.AsUnion.Value.HasMember(typeDef.AsObject.Value.Name)
 		default:
 			return false
 		}
@@ -627,6 +694,11 @@ func (typeDef *TypeDef) IsSubtypeOf(otherDef *TypeDef) bool {
 			return false
 		}
 		return typeDef.AsInterface.Value.IsSubtypeOf(otherDef.AsInterface.Value)
+	case TypeDefKindUnion:
+		if otherDef.Kind != TypeDefKindUnion {
+			return false
+		}
+		return typeDef.AsUnion.Value.Name == otherDef.AsUnion.Value.Name
 	default:
 		return false
 	}
@@ -1057,6 +1129,83 @@ func (enum EnumTypeDef) Clone() *EnumTypeDef {
 	return &cp
 }
 
+type UnionTypeDef struct {
+	// Name is the standardized name of the union (CamelCase), as used for the union in the graphql schema
+	Name        string                     `field:"true" doc:"The name of the union."`
+	Description string                     `field:"true" doc:"A doc string for the union, if any."`
+	Members     []*TypeDef                 `field:"true" doc:"The object types that are members of the union."`
+	SourceMap   dagql.Nullable[*SourceMap] `field:"true" doc:"The location of this union declaration."`
+
+	// SourceModuleName is currently only set when returning the TypeDef from the Unions field on Module
+	SourceModuleName string `field:"true" doc:"If this UnionTypeDef is associated with a Module, the name of the module. Unset otherwise."`
+
+	// Below are not in public API
+
+	// The original name of the union as provided by the SDK that defined it, used
+	// when invoking the SDK so it doesn't need to think as hard about case conversions
+	OriginalName string
+}
+
+func (*UnionTypeDef) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "UnionTypeDef",
+		NonNull:   true,
+	}
+}
+
+func (*UnionTypeDef) TypeDescription() string {
+	return "A definition of a custom union of objects defined in a Module."
+}
+
+func NewUnionTypeDef(name, description string, sourceMap *SourceMap) *UnionTypeDef {
+	typedef := &UnionTypeDef{
+		Name:         strcase.ToCamel(name),
+		OriginalName: name,
+		Description:  description,
+	}
+	if sourceMap != nil {
+		typedef.SourceMap = dagql.NonNull(sourceMap)
+	}
+	return typedef
+}
+
+func (union UnionTypeDef) Clone() *UnionTypeDef {
+	cp := union
+
+	cp.Members = make([]*TypeDef, len(union.Members))
+	for i, member := range union.Members {
+		cp.Members[i] = member.Clone()
+	}
+	if union.SourceMap.Valid {
+		cp.SourceMap.Value = union.SourceMap.Value.Clone()
+	}
+
+	return &cp
+}
+
+// HasMember returns true if the object with the given name is a member of the
+// union.
+func (union *UnionTypeDef) HasMember(name string) bool {
+	for _, member := range union.Members {
+		if member.AsObject.Value.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ToUnionSpec returns the dagql union for the type def.
+func (union *UnionTypeDef) ToUnionSpec() dagql.UnionSpec {
+	spec := dagql.UnionSpec{
+		Name:        union.Name,
+		Description: union.Description,
+	}
+	for _, member := range union.Members {
+		spec.Members = append(spec.Members, member.AsObject.Value.Name)
+	}
+	return spec
+}
+
 type EnumMemberTypeDef struct {
 	Name        string                     `field:"true" doc:"The name of the enum member."`
 	Value       string                     `field:"true" doc:"The value of the enum member"`
@@ -1192,6 +1341,12 @@ var (
 		"Always paired with an EnumTypeDef.",
 	)
 	_ = TypeDefKinds.AliasView("ENUM", "ENUM_KIND", enumView)
+
+	TypeDefKindUnion = TypeDefKinds.Register("UNION_KIND",
+		"A GraphQL union of object types",
+		"Always paired with a UnionTypeDef.",
+	)
+	_ = TypeDefKinds.AliasView("UNION", "UNION_KIND", enumView)
 )
 
 func (k TypeDefKind) Type() *ast.Type {
@@ -55,6 +55,18 @@ var Samples = map[TypeDefKind]*TypeDef{
 			Name: "FooEnum",
 		}),
 	},
+	TypeDefKindUnion: {
+		Kind: TypeDefKindUnion,
+		AsUnion: dagql.NonNull(&UnionTypeDef{
+			Name: "FooUnion",
+			Members: []*TypeDef{{
+				Kind: TypeDefKindObject,
+				AsObject: dagql.NonNull(&ObjectTypeDef{
+					Name: "FooObject",
+				}),
+			}},
+		}),
+	},
 	TypeDefKindVoid: {
 		Kind: TypeDefKindVoid,
 	},
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/engine/server/resource"
+	"github.com/dagger/dagger/engine/slog"
+)
+
+type ModuleUnionType struct {
+	typeDef *UnionTypeDef
+	mod     *Module
+}
+
+var _ ModType = &ModuleUnionType{}
+
+func (m *ModuleUnionType) SourceMod() Mod {
+	if m.mod == nil {
+		return nil
+	}
+	return m.mod
+}
+
+func (m *ModuleUnionType) TypeDef() *TypeDef {
+	return &TypeDef{
+		Kind:    TypeDefKindUnion,
+		AsUnion: dagql.NonNull(m.typeDef),
+	}
+}
+
+func (m *ModuleUnionType) ConvertFromSDKResult(ctx context.Context, value any) (dagql.AnyResult, error) {
+	if value == nil {
+		slog.Warn("%T.ConvertFromSDKResult: got nil value", m)
+		return nil, nil
+	}
+
+	var id call.ID
+	switch value := value.(type) {
+	case string:
+		if err := id.Decode(value); err != nil {
+			return nil, fmt.Errorf("decode ID: %w", err)
+		}
+	case dagql.IDable:
+		id = *value.ID()
+	default:
+		return nil, fmt.Errorf("unexpected result value type %T for union %q", value, m.typeDef.Name)
+	}
+
+	val, _, err := m.loadMember(ctx, &id)
+	if err != nil {
+		return nil, err
+	}
+	union, err := dagql.NewUnionValue(m.typeDef.ToUnionSpec(), val)
+	if err != nil {
+		return nil, err
+	}
+	return dagql.NewResultForCurrentID(ctx, union)
+}
+
+func (m *ModuleUnionType) ConvertToSDKInput(ctx context.Context, value dagql.Typed) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch value := value.(type) {
+	case dagql.UnionValue:
+		if value.Value == nil {
+			return nil, nil
+		}
+		return value.Value.ID().Encode()
+	case DynamicID:
+		return value.ID().Encode()
+	default:
+		return nil, fmt.Errorf("unexpected union value type for conversion to sdk input %T", value)
+	}
+}
+
+func (m *ModuleUnionType) CollectCoreIDs(ctx context.Context, value dagql.AnyResult, ids map[digest.Digest]*resource.ID) error {
+	if value == nil {
+		return nil
+	}
+	union, ok := value.Unwrap().(dagql.UnionValue)
+	if !ok {
+		return fmt.Errorf("unexpected union value type for collecting IDs %T", value.Unwrap())
+	}
+	if union.Value == nil {
+		return nil
+	}
+	val, memberType, err := m.loadMember(ctx, union.Value.ID())
+	if err != nil {
+		return err
+	}
+	return memberType.CollectCoreIDs(ctx, val, ids)
+}
+
+// loadMember loads the object with the given ID, checking that it is a member
+// of the union.
+func (m *ModuleUnionType) loadMember(ctx context.Context, id *call.ID) (dagql.AnyObjectResult, ModType, error) {
+	query, err := CurrentQuery(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("current query: %w", err)
+	}
+	deps, err := query.IDDeps(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("schema: %w", err)
+	}
+	dag, err := deps.Schema(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("schema: %w", err)
+	}
+	val, err := dag.Load(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load union member ID %s: %w", id.Display(), err)
+	}
+
+	// An SDK could provide arbitrary IDs of objects here, so check the
+	// object's type is actually one of the union's members.
+	typeName := val.ObjectType().TypeName()
+	if !m.typeDef.HasMember(typeName) {
+		return nil, nil, fmt.Errorf("type %s is not a member of union %s", typeName, m.typeDef.Name)
+	}
+	memberType, ok, err := deps.ModTypeFor(ctx, &TypeDef{
+		Kind: TypeDefKindObject,
+		AsObject: dagql.NonNull(&ObjectTypeDef{
+			Name: typeName,
+		}),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get mod type for union member %q: %w", typeName, err)
+	}
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find object type for union member %q", typeName)
+	}
+	return val, memberType, nil
+}
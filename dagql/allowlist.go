@@ -0,0 +1,254 @@
+package dagql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// PersistedQuery is a named operation registered ahead of time.
+type PersistedQuery struct {
+	Name  string
+	Query string
+}
+
+// OperationAllowlist is a set of persisted queries, identified by the SHA-256
+// hash of their query text, optionally restricting clients to them.
+//
+// Clients may send the hash of a persisted query instead of its text, using
+// the Automatic Persisted Queries protocol.
+//
+// When enforced, queries are allowed if they have the shape of a persisted
+// query: the same operations and selections, whatever their argument values.
+// The IDs passed as arguments may only be built from fields that persisted
+// queries select, since loading an ID evaluates the calls it was built from.
+type OperationAllowlist struct {
+	queries map[string]PersistedQuery
+
+	// shapes are the hashes of the normalized persisted queries
+	shapes map[string]struct{}
+
+	// enforce rejects queries that are not persisted
+	enforce bool
+
+	// fields caches the fields selected by the persisted queries, by the
+	// digest of the schema they were resolved against
+	fields sync.Map
+}
+
+// NewOperationAllowlist returns an allowlist of the given queries. If enforce
+// is true, other queries are rejected.
+func NewOperationAllowlist(queries []PersistedQuery, enforce bool) (*OperationAllowlist, error) {
+	allowlist := &OperationAllowlist{
+		queries: make(map[string]PersistedQuery, len(queries)),
+		shapes:  make(map[string]struct{}, len(queries)),
+		enforce: enforce,
+	}
+	for _, q := range queries {
+		shape, err := normalizeQuery(q.Query)
+		if err != nil {
+			return nil, fmt.Errorf("persisted query %q: %w", q.Name, err)
+		}
+		allowlist.queries[PersistedQueryHash(q.Query)] = q
+		allowlist.shapes[PersistedQueryHash(shape)] = struct{}{}
+	}
+	return allowlist, nil
+}
+
+// PersistedQueryHash returns the hash identifying the given query text.
+func PersistedQueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the persisted query with the given hash.
+func (a *OperationAllowlist) Lookup(hash string) (PersistedQuery, bool) {
+	q, ok := a.queries[hash]
+	return q, ok
+}
+
+// Len returns the number of persisted queries.
+func (a *OperationAllowlist) Len() int {
+	return len(a.queries)
+}
+
+// Enforced returns whether queries that are not persisted are rejected.
+func (a *OperationAllowlist) Enforced() bool {
+	return a != nil && a.enforce
+}
+
+// Check returns an error if the allowlist is enforced and the query doesn't
+// have the shape of a persisted query.
+func (a *OperationAllowlist) Check(query string) error {
+	if !a.enforce {
+		return nil
+	}
+	shape, err := normalizeQuery(query)
+	if err != nil {
+		return err
+	}
+	if _, ok := a.shapes[PersistedQueryHash(shape)]; !ok {
+		return fmt.Errorf("query is not in the operation allowlist; only persisted queries may be executed")
+	}
+	return nil
+}
+
+// checkIDArgs returns an error if the allowlist is enforced and the given IDs,
+// passed as arguments to a query, were built from fields that no persisted
+// query selects. Otherwise any pipeline could be passed to a persisted query
+// as an ID.
+func (a *OperationAllowlist) checkIDArgs(s *Server, ids []*call.ID) error {
+	if !a.enforce || len(ids) == 0 {
+		return nil
+	}
+	allowed := a.selectedFields(s)
+	var err error
+	s.idCalls(ids, func(id *call.ID, receiverType string) {
+		field := receiverType + "." + id.Field()
+		if _, ok := allowed[field]; !ok && err == nil {
+			err = fmt.Errorf("ID argument calls %s, which no persisted query selects", field)
+		}
+	})
+	return err
+}
+
+// selectedFields returns the fields selected by the persisted queries, as
+// Type.field, resolved against the schema of the given server.
+func (a *OperationAllowlist) selectedFields(s *Server) map[string]struct{} {
+	dgst := s.SchemaDigest()
+	if fields, ok := a.fields.Load(dgst); ok {
+		return fields.(map[string]struct{})
+	}
+	schema := s.Schema()
+	fields := map[string]struct{}{}
+	for _, q := range a.queries {
+		doc, err := parser.ParseQuery(&ast.Source{Input: q.Query})
+		if err != nil {
+			continue
+		}
+		// validating resolves the definitions of the selections; queries
+		// selecting fields of modules the schema doesn't have fail, but their
+		// other fields are still resolved
+		//nolint:staticcheck // see ExecOp
+		validator.Validate(schema, doc)
+		for _, op := range doc.Operations {
+			collectSelectedFields(fields, op.SelectionSet)
+		}
+		for _, frag := range doc.Fragments {
+			collectSelectedFields(fields, frag.SelectionSet)
+		}
+	}
+	a.fields.Store(dgst, fields)
+	return fields
+}
+
+func collectSelectedFields(fields map[string]struct{}, sels ast.SelectionSet) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.ObjectDefinition != nil {
+				fields[sel.ObjectDefinition.Name+"."+sel.Name] = struct{}{}
+			}
+			collectSelectedFields(fields, sel.SelectionSet)
+		case *ast.InlineFragment:
+			collectSelectedFields(fields, sel.SelectionSet)
+		}
+	}
+}
+
+// normalizeQuery returns the shape of the given query: its operations and
+// selections without formatting, operation names, variable definitions or
+// argument values. Every argument value, given inline or as a variable, is
+// replaced with the same placeholder variable, so that clients passing values
+// either way match the same persisted query.
+func normalizeQuery(query string) (string, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return "", err
+	}
+	for _, op := range doc.Operations {
+		op.Name = ""
+		op.VariableDefinitions = nil
+		normalizeDirectives(op.Directives)
+		normalizeSelections(op.SelectionSet)
+	}
+	for _, frag := range doc.Fragments {
+		normalizeDirectives(frag.Directives)
+		normalizeSelections(frag.SelectionSet)
+	}
+	var shape strings.Builder
+	formatter.NewFormatter(&shape, formatter.WithCompacted()).FormatQueryDocument(doc)
+	return shape.String(), nil
+}
+
+func normalizeSelections(sels ast.SelectionSet) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			normalizeArguments(sel.Arguments)
+			normalizeDirectives(sel.Directives)
+			normalizeSelections(sel.SelectionSet)
+		case *ast.InlineFragment:
+			normalizeDirectives(sel.Directives)
+			normalizeSelections(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			normalizeDirectives(sel.Directives)
+		}
+	}
+}
+
+func normalizeDirectives(dirs ast.DirectiveList) {
+	for _, dir := range dirs {
+		normalizeArguments(dir.Arguments)
+	}
+}
+
+func normalizeArguments(args ast.ArgumentList) {
+	for _, arg := range args {
+		arg.Value = &ast.Value{Kind: ast.Variable, Raw: "_"}
+	}
+}
+
+type operationAllowlistKey struct{}
+
+// WithOperationAllowlist sets the allowlist applied to operations executed
+// with the returned context. A nil allowlist allows everything.
+func WithOperationAllowlist(ctx context.Context, allowlist *OperationAllowlist) context.Context {
+	return context.WithValue(ctx, operationAllowlistKey{}, allowlist)
+}
+
+// OperationAllowlistFromContext returns the allowlist set with
+// WithOperationAllowlist, or nil.
+func OperationAllowlistFromContext(ctx context.Context) *OperationAllowlist {
+	allowlist, _ := ctx.Value(operationAllowlistKey{}).(*OperationAllowlist)
+	return allowlist
+}
+
+// persistedQueryCache is the cache of Automatic Persisted Queries, which
+// resolves the hashes of the allowlist's queries first.
+type persistedQueryCache struct {
+	*lru.LRU[string]
+}
+
+var _ graphql.Cache[string] = persistedQueryCache{}
+
+func (c persistedQueryCache) Get(ctx context.Context, hash string) (string, bool) {
+	if allowlist := OperationAllowlistFromContext(ctx); allowlist != nil {
+		if q, ok := allowlist.Lookup(hash); ok {
+			return q.Query, true
+		}
+	}
+	return c.LRU.Get(ctx, hash)
+}
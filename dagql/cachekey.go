@@ -3,11 +3,15 @@ package dagql
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/moby/buildkit/identity"
 	"github.com/opencontainers/go-digest"
 	"github.com/zeebo/xxh3"
 
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine"
 )
 
@@ -43,6 +47,7 @@ func CachePerClientObject[A any](
 	}
 
 	cacheCfg.Digest = HashFrom(cacheCfg.Digest.String(), clientMD.ClientID)
+	cacheCfg.Scoped = true
 	return &cacheCfg, nil
 }
 
@@ -73,6 +78,7 @@ func CachePerSessionObject[A any](
 	}
 
 	cacheCfg.Digest = HashFrom(cacheCfg.Digest.String(), clientMD.SessionID)
+	cacheCfg.Scoped = true
 	return &cacheCfg, nil
 }
 
@@ -112,6 +118,7 @@ func CachePerCall[P Typed, A any](
 ) (*CacheConfig, error) {
 	randID := identity.NewID()
 	cacheCfg.Digest = HashFrom(randID)
+	cacheCfg.Scoped = true
 	return &cacheCfg, nil
 }
 
@@ -159,10 +166,42 @@ func CachePerClientSchema[P Typed, A any](srv *Server) func(context.Context, Obj
 			srv.SchemaDigest().String(),
 			clientMD.ClientID,
 		)
+		cfg.Scoped = true
 		return &cfg, nil
 	}
 }
 
+// TTLDigest mixes the window of the given duration containing now into the
+// digest. Windows are aligned to the Unix epoch rather than to the first
+// call, so all engines agree on when a window ends.
+func TTLDigest(dgst digest.Digest, ttl time.Duration, now time.Time) digest.Digest {
+	if ttl <= 0 {
+		return dgst
+	}
+	window := now.UnixNano() / int64(ttl)
+	return HashFrom(
+		dgst.String(),
+		"ttl",
+		strconv.FormatInt(int64(ttl), 10),
+		strconv.FormatInt(window, 10),
+	)
+}
+
+// IDWithArgs returns a copy of the ID keeping only the named arguments, with
+// its digest reset accordingly.
+func IDWithArgs(id *call.ID, argNames ...string) *call.ID {
+	var drop []string
+	for _, arg := range id.Args() {
+		if !slices.Contains(argNames, arg.Name()) {
+			drop = append(drop, arg.Name())
+		}
+	}
+	if len(drop) == 0 {
+		return id
+	}
+	return id.WithoutArguments(drop...)
+}
+
 func HashFrom(ins ...string) digest.Digest {
 	h := xxh3.New()
 	for _, in := range ins {
@@ -0,0 +1,235 @@
+package call
+
+import (
+	"fmt"
+)
+
+// DiffKind is the kind of difference found between two IDs.
+type DiffKind string
+
+const (
+	// DiffField is a difference in the field called.
+	DiffField DiffKind = "field"
+	// DiffType is a difference in the type returned by the call.
+	DiffType DiffKind = "type"
+	// DiffNth is a difference in the element selected from a list.
+	DiffNth DiffKind = "nth"
+	// DiffView is a difference in the view the call was made in.
+	DiffView DiffKind = "view"
+	// DiffModule is a difference in the module implementing the call, other
+	// than a difference in the call loading it.
+	DiffModule DiffKind = "module"
+	// DiffArg is a difference in the value of an argument, other than a
+	// difference in an ID it contains.
+	DiffArg DiffKind = "arg"
+	// DiffContent is a difference in the digest of calls that are otherwise
+	// the same, when the digest of a call is set from its content rather than
+	// its arguments.
+	DiffContent DiffKind = "content"
+)
+
+// Diff is the first difference found between two IDs.
+type Diff struct {
+	Kind DiffKind
+
+	// Left and Right are the diverging calls of each ID.
+	Left  *ID
+	Right *ID
+
+	// Arg is the name of the diverging argument, for DiffArg. Nested values
+	// are separated by dots, and list indexes are in brackets.
+	Arg string
+
+	// LeftValue and RightValue display the diverging values.
+	LeftValue  string
+	RightValue string
+}
+
+func (d *Diff) String() string {
+	what := string(d.Kind)
+	if d.Arg != "" {
+		what = fmt.Sprintf("argument %q", d.Arg)
+	}
+	return fmt.Sprintf("%s: %s differs: %s != %s", d.Left.Path(), what, d.LeftValue, d.RightValue)
+}
+
+// DiffIDs returns the first difference between two IDs, or nil if they have
+// the same digest.
+//
+// Calls are compared in the order they are made, so the difference found is
+// the earliest call that diverges: receivers first, then the modules
+// implementing calls, then arguments, including IDs passed as arguments. Only
+// what contributes to the digest of a call is compared, so sensitive
+// arguments are ignored, and calls with the same content digest are the same
+// regardless of how they were made.
+func DiffIDs(left, right *ID) *Diff {
+	if left.Digest() == right.Digest() {
+		return nil
+	}
+	if left.receiver != nil && right.receiver != nil {
+		if diff := DiffIDs(left.receiver, right.receiver); diff != nil {
+			return diff
+		}
+	}
+	if diff := diffModules(left, right); diff != nil {
+		return diff
+	}
+
+	diff := func(kind DiffKind, l, r any) *Diff {
+		return &Diff{
+			Kind:       kind,
+			Left:       left,
+			Right:      right,
+			LeftValue:  fmt.Sprint(l),
+			RightValue: fmt.Sprint(r),
+		}
+	}
+	switch {
+	case left.pb.Field != right.pb.Field ||
+		(left.receiver == nil) != (right.receiver == nil):
+		return diff(DiffField, left.Path(), right.Path())
+	case left.typ.ToAST().String() != right.typ.ToAST().String():
+		return diff(DiffType, left.typ.ToAST(), right.typ.ToAST())
+	case left.pb.Nth != right.pb.Nth:
+		return diff(DiffNth, left.pb.Nth, right.pb.Nth)
+	case left.pb.View != right.pb.View:
+		return diff(DiffView, left.pb.View, right.pb.View)
+	}
+
+	if argDiff := diffArgs(left, right); argDiff != nil {
+		return argDiff
+	}
+
+	// the calls are the same, so one of their digests was set from content
+	return diff(DiffContent, left.Digest(), right.Digest())
+}
+
+func diffModules(left, right *ID) *Diff {
+	lmod, rmod := left.module, right.module
+	if lmod == nil && rmod == nil {
+		return nil
+	}
+	if lmod != nil && rmod != nil {
+		if diff := DiffIDs(lmod.id, rmod.id); diff != nil {
+			return diff
+		}
+		if lmod.pb.Name == rmod.pb.Name && lmod.pb.Ref == rmod.pb.Ref && lmod.pb.Pin == rmod.pb.Pin {
+			return nil
+		}
+	}
+	return &Diff{
+		Kind:       DiffModule,
+		Left:       left,
+		Right:      right,
+		LeftValue:  displayModule(lmod),
+		RightValue: displayModule(rmod),
+	}
+}
+
+func displayModule(mod *Module) string {
+	if mod == nil {
+		return "<none>"
+	}
+	if mod.pb.Pin != "" {
+		return fmt.Sprintf("%s (%s@%s)", mod.pb.Name, mod.pb.Ref, mod.pb.Pin)
+	}
+	return fmt.Sprintf("%s (%s)", mod.pb.Name, mod.pb.Ref)
+}
+
+func diffArgs(left, right *ID) *Diff {
+	largs := digestArgs(left)
+	rargs := digestArgs(right)
+	names := make([]string, 0, len(largs))
+	seen := map[string]bool{}
+	for _, arg := range left.args {
+		if _, ok := largs[arg.pb.Name]; ok && !seen[arg.pb.Name] {
+			names = append(names, arg.pb.Name)
+			seen[arg.pb.Name] = true
+		}
+	}
+	for _, arg := range right.args {
+		if _, ok := rargs[arg.pb.Name]; ok && !seen[arg.pb.Name] {
+			names = append(names, arg.pb.Name)
+			seen[arg.pb.Name] = true
+		}
+	}
+	for _, name := range names {
+		if diff := diffLiterals(left, right, name, largs[name], rargs[name]); diff != nil {
+			return diff
+		}
+	}
+	return nil
+}
+
+// digestArgs returns the arguments of the ID that contribute to its digest.
+func digestArgs(id *ID) map[string]Literal {
+	args := map[string]Literal{}
+	for _, arg := range id.args {
+		if arg.isSensitive {
+			continue
+		}
+		args[arg.pb.Name] = arg.value
+	}
+	return args
+}
+
+func diffLiterals(left, right *ID, path string, l, r Literal) *Diff {
+	argDiff := func() *Diff {
+		return &Diff{
+			Kind:       DiffArg,
+			Left:       left,
+			Right:      right,
+			Arg:        path,
+			LeftValue:  displayLiteral(l),
+			RightValue: displayLiteral(r),
+		}
+	}
+	switch lv := l.(type) {
+	case *LiteralID:
+		rv, ok := r.(*LiteralID)
+		if !ok {
+			return argDiff()
+		}
+		return DiffIDs(lv.Value(), rv.Value())
+	case *LiteralList:
+		rv, ok := r.(*LiteralList)
+		if !ok || lv.Len() != rv.Len() {
+			return argDiff()
+		}
+		for i := range lv.values {
+			if diff := diffLiterals(left, right, fmt.Sprintf("%s[%d]", path, i), lv.values[i], rv.values[i]); diff != nil {
+				return diff
+			}
+		}
+		return nil
+	case *LiteralObject:
+		rv, ok := r.(*LiteralObject)
+		if !ok || lv.Len() != rv.Len() {
+			return argDiff()
+		}
+		for i, field := range lv.values {
+			rfield := rv.values[i]
+			if field == nil || rfield == nil || field.pb.Name != rfield.pb.Name {
+				return argDiff()
+			}
+			if diff := diffLiterals(left, right, path+"."+field.pb.Name, field.value, rfield.value); diff != nil {
+				return diff
+			}
+		}
+		return nil
+	default:
+		if l == nil || r == nil ||
+			fmt.Sprintf("%T", l) != fmt.Sprintf("%T", r) ||
+			displayLiteral(l) != displayLiteral(r) {
+			return argDiff()
+		}
+		return nil
+	}
+}
+
+func displayLiteral(lit Literal) string {
+	if lit == nil {
+		return "<unset>"
+	}
+	return lit.Display()
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"sync"
 
@@ -217,6 +218,9 @@ func (id *ID) Name() string {
 
 // Return a new ID that's the selection of the nth element of the return value of the existing ID.
 // The new digest is derived from the existing ID's digest and the nth index.
+//
+// For IDs of fields that stream values, which are not lists, the element type
+// is the field's type.
 func (id *ID) SelectNth(nth int) *ID {
 	buf := []byte(id.Digest())
 	buf = binary.LittleEndian.AppendUint64(buf, uint64(nth))
@@ -224,8 +228,12 @@ func (id *ID) SelectNth(nth int) *ID {
 	h.Write(buf)
 	dgst := digest.NewDigest("xxh3", h)
 
+	elemType := id.pb.Type.Elem
+	if elemType == nil {
+		elemType = id.pb.Type
+	}
 	return id.Append(
-		id.pb.Type.Elem.ToAST(),
+		elemType.ToAST(),
 		id.pb.Field,
 		View(id.pb.View),
 		id.module,
@@ -336,6 +344,32 @@ func (id *ID) WithArgument(arg *Argument) *ID {
 	)
 }
 
+// WithoutArguments returns a new ID that's the same as before except without
+// the named arguments. As with WithArgument, the digest will reset to the
+// default "recipe-based" value.
+func (id *ID) WithoutArguments(names ...string) *ID {
+	if id == nil {
+		return nil
+	}
+
+	newArgs := make([]*Argument, 0, len(id.args))
+	for _, arg := range id.args {
+		if !slices.Contains(names, arg.pb.Name) {
+			newArgs = append(newArgs, arg)
+		}
+	}
+
+	return id.receiver.Append(
+		id.pb.Type.ToAST(),
+		id.pb.Field,
+		View(id.pb.View),
+		id.module,
+		int(id.pb.Nth),
+		"", // reset to default digest
+		newArgs...,
+	)
+}
+
 func (id *ID) Encode() (string, error) {
 	dagPB, err := id.ToProto()
 	if err != nil {
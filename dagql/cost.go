@@ -0,0 +1,251 @@
+package dagql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+const (
+	// DefaultFieldCost is the cost of a field without a @cost directive.
+	DefaultFieldCost = 1
+
+	// listCostFactor is the number of elements a list is assumed to have when
+	// estimating the cost of selecting fields on them.
+	listCostFactor = 10
+)
+
+// CostLimits bounds the queries accepted by a server. Zero values mean no
+// limit.
+type CostLimits struct {
+	// MaxDepth is the maximum nesting depth of a query's selections.
+	MaxDepth int
+	// MaxCost is the maximum estimated cost of a query.
+	MaxCost int
+}
+
+func (limits CostLimits) IsZero() bool {
+	return limits.MaxDepth == 0 && limits.MaxCost == 0
+}
+
+// Min returns the stricter of each of the two limits.
+func (limits CostLimits) Min(other CostLimits) CostLimits {
+	return CostLimits{
+		MaxDepth: minLimit(limits.MaxDepth, other.MaxDepth),
+		MaxCost:  minLimit(limits.MaxCost, other.MaxCost),
+	}
+}
+
+func minLimit(a, b int) int {
+	switch {
+	case a == 0:
+		return b
+	case b == 0:
+		return a
+	default:
+		return min(a, b)
+	}
+}
+
+type costLimitsKey struct{}
+
+// WithCostLimits sets the limits applied to queries executed with the
+// returned context.
+func WithCostLimits(ctx context.Context, limits CostLimits) context.Context {
+	return context.WithValue(ctx, costLimitsKey{}, limits)
+}
+
+// CostLimitsFromContext returns the limits set with WithCostLimits.
+func CostLimitsFromContext(ctx context.Context) CostLimits {
+	limits, _ := ctx.Value(costLimitsKey{}).(CostLimits)
+	return limits
+}
+
+// QueryCost is the estimated cost of a query, computed from its selections
+// before executing it.
+type QueryCost struct {
+	Depth    int `field:"true" doc:"The maximum nesting depth of the query's selections."`
+	Cost     int `field:"true" doc:"The estimated cost of the query."`
+	MaxDepth int `field:"true" doc:"The maximum depth allowed by the engine, or 0 if unlimited."`
+	MaxCost  int `field:"true" doc:"The maximum cost allowed by the engine, or 0 if unlimited."`
+}
+
+func (*QueryCost) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "QueryCost",
+		NonNull:   true,
+	}
+}
+
+func (*QueryCost) TypeDescription() string {
+	return "The estimated cost of a query."
+}
+
+// Check returns an error if the cost exceeds the given limits.
+func (cost *QueryCost) Check(limits CostLimits) error {
+	if limits.MaxDepth > 0 && cost.Depth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", cost.Depth, limits.MaxDepth)
+	}
+	if limits.MaxCost > 0 && cost.Cost > limits.MaxCost {
+		return fmt.Errorf("query cost %d exceeds the limit of %d", cost.Cost, limits.MaxCost)
+	}
+	return nil
+}
+
+// EstimateCost parses the given query and estimates the cost of the given
+// operation, or of all its operations if operationName is empty.
+func (s *Server) EstimateCost(query string, operationName string) (*QueryCost, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil, gqlErrs(err)
+	}
+	//nolint:staticcheck // see ExecOp
+	listErr := validator.Validate(s.Schema(), doc)
+	if len(listErr) != 0 {
+		for _, e := range listErr {
+			errcode.Set(e, errcode.ValidationFailed)
+		}
+		return nil, listErr
+	}
+	gqlOp := &graphql.OperationContext{
+		Doc:           doc,
+		OperationName: operationName,
+	}
+	ids, err := s.operationIDArgs(gqlOp)
+	if err != nil {
+		return nil, err
+	}
+	return s.estimateOperationCost(gqlOp, ids), nil
+}
+
+// estimateOperationCost estimates the cost of the given validated operation,
+// including the calls in the IDs passed to it as arguments (see
+// operationIDArgs), since loading them evaluates those calls.
+func (s *Server) estimateOperationCost(gqlOp *graphql.OperationContext, ids []*call.ID) *QueryCost {
+	cost := estimateDocCost(gqlOp.Doc, gqlOp.OperationName)
+	idCost, idDepth := s.idArgsCost(ids)
+	cost.Cost += idCost
+	cost.Depth = max(cost.Depth, idDepth)
+	return cost
+}
+
+// idArgsCost returns the cost and depth of the given IDs. Each call in them
+// costs its field's weight, once however many IDs share it, and the depth of
+// an ID is its longest chain of calls, through receivers and arguments.
+func (s *Server) idArgsCost(ids []*call.ID) (cost int, depth int) {
+	s.idCalls(ids, func(id *call.ID, receiverType string) {
+		if spec, ok := s.idFieldSpec(id, receiverType); ok {
+			cost += spec.cost()
+		} else {
+			cost += DefaultFieldCost
+		}
+	})
+	depths := map[string]int{}
+	for _, id := range ids {
+		depth = max(depth, idDepth(id, depths))
+	}
+	return cost, depth
+}
+
+func idDepth(id *call.ID, depths map[string]int) int {
+	if id == nil {
+		return 0
+	}
+	dgst := id.Digest().String()
+	if depth, ok := depths[dgst]; ok {
+		return depth
+	}
+	depth := idDepth(id.Receiver(), depths)
+	for _, arg := range id.Args() {
+		depth = max(depth, literalDepth(arg.Value(), depths))
+	}
+	depths[dgst] = depth + 1
+	return depth + 1
+}
+
+func literalDepth(lit call.Literal, depths map[string]int) (depth int) {
+	switch x := lit.(type) {
+	case *call.LiteralID:
+		depth = idDepth(x.Value(), depths)
+	case *call.LiteralList:
+		_ = x.Range(func(_ int, v call.Literal) error {
+			depth = max(depth, literalDepth(v, depths))
+			return nil
+		})
+	case *call.LiteralObject:
+		_ = x.Range(func(_ int, _ string, v call.Literal) error {
+			depth = max(depth, literalDepth(v, depths))
+			return nil
+		})
+	}
+	return depth
+}
+
+func estimateDocCost(doc *ast.QueryDocument, operationName string) *QueryCost {
+	total := &QueryCost{}
+	for _, op := range doc.Operations {
+		if operationName != "" && op.Name != operationName {
+			continue
+		}
+		cost, depth := selectionSetCost(op.SelectionSet)
+		total.Cost += cost
+		total.Depth = max(total.Depth, depth)
+	}
+	return total
+}
+
+// selectionSetCost returns the cost and depth of the given selections. Each
+// field costs its @cost weight, plus the cost of its sub-selections multiplied
+// by listCostFactor if it returns a list.
+func selectionSetCost(sels ast.SelectionSet) (cost int, depth int) {
+	for _, sel := range sels {
+		var selCost, selDepth int
+		switch sel := sel.(type) {
+		case *ast.Field:
+			childCost, childDepth := selectionSetCost(sel.SelectionSet)
+			if sel.Definition != nil && sel.Definition.Type.Elem != nil {
+				childCost *= listCostFactor
+			}
+			selCost = fieldDefinitionCost(sel.Definition) + childCost
+			selDepth = childDepth + 1
+		case *ast.InlineFragment:
+			selCost, selDepth = selectionSetCost(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			if sel.Definition != nil {
+				selCost, selDepth = selectionSetCost(sel.Definition.SelectionSet)
+			}
+		}
+		cost += selCost
+		depth = max(depth, selDepth)
+	}
+	return cost, depth
+}
+
+// fieldDefinitionCost returns the weight of the @cost directive on the given
+// field, or DefaultFieldCost.
+func fieldDefinitionCost(def *ast.FieldDefinition) int {
+	if def == nil {
+		return DefaultFieldCost
+	}
+	directive := def.Directives.ForName("cost")
+	if directive == nil {
+		return DefaultFieldCost
+	}
+	arg := directive.Arguments.ForName("weight")
+	if arg == nil || arg.Value == nil {
+		return DefaultFieldCost
+	}
+	weight, err := strconv.Atoi(arg.Value.Raw)
+	if err != nil {
+		return DefaultFieldCost
+	}
+	return weight
+}
@@ -8,13 +8,16 @@ import (
 	"io"
 	"math"
 	"math/rand/v2"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/moby/buildkit/identity"
 	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
@@ -1171,6 +1174,560 @@ func TestImpureIDsReEvaluate(t *testing.T) {
 	assert.Equal(t, called, 2)
 }
 
+func TestIDWithArgs(t *testing.T) {
+	srv := dagql.NewServer(Query{}, newCache())
+	points.Install[Query](srv)
+
+	gql := client.New(dagql.NewDefaultHandler(srv))
+
+	type tallyArgs struct {
+		Label   string
+		Verbose bool `default:"false"`
+	}
+	called := 0
+	dagql.Fields[*points.Point]{
+		dagql.FuncWithCacheKey("tally", func(ctx context.Context, self *points.Point, args tallyArgs) (int, error) {
+			called++
+			return called, nil
+		}, func(ctx context.Context, _ dagql.ObjectResult[*points.Point], _ tallyArgs, cfg dagql.CacheConfig) (*dagql.CacheConfig, error) {
+			// only the label identifies the result
+			cfg.Digest = dagql.IDWithArgs(dagql.CurrentID(ctx), "label").Digest()
+			return &cfg, nil
+		}),
+	}.Install(srv)
+
+	tally := func(args string) int {
+		var res struct {
+			Point struct {
+				Tally int
+			}
+		}
+		req(t, gql, `query {
+			point(x: 6, y: 7) {
+				tally(`+args+`)
+			}
+		}`, &res)
+		return res.Point.Tally
+	}
+
+	assert.Equal(t, tally(`label: "a"`), 1)
+	assert.Equal(t, tally(`label: "a", verbose: true`), 1)
+	assert.Equal(t, tally(`label: "b", verbose: true`), 2)
+	assert.Equal(t, called, 2)
+}
+
+func TestTTLDigest(t *testing.T) {
+	dgst := dagql.HashFrom("some", "call")
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	inWindow := dagql.TTLDigest(dgst, time.Hour, start)
+	assert.Equal(t, dagql.TTLDigest(dgst, time.Hour, start.Add(59*time.Minute)), inWindow)
+	assert.Assert(t, dagql.TTLDigest(dgst, time.Hour, start.Add(time.Hour)) != inWindow)
+	assert.Assert(t, dagql.TTLDigest(dgst, 2*time.Hour, start) != inWindow)
+	assert.Equal(t, dagql.TTLDigest(dgst, 0, start), dgst)
+}
+
+func TestPersistedResultsSurviveRestart(t *testing.T) {
+	store, err := cache.OpenPersistentStore(filepath.Join(t.TempDir(), "results.db"), 0)
+	assert.NilError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	labelCalls := 0
+	coordsCalls := 0
+	newServer := func() *client.Client {
+		// a fresh in-memory cache, as if the engine restarted
+		srv := dagql.NewServer(Query{}, newCache().WithPersistentCache(store))
+		points.Install[Query](srv)
+		dagql.Fields[*points.Point]{
+			dagql.Func("label", func(ctx context.Context, self *points.Point, _ struct{}) (dagql.String, error) {
+				labelCalls++
+				return dagql.NewString(fmt.Sprintf("(%d, %d)", self.X, self.Y)), nil
+			}).Persist(),
+			dagql.Func("coords", func(ctx context.Context, self *points.Point, _ struct{}) (dagql.Array[dagql.Int], error) {
+				coordsCalls++
+				return dagql.NewIntArray(self.X, self.Y), nil
+			}).Persist(),
+			dagql.FuncWithCacheKey("snapshot", func(ctx context.Context, self *points.Point, _ struct{}) (*points.Point, error) {
+				return self, nil
+			}, func(ctx context.Context, _ dagql.ObjectResult[*points.Point], _ struct{}, cfg dagql.CacheConfig) (*dagql.CacheConfig, error) {
+				// as if scoped to a client that keeps the same ID across restarts
+				cfg.Scoped = true
+				return &cfg, nil
+			}),
+		}.Install(srv)
+		return client.New(dagql.NewDefaultHandler(srv))
+	}
+
+	var res struct {
+		Point struct {
+			Label  string
+			Coords []int
+		}
+	}
+	query := `query {
+		point(x: 6, y: 7) {
+			label
+			coords
+		}
+	}`
+
+	req(t, newServer(), query, &res)
+	assert.Equal(t, res.Point.Label, "(6, 7)")
+	assert.DeepEqual(t, res.Point.Coords, []int{6, 7})
+	assert.Equal(t, labelCalls, 1)
+	assert.Equal(t, coordsCalls, 1)
+
+	res.Point.Label = ""
+	res.Point.Coords = nil
+	req(t, newServer(), query, &res)
+	assert.Equal(t, res.Point.Label, "(6, 7)")
+	assert.DeepEqual(t, res.Point.Coords, []int{6, 7})
+	assert.Equal(t, labelCalls, 1)
+	assert.Equal(t, coordsCalls, 1)
+
+	// other calls are still evaluated
+	req(t, newServer(), `query { point(x: 1, y: 2) { label } }`, &res)
+	assert.Equal(t, res.Point.Label, "(1, 2)")
+	assert.Equal(t, labelCalls, 2)
+
+	// results derived from a call scoped to a client, session or single call
+	// are never persisted
+	var snapshotRes struct {
+		Point struct {
+			Snapshot struct {
+				Label string
+			}
+		}
+	}
+	for i := range 2 {
+		req(t, newServer(), `query { point(x: 3, y: 4) { snapshot { label } } }`, &snapshotRes)
+		assert.Equal(t, snapshotRes.Point.Snapshot.Label, "(3, 4)")
+		assert.Equal(t, labelCalls, 3+i)
+	}
+}
+
+func TestQueryCost(t *testing.T) {
+	srv := dagql.NewServer(Query{}, newCache())
+	points.Install[Query](srv)
+	calls := 0
+	dagql.Fields[*points.Point]{
+		dagql.Func("label", func(ctx context.Context, self *points.Point, _ struct{}) (dagql.String, error) {
+			calls++
+			return dagql.NewString(fmt.Sprintf("(%d, %d)", self.X, self.Y)), nil
+		}).Cost(50),
+		dagql.Func("costly", func(ctx context.Context, self *points.Point, _ struct{}) (*points.Point, error) {
+			return self, nil
+		}).Cost(50),
+		dagql.Func("midpoint", func(ctx context.Context, self *points.Point, args struct {
+			Other dagql.ID[*points.Point]
+		}) (*points.Point, error) {
+			other, err := args.Other.Load(ctx, srv)
+			if err != nil {
+				return nil, err
+			}
+			return &points.Point{X: (self.X + other.Self().X) / 2, Y: (self.Y + other.Self().Y) / 2}, nil
+		}),
+	}.Install(srv)
+
+	for _, tc := range []struct {
+		query string
+		depth int
+		cost  int
+	}{
+		{`{ point(x: 1, y: 2) { x y } }`, 2, 3},
+		{`{ point(x: 1, y: 2) { neighbors { x } } }`, 3, 12},
+		{`{ point(x: 1, y: 2) { label } }`, 2, 51},
+		{`{ point(x: 1, y: 2) { ...xy } } fragment xy on Point { x y }`, 2, 3},
+	} {
+		cost, err := srv.EstimateCost(tc.query, "")
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(cost.Depth, tc.depth), tc.query)
+		assert.Check(t, cmp.Equal(cost.Cost, tc.cost), tc.query)
+	}
+
+	_, err := srv.EstimateCost(`{ point(x: 1, y: 2) { bogus } }`, "")
+	assert.ErrorContains(t, err, "bogus")
+
+	// IDs passed as arguments cost the calls they were built from, including
+	// the calls in the IDs nested in them
+	gql := client.New(dagql.NewDefaultHandler(srv))
+	var idRes struct {
+		Point struct {
+			Costly struct {
+				ID string
+			}
+			Midpoint struct {
+				ID string
+			}
+		}
+	}
+	req(t, gql, `{ point(x: 1, y: 2) { costly { id } } }`, &idRes)
+	costlyID := idRes.Point.Costly.ID
+	assert.NilError(t, gql.Post(`query($other: PointID!) { point(x: 5, y: 5) { midpoint(other: $other) { id } } }`, &idRes, client.Var("other", costlyID)))
+	midpointID := idRes.Point.Midpoint.ID
+
+	for _, tc := range []struct {
+		id    string
+		depth int
+		cost  int
+	}{
+		// point + line + length, then point + costly
+		{costlyID, 3, 3 + 51},
+		// point + midpoint, then the calls of the other point
+		{midpointID, 3, 3 + 2 + 51},
+	} {
+		cost, err := srv.EstimateCost(`{ point(x: 0, y: 0) { line(to: "`+tc.id+`") { length } } }`, "")
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(cost.Depth, tc.depth))
+		assert.Check(t, cmp.Equal(cost.Cost, tc.cost))
+	}
+
+	limited := func(limits dagql.CostLimits) *client.Client {
+		handler := dagql.NewDefaultHandler(srv)
+		return client.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler.ServeHTTP(w, r.WithContext(dagql.WithCostLimits(r.Context(), limits)))
+		}))
+	}
+
+	t.Run("max depth", func(t *testing.T) {
+		gql := limited(dagql.CostLimits{MaxDepth: 2})
+		var res struct {
+			Point struct {
+				X int
+			}
+		}
+		req(t, gql, `{ point(x: 1, y: 2) { x } }`, &res)
+		assert.Equal(t, res.Point.X, 1)
+		reqFail(t, gql, `{ point(x: 1, y: 2) { neighbors { x } } }`, "query depth 3 exceeds the limit of 2")
+	})
+
+	t.Run("max cost", func(t *testing.T) {
+		gql := limited(dagql.CostLimits{MaxCost: 20})
+		reqFail(t, gql, `{ point(x: 1, y: 2) { label } }`, "query cost 51 exceeds the limit of 20")
+		// rejected before any work begins
+		assert.Equal(t, calls, 0)
+
+		err := gql.Post(`query($to: PointID!) { point(x: 0, y: 0) { line(to: $to) { length } } }`, &struct{}{}, client.Var("to", costlyID))
+		assert.ErrorContains(t, err, "query cost 54 exceeds the limit of 20")
+	})
+}
+
+func TestCostLimitsMin(t *testing.T) {
+	engine := dagql.CostLimits{MaxDepth: 10, MaxCost: 100}
+	assert.Equal(t, engine.Min(dagql.CostLimits{}), engine)
+	assert.Equal(t, dagql.CostLimits{}.Min(engine), engine)
+	assert.Equal(t,
+		engine.Min(dagql.CostLimits{MaxDepth: 5, MaxCost: 1000}),
+		dagql.CostLimits{MaxDepth: 5, MaxCost: 100},
+	)
+	assert.Equal(t,
+		dagql.CostLimits{MaxCost: 100}.Min(dagql.CostLimits{MaxDepth: 5}),
+		dagql.CostLimits{MaxDepth: 5, MaxCost: 100},
+	)
+}
+
+func TestOperationAllowlist(t *testing.T) {
+	srv := dagql.NewServer(Query{}, newCache())
+	points.Install[Query](srv)
+
+	persisted := `{ point(x: 1, y: 2) { x } }`
+	persistedLine := `query Line($to: PointID!) { point(x: 0, y: 0) { line(to: $to) { length } } }`
+	allowlisted := func(enforce bool) *client.Client {
+		allowlist, err := dagql.NewOperationAllowlist([]dagql.PersistedQuery{
+			{Name: "pointX", Query: persisted},
+			{Name: "line", Query: persistedLine},
+		}, enforce)
+		assert.NilError(t, err)
+		handler := dagql.NewDefaultHandler(srv)
+		return client.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler.ServeHTTP(w, r.WithContext(dagql.WithOperationAllowlist(r.Context(), allowlist)))
+		}))
+	}
+
+	var res struct {
+		Point struct {
+			X int
+		}
+	}
+	byHash := client.Extensions(map[string]any{
+		"persistedQuery": map[string]any{
+			"version":    1,
+			"sha256Hash": dagql.PersistedQueryHash(persisted),
+		},
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		gql := allowlisted(true)
+		req(t, gql, persisted, &res)
+		assert.Equal(t, res.Point.X, 1)
+
+		// queries with the same shape are allowed, whatever their values
+		req(t, gql, `{point(x:3,y:4){x}}`, &res)
+		assert.Equal(t, res.Point.X, 3)
+		assert.NilError(t, gql.Post(`query PointX($x: Int!) { point(x: $x, y: 4) { x } }`, &res, client.Var("x", 5)))
+		assert.Equal(t, res.Point.X, 5)
+
+		reqFail(t, gql, `{ point(x: 1, y: 2) { y } }`, "not in the operation allowlist")
+		reqFail(t, gql, `{ point(x: 1, y: 2) { x y } }`, "not in the operation allowlist")
+		reqFail(t, gql, `{ x: point(x: 1, y: 2) { x } }`, "not in the operation allowlist")
+
+		res.Point.X = 0
+		assert.NilError(t, gql.Post("", &res, byHash))
+		assert.Equal(t, res.Point.X, 1)
+	})
+
+	t.Run("ID arguments", func(t *testing.T) {
+		var idRes struct {
+			Point struct {
+				ID        string
+				ShiftLeft struct {
+					ID string
+				}
+			}
+		}
+		req(t, client.New(dagql.NewDefaultHandler(srv)), `{ point(x: 3, y: 4) { id shiftLeft { id } } }`, &idRes)
+
+		var lineRes struct {
+			Point struct {
+				Line struct {
+					Length float64
+				}
+			}
+		}
+		gql := allowlisted(true)
+		// IDs built from fields that persisted queries select are allowed
+		assert.NilError(t, gql.Post(persistedLine, &lineRes, client.Var("to", idRes.Point.ID)))
+		assert.Equal(t, lineRes.Point.Line.Length, 5.0)
+
+		// others would let any pipeline run through a persisted query
+		err := gql.Post(persistedLine, &lineRes, client.Var("to", idRes.Point.ShiftLeft.ID))
+		assert.ErrorContains(t, err, "ID argument calls Point.shiftLeft, which no persisted query selects")
+
+		// unless the allowlist isn't enforced
+		assert.NilError(t, allowlisted(false).Post(persistedLine, &lineRes, client.Var("to", idRes.Point.ShiftLeft.ID)))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := dagql.NewOperationAllowlist([]dagql.PersistedQuery{
+			{Name: "broken", Query: `{ point(x: 1`},
+		}, true)
+		assert.ErrorContains(t, err, `persisted query "broken"`)
+	})
+
+	t.Run("not enforced", func(t *testing.T) {
+		gql := allowlisted(false)
+		req(t, gql, `{ point(x: 3, y: 4) { x } }`, &res)
+		assert.Equal(t, res.Point.X, 3)
+
+		res.Point.X = 0
+		assert.NilError(t, gql.Post("", &res, byHash))
+		assert.Equal(t, res.Point.X, 1)
+	})
+}
+
+func TestLifecyclePolicy(t *testing.T) {
+	srv := dagql.NewServer(Query{}, newCache())
+	points.Install[Query](srv)
+	dagql.Fields[*points.Point]{
+		dagql.Func("oldX", func(ctx context.Context, self *points.Point, _ struct{}) (int, error) {
+			return self.X, nil
+		}).Deprecated("use x instead"),
+		dagql.Func("newX", func(ctx context.Context, self *points.Point, _ struct{}) (int, error) {
+			return self.X, nil
+		}).Experimental("may change"),
+		dagql.Func("newSelf", func(ctx context.Context, self *points.Point, _ struct{}) (*points.Point, error) {
+			return self, nil
+		}).Experimental("may change"),
+	}.Install(srv)
+
+	var idRes struct {
+		Point struct {
+			NewSelf struct {
+				ID string
+			}
+		}
+	}
+	req(t, client.New(dagql.NewDefaultHandler(srv)), `{ point(x: 3, y: 4) { newSelf { id } } }`, &idRes)
+	experimentalID := idRes.Point.NewSelf.ID
+	const lineQuery = `query($to: PointID!) { point(x: 0, y: 0) { line(to: $to) { length } } }`
+
+	var warned []dagql.LifecycleWarning
+	withPolicy := func(policy dagql.LifecyclePolicy) *client.Client {
+		handler := dagql.NewDefaultHandler(srv)
+		return client.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler.ServeHTTP(w, r.WithContext(dagql.WithLifecyclePolicy(r.Context(), policy)))
+		}))
+	}
+
+	t.Run("warns once per tracker", func(t *testing.T) {
+		warned = nil
+		gql := withPolicy(dagql.LifecyclePolicy{
+			Tracker: dagql.NewLifecycleTracker(func(_ context.Context, w dagql.LifecycleWarning) {
+				warned = append(warned, w)
+			}),
+		})
+		res, err := gql.RawPost(`{ point(x: 1, y: 2) { oldX newX } }`)
+		assert.NilError(t, err)
+		assert.Equal(t, len(res.Errors), 0)
+		assert.DeepEqual(t, res.Extensions["warnings"], []any{
+			map[string]any{"kind": "deprecated", "type": "Point", "field": "oldX", "reason": "use x instead"},
+			map[string]any{"kind": "experimental", "type": "Point", "field": "newX", "reason": "may change"},
+		})
+		assert.DeepEqual(t, warned, []dagql.LifecycleWarning{
+			{Kind: dagql.LifecycleDeprecated, Type: "Point", Field: "oldX", Reason: "use x instead"},
+			{Kind: dagql.LifecycleExperimental, Type: "Point", Field: "newX", Reason: "may change"},
+		})
+
+		res, err = gql.RawPost(`{ point(x: 3, y: 4) { oldX } }`)
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Nil(res.Extensions["warnings"]))
+		assert.Equal(t, len(warned), 2)
+
+		// the calls in IDs passed as arguments are used too
+		res, err = gql.RawPost(lineQuery, client.Var("to", experimentalID))
+		assert.NilError(t, err)
+		assert.Equal(t, len(res.Errors), 0)
+		assert.DeepEqual(t, res.Extensions["warnings"], []any{
+			map[string]any{"kind": "experimental", "type": "Point", "field": "newSelf", "reason": "may change"},
+		})
+	})
+
+	t.Run("rejects experimental", func(t *testing.T) {
+		gql := withPolicy(dagql.LifecyclePolicy{RejectExperimental: true})
+		reqFail(t, gql, `{ point(x: 1, y: 2) { newX } }`, "experimental APIs are disabled")
+
+		var res struct {
+			Point struct {
+				OldX int
+			}
+		}
+		req(t, gql, `{ point(x: 1, y: 2) { oldX } }`, &res)
+		assert.Equal(t, res.Point.OldX, 1)
+
+		err := gql.Post(lineQuery, &struct{}{}, client.Var("to", experimentalID))
+		assert.ErrorContains(t, err, "Point.newSelf is experimental")
+	})
+}
+
+func TestSubscriptions(t *testing.T) {
+	srv := dagql.NewServer(Query{}, newCache())
+	points.Install[Query](srv)
+	dagql.Fields[*points.Point]{
+		dagql.Func("countdown", func(ctx context.Context, self *points.Point, _ struct{}) (dagql.Stream[dagql.Int], error) {
+			return dagql.NewStream(func(yield func(dagql.Int, error) bool) {
+				for i := self.X; i > 0; i-- {
+					if !yield(dagql.NewInt(i), nil) {
+						return
+					}
+				}
+			}), nil
+		}),
+		dagql.Func("walk", func(ctx context.Context, self *points.Point, args struct {
+			Steps int
+		}) (dagql.Stream[*points.Point], error) {
+			return dagql.NewStream(func(yield func(*points.Point, error) bool) {
+				for i := 1; i <= args.Steps; i++ {
+					if !yield(&points.Point{X: self.X + i, Y: self.Y}, nil) {
+						return
+					}
+				}
+				yield(nil, fmt.Errorf("tired"))
+			}), nil
+		}),
+	}.Install(srv)
+	gql := client.New(dagql.NewDefaultHandler(srv))
+
+	pointID := func(x, y int) string {
+		var res struct {
+			Point struct {
+				ID string
+			}
+		}
+		req(t, gql, fmt.Sprintf(`{ point(x: %d, y: %d) { id } }`, x, y), &res)
+		return res.Point.ID
+	}
+
+	t.Run("schema", func(t *testing.T) {
+		schema := srv.Schema()
+		assert.Assert(t, schema.Types["Point"].Fields.ForName("countdown") == nil)
+		assert.Assert(t, schema.Subscription != nil)
+		walk := schema.Subscription.Fields.ForName("pointWalk")
+		assert.Assert(t, walk != nil)
+		assert.Equal(t, walk.Type.String(), "Point!")
+		assert.Equal(t, walk.Arguments[0].Name, "point")
+		assert.Equal(t, walk.Arguments[0].Type.String(), "PointID!")
+		assert.Equal(t, walk.Arguments[1].Name, "steps")
+		assert.Assert(t, walk.Directives.ForName("stream") != nil)
+	})
+
+	t.Run("over SSE", func(t *testing.T) {
+		sse := gql.SSE(context.Background(),
+			`subscription($point: PointID!) { pointCountdown(point: $point) }`,
+			client.Var("point", pointID(3, 0)))
+		defer sse.Close()
+
+		var counts []any
+		for range 3 {
+			var res client.SSEResponse
+			assert.NilError(t, sse.Next(&res))
+			counts = append(counts, res.Data.(map[string]any)["pointCountdown"])
+		}
+		assert.DeepEqual(t, counts, []any{3.0, 2.0, 1.0})
+	})
+
+	t.Run("objects", func(t *testing.T) {
+		var xs []any
+		err := srv.Subscribe(context.Background(), &graphql.OperationContext{
+			RawQuery:  `subscription($point: PointID!) { steps: pointWalk(point: $point, steps: 2) { x } }`,
+			Variables: map[string]any{"point": pointID(1, 2)},
+		}, func(res map[string]any) error {
+			walk := res["steps"].(map[string]any)
+			xs = append(xs, walk["x"])
+			return nil
+		})
+		assert.ErrorContains(t, err, "tired")
+		assert.DeepEqual(t, xs, []any{dagql.NewInt(2), dagql.NewInt(3)})
+	})
+
+	t.Run("not in queries", func(t *testing.T) {
+		reqFail(t, gql, `{ point(x: 3, y: 0) { countdown } }`, "Cannot query field")
+	})
+}
+
+func TestGetOrInitializePersisted(t *testing.T) {
+	ctx := context.Background()
+	store, err := cache.OpenPersistentStore(filepath.Join(t.TempDir(), "results.db"), 0)
+	assert.NilError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	type def struct {
+		Names []string
+	}
+	calls := 0
+	load := func(c *dagql.SessionCache, typeName string) def {
+		v, err := dagql.GetOrInitializePersisted(ctx, c, dagql.HashFrom("def"), typeName, func(context.Context) (def, error) {
+			calls++
+			return def{Names: []string{"a", "b"}}, nil
+		})
+		assert.NilError(t, err)
+		return v
+	}
+
+	// without a persistent cache, it's always computed
+	assert.DeepEqual(t, load(newCache(), "Def"), def{Names: []string{"a", "b"}})
+	assert.DeepEqual(t, load(newCache(), "Def"), def{Names: []string{"a", "b"}})
+	assert.Equal(t, calls, 2)
+
+	// with one, it's only computed once across restarts
+	assert.DeepEqual(t, load(newCache().WithPersistentCache(store), "Def"), def{Names: []string{"a", "b"}})
+	assert.DeepEqual(t, load(newCache().WithPersistentCache(store), "Def"), def{Names: []string{"a", "b"}})
+	assert.Equal(t, calls, 3)
+
+	// unless its encoding changed
+	assert.DeepEqual(t, load(newCache().WithPersistentCache(store), "DefV2"), def{Names: []string{"a", "b"}})
+	assert.Equal(t, calls, 4)
+}
+
 func TestPassingObjectsAround(t *testing.T) {
 	srv := dagql.NewServer(Query{}, newCache())
 	points.Install[Query](srv)
@@ -2506,6 +3063,186 @@ func TestCustomDigest(t *testing.T) {
 	}
 }
 
+func TestUnions(t *testing.T) {
+	srv := dagql.NewServer(Query{}, newCache())
+	points.Install[Query](srv)
+	introspection.Install[Query](srv)
+
+	shape := dagql.UnionSpec{
+		Name:        "Shape",
+		Description: "A point or a line.",
+		Members:     []string{"Point", "Line"},
+	}
+	shape.Install(srv)
+	srv.Root().ObjectType().Extend(
+		dagql.FieldSpec{
+			Name: "shape",
+			Type: dagql.UnionValue{Union: shape.Name},
+			Args: dagql.NewInputSpecs(dagql.InputSpec{
+				Name: "line",
+				Type: dagql.Boolean(false),
+			}),
+		},
+		func(ctx context.Context, _ dagql.AnyResult, args map[string]dagql.Input) (dagql.AnyResult, error) {
+			sels := []dagql.Selector{{
+				Field: "point",
+				Args:  []dagql.NamedInput{{Name: "x", Value: dagql.NewInt(1)}},
+			}}
+			if args["line"].(dagql.Boolean) {
+				var to dagql.Result[*points.Point]
+				if err := srv.Select(ctx, srv.Root(), &to, dagql.Selector{Field: "point"}); err != nil {
+					return nil, err
+				}
+				sels = append(sels, dagql.Selector{
+					Field: "line",
+					Args:  []dagql.NamedInput{{Name: "to", Value: dagql.NewID[*points.Point](to.ID())}},
+				})
+			}
+			var obj dagql.AnyObjectResult
+			if err := srv.Select(ctx, srv.Root(), &obj, sels...); err != nil {
+				return nil, err
+			}
+			val, err := dagql.NewUnionValue(shape, obj)
+			if err != nil {
+				return nil, err
+			}
+			return dagql.NewResultForCurrentID(ctx, val)
+		},
+		dagql.CacheSpec{},
+	)
+	gql := client.New(dagql.NewDefaultHandler(srv))
+
+	const query = `query Shape($line: Boolean!) {
+		shape(line: $line) {
+			__typename
+			... on Point { x y }
+			... on Line { length }
+		}
+	}`
+
+	t.Run("first member", func(t *testing.T) {
+		var res struct {
+			Shape map[string]any
+		}
+		assert.NilError(t, gql.Post(query, &res, client.Var("line", false)))
+		assert.DeepEqual(t, res.Shape, map[string]any{
+			"__typename": "Point",
+			"x":          float64(1),
+			"y":          float64(0),
+		})
+	})
+
+	t.Run("second member", func(t *testing.T) {
+		var res struct {
+			Shape map[string]any
+		}
+		assert.NilError(t, gql.Post(query, &res, client.Var("line", true)))
+		assert.DeepEqual(t, res.Shape, map[string]any{
+			"__typename": "Line",
+			"length":     float64(1),
+		})
+	})
+
+	t.Run("typename on objects", func(t *testing.T) {
+		var res struct {
+			Typename string `json:"__typename"`
+			Point    struct {
+				Typename string `json:"__typename"`
+			}
+		}
+		req(t, gql, `{ __typename point { __typename } }`, &res)
+		assert.Equal(t, res.Typename, "Query")
+		assert.Equal(t, res.Point.Typename, "Point")
+	})
+
+	t.Run("fields must be selected in fragments", func(t *testing.T) {
+		reqFail(t, gql, `{ shape(line: false) { x } }`, "x")
+	})
+
+	t.Run("introspection", func(t *testing.T) {
+		var res struct {
+			Type struct {
+				Kind          string
+				PossibleTypes []struct {
+					Name string
+				}
+			} `json:"__type"`
+		}
+		req(t, gql, `{ __type(name: "Shape") { kind possibleTypes { name } } }`, &res)
+		assert.Equal(t, res.Type.Kind, "UNION")
+		names := []string{}
+		for _, pt := range res.Type.PossibleTypes {
+			names = append(names, pt.Name)
+		}
+		assert.DeepEqual(t, names, []string{"Point", "Line"})
+	})
+}
+
+func TestDiffIDs(t *testing.T) {
+	ctrType := ast.NonNullNamedType("Container", nil)
+	dirType := ast.NonNullNamedType("Directory", nil)
+	from := func(address string) *call.ID {
+		return call.New().
+			Append(ctrType, "container", "", nil, 0, "").
+			Append(ctrType, "from", "", nil, 0, "", call.NewArgument("address", call.NewLiteralString(address), false))
+	}
+	dir := func(path string) *call.ID {
+		return call.New().
+			Append(dirType, "directory", "", nil, 0, "").
+			Append(dirType, "withNewFile", "", nil, 0, "", call.NewArgument("path", call.NewLiteralString(path), false))
+	}
+	withDir := func(ctr *call.ID, path string, dir *call.ID) *call.ID {
+		return ctr.Append(ctrType, "withDirectory", "", nil, 0, "",
+			call.NewArgument("path", call.NewLiteralString(path), false),
+			call.NewArgument("directory", call.NewLiteralID(dir), false))
+	}
+
+	t.Run("same", func(t *testing.T) {
+		assert.Assert(t, call.DiffIDs(withDir(from("alpine"), "/src", dir("a")), withDir(from("alpine"), "/src", dir("a"))) == nil)
+	})
+
+	t.Run("earliest call", func(t *testing.T) {
+		diff := call.DiffIDs(withDir(from("alpine"), "/src", dir("a")), withDir(from("debian"), "/app", dir("a")))
+		assert.Assert(t, diff != nil)
+		assert.Equal(t, diff.Kind, call.DiffArg)
+		assert.Equal(t, diff.Left.Field(), "from")
+		assert.Equal(t, diff.Arg, "address")
+		assert.Equal(t, diff.LeftValue, `"alpine"`)
+		assert.Equal(t, diff.RightValue, `"debian"`)
+	})
+
+	t.Run("ID argument", func(t *testing.T) {
+		diff := call.DiffIDs(withDir(from("alpine"), "/src", dir("a")), withDir(from("alpine"), "/src", dir("b")))
+		assert.Assert(t, diff != nil)
+		assert.Equal(t, diff.Left.Field(), "withNewFile")
+		assert.Equal(t, diff.Arg, "path")
+	})
+
+	t.Run("content digest", func(t *testing.T) {
+		left := withDir(from("alpine"), "/src", dir("a").WithDigest("sha256:aaaa"))
+		right := withDir(from("alpine"), "/src", dir("a").WithDigest("sha256:bbbb"))
+		diff := call.DiffIDs(left, right)
+		assert.Assert(t, diff != nil)
+		assert.Equal(t, diff.Kind, call.DiffContent)
+		assert.Equal(t, diff.LeftValue, "sha256:aaaa")
+
+		// the same content is the same, however it was made
+		assert.Assert(t, call.DiffIDs(
+			withDir(from("alpine"), "/src", dir("a").WithDigest("sha256:aaaa")),
+			withDir(from("alpine"), "/src", dir("b").WithDigest("sha256:aaaa")),
+		) == nil)
+	})
+
+	t.Run("sensitive arguments", func(t *testing.T) {
+		withSecret := func(val string) *call.ID {
+			return from("alpine").Append(ctrType, "withSecretVariable", "", nil, 0, "",
+				call.NewArgument("name", call.NewLiteralString("TOKEN"), false),
+				call.NewArgument("plaintext", call.NewLiteralString(val), true))
+		}
+		assert.Assert(t, call.DiffIDs(withSecret("a"), withSecret("b")) == nil)
+	})
+}
+
 func TestServerSelect(t *testing.T) {
 	// Create a new server with a simple object hierarchy for testing
 	srv := dagql.NewServer(Query{}, newCache())
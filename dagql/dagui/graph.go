@@ -0,0 +1,263 @@
+package dagui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dagger/dagger/dagql/call/callpbv1"
+)
+
+// CallGraph is the graph of calls making up an ID, with each call appearing
+// once regardless of how many times it is referenced.
+type CallGraph struct {
+	// Root is the digest of the call the ID refers to.
+	Root  string           `json:"root"`
+	Nodes []*CallGraphNode `json:"nodes"`
+	Edges []*CallGraphEdge `json:"edges"`
+}
+
+// CallGraphNode is a call in a CallGraph.
+type CallGraphNode struct {
+	Digest string         `json:"digest"`
+	Module string         `json:"module,omitempty"`
+	Type   string         `json:"type"`
+	Field  string         `json:"field"`
+	Args   []CallGraphArg `json:"args,omitempty"`
+	Nth    int64          `json:"nth,omitempty"`
+
+	// Duration (in nanoseconds) and Cached are known when telemetry for the call
+	// is available.
+	Duration *time.Duration `json:"duration,omitempty"`
+	Cached   *bool          `json:"cached,omitempty"`
+}
+
+// CallGraphArg is an argument of a call, with its value formatted for display.
+// IDs passed as arguments are displayed as "<input>", and appear as edges
+// instead.
+type CallGraphArg struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CallGraphEdgeKind is how a call depends on another.
+type CallGraphEdgeKind string
+
+const (
+	// CallGraphEdgeReceiver is an edge from the receiver of a call.
+	CallGraphEdgeReceiver CallGraphEdgeKind = "receiver"
+	// CallGraphEdgeArg is an edge from a call passed as an argument.
+	CallGraphEdgeArg CallGraphEdgeKind = "arg"
+	// CallGraphEdgeModule is an edge from the call loading the module
+	// implementing a call.
+	CallGraphEdgeModule CallGraphEdgeKind = "module"
+)
+
+// CallGraphEdge is a dependency of one call on another.
+type CallGraphEdge struct {
+	From string            `json:"from"`
+	To   string            `json:"to"`
+	Kind CallGraphEdgeKind `json:"kind"`
+	// Arg is the name of the argument, for arg edges.
+	Arg string `json:"arg,omitempty"`
+}
+
+// NewCallGraph returns the graph of calls of the given DAG, annotated with the
+// telemetry recorded in db, if not nil.
+func NewCallGraph(dag *callpbv1.DAG, db *DB) *CallGraph {
+	graph := &CallGraph{Root: dag.RootDigest}
+
+	digests := make([]string, 0, len(dag.CallsByDigest))
+	for dgst := range dag.CallsByDigest {
+		digests = append(digests, dgst)
+	}
+	sort.Strings(digests)
+
+	now := time.Now()
+	for _, dgst := range digests {
+		call := dag.CallsByDigest[dgst]
+		node := &CallGraphNode{
+			Digest: dgst,
+			Type:   call.Type.ToAST().String(),
+			Field:  call.Field,
+			Nth:    call.Nth,
+		}
+		if call.Module != nil {
+			node.Module = call.Module.Name
+			graph.addEdge(call.Module.CallDigest, dgst, CallGraphEdgeModule, "")
+		}
+		if call.ReceiverDigest != "" {
+			graph.addEdge(call.ReceiverDigest, dgst, CallGraphEdgeReceiver, "")
+		}
+		for _, arg := range call.Args {
+			node.Args = append(node.Args, CallGraphArg{
+				Name:  arg.Name,
+				Value: displayLit(arg.Value),
+			})
+			for _, argDgst := range literalCallDigests(arg.Value) {
+				graph.addEdge(argDgst, dgst, CallGraphEdgeArg, arg.Name)
+			}
+		}
+		if db != nil {
+			if span := db.MostInterestingSpan(dgst); span != nil {
+				duration := span.Activity.Duration(now)
+				cached := span.IsCached()
+				node.Duration = &duration
+				node.Cached = &cached
+			}
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	return graph
+}
+
+func (graph *CallGraph) addEdge(from, to string, kind CallGraphEdgeKind, arg string) {
+	graph.Edges = append(graph.Edges, &CallGraphEdge{
+		From: from,
+		To:   to,
+		Kind: kind,
+		Arg:  arg,
+	})
+}
+
+// literalCallDigests returns the digests of the calls referenced by the
+// literal, including within lists and objects.
+func literalCallDigests(lit *callpbv1.Literal) []string {
+	switch val := lit.GetValue().(type) {
+	case *callpbv1.Literal_CallDigest:
+		return []string{val.CallDigest}
+	case *callpbv1.Literal_List:
+		var dgsts []string
+		for _, item := range val.List.GetValues() {
+			dgsts = append(dgsts, literalCallDigests(item)...)
+		}
+		return dgsts
+	case *callpbv1.Literal_Object:
+		var dgsts []string
+		for _, item := range val.Object.GetValues() {
+			dgsts = append(dgsts, literalCallDigests(item.GetValue())...)
+		}
+		return dgsts
+	default:
+		return nil
+	}
+}
+
+// Label returns a one-line description of the call, such as
+// `withExec(args: ["echo"]): Container!`, prefixed with the name of its module
+// if any.
+func (node *CallGraphNode) Label() string {
+	var label strings.Builder
+	if node.Module != "" {
+		fmt.Fprintf(&label, "%s.", node.Module)
+	}
+	fmt.Fprintf(&label, "%s", node.Field)
+	for i, arg := range node.Args {
+		if i == 0 {
+			label.WriteString("(")
+		} else {
+			label.WriteString(", ")
+		}
+		fmt.Fprintf(&label, "%s: %s", arg.Name, arg.Value)
+		if i == len(node.Args)-1 {
+			label.WriteString(")")
+		}
+	}
+	if node.Nth != 0 {
+		fmt.Fprintf(&label, "#%d", node.Nth)
+	}
+	fmt.Fprintf(&label, ": %s", node.Type)
+	return label.String()
+}
+
+// telemetryLabel describes the node's telemetry, if any.
+func (node *CallGraphNode) telemetryLabel() string {
+	if node.Duration == nil {
+		return ""
+	}
+	if node.Cached != nil && *node.Cached {
+		return "CACHED"
+	}
+	return node.Duration.Round(time.Millisecond).String()
+}
+
+// WriteJSON writes the graph as JSON.
+func (graph *CallGraph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(graph)
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language.
+func (graph *CallGraph) WriteDOT(w io.Writer) error {
+	var out strings.Builder
+	fmt.Fprintln(&out, "digraph {")
+	for _, node := range graph.Nodes {
+		label := node.Label()
+		if tel := node.telemetryLabel(); tel != "" {
+			label += "\n" + tel
+		}
+		attrs := fmt.Sprintf("label=%q shape=box", label)
+		if node.Digest == graph.Root {
+			attrs += " penwidth=2"
+		}
+		if node.Cached != nil && *node.Cached {
+			attrs += " style=dashed"
+		}
+		fmt.Fprintf(&out, "  %q [%s];\n", node.Digest, attrs)
+	}
+	for _, edge := range graph.Edges {
+		switch edge.Kind {
+		case CallGraphEdgeReceiver:
+			fmt.Fprintf(&out, "  %q -> %q [color=black];\n", edge.From, edge.To)
+		case CallGraphEdgeArg:
+			fmt.Fprintf(&out, "  %q -> %q [color=blue label=%q];\n", edge.From, edge.To, edge.Arg)
+		case CallGraphEdgeModule:
+			fmt.Fprintf(&out, "  %q -> %q [color=magenta style=dotted];\n", edge.From, edge.To)
+		}
+	}
+	fmt.Fprintln(&out, "}")
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+func (graph *CallGraph) WriteMermaid(w io.Writer) error {
+	// Mermaid node IDs can't contain ':', so refer to nodes by index
+	ids := make(map[string]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		ids[node.Digest] = fmt.Sprintf("n%d", i)
+	}
+
+	var out strings.Builder
+	fmt.Fprintln(&out, "flowchart TD")
+	for _, node := range graph.Nodes {
+		label := mermaidEscape(node.Label())
+		if tel := node.telemetryLabel(); tel != "" {
+			label += "<br/>" + tel
+		}
+		fmt.Fprintf(&out, "  %s[\"%s\"]\n", ids[node.Digest], label)
+	}
+	for _, edge := range graph.Edges {
+		switch edge.Kind {
+		case CallGraphEdgeArg:
+			fmt.Fprintf(&out, "  %s -->|%s| %s\n", ids[edge.From], mermaidEscape(edge.Arg), ids[edge.To])
+		case CallGraphEdgeModule:
+			fmt.Fprintf(&out, "  %s -.-> %s\n", ids[edge.From], ids[edge.To])
+		default:
+			fmt.Fprintf(&out, "  %s --> %s\n", ids[edge.From], ids[edge.To])
+		}
+	}
+	fmt.Fprintf(&out, "  style %s stroke-width:3px\n", ids[graph.Root])
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// mermaidEscape escapes text for use in a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s)
+}
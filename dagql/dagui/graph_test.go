@@ -0,0 +1,226 @@
+package dagui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/dagql/call/callpbv1"
+)
+
+// testCallDAG returns the DAG of
+// `container.withExec(args: ["echo", "say \"hi\""], source: <directory>)`
+// followed by the `build` function of the `mymod` module.
+func testCallDAG() *callpbv1.DAG {
+	str := func(s string) *callpbv1.Literal {
+		return &callpbv1.Literal{Value: &callpbv1.Literal_String_{String_: s}}
+	}
+	typ := func(name string) *callpbv1.Type {
+		return &callpbv1.Type{NamedType: name, NonNull: true}
+	}
+	return &callpbv1.DAG{
+		RootDigest: "sha256:build",
+		CallsByDigest: map[string]*callpbv1.Call{
+			"sha256:mod": {
+				Digest: "sha256:mod",
+				Type:   typ("Module"),
+				Field:  "asModule",
+			},
+			"sha256:src": {
+				Digest: "sha256:src",
+				Type:   typ("Directory"),
+				Field:  "directory",
+			},
+			"sha256:ctr": {
+				Digest: "sha256:ctr",
+				Type:   typ("Container"),
+				Field:  "container",
+			},
+			"sha256:exec": {
+				Digest:         "sha256:exec",
+				ReceiverDigest: "sha256:ctr",
+				Type:           typ("Container"),
+				Field:          "withExec",
+				Args: []*callpbv1.Argument{
+					{
+						Name: "args",
+						Value: &callpbv1.Literal{Value: &callpbv1.Literal_List{List: &callpbv1.List{
+							Values: []*callpbv1.Literal{str("echo"), str(`say "hi"`)},
+						}}},
+					},
+					{
+						Name:  "source",
+						Value: &callpbv1.Literal{Value: &callpbv1.Literal_CallDigest{CallDigest: "sha256:src"}},
+					},
+				},
+			},
+			"sha256:build": {
+				Digest:         "sha256:build",
+				ReceiverDigest: "sha256:exec",
+				Type:           typ("Container"),
+				Field:          "build",
+				Nth:            2,
+				Module:         &callpbv1.Module{Name: "mymod", CallDigest: "sha256:mod"},
+			},
+		},
+	}
+}
+
+func TestNewCallGraph(t *testing.T) {
+	graph := NewCallGraph(testCallDAG(), nil)
+	require.Equal(t, "sha256:build", graph.Root)
+
+	var labels []string
+	for _, node := range graph.Nodes {
+		labels = append(labels, node.Digest+" "+node.Label())
+		require.Nil(t, node.Duration)
+		require.Nil(t, node.Cached)
+	}
+	require.Equal(t, []string{
+		`sha256:build mymod.build#2: Container!`,
+		`sha256:ctr container: Container!`,
+		`sha256:exec withExec(args: ["echo", "say \"hi\""], source: <input>): Container!`,
+		`sha256:mod asModule: Module!`,
+		`sha256:src directory: Directory!`,
+	}, labels)
+
+	require.Equal(t, []*CallGraphEdge{
+		{From: "sha256:mod", To: "sha256:build", Kind: CallGraphEdgeModule},
+		{From: "sha256:exec", To: "sha256:build", Kind: CallGraphEdgeReceiver},
+		{From: "sha256:ctr", To: "sha256:exec", Kind: CallGraphEdgeReceiver},
+		{From: "sha256:src", To: "sha256:exec", Kind: CallGraphEdgeArg, Arg: "source"},
+	}, graph.Edges)
+}
+
+func TestCallGraphWrite(t *testing.T) {
+	graph := NewCallGraph(testCallDAG(), nil)
+	// telemetry is only known for the container call, which was cached
+	duration, cached := 1500*time.Millisecond, true
+	graph.Nodes[1].Duration = &duration
+	graph.Nodes[1].Cached = &cached
+
+	for _, tc := range []struct {
+		format string
+		write  func(*CallGraph, *strings.Builder) error
+		expect string
+	}{
+		{
+			format: "dot",
+			write: func(graph *CallGraph, w *strings.Builder) error {
+				return graph.WriteDOT(w)
+			},
+			expect: `digraph {
+  "sha256:build" [label="mymod.build#2: Container!" shape=box penwidth=2];
+  "sha256:ctr" [label="container: Container!\nCACHED" shape=box style=dashed];
+  "sha256:exec" [label="withExec(args: [\"echo\", \"say \\\"hi\\\"\"], source: <input>): Container!" shape=box];
+  "sha256:mod" [label="asModule: Module!" shape=box];
+  "sha256:src" [label="directory: Directory!" shape=box];
+  "sha256:mod" -> "sha256:build" [color=magenta style=dotted];
+  "sha256:exec" -> "sha256:build" [color=black];
+  "sha256:ctr" -> "sha256:exec" [color=black];
+  "sha256:src" -> "sha256:exec" [color=blue label="source"];
+}
+`,
+		},
+		{
+			format: "mermaid",
+			write: func(graph *CallGraph, w *strings.Builder) error {
+				return graph.WriteMermaid(w)
+			},
+			expect: `flowchart TD
+  n0["mymod.build#2: Container!"]
+  n1["container: Container!<br/>CACHED"]
+  n2["withExec(args: [#quot;echo#quot;, #quot;say \#quot;hi\#quot;#quot;], source: <input>): Container!"]
+  n3["asModule: Module!"]
+  n4["directory: Directory!"]
+  n3 -.-> n0
+  n2 --> n0
+  n1 --> n2
+  n4 -->|source| n2
+  style n0 stroke-width:3px
+`,
+		},
+		{
+			format: "json",
+			write: func(graph *CallGraph, w *strings.Builder) error {
+				return graph.WriteJSON(w)
+			},
+			expect: `{
+  "root": "sha256:build",
+  "nodes": [
+    {
+      "digest": "sha256:build",
+      "module": "mymod",
+      "type": "Container!",
+      "field": "build",
+      "nth": 2
+    },
+    {
+      "digest": "sha256:ctr",
+      "type": "Container!",
+      "field": "container",
+      "duration": 1500000000,
+      "cached": true
+    },
+    {
+      "digest": "sha256:exec",
+      "type": "Container!",
+      "field": "withExec",
+      "args": [
+        {
+          "name": "args",
+          "value": "[\"echo\", \"say \\\"hi\\\"\"]"
+        },
+        {
+          "name": "source",
+          "value": "<input>"
+        }
+      ]
+    },
+    {
+      "digest": "sha256:mod",
+      "type": "Module!",
+      "field": "asModule"
+    },
+    {
+      "digest": "sha256:src",
+      "type": "Directory!",
+      "field": "directory"
+    }
+  ],
+  "edges": [
+    {
+      "from": "sha256:mod",
+      "to": "sha256:build",
+      "kind": "module"
+    },
+    {
+      "from": "sha256:exec",
+      "to": "sha256:build",
+      "kind": "receiver"
+    },
+    {
+      "from": "sha256:ctr",
+      "to": "sha256:exec",
+      "kind": "receiver"
+    },
+    {
+      "from": "sha256:src",
+      "to": "sha256:exec",
+      "kind": "arg",
+      "arg": "source"
+    }
+  ]
+}
+`,
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			var out strings.Builder
+			require.NoError(t, tc.write(graph, &out))
+			require.Equal(t, tc.expect, out.String())
+		})
+	}
+}
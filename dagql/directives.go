@@ -1,6 +1,8 @@
 package dagql
 
 import (
+	"strconv"
+
 	"github.com/vektah/gqlparser/v2/ast"
 
 	"github.com/dagger/dagger/dagql/call"
@@ -100,6 +102,27 @@ func experimental(reason string) *ast.Directive {
 	}
 }
 
+func cost(weight int) *ast.Directive {
+	return &ast.Directive{
+		Name: "cost",
+		Arguments: []*ast.Argument{
+			{
+				Name: "weight",
+				Value: &ast.Value{
+					Kind: ast.IntValue,
+					Raw:  strconv.Itoa(weight),
+				},
+			},
+		},
+	}
+}
+
+func stream() *ast.Directive {
+	return &ast.Directive{
+		Name: "stream",
+	}
+}
+
 func internal() *ast.Directive {
 	return &ast.Directive{
 		Name: "internal",
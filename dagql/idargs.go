@@ -0,0 +1,175 @@
+package dagql
+
+import (
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// operationIDArgs returns the IDs passed as arguments to the fields selected
+// by the given operation, or by all its operations if its name is empty,
+// whether they're passed literally or through variables.
+//
+// Each ID stands for the calls it was built from, which are evaluated when
+// the ID is loaded, so checks made before executing an operation must look
+// inside them too.
+func (s *Server) operationIDArgs(gqlOp *graphql.OperationContext) ([]*call.ID, error) {
+	var ids []*call.ID
+	visitedFragments := map[string]bool{}
+	for _, op := range gqlOp.Doc.Operations {
+		if gqlOp.OperationName != "" && op.Name != gqlOp.OperationName {
+			continue
+		}
+		var err error
+		ids, err = s.appendSelectionIDArgs(ids, op.SelectionSet, gqlOp.Variables, visitedFragments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func (s *Server) appendSelectionIDArgs(ids []*call.ID, sels ast.SelectionSet, vars map[string]any, visitedFragments map[string]bool) ([]*call.ID, error) {
+	var err error
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Definition != nil {
+				for _, arg := range sel.Arguments {
+					argDef := sel.Definition.Arguments.ForName(arg.Name)
+					if argDef == nil || arg.Value == nil {
+						continue
+					}
+					val, err := arg.Value.Value(vars)
+					if err != nil {
+						return nil, err
+					}
+					ids, err = s.appendInputIDs(ids, argDef.Type, val)
+					if err != nil {
+						return nil, fmt.Errorf("%s(%s:): %w", sel.Name, arg.Name, err)
+					}
+				}
+			}
+			ids, err = s.appendSelectionIDArgs(ids, sel.SelectionSet, vars, visitedFragments)
+		case *ast.InlineFragment:
+			ids, err = s.appendSelectionIDArgs(ids, sel.SelectionSet, vars, visitedFragments)
+		case *ast.FragmentSpread:
+			if sel.Definition == nil || visitedFragments[sel.Name] {
+				continue
+			}
+			visitedFragments[sel.Name] = true
+			ids, err = s.appendSelectionIDArgs(ids, sel.Definition.SelectionSet, vars, visitedFragments)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// appendInputIDs appends the IDs in the given input value of the given type,
+// looking into lists and input objects.
+func (s *Server) appendInputIDs(ids []*call.ID, typ *ast.Type, val any) ([]*call.ID, error) {
+	if val == nil {
+		return ids, nil
+	}
+	if typ.Elem != nil {
+		list, ok := val.([]any)
+		if !ok {
+			// a single value is coerced to a list of one
+			return s.appendInputIDs(ids, typ.Elem, val)
+		}
+		var err error
+		for _, elem := range list {
+			ids, err = s.appendInputIDs(ids, typ.Elem, elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return ids, nil
+	}
+	if scalar, ok := s.ScalarType(typ.NamedType); ok {
+		if _, isID := scalar.(IDType); !isID {
+			return ids, nil
+		}
+		input, err := scalar.DecodeInput(val)
+		if err != nil {
+			return nil, err
+		}
+		if idable, ok := input.(IDable); ok && idable.ID() != nil {
+			ids = append(ids, idable.ID())
+		}
+		return ids, nil
+	}
+	def := s.Schema().Types[typ.NamedType]
+	if def == nil || def.Kind != ast.InputObject {
+		return ids, nil
+	}
+	fields, ok := val.(map[string]any)
+	if !ok {
+		return ids, nil
+	}
+	var err error
+	for _, field := range def.Fields {
+		ids, err = s.appendInputIDs(ids, field.Type, fields[field.Name])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// idCalls calls fn once for each call in the given IDs, including their
+// receivers and the IDs nested in their arguments. receiverType is the name
+// of the type the call selects a field on.
+func (s *Server) idCalls(ids []*call.ID, fn func(id *call.ID, receiverType string)) {
+	seen := map[string]bool{}
+	var walkID func(id *call.ID)
+	var walkLiteral func(lit call.Literal)
+	walkID = func(id *call.ID) {
+		if id == nil || seen[id.Digest().String()] {
+			return
+		}
+		seen[id.Digest().String()] = true
+		receiverType := s.root.Type().Name()
+		if recv := id.Receiver(); recv != nil {
+			receiverType = recv.Type().NamedType()
+			walkID(recv)
+		}
+		for _, arg := range id.Args() {
+			walkLiteral(arg.Value())
+		}
+		fn(id, receiverType)
+	}
+	walkLiteral = func(lit call.Literal) {
+		switch x := lit.(type) {
+		case *call.LiteralID:
+			walkID(x.Value())
+		case *call.LiteralList:
+			_ = x.Range(func(_ int, v call.Literal) error {
+				walkLiteral(v)
+				return nil
+			})
+		case *call.LiteralObject:
+			_ = x.Range(func(_ int, _ string, v call.Literal) error {
+				walkLiteral(v)
+				return nil
+			})
+		}
+	}
+	for _, id := range ids {
+		walkID(id)
+	}
+}
+
+// idFieldSpec returns the spec of the field selected by the given call.
+func (s *Server) idFieldSpec(id *call.ID, receiverType string) (FieldSpec, bool) {
+	class, ok := s.ObjectType(receiverType)
+	if !ok {
+		return FieldSpec{}, false
+	}
+	return class.FieldSpec(id.Field(), id.View())
+}
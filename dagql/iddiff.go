@@ -0,0 +1,48 @@
+package dagql
+
+import (
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// IDDiff is the first difference found between two IDs, explaining why they
+// have different digests, e.g. why one missed the cache of the other.
+type IDDiff struct {
+	Kind        string `field:"true" doc:"The kind of difference: field, type, nth, view, module, arg, or content."`
+	Path        string `field:"true" doc:"The path of the diverging call in the first ID."`
+	Arg         string `field:"true" doc:"The name of the diverging argument, if any."`
+	Left        string `field:"true" doc:"The diverging value in the first ID."`
+	Right       string `field:"true" doc:"The diverging value in the second ID."`
+	LeftDigest  string `field:"true" doc:"The digest of the diverging call in the first ID."`
+	RightDigest string `field:"true" doc:"The digest of the diverging call in the second ID."`
+}
+
+// NewIDDiff returns the first difference between two IDs, or nil if they are
+// the same.
+func NewIDDiff(left, right *call.ID) *IDDiff {
+	diff := call.DiffIDs(left, right)
+	if diff == nil {
+		return nil
+	}
+	return &IDDiff{
+		Kind:        string(diff.Kind),
+		Path:        diff.Left.Path(),
+		Arg:         diff.Arg,
+		Left:        diff.LeftValue,
+		Right:       diff.RightValue,
+		LeftDigest:  diff.Left.Digest().String(),
+		RightDigest: diff.Right.Digest().String(),
+	}
+}
+
+func (*IDDiff) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "IDDiff",
+		NonNull:   true,
+	}
+}
+
+func (*IDDiff) TypeDescription() string {
+	return "The first difference found between two IDs."
+}
@@ -118,6 +118,10 @@ type ShellHandler interface {
 	// ReactToInput allows reacting to live input before it's submitted
 	ReactToInput(ctx context.Context, msg tea.KeyMsg) tea.Cmd
 
+	// HistoryFile returns the path of the file to persist the history in, or
+	// an empty string for the default
+	HistoryFile() string
+
 	// Shell handlers can man-in-the-middle history items to preserve per-entry modes etc.
 	editline.HistoryEncoder
 }
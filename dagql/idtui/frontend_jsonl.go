@@ -0,0 +1,435 @@
+package idtui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/telemetry"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dagger/dagger/dagql/call/callpbv1"
+	"github.com/dagger/dagger/dagql/dagui"
+	"github.com/dagger/dagger/engine/slog"
+	"github.com/dagger/dagger/util/cleanups"
+	"github.com/muesli/termenv"
+	"github.com/pkg/browser"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// JSONL event types.
+const (
+	JSONLSpanStart = "span.start"
+	JSONLSpanEnd   = "span.end"
+	JSONLLog       = "log"
+	JSONLTraceURL  = "trace.url"
+	JSONLError     = "error"
+)
+
+// JSONLEvent is a line written by the JSONL frontend.
+type JSONLEvent struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	SpanID   string `json:"spanId,omitempty"`
+	ParentID string `json:"parentId,omitempty"`
+
+	// Name is the name of the span, and Call the API call it represents,
+	// if any.
+	Name string `json:"name,omitempty"`
+	Call string `json:"call,omitempty"`
+
+	// Status is set when a span ends: "ok", "error" or "canceled".
+	Status     string  `json:"status,omitempty"`
+	Cached     bool    `json:"cached,omitempty"`
+	DurationMS float64 `json:"durationMs,omitempty"`
+
+	// Stream is "stdout" or "stderr" for a log's body.
+	Stream string `json:"stream,omitempty"`
+	Body   string `json:"body,omitempty"`
+
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type frontendJSONL struct {
+	dagui.FrontendOpts
+
+	db *dagui.DB
+
+	mu  sync.Mutex
+	enc *json.Encoder
+
+	// started and ended track the spans that had their events written
+	started map[dagui.SpanID]bool
+	ended   map[dagui.SpanID]bool
+
+	// pendingLogs holds logs received before their span
+	pendingLogs map[dagui.SpanID][]sdklog.Record
+}
+
+// NewJSONL creates a frontend that writes progress as JSON lines: an event
+// when a span starts and ends, for each log, and for errors.
+//
+// Spans are filtered the same way as the other frontends, so verbosity
+// flags apply. The command's own stdout is still written to stdout, so that
+// results can be consumed separately.
+func NewJSONL(w io.Writer) Frontend {
+	return &frontendJSONL{
+		db:          dagui.NewDB(),
+		enc:         json.NewEncoder(w),
+		started:     make(map[dagui.SpanID]bool),
+		ended:       make(map[dagui.SpanID]bool),
+		pendingLogs: make(map[dagui.SpanID][]sdklog.Record),
+	}
+}
+
+func (fe *frontendJSONL) Run(ctx context.Context, opts dagui.FrontendOpts, run func(context.Context) (cleanups.CleanupF, error)) error {
+	fe.FrontendOpts = opts
+
+	cleanup, runErr := run(ctx)
+	if cleanup != nil {
+		runErr = errors.Join(runErr, cleanup())
+	}
+
+	fe.mu.Lock()
+	if fe.TelemetryError != nil {
+		fe.emit(JSONLEvent{
+			Type:  JSONLError,
+			Error: "failures detected while emitting telemetry: " + fe.TelemetryError.Error(),
+		})
+	}
+	if runErr != nil {
+		fe.emit(JSONLEvent{Type: JSONLError, Error: runErr.Error()})
+	}
+	// stderr of the command was written as log events already
+	renderPrimaryOutput(io.Discard, fe.db)
+	fe.mu.Unlock()
+
+	fe.db.WriteDot(opts.DotOutputFilePath, opts.DotFocusField, opts.DotShowInternal)
+
+	return runErr
+}
+
+func (fe *frontendJSONL) emit(ev JSONLEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if err := fe.enc.Encode(ev); err != nil {
+		slog.Debug("failed to write progress event", "err", err)
+	}
+}
+
+func (fe *frontendJSONL) Opts() *dagui.FrontendOpts {
+	return &fe.FrontendOpts
+}
+
+func (fe *frontendJSONL) SetVerbosity(n int) {
+	fe.mu.Lock()
+	fe.Verbosity = n
+	fe.mu.Unlock()
+}
+
+func (fe *frontendJSONL) SetPrimary(spanID dagui.SpanID) {
+	fe.mu.Lock()
+	fe.db.SetPrimarySpan(spanID)
+	fe.ZoomedSpan = spanID
+	fe.FocusedSpan = spanID
+	fe.mu.Unlock()
+}
+
+func (fe *frontendJSONL) RevealAllSpans() {
+	fe.mu.Lock()
+	fe.ZoomedSpan = dagui.SpanID{}
+	fe.mu.Unlock()
+}
+
+func (fe *frontendJSONL) Background(cmd tea.ExecCommand, raw bool) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (fe *frontendJSONL) SetCloudURL(ctx context.Context, url string, msg string, logged bool) {
+	if fe.OpenWeb {
+		if err := browser.OpenURL(url); err != nil {
+			slog.Warn("failed to open URL", "url", url, "err", err)
+		}
+	}
+	fe.mu.Lock()
+	fe.emit(JSONLEvent{Type: JSONLTraceURL, URL: url})
+	fe.mu.Unlock()
+}
+
+func (fe *frontendJSONL) SetClient(client *dagger.Client) {
+}
+
+func (fe *frontendJSONL) Shell(ctx context.Context, handler ShellHandler) {
+	fe.mu.Lock()
+	fe.emit(JSONLEvent{Type: JSONLError, Error: "shell not supported in jsonl mode"})
+	fe.mu.Unlock()
+}
+
+func (fe *frontendJSONL) HandlePrompt(ctx context.Context, prompt string, dest any) error {
+	return fmt.Errorf("prompts not supported in jsonl frontend")
+}
+
+// visible returns true if events should be written for the span.
+func (fe *frontendJSONL) visible(span *dagui.Span) bool {
+	if span.Name == "" {
+		// not received yet
+		return false
+	}
+	if !fe.ShouldShow(fe.db, span) {
+		return false
+	}
+	for p := range span.Parents {
+		if p.Encapsulate || !fe.ShouldShow(fe.db, p) {
+			return false
+		}
+	}
+	return !span.Encapsulated || span.IsFailedOrCausedFailure()
+}
+
+func (fe *frontendJSONL) spanEvent(typ string, span *dagui.Span) JSONLEvent {
+	ev := JSONLEvent{
+		Type:   typ,
+		SpanID: span.ID.String(),
+		Name:   span.Name,
+	}
+	// report the closest ancestor that was written as the parent
+	for p := range span.Parents {
+		if fe.started[p.ID] {
+			ev.ParentID = p.ID.String()
+			break
+		}
+	}
+	if call := span.Call(); call != nil {
+		ev.Call = callSummary(fe.db, call)
+	}
+	return ev
+}
+
+func (fe *frontendJSONL) startSpan(span *dagui.Span) {
+	ev := fe.spanEvent(JSONLSpanStart, span)
+	ev.Time = span.StartTime
+	fe.emit(ev)
+	fe.started[span.ID] = true
+
+	if records, ok := fe.pendingLogs[span.ID]; ok {
+		delete(fe.pendingLogs, span.ID)
+		fe.writeLogs(span, records)
+	}
+}
+
+func (fe *frontendJSONL) endSpan(span *dagui.Span) {
+	ev := fe.spanEvent(JSONLSpanEnd, span)
+	ev.Time = span.EndTime
+	ev.DurationMS = float64(span.EndTime.Sub(span.StartTime).Microseconds()) / 1000
+	ev.Cached = span.IsCached()
+	switch {
+	case span.IsCanceled():
+		ev.Status = "canceled"
+	case span.IsFailedOrCausedFailure():
+		ev.Status = "error"
+		ev.Error = spanError(span)
+	default:
+		ev.Status = "ok"
+	}
+	fe.emit(ev)
+	fe.ended[span.ID] = true
+}
+
+// spanError returns the error message of a failed span, or of the spans
+// that caused it to fail.
+func spanError(span *dagui.Span) string {
+	if span.Status.Description != "" {
+		return span.Status.Description
+	}
+	var msgs []string
+	for _, errSpan := range span.Errors().Order {
+		if errSpan.Status.Description != "" {
+			msgs = append(msgs, errSpan.Status.Description)
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (fe *frontendJSONL) writeLogs(span *dagui.Span, records []sdklog.Record) {
+	for _, record := range records {
+		body := record.Body().AsString()
+		if body == "" {
+			// EOF
+			continue
+		}
+		var verbose bool
+		stream := "stdout"
+		record.WalkAttributes(func(kv log.KeyValue) bool {
+			switch kv.Key {
+			case telemetry.LogsVerboseAttr:
+				verbose = kv.Value.AsBool()
+			case telemetry.StdioStreamAttr:
+				if kv.Value.AsInt64() == 2 {
+					stream = "stderr"
+				}
+			}
+			return true
+		})
+		if verbose && !fe.Debug {
+			continue
+		}
+		fe.emit(JSONLEvent{
+			Type:   JSONLLog,
+			Time:   record.Timestamp(),
+			SpanID: span.ID.String(),
+			Stream: stream,
+			Body:   body,
+		})
+	}
+}
+
+func (fe *frontendJSONL) SpanExporter() sdktrace.SpanExporter {
+	return jsonlSpanExporter{fe}
+}
+
+type jsonlSpanExporter struct {
+	*frontendJSONL
+}
+
+func (fe jsonlSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+
+	if err := fe.db.ExportSpans(ctx, spans); err != nil {
+		return err
+	}
+
+	for _, s := range spans {
+		span := fe.db.Spans.Map[dagui.SpanID{SpanID: s.SpanContext().SpanID()}]
+		if span == nil || fe.ended[span.ID] {
+			continue
+		}
+		if !fe.started[span.ID] {
+			if !fe.visible(span) {
+				continue
+			}
+			fe.startSpan(span)
+		}
+		if !span.IsRunning() {
+			fe.endSpan(span)
+		}
+	}
+	return nil
+}
+
+func (fe jsonlSpanExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (fe jsonlSpanExporter) Shutdown(ctx context.Context) error {
+	return fe.db.Shutdown(ctx)
+}
+
+func (fe *frontendJSONL) LogExporter() sdklog.Exporter {
+	return jsonlLogExporter{fe}
+}
+
+type jsonlLogExporter struct {
+	*frontendJSONL
+}
+
+func (fe jsonlLogExporter) Export(ctx context.Context, logs []sdklog.Record) error {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+
+	if err := fe.db.LogExporter().Export(ctx, logs); err != nil {
+		return err
+	}
+	for _, record := range logs {
+		spanID := dagui.SpanID{SpanID: record.SpanID()}
+		span := fe.db.Spans.Map[spanID]
+		switch {
+		case span == nil || span.Name == "":
+			// wait for the span, which may still become visible
+			fe.pendingLogs[spanID] = append(fe.pendingLogs[spanID], record)
+		case fe.started[spanID]:
+			fe.writeLogs(span, []sdklog.Record{record})
+		}
+	}
+	return nil
+}
+
+func (fe jsonlLogExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (fe jsonlLogExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (fe *frontendJSONL) MetricExporter() sdkmetric.Exporter {
+	return jsonlMetricExporter{fe}
+}
+
+type jsonlMetricExporter struct {
+	*frontendJSONL
+}
+
+func (fe jsonlMetricExporter) Export(ctx context.Context, resourceMetrics *metricdata.ResourceMetrics) error {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+
+	return fe.db.MetricExporter().Export(ctx, resourceMetrics)
+}
+
+func (fe jsonlMetricExporter) Temporality(ik sdkmetric.InstrumentKind) metricdata.Temporality {
+	return fe.db.Temporality(ik)
+}
+
+func (fe jsonlMetricExporter) Aggregation(ik sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return fe.db.Aggregation(ik)
+}
+
+func (fe jsonlMetricExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (fe jsonlMetricExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// callSummary renders a call on a single line without colors, showing
+// object arguments as their type, e.g. `Go.test(source: Directory)`.
+func callSummary(db *dagui.DB, call *callpbv1.Call) string {
+	var buf strings.Builder
+	out := NewOutput(&buf, termenv.WithProfile(termenv.Ascii))
+	r := newRenderer(db, 0, dagui.FrontendOpts{})
+
+	if call.ReceiverDigest != "" {
+		fmt.Fprint(out, db.MustCall(call.ReceiverDigest).Type.ToAST().Name()+".")
+	}
+	fmt.Fprint(out, call.Field)
+	if len(call.Args) > 0 {
+		fmt.Fprint(out, "(")
+		for i, arg := range call.Args {
+			if i > 0 {
+				fmt.Fprint(out, ", ")
+			}
+			fmt.Fprintf(out, "%s: ", arg.GetName())
+			if dig := arg.GetValue().GetCallDigest(); dig != "" {
+				fmt.Fprint(out, db.MustCall(dig).Type.ToAST().Name())
+			} else {
+				r.renderLiteral(out, arg.GetValue())
+			}
+		}
+		fmt.Fprint(out, ")")
+	}
+	return buf.String()
+}
@@ -0,0 +1,38 @@
+package idtui
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/dagql/dagui"
+)
+
+func TestJSONL(t *testing.T) {
+	var buf strings.Builder
+	fe := NewJSONL(&buf).(*frontendJSONL)
+	fe.Verbosity = dagui.ShowCompletedVerbosity
+	require.NoError(t, fe.SpanExporter().ExportSpans(context.Background(), testCallSpans(t)))
+
+	var events []JSONLEvent
+	dec := json.NewDecoder(strings.NewReader(buf.String()))
+	for dec.More() {
+		var ev JSONLEvent
+		require.NoError(t, dec.Decode(&ev))
+		ev.Time = time.Time{}
+		events = append(events, ev)
+	}
+	require.Equal(t, []JSONLEvent{
+		{Type: JSONLSpanStart, SpanID: "0100000000000000", Name: "Query.go", Call: "go"},
+		{Type: JSONLSpanEnd, SpanID: "0100000000000000", Name: "Query.go", Call: "go", Status: "ok", DurationMS: 1000},
+		{Type: JSONLSpanStart, SpanID: "0200000000000000", Name: "Go.test", Call: `Go.test(pkg: "./...")`},
+		{
+			Type: JSONLSpanEnd, SpanID: "0200000000000000", Name: "Go.test", Call: `Go.test(pkg: "./...")`,
+			Status: "error", DurationMS: 1500, Error: "exit code 1\nFAIL ./pkg",
+		},
+	}, events)
+}
@@ -38,7 +38,9 @@ import (
 	"github.com/dagger/dagger/util/cleanups"
 )
 
-var historyFile = filepath.Join(xdg.DataHome, "dagger", "histfile")
+// DefaultHistoryFile is where the shell history is persisted, unless the
+// ShellHandler sets another file.
+var DefaultHistoryFile = filepath.Join(xdg.DataHome, "dagger", "histfile")
 
 var ErrShellExited = errors.New("shell exited")
 var ErrInterrupted = errors.New("interrupted")
@@ -65,6 +67,7 @@ type frontendPretty struct {
 	// updated by Shell
 	shell           ShellHandler
 	shellCtx        context.Context
+	historyFile     string
 	shellInterrupt  context.CancelCauseFunc
 	promptFg        termenv.Color
 	editline        *editline.Model
@@ -228,10 +231,10 @@ func (fe *frontendPretty) Run(ctx context.Context, opts dagui.FrontendOpts, run
 	}
 
 	if fe.editline != nil && fe.shell != nil {
-		if err := os.MkdirAll(filepath.Dir(historyFile), 0755); err != nil {
+		if err := os.MkdirAll(filepath.Dir(fe.historyFile), 0755); err != nil {
 			slog.Error("failed to create history directory", "err", err)
 		}
-		if err := history.SaveHistory(fe.editline.GetHistory(), historyFile); err != nil {
+		if err := history.SaveHistory(fe.editline.GetHistory(), fe.historyFile); err != nil {
 			slog.Error("failed to save history", "err", err)
 		}
 	}
@@ -1062,8 +1065,12 @@ func (fe *frontendPretty) update(msg tea.Msg) (*frontendPretty, tea.Cmd) { //nol
 		fe.initEditline()
 
 		// restore history
+		fe.historyFile = msg.handler.HistoryFile()
+		if fe.historyFile == "" {
+			fe.historyFile = DefaultHistoryFile
+		}
 		fe.editline.MaxHistorySize = 1000
-		if history, err := history.LoadHistory(historyFile); err == nil {
+		if history, err := history.LoadHistory(fe.historyFile); err == nil {
 			fe.editline.SetHistory(history)
 		}
 		fe.editline.HistoryEncoder = msg.handler
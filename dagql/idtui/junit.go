@@ -0,0 +1,130 @@
+package idtui
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dagger/dagger/dagql/dagui"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr,omitempty"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+
+	start, end time.Time
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes a JUnit XML report with a test case for each module
+// function call that completed, grouped in a test suite per module.
+//
+// Canceled calls are reported as skipped, and failed calls, including those
+// that failed because of a call they made, as failures.
+func WriteJUnit(w io.Writer, db *dagui.DB, name string) error {
+	report := &junitTestSuites{Name: name}
+	suites := map[string]*junitTestSuite{}
+
+	var start, end time.Time
+	for _, span := range db.Spans.Order {
+		call := span.Call()
+		if call == nil || call.Module == nil || span.Internal || span.IsRunning() {
+			continue
+		}
+
+		suite := suites[call.Module.Name]
+		if suite == nil {
+			suite = &junitTestSuite{Name: call.Module.Name, start: span.StartTime}
+			suites[call.Module.Name] = suite
+			report.Suites = append(report.Suites, suite)
+		}
+
+		tc := junitTestCase{
+			Name:      callSummary(db, call),
+			Classname: call.Module.Name,
+			Time:      junitDuration(span.EndTime.Sub(span.StartTime)),
+		}
+		switch {
+		case span.IsCanceled():
+			tc.Skipped = &junitSkipped{Message: "canceled"}
+			suite.Skipped++
+		case span.IsFailedOrCausedFailure():
+			msg := spanError(span)
+			summary, _, _ := strings.Cut(msg, "\n")
+			tc.Failure = &junitFailure{Message: summary, Text: msg}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+
+		if span.StartTime.Before(suite.start) {
+			suite.start = span.StartTime
+		}
+		if span.EndTime.After(suite.end) {
+			suite.end = span.EndTime
+		}
+		if start.IsZero() || span.StartTime.Before(start) {
+			start = span.StartTime
+		}
+		if span.EndTime.After(end) {
+			end = span.EndTime
+		}
+	}
+
+	for _, suite := range report.Suites {
+		suite.Time = junitDuration(suite.end.Sub(suite.start))
+		suite.Timestamp = suite.start.UTC().Format(time.RFC3339)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+	}
+	report.Time = junitDuration(end.Sub(start))
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func junitDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,90 @@
+package idtui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"dagger.io/dagger/telemetry"
+	"github.com/dagger/dagger/dagql/call/callpbv1"
+	"github.com/dagger/dagger/dagql/dagui"
+)
+
+// testCallSpans returns spans for `go` and `go.test(pkg: "./...")` module
+// function calls, the second of which failed.
+func testCallSpans(t *testing.T) []sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	mod := &callpbv1.Module{Name: "go", Ref: "github.com/example/go"}
+	ctor := &callpbv1.Call{
+		Type:   &callpbv1.Type{NamedType: "Go"},
+		Field:  "go",
+		Module: mod,
+		Digest: "sha256:ctor",
+	}
+	test := &callpbv1.Call{
+		ReceiverDigest: ctor.Digest,
+		Type:           &callpbv1.Type{NamedType: "Void"},
+		Field:          "test",
+		Args: []*callpbv1.Argument{{
+			Name:  "pkg",
+			Value: &callpbv1.Literal{Value: &callpbv1.Literal_String_{String_: "./..."}},
+		}},
+		Module: mod,
+		Digest: "sha256:test",
+	}
+
+	traceID := trace.TraceID{1}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stub := func(id byte, name string, call *callpbv1.Call, offset, d time.Duration, status sdktrace.Status) sdktrace.ReadOnlySpan {
+		payload, err := call.Encode()
+		require.NoError(t, err)
+		return tracetest.SpanStub{
+			Name: name,
+			SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: traceID,
+				SpanID:  trace.SpanID{id},
+			}),
+			StartTime: start.Add(offset),
+			EndTime:   start.Add(offset + d),
+			Attributes: []attribute.KeyValue{
+				attribute.String(telemetry.DagDigestAttr, call.Digest),
+				attribute.String(telemetry.DagCallAttr, payload),
+			},
+			Status: status,
+		}.Snapshot()
+	}
+	return []sdktrace.ReadOnlySpan{
+		stub(1, "Query.go", ctor, 0, time.Second, sdktrace.Status{Code: codes.Ok}),
+		stub(2, "Go.test", test, time.Second, 1500*time.Millisecond, sdktrace.Status{
+			Code:        codes.Error,
+			Description: "exit code 1\nFAIL ./pkg",
+		}),
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	db := dagui.NewDB()
+	require.NoError(t, db.ExportSpans(context.Background(), testCallSpans(t)))
+
+	var buf strings.Builder
+	require.NoError(t, WriteJUnit(&buf, db, "dagger call test"))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="dagger call test" tests="2" failures="1" skipped="0" time="2.500">
+  <testsuite name="go" tests="2" failures="1" skipped="0" time="2.500" timestamp="2024-01-01T00:00:00Z">
+    <testcase name="go" classname="go" time="1.000"></testcase>
+    <testcase name="Go.test(pkg: &#34;./...&#34;)" classname="go" time="1.500">
+      <failure message="exit code 1">exit code 1&#xA;FAIL ./pkg</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
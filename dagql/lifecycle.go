@@ -0,0 +1,218 @@
+package dagql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// LifecycleKind is the lifecycle stage of a field that isn't stable.
+type LifecycleKind string
+
+const (
+	// LifecycleDeprecated marks a field with the @deprecated directive.
+	LifecycleDeprecated LifecycleKind = "deprecated"
+	// LifecycleExperimental marks a field with the @experimental directive.
+	LifecycleExperimental LifecycleKind = "experimental"
+)
+
+// LifecycleWarning reports the use of a deprecated or experimental field or
+// argument.
+type LifecycleWarning struct {
+	Kind  LifecycleKind `json:"kind"`
+	Type  string        `json:"type"`
+	Field string        `json:"field"`
+	// Arg is set when the argument is deprecated or experimental, rather
+	// than the field.
+	Arg    string `json:"arg,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// API returns the field or argument the warning is about, such as
+// `Container.withExec` or `Container.withExec(args:)`.
+func (w LifecycleWarning) API() string {
+	api := w.Type + "." + w.Field
+	if w.Arg != "" {
+		api += fmt.Sprintf("(%s:)", w.Arg)
+	}
+	return api
+}
+
+func (w LifecycleWarning) String() string {
+	msg := fmt.Sprintf("%s is %s", w.API(), w.Kind)
+	if w.Reason != "" {
+		msg += ": " + w.Reason
+	}
+	return msg
+}
+
+// LifecycleTracker reports the deprecated and experimental fields used
+// during a session, once each.
+type LifecycleTracker struct {
+	onWarning func(context.Context, LifecycleWarning)
+
+	seen   map[LifecycleWarning]struct{}
+	seenMu sync.Mutex
+}
+
+// NewLifecycleTracker returns a tracker calling onWarning the first time each
+// deprecated or experimental field is used. onWarning may be nil.
+func NewLifecycleTracker(onWarning func(context.Context, LifecycleWarning)) *LifecycleTracker {
+	return &LifecycleTracker{
+		onWarning: onWarning,
+		seen:      map[LifecycleWarning]struct{}{},
+	}
+}
+
+// firstUse returns the warnings that haven't been seen before, marking them
+// as seen.
+func (t *LifecycleTracker) firstUse(ctx context.Context, warnings []LifecycleWarning) []LifecycleWarning {
+	t.seenMu.Lock()
+	var first []LifecycleWarning
+	for _, w := range warnings {
+		if _, ok := t.seen[w]; ok {
+			continue
+		}
+		t.seen[w] = struct{}{}
+		first = append(first, w)
+	}
+	t.seenMu.Unlock()
+
+	if t.onWarning != nil {
+		for _, w := range first {
+			t.onWarning(ctx, w)
+		}
+	}
+	return first
+}
+
+// LifecyclePolicy is how a server handles operations using deprecated or
+// experimental fields.
+type LifecyclePolicy struct {
+	// Tracker reports the first use of each field, if set.
+	Tracker *LifecycleTracker
+	// RejectExperimental rejects operations using experimental fields.
+	RejectExperimental bool
+}
+
+type lifecyclePolicyKey struct{}
+
+// WithLifecyclePolicy sets the policy applied to operations executed with
+// the returned context.
+func WithLifecyclePolicy(ctx context.Context, policy LifecyclePolicy) context.Context {
+	return context.WithValue(ctx, lifecyclePolicyKey{}, policy)
+}
+
+// LifecyclePolicyFromContext returns the policy set with WithLifecyclePolicy.
+func LifecyclePolicyFromContext(ctx context.Context) LifecyclePolicy {
+	policy, _ := ctx.Value(lifecyclePolicyKey{}).(LifecyclePolicy)
+	return policy
+}
+
+// IsZero returns whether the policy neither reports nor rejects anything.
+func (policy LifecyclePolicy) IsZero() bool {
+	return policy.Tracker == nil && !policy.RejectExperimental
+}
+
+// check returns the warnings to report for the given validated operation and
+// the IDs passed to it as arguments, or an error if either uses a field
+// rejected by the policy.
+func (policy LifecyclePolicy) check(ctx context.Context, s *Server, doc *ast.QueryDocument, operationName string, ids []*call.ID) ([]LifecycleWarning, error) {
+	if policy.IsZero() {
+		return nil, nil
+	}
+	var warnings []LifecycleWarning
+	for _, op := range doc.Operations {
+		if operationName != "" && op.Name != operationName {
+			continue
+		}
+		warnings = appendLifecycleWarnings(warnings, op.SelectionSet, map[string]bool{})
+	}
+	// loading an ID evaluates the calls it was built from, so they're used
+	// by the operation too
+	schema := s.Schema()
+	s.idCalls(ids, func(id *call.ID, receiverType string) {
+		def := schema.Types[receiverType]
+		if def == nil {
+			return
+		}
+		fieldDef := def.Fields.ForName(id.Field())
+		if fieldDef == nil {
+			return
+		}
+		warnings = appendDirectiveWarnings(warnings, fieldDef.Directives, receiverType, id.Field(), "")
+		for _, arg := range id.Args() {
+			argDef := fieldDef.Arguments.ForName(arg.Name())
+			if argDef == nil {
+				continue
+			}
+			warnings = appendDirectiveWarnings(warnings, argDef.Directives, receiverType, id.Field(), arg.Name())
+		}
+	})
+	if policy.RejectExperimental {
+		for _, w := range warnings {
+			if w.Kind == LifecycleExperimental {
+				return nil, fmt.Errorf("%s, and experimental APIs are disabled by the engine", w)
+			}
+		}
+	}
+	if policy.Tracker == nil {
+		return nil, nil
+	}
+	return policy.Tracker.firstUse(ctx, warnings), nil
+}
+
+// appendLifecycleWarnings appends a warning for each deprecated or
+// experimental field or argument in the given selections.
+func appendLifecycleWarnings(warnings []LifecycleWarning, sels ast.SelectionSet, visitedFragments map[string]bool) []LifecycleWarning {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Definition != nil && sel.ObjectDefinition != nil {
+				typeName := sel.ObjectDefinition.Name
+				warnings = appendDirectiveWarnings(warnings, sel.Definition.Directives, typeName, sel.Name, "")
+				for _, arg := range sel.Arguments {
+					argDef := sel.Definition.Arguments.ForName(arg.Name)
+					if argDef == nil {
+						continue
+					}
+					warnings = appendDirectiveWarnings(warnings, argDef.Directives, typeName, sel.Name, arg.Name)
+				}
+			}
+			warnings = appendLifecycleWarnings(warnings, sel.SelectionSet, visitedFragments)
+		case *ast.InlineFragment:
+			warnings = appendLifecycleWarnings(warnings, sel.SelectionSet, visitedFragments)
+		case *ast.FragmentSpread:
+			if sel.Definition == nil || visitedFragments[sel.Name] {
+				continue
+			}
+			visitedFragments[sel.Name] = true
+			warnings = appendLifecycleWarnings(warnings, sel.Definition.SelectionSet, visitedFragments)
+		}
+	}
+	return warnings
+}
+
+func appendDirectiveWarnings(warnings []LifecycleWarning, directives ast.DirectiveList, typeName, field, arg string) []LifecycleWarning {
+	for _, kind := range []LifecycleKind{LifecycleDeprecated, LifecycleExperimental} {
+		directive := directives.ForName(string(kind))
+		if directive == nil {
+			continue
+		}
+		w := LifecycleWarning{
+			Kind:  kind,
+			Type:  typeName,
+			Field: field,
+			Arg:   arg,
+		}
+		if reason := directive.Arguments.ForName("reason"); reason != nil && reason.Value != nil {
+			w.Reason = reason.Value.Raw
+		}
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
@@ -274,9 +274,14 @@ func (n Nullable[T]) MarshalJSON() ([]byte, error) {
 }
 
 func (n *Nullable[T]) UnmarshalJSON(p []byte) error {
+	if string(p) == "null" {
+		*n = Nullable[T]{}
+		return nil
+	}
 	if err := json.Unmarshal(p, &n.Value); err != nil {
 		return err
 	}
+	n.Valid = true
 	return nil
 }
 
@@ -453,13 +453,14 @@ func (r ObjectResult[T]) Select(ctx context.Context, s *Server, sel Selector) (A
 	if err != nil {
 		return nil, err
 	}
-	return r.call(ctx, s, preselectResult.newID, preselectResult.inputArgs, preselectResult.doNotCache)
+	return r.call(ctx, s, preselectResult.newID, preselectResult.inputArgs, preselectResult.doNotCache, preselectResult.persistAs)
 }
 
 type preselectResult struct {
 	inputArgs  map[string]Input
 	newID      *call.ID
 	doNotCache bool
+	persistAs  Persistable
 }
 
 // sortArgsToSchema sorts the arguments to match the schema definition order.
@@ -583,12 +584,16 @@ func (r ObjectResult[T]) preselect(ctx context.Context, s *Server, sel Selector)
 		if cacheCfg.Digest != origDgst {
 			newID = newID.WithDigest(cacheCfg.Digest)
 		}
+		if cacheCfg.Scoped {
+			s.Cache.markScoped(newID.Digest())
+		}
 	}
 
 	return &preselectResult{
 		inputArgs:  inputArgs,
 		newID:      newID,
 		doNotCache: doNotCache,
+		persistAs:  field.persistAs(s, newID),
 	}, nil
 }
 
@@ -607,7 +612,7 @@ func (r ObjectResult[T]) Call(ctx context.Context, s *Server, newID *call.ID) (A
 	}
 
 	doNotCache := field.CacheSpec.DoNotCache != ""
-	return r.call(ctx, s, newID, inputArgs, doNotCache)
+	return r.call(ctx, s, newID, inputArgs, doNotCache, field.persistAs(s, newID))
 }
 
 func ExtractIDArgs(specs InputSpecs, id *call.ID) (map[string]Input, error) {
@@ -652,6 +657,7 @@ func (r ObjectResult[T]) call(
 	newID *call.ID,
 	inputArgs map[string]Input,
 	doNotCache bool,
+	persistAs Persistable,
 ) (AnyResult, error) {
 	ctx = idToContext(ctx, newID)
 	ctx = srvToContext(ctx, s)
@@ -666,6 +672,9 @@ func (r ObjectResult[T]) call(
 			return s.telemetry(ctx, r, newID)
 		}))
 	}
+	if persistAs != nil && !doNotCache {
+		opts = append(opts, WithPersistence(newID, persistAs))
+	}
 	res, err := s.Cache.GetOrInitializeWithCallbacks(ctx, callCacheKey, true, func(ctx context.Context) (*CacheValWithCallbacks, error) {
 		valWithCallbacks, err := r.class.Call(ctx, s, r, newID.Field(), newID.View(), inputArgs)
 		if err != nil {
@@ -867,6 +876,11 @@ func NodeFuncWithCacheKey[T Typed, A any, R any](
 		},
 	}
 
+	if _, ok := spec.Type.(AnyStream); ok {
+		// each subscription gets its own stream of values
+		field.CacheSpec.DoNotCache = "streams values to subscriptions"
+	}
+
 	if cacheFn != nil {
 		field.CacheSpec.GetCacheConfig = func(ctx context.Context, self AnyResult, argVals map[string]Input, view call.View, baseCfg CacheConfig) (*CacheConfig, error) {
 			if argsErr != nil {
@@ -910,6 +924,9 @@ type FieldSpec struct {
 	Module *call.Module
 	// Directives is the list of GraphQL directives attached to this field.
 	Directives []*ast.Directive
+	// Cost is the relative cost of selecting the field, used to estimate the
+	// cost of queries. Defaults to DefaultFieldCost.
+	Cost int
 
 	// ViewFilter is filter that specifies under which views this field is
 	// accessible. If not view is present, the default is the "global" view.
@@ -936,9 +953,22 @@ func (spec FieldSpec) FieldDefinition(view call.View) *ast.FieldDefinition {
 	if spec.ExperimentalReason != "" {
 		def.Directives = append(def.Directives, experimental(spec.ExperimentalReason))
 	}
+	if spec.Cost != 0 {
+		def.Directives = append(def.Directives, cost(spec.Cost))
+	}
+	if _, ok := spec.Type.(AnyStream); ok {
+		def.Directives = append(def.Directives, stream())
+	}
 	return def
 }
 
+func (spec FieldSpec) cost() int {
+	if spec.Cost != 0 {
+		return spec.Cost
+	}
+	return DefaultFieldCost
+}
+
 // InputSpec specifies a field argument, or an input field.
 type InputSpec struct {
 	// Name is the name of the argument.
@@ -1244,6 +1274,12 @@ type CacheSpec struct {
 	// If set, the result of this field will never be cached and not have concurrent equal
 	// calls deduped. The string value is a reason why the field should not be cached.
 	DoNotCache string
+
+	// If set, the result of this field is also stored in the persistent cache (if
+	// any) so that it survives engine restarts. Only fields whose result is
+	// fully determined by their ID and whose type is Persistable may be
+	// persisted.
+	Persist bool
 }
 
 type GenericGetCacheConfigFunc func(context.Context, AnyResult, map[string]Input, call.View, CacheConfig) (*CacheConfig, error)
@@ -1255,6 +1291,11 @@ type GetCacheConfigFunc[T Typed, A any] func(context.Context, ObjectResult[T], A
 type CacheConfig struct {
 	Digest      digest.Digest
 	UpdatedArgs map[string]Input
+
+	// Scoped is set when the digest is scoped to the calling client or
+	// session, or to the single call, so that the result and anything derived
+	// from it are never persisted.
+	Scoped bool
 }
 
 // Field defines a field of an Object type.
@@ -1289,6 +1330,42 @@ func (field Field[T]) DoNotCache(reason string, paras ...string) Field[T] {
 	return field
 }
 
+// Persist marks the field's result to be stored in the persistent cache, so it
+// can be reused across engine restarts.
+func (field Field[T]) Persist() Field[T] {
+	if field.Spec.extend {
+		panic("cannot call on extended field")
+	}
+	if !isPersistable(field.Spec.Type) {
+		panic(fmt.Sprintf("field %q: cannot persist values of type %s", field.Spec.Name, field.Spec.Type.Type()))
+	}
+	field.CacheSpec.Persist = true
+	return field
+}
+
+// persistAs returns the type to persist the result of a call to the field as,
+// or nil if it shouldn't be persisted, including when the call was derived from
+// one whose cache key is scoped to a client, session or single call.
+func (field Field[T]) persistAs(s *Server, id *call.ID) Persistable {
+	if !field.CacheSpec.Persist || field.CacheSpec.DoNotCache != "" || id.Nth() != 0 {
+		return nil
+	}
+	if s.Cache != nil && s.Cache.isScoped(id) {
+		return nil
+	}
+	return field.Spec.Type.(Persistable)
+}
+
+// Cost sets the relative cost of selecting the field, used to estimate the
+// cost of queries before executing them.
+func (field Field[T]) Cost(weight int) Field[T] {
+	if field.Spec.extend {
+		panic("cannot call on extended field")
+	}
+	field.Spec.Cost = weight
+	return field
+}
+
 // Doc sets the description of the field. Each argument is joined by two empty
 // lines.
 func (field Field[T]) Doc(paras ...string) Field[T] {
@@ -0,0 +1,186 @@
+package dagql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/engine/slog"
+)
+
+// PersistentCache stores encoded results across engine restarts, keyed by the
+// digest of the call that produced them.
+type PersistentCache interface {
+	Get(ctx context.Context, key CacheKeyType) (typeName string, data []byte, ok bool, err error)
+	Put(ctx context.Context, key CacheKeyType, typeName string, data []byte) error
+}
+
+// Persistable is a type whose values can be stored in a PersistentCache.
+type Persistable interface {
+	Typed
+
+	// EncodePersisted encodes the value for storage.
+	EncodePersisted() ([]byte, error)
+
+	// DecodePersisted decodes a value previously encoded with EncodePersisted
+	// into a result for the given ID.
+	DecodePersisted(id *call.ID, data []byte) (AnyResult, error)
+}
+
+// isPersistable returns whether values of the given type can be persisted,
+// taking list element types into account.
+func isPersistable(typ Typed) bool {
+	if _, ok := typ.(Persistable); !ok {
+		return false
+	}
+	if enum, ok := typ.(Enumerable); ok {
+		return isPersistable(enum.Element())
+	}
+	return true
+}
+
+// WithPersistentCache returns a copy of the session cache that also loads and
+// stores the results of persisted fields in the given persistent cache.
+func (c *SessionCache) WithPersistentCache(p PersistentCache) *SessionCache {
+	return &SessionCache{
+		cache:      c.cache,
+		persistent: p,
+	}
+}
+
+// WithPersistence persists the result of the call as a value of the given
+// type with the given ID, if the session cache has a persistent cache.
+func WithPersistence(id *call.ID, typ Persistable) CacheCallOpt {
+	return CacheCallOptFunc(func(opts *CacheCallOpts) {
+		opts.PersistID = id
+		opts.PersistType = typ
+	})
+}
+
+// markScoped records that the cache key of the call with the given digest is
+// scoped to a client, session or single call.
+func (c *SessionCache) markScoped(dgst digest.Digest) {
+	c.scopedDigests.Store(dgst, struct{}{})
+}
+
+// isScoped returns whether the ID, or any ID it was derived from, has a cache
+// key scoped to a client, session or single call. Persisting such a result
+// would be useless at best, since no other client could ever hit it, and
+// would store data that belongs to a single client at worst.
+func (c *SessionCache) isScoped(id *call.ID) bool {
+	walker, err := WalkID(id, false)
+	if err != nil {
+		return true
+	}
+	for dgst := range walker.memo {
+		if _, ok := c.scopedDigests.Load(dgst); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// persisting wraps fn to first look for the result in the persistent cache,
+// and otherwise store the result there once computed.
+func (c *SessionCache) persisting(
+	key CacheKeyType,
+	o CacheCallOpts,
+	fn func(context.Context) (*CacheValWithCallbacks, error),
+) func(context.Context) (*CacheValWithCallbacks, error) {
+	if c.persistent == nil || o.PersistType == nil || key == "" {
+		return fn
+	}
+	typeName := o.PersistType.Type().String()
+	return func(ctx context.Context) (*CacheValWithCallbacks, error) {
+		if val, err := c.loadPersisted(ctx, key, typeName, o); err != nil {
+			slog.Warn("failed to load persisted result", "key", key.String(), "error", err)
+		} else if val != nil {
+			return &CacheValWithCallbacks{Value: val}, nil
+		}
+
+		res, err := fn(ctx)
+		if err != nil || res == nil || res.Value == nil {
+			return res, err
+		}
+		if res.PostCall != nil || res.OnRelease != nil {
+			// the result depends on more than its value; keep it in memory only
+			return res, nil
+		}
+		if err := c.storePersisted(ctx, key, typeName, res.Value); err != nil {
+			slog.Warn("failed to persist result", "key", key.String(), "error", err)
+		}
+		return res, nil
+	}
+}
+
+func (c *SessionCache) loadPersisted(ctx context.Context, key CacheKeyType, typeName string, o CacheCallOpts) (AnyResult, error) {
+	storedType, data, ok, err := c.persistent.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if storedType != typeName {
+		// the field's type changed since it was stored; recompute it
+		return nil, nil
+	}
+	return o.PersistType.DecodePersisted(o.PersistID, data)
+}
+
+func (c *SessionCache) storePersisted(ctx context.Context, key CacheKeyType, typeName string, val AnyResult) error {
+	persistable, ok := val.Unwrap().(Persistable)
+	if !ok {
+		return fmt.Errorf("%T cannot be persisted", val.Unwrap())
+	}
+	data, err := persistable.EncodePersisted()
+	if err != nil {
+		return err
+	}
+	return c.persistent.Put(ctx, key, typeName, data)
+}
+
+// GetOrInitializePersisted returns the value stored under key in the
+// persistent cache of the session cache, or computes it with fn and stores it
+// there. Values are stored as JSON along with typeName, which must change
+// whenever their encoding does. Without a persistent cache, it just calls fn.
+//
+// This is for values that are expensive to compute but aren't the result of a
+// single field, and so can't use Persist. The key must be fully determined by
+// the inputs of fn.
+func GetOrInitializePersisted[T any](
+	ctx context.Context,
+	c *SessionCache,
+	key CacheKeyType,
+	typeName string,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	if c == nil || c.persistent == nil {
+		return fn(ctx)
+	}
+
+	storedType, data, ok, err := c.persistent.Get(ctx, key)
+	if err != nil {
+		slog.Warn("failed to load persisted value", "key", key.String(), "error", err)
+	} else if ok && storedType == typeName {
+		var v T
+		err := json.Unmarshal(data, &v)
+		if err == nil {
+			return v, nil
+		}
+		slog.Warn("failed to decode persisted value", "key", key.String(), "error", err)
+	}
+
+	v, err := fn(ctx)
+	if err != nil {
+		return v, err
+	}
+	data, err = json.Marshal(v)
+	if err == nil {
+		err = c.persistent.Put(ctx, key, typeName, data)
+	}
+	if err != nil {
+		slog.Warn("failed to persist value", "key", key.String(), "error", err)
+	}
+	return v, nil
+}
@@ -7,11 +7,17 @@ import (
 	"fmt"
 	"reflect"
 	"runtime/debug"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/errcode"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/iancoleman/strcase"
 	"github.com/opencontainers/go-digest"
 	"github.com/sourcegraph/conc/pool"
@@ -160,8 +166,28 @@ func (s *Server) invalidateSchemaCache() {
 }
 
 func NewDefaultHandler(es graphql.ExecutableSchema) *handler.Server {
-	// TODO: avoid this deprecated method, and customize the options
-	return handler.NewDefaultServer(es) //nolint: staticcheck
+	srv := handler.New(es)
+
+	// SSE must come before POST, which would otherwise handle its requests
+	srv.AddTransport(transport.SSE{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: persistedQueryCache{lru.New[string](100)},
+	})
+
+	return srv
 }
 
 var coreScalars = []ScalarType{
@@ -219,6 +245,32 @@ var coreDirectives = []DirectiveSpec{
 			DirectiveLocationEnumValue,
 		},
 	},
+	{
+		Name: "cost",
+		Description: FormatDescription(
+			`Indicates the relative cost of selecting a field, used to estimate the
+			cost of queries before executing them.`),
+		Args: NewInputSpecs(
+			InputSpec{
+				Name:        "weight",
+				Description: FormatDescription(`The cost of selecting the field, not including its sub-selections.`),
+				Type:        Int(0),
+				Default:     Int(DefaultFieldCost),
+			},
+		),
+		Locations: []DirectiveLocation{
+			DirectiveLocationFieldDefinition,
+		},
+	},
+	{
+		Name: "stream",
+		Description: FormatDescription(
+			`Indicates that a field streams its values as they are produced. It
+			can only be selected in a subscription.`),
+		Locations: []DirectiveLocation{
+			DirectiveLocationFieldDefinition,
+		},
+	},
 	{
 		Name:        "sourceMap",
 		Description: FormatDescription(`Indicates the source information for where a given field is defined.`),
@@ -419,24 +471,60 @@ func (s *Server) Schema() *ast.Schema {
 			Types:         make(map[string]*ast.Definition),
 			PossibleTypes: make(map[string][]*ast.Definition),
 		}
+		subscription := &ast.Definition{
+			Kind:        ast.Object,
+			Name:        subscriptionType,
+			Description: "Subscriptions to fields that stream their values as they are produced.",
+		}
 		for _, t := range s.objects { // TODO stable order
 			def := definition(ast.Object, t, view)
 			if def.Name == queryType {
 				schema.Query = def
 			}
+			// stream fields can only be selected from the subscription type
+			def.Fields = slices.DeleteFunc(def.Fields, func(field *ast.FieldDefinition) bool {
+				if field.Directives.ForName("stream") == nil {
+					return false
+				}
+				if subField, ok := subscriptionFieldDefinition(t, field); ok {
+					subscription.Fields = append(subscription.Fields, subField)
+				}
+				return true
+			})
 			schema.AddTypes(def)
 			schema.AddPossibleType(def.Name, def)
 		}
+		if len(subscription.Fields) > 0 {
+			sort.Slice(subscription.Fields, func(i, j int) bool {
+				return subscription.Fields[i].Name < subscription.Fields[j].Name
+			})
+			schema.Subscription = subscription
+			schema.AddTypes(subscription)
+			schema.AddPossibleType(subscription.Name, subscription)
+		}
 		for _, t := range s.scalars {
 			def := definition(ast.Scalar, t, view)
 			schema.AddTypes(def)
 			schema.AddPossibleType(def.Name, def)
 		}
+		var unions []*ast.Definition
 		for _, t := range s.typeDefs {
 			def := t.TypeDefinition(view)
 			schema.AddTypes(def)
+			if def.Kind == ast.Union {
+				// the possible types of a union are its members
+				unions = append(unions, def)
+				continue
+			}
 			schema.AddPossibleType(def.Name, def)
 		}
+		for _, def := range unions {
+			for _, member := range def.Types {
+				if memberDef, ok := schema.Types[member]; ok {
+					schema.AddPossibleType(def.Name, memberDef)
+				}
+			}
+		}
 		schema.Directives = map[string]*ast.DirectiveDefinition{}
 		for n, d := range s.directives {
 			schema.Directives[n] = d.DirectiveDefinition(view)
@@ -458,10 +546,18 @@ func (s *Server) SchemaDigest() digest.Digest {
 	return s.schemaDigests[s.View]
 }
 
-// Complexity returns the complexity of the given field.
+// Complexity returns the complexity of the given field, which is its cost
+// plus the complexity of its sub-selections.
 func (s *Server) Complexity(ctx context.Context, typeName, field string, childComplexity int, args map[string]any) (int, bool) {
-	// TODO
-	return 1, false
+	class, ok := s.ObjectType(typeName)
+	if !ok {
+		return DefaultFieldCost + childComplexity, false
+	}
+	spec, ok := class.FieldSpec(field, s.View)
+	if !ok {
+		return DefaultFieldCost + childComplexity, false
+	}
+	return spec.cost() + childComplexity, true
 }
 
 // ExtendedError is an error that can provide extra data in an error response.
@@ -472,6 +568,15 @@ type ExtendedError interface {
 
 // Exec implements graphql.ExecutableSchema.
 func (s *Server) Exec(ctx1 context.Context) graphql.ResponseHandler {
+	if gqlOp := graphql.GetOperationContext(ctx1); gqlOp.Operation != nil && gqlOp.Operation.Operation == ast.Subscription {
+		if err := gqlOp.Validate(ctx1); err != nil {
+			return graphql.OneShot(graphql.ErrorResponse(ctx1, "validate: %s", err))
+		}
+		if _, err := s.checkOperation(ctx1, gqlOp); err != nil {
+			return graphql.OneShot(&graphql.Response{Errors: gqlErrs(err)})
+		}
+		return s.execSubscription(ctx1, gqlOp)
+	}
 	return func(ctx context.Context) (res *graphql.Response) {
 		gqlOp := graphql.GetOperationContext(ctx)
 
@@ -479,6 +584,14 @@ func (s *Server) Exec(ctx1 context.Context) graphql.ResponseHandler {
 			return graphql.ErrorResponse(ctx, "validate: %s", err)
 		}
 
+		warnings, err := s.checkOperation(ctx, gqlOp)
+		if err != nil {
+			return &graphql.Response{Errors: gqlErrs(err)}
+		}
+		if len(warnings) > 0 {
+			graphql.RegisterExtension(ctx, "warnings", warnings)
+		}
+
 		results, err := s.ExecOp(ctx, gqlOp)
 		if err != nil {
 			return &graphql.Response{
@@ -497,6 +610,57 @@ func (s *Server) Exec(ctx1 context.Context) graphql.ResponseHandler {
 	}
 }
 
+// checkOperation rejects operations disallowed by the allowlist, exceeding
+// the cost limits, or using fields rejected by the lifecycle policy of the
+// context, before any work begins. It returns the lifecycle warnings to report
+// to the client.
+//
+// It only applies to operations received from clients; queries made by the
+// engine itself with Query or ExecOp are not restricted.
+func (s *Server) checkOperation(ctx context.Context, gqlOp *graphql.OperationContext) ([]LifecycleWarning, error) {
+	allowlist := OperationAllowlistFromContext(ctx)
+	limits := CostLimitsFromContext(ctx)
+	policy := LifecyclePolicyFromContext(ctx)
+
+	// IDs passed as arguments are checked too, since loading them evaluates
+	// the calls they were built from
+	var ids []*call.ID
+	if allowlist.Enforced() || !limits.IsZero() || !policy.IsZero() {
+		var err error
+		ids, err = s.operationIDArgs(gqlOp)
+		if err != nil {
+			return nil, operationRejectedErr(err)
+		}
+	}
+
+	if allowlist != nil {
+		if err := allowlist.Check(gqlOp.RawQuery); err != nil {
+			return nil, operationRejectedErr(err)
+		}
+		if err := allowlist.checkIDArgs(s, ids); err != nil {
+			return nil, operationRejectedErr(err)
+		}
+	}
+	if !limits.IsZero() {
+		if err := s.estimateOperationCost(gqlOp, ids).Check(limits); err != nil {
+			return nil, operationRejectedErr(err)
+		}
+	}
+	warnings, err := policy.check(ctx, s, gqlOp.Doc, gqlOp.OperationName, ids)
+	if err != nil {
+		return nil, operationRejectedErr(err)
+	}
+	return warnings, nil
+}
+
+// operationRejectedErr marks err as a validation failure, as the operation
+// was rejected before executing it.
+func operationRejectedErr(err error) error {
+	gqlErr := gqlerror.Wrap(err)
+	errcode.Set(gqlErr, errcode.ValidationFailed)
+	return gqlErr
+}
+
 func gqlErrs(err error) (errs gqlerror.List) {
 	if list, ok := err.(gqlerror.List); ok {
 		return list
@@ -512,21 +676,8 @@ func gqlErrs(err error) (errs gqlerror.List) {
 }
 
 func (s *Server) ExecOp(ctx context.Context, gqlOp *graphql.OperationContext) (map[string]any, error) {
-	if gqlOp.Doc == nil {
-		var err error
-		gqlOp.Doc, err = parser.ParseQuery(&ast.Source{Input: gqlOp.RawQuery})
-		if err != nil {
-			return nil, gqlErrs(err)
-		}
-
-		//nolint:staticcheck // annoying, but we can't easily switch to this without inconsistencies
-		listErr := validator.Validate(s.Schema(), gqlOp.Doc)
-		if len(listErr) != 0 {
-			for _, e := range listErr {
-				errcode.Set(e, errcode.ValidationFailed)
-			}
-			return nil, listErr
-		}
+	if err := s.parseDoc(gqlOp); err != nil {
+		return nil, err
 	}
 	results := make(map[string]any)
 	for _, op := range gqlOp.Doc.Operations {
@@ -547,13 +698,38 @@ func (s *Server) ExecOp(ctx context.Context, gqlOp *graphql.OperationContext) (m
 			// TODO
 			return nil, fmt.Errorf("mutations not supported")
 		case ast.Subscription:
-			// TODO
-			return nil, fmt.Errorf("subscriptions not supported")
+			if gqlOp.OperationName != "" && gqlOp.OperationName != op.Name {
+				continue
+			}
+			return nil, fmt.Errorf("subscriptions must be executed with Subscribe")
 		}
 	}
 	return results, nil
 }
 
+// parseDoc parses and validates the operation's query, if it hasn't been
+// already.
+func (s *Server) parseDoc(gqlOp *graphql.OperationContext) error {
+	if gqlOp.Doc != nil {
+		return nil
+	}
+	var err error
+	gqlOp.Doc, err = parser.ParseQuery(&ast.Source{Input: gqlOp.RawQuery})
+	if err != nil {
+		return gqlErrs(err)
+	}
+
+	//nolint:staticcheck // annoying, but we can't easily switch to this without inconsistencies
+	listErr := validator.Validate(s.Schema(), gqlOp.Doc)
+	if len(listErr) != 0 {
+		for _, e := range listErr {
+			errcode.Set(e, errcode.ValidationFailed)
+		}
+		return listErr
+	}
+	return nil
+}
+
 // Resolve resolves the given selections on the given object.
 //
 // Each selection is resolved in parallel, and the results are returned in a
@@ -561,8 +737,13 @@ func (s *Server) ExecOp(ctx context.Context, gqlOp *graphql.OperationContext) (m
 func (s *Server) Resolve(ctx context.Context, self AnyObjectResult, sels ...Selection) (map[string]any, error) {
 	results := new(sync.Map)
 
+	typeName := self.ObjectType().TypeName()
 	pool := pool.New().WithErrors()
 	for _, sel := range sels {
+		if sel.TypeCondition != "" && sel.TypeCondition != typeName {
+			// selected in a fragment on another member of a union
+			continue
+		}
 		pool.Go(func() error {
 			res, err := s.resolvePath(ctx, self, sel)
 			if err != nil {
@@ -713,8 +894,8 @@ func (s *Server) Select(ctx context.Context, self AnyObjectResult, dest any, sel
 				}
 			}
 			return nil
-		} else if s.isObjectType(res.Type().Name()) {
-			// if the result is an Object, set it as the next selection target, and
+		} else if s.isObjectType(res.Type().Name()) || s.isUnionType(res.Type().Name()) {
+			// if the result is an Object (or a union of them), set it as the next selection target, and
 			// assign res to the "hydrated" Object
 			self, err = s.toSelectable(res)
 			if err != nil {
@@ -736,6 +917,11 @@ func (s *Server) isObjectType(typeName string) bool {
 	return ok
 }
 
+func (s *Server) isUnionType(typeName string) bool {
+	_, ok := s.Union(typeName)
+	return ok
+}
+
 // Attach an install hook
 func (s *Server) AddInstallHook(hook InstallHook) {
 	s.installLock.Lock()
@@ -926,6 +1112,10 @@ func (s *Server) resolvePath(ctx context.Context, self AnyObjectResult, sel Sele
 		}
 	}()
 
+	if sel.Selector.Field == typenameField {
+		return self.ObjectType().TypeName(), nil
+	}
+
 	if sel.Selector.Nth != 0 {
 		// NOTE: this is explicitly not handled - but it's fine because
 		// resolvePath is called from selectors from field parsing, so we
@@ -943,6 +1133,10 @@ func (s *Server) resolvePath(ctx context.Context, self AnyObjectResult, sel Sele
 		return nil, nil
 	}
 
+	if _, ok := val.Unwrap().(AnyStream); ok {
+		return nil, errNotSubscription
+	}
+
 	enum, ok := val.Unwrap().(Enumerable)
 	if ok {
 		// we're sub-selecting into an enumerable value, so we need to resolve each
@@ -997,6 +1191,14 @@ func (s *Server) toSelectable(val AnyResult) (AnyObjectResult, error) {
 		return sel, nil
 	}
 
+	// union values hold an object with its own ID
+	if union, ok := UnwrapAs[UnionValue](val); ok {
+		if union.Value == nil {
+			return nil, fmt.Errorf("toSelectable: union %s has no value", union.Union)
+		}
+		return union.Value, nil
+	}
+
 	className := val.Type().Name()
 	class, ok := s.ObjectType(className)
 	if ok {
@@ -1028,7 +1230,8 @@ func (s *Server) parseASTSelections(ctx context.Context, gqlOp *graphql.Operatio
 	vars := gqlOp.Variables
 
 	class := s.objects[self.Name()]
-	if class == nil {
+	union, isUnion := s.Union(self.Name())
+	if class == nil && !isUnion {
 		return nil, fmt.Errorf("parseASTSelections: not an Object type: %q", self.Name())
 	}
 
@@ -1036,6 +1239,16 @@ func (s *Server) parseASTSelections(ctx context.Context, gqlOp *graphql.Operatio
 	for _, sel := range astSels {
 		switch x := sel.(type) {
 		case *ast.Field:
+			if x.Name == typenameField {
+				sels = append(sels, Selection{
+					Alias:    x.Alias,
+					Selector: Selector{Field: typenameField},
+				})
+				continue
+			}
+			if class == nil {
+				return nil, fmt.Errorf("cannot select field %q on union %q; select it in a fragment on a member type", x.Name, self.Name())
+			}
 			sel, resType, err := class.ParseField(ctx, s.View, x, vars)
 			if err != nil {
 				return nil, fmt.Errorf("parse field %q: %w", x.Name, err)
@@ -1058,13 +1271,17 @@ func (s *Server) parseASTSelections(ctx context.Context, gqlOp *graphql.Operatio
 			if fragment == nil {
 				return nil, fmt.Errorf("unknown fragment: %s", x.Name)
 			}
-			if len(fragment.SelectionSet) > 0 {
-				subsels, err := s.parseASTSelections(ctx, gqlOp, self, fragment.SelectionSet)
-				if err != nil {
-					return nil, err
-				}
-				sels = append(sels, subsels...)
+			subsels, err := s.parseFragmentSelections(ctx, gqlOp, self, union, fragment.TypeCondition, fragment.SelectionSet)
+			if err != nil {
+				return nil, err
+			}
+			sels = append(sels, subsels...)
+		case *ast.InlineFragment:
+			subsels, err := s.parseFragmentSelections(ctx, gqlOp, self, union, x.TypeCondition, x.SelectionSet)
+			if err != nil {
+				return nil, err
 			}
+			sels = append(sels, subsels...)
 		default:
 			return nil, fmt.Errorf("unknown field type: %T", x)
 		}
@@ -1073,11 +1290,45 @@ func (s *Server) parseASTSelections(ctx context.Context, gqlOp *graphql.Operatio
 	return sels, nil
 }
 
+// parseFragmentSelections parses the selections of a fragment on the given
+// type. Fragments on the members of a union only apply to values of that
+// member type.
+func (s *Server) parseFragmentSelections(
+	ctx context.Context,
+	gqlOp *graphql.OperationContext,
+	self *ast.Type,
+	union UnionSpec,
+	typeCondition string,
+	astSels ast.SelectionSet,
+) ([]Selection, error) {
+	if len(astSels) == 0 {
+		return nil, nil
+	}
+	if union.Name == "" || typeCondition == "" || typeCondition == union.Name {
+		return s.parseASTSelections(ctx, gqlOp, self, astSels)
+	}
+	if !union.HasMember(typeCondition) {
+		return nil, fmt.Errorf("%s is not a member of union %s", typeCondition, union.Name)
+	}
+	sels, err := s.parseASTSelections(ctx, gqlOp, &ast.Type{NamedType: typeCondition, NonNull: true}, astSels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sels {
+		sels[i].TypeCondition = typeCondition
+	}
+	return sels, nil
+}
+
 // Selection represents a selection of a field on an object.
 type Selection struct {
 	Alias         string
 	Selector      Selector
 	Subselections []Selection
+
+	// TypeCondition is the object type the selection applies to, for
+	// selections made in a fragment on a member of a union.
+	TypeCondition string
 }
 
 // Name returns the name of the selection, which is either the alias or the
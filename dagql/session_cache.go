@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine/cache"
 	"github.com/dagger/dagger/engine/slog"
 	"github.com/opencontainers/go-digest"
@@ -28,6 +29,15 @@ type SessionCache struct {
 	isClosed bool
 
 	seenKeys sync.Map
+
+	// persistent, if set, stores the results of persisted fields across
+	// engine restarts.
+	persistent PersistentCache
+
+	// scopedDigests holds the digests of calls whose cache key was scoped to
+	// a client, session or single call, so that results derived from them are
+	// never persisted.
+	scopedDigests sync.Map
 }
 
 func NewSessionCache(
@@ -44,6 +54,11 @@ type CacheCallOpt interface {
 
 type CacheCallOpts struct {
 	Telemetry TelemetryFunc
+
+	// PersistID and PersistType, if set, are used to load and store the result
+	// in the persistent cache.
+	PersistID   *call.ID
+	PersistType Persistable
 }
 
 type TelemetryFunc func(context.Context) (context.Context, func(AnyResult, bool, error))
@@ -160,7 +175,7 @@ func (c *SessionCache) GetOrInitializeWithCallbacks(
 		ctx = telemetryCtx
 	}
 
-	res, err = c.cache.GetOrInitializeWithCallbacks(ctx, key, skipDedupe, fn)
+	res, err = c.cache.GetOrInitializeWithCallbacks(ctx, key, skipDedupe, c.persisting(key, o, fn))
 	if err != nil {
 		return nil, err
 	}
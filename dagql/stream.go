@@ -0,0 +1,299 @@
+package dagql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"unicode"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// Stream is a sequence of values produced over time, delivered to clients
+// through subscriptions.
+//
+// A field returning a Stream has the type of its values in the schema, and can
+// only be selected in a subscription operation. Streams are never cached.
+type Stream[T Typed] struct {
+	seq iter.Seq2[T, error]
+}
+
+// NewStream returns a Stream yielding the values of the given sequence. The
+// sequence should stop when its context is canceled.
+func NewStream[T Typed](seq iter.Seq2[T, error]) Stream[T] {
+	return Stream[T]{seq: seq}
+}
+
+var _ AnyStream = Stream[String]{}
+
+func (Stream[T]) Type() *ast.Type {
+	var zero T
+	return zero.Type()
+}
+
+func (Stream[T]) Element() Typed {
+	var zero T
+	return zero
+}
+
+func (s Stream[T]) Values() iter.Seq2[Typed, error] {
+	return func(yield func(Typed, error) bool) {
+		if s.seq == nil {
+			return
+		}
+		for val, err := range s.seq {
+			if !yield(val, err) {
+				return
+			}
+		}
+	}
+}
+
+// AnyStream is a Stream of any type.
+type AnyStream interface {
+	Typed
+
+	// Element returns the type of the values of the stream.
+	Element() Typed
+
+	// Values returns the values of the stream as they are produced.
+	Values() iter.Seq2[Typed, error]
+}
+
+// errNotSubscription is returned when a stream is selected outside of a
+// subscription.
+var errNotSubscription = errors.New("streaming fields can only be selected in a subscription")
+
+// subscriptionType is the name of the root type of subscriptions.
+const subscriptionType = "Subscription"
+
+// subscriptionFieldDefinition returns the field of the subscription type for a
+// stream field of the given object type. The field is named after the type
+// and the stream field (e.g. containerStdoutStream), and takes the ID of the
+// object to subscribe to, named after the type, before the stream field's own
+// arguments.
+func subscriptionFieldDefinition(class ObjectType, field *ast.FieldDefinition) (*ast.FieldDefinition, bool) {
+	idType, ok := class.IDType()
+	if !ok {
+		return nil, false
+	}
+	idArg := lowerTypeName(class.TypeName())
+	if field.Arguments.ForName(idArg) != nil {
+		return nil, false
+	}
+	args := ast.ArgumentDefinitionList{
+		{
+			Name:        idArg,
+			Description: fmt.Sprintf("The %s to subscribe to.", class.TypeName()),
+			Type:        idType.Type(),
+		},
+	}
+	return &ast.FieldDefinition{
+		Name:        subscriptionFieldName(class.TypeName(), field.Name),
+		Description: field.Description,
+		Arguments:   append(args, field.Arguments...),
+		Type:        field.Type,
+		Directives:  field.Directives,
+	}, true
+}
+
+// lowerTypeName lower-cases the leading word or acronym of a type name, so
+// Container becomes container and LLM becomes llm.
+func lowerTypeName(typeName string) string {
+	runes := []rune(typeName)
+	for i, r := range runes {
+		if !unicode.IsUpper(r) {
+			if i > 1 {
+				// keep the start of the next word, e.g. HTTPService -> httpService
+				runes[i-1] = unicode.ToUpper(runes[i-1])
+			}
+			break
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+func subscriptionFieldName(typeName, fieldName string) string {
+	return lowerTypeName(typeName) + strings.ToUpper(fieldName[:1]) + fieldName[1:]
+}
+
+// subscriptionField returns the object type and stream field that the given
+// field of the subscription type subscribes to.
+func (s *Server) subscriptionField(name string) (ObjectType, FieldSpec, bool) {
+	for typeName, class := range s.objects {
+		prefix := lowerTypeName(typeName)
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		fieldName := strings.ToLower(rest[:1]) + rest[1:]
+		spec, ok := class.FieldSpec(fieldName, s.View)
+		if !ok {
+			continue
+		}
+		if _, ok := spec.Type.(AnyStream); !ok {
+			continue
+		}
+		if subscriptionFieldName(typeName, spec.Name) == name {
+			return class, spec, true
+		}
+	}
+	return nil, FieldSpec{}, false
+}
+
+// Subscribe executes the subscription operation of the given query, calling fn
+// with the results each time its stream produces a value, until the stream
+// ends, fn returns an error, or ctx is canceled.
+//
+// A subscription selects a single field of the subscription type, which
+// subscribes to a stream field of the object whose ID it's given.
+func (s *Server) Subscribe(ctx context.Context, gqlOp *graphql.OperationContext, fn func(map[string]any) error) error {
+	if err := s.parseDoc(gqlOp); err != nil {
+		return err
+	}
+	var op *ast.OperationDefinition
+	for _, o := range gqlOp.Doc.Operations {
+		if gqlOp.OperationName != "" && gqlOp.OperationName != o.Name {
+			continue
+		}
+		op = o
+		break
+	}
+	if op == nil || op.Operation != ast.Subscription {
+		return fmt.Errorf("no subscription operation found")
+	}
+	if len(op.SelectionSet) != 1 {
+		return fmt.Errorf("subscriptions must select exactly one field")
+	}
+	astField, ok := op.SelectionSet[0].(*ast.Field)
+	if !ok {
+		return fmt.Errorf("subscriptions must select exactly one field")
+	}
+	class, spec, ok := s.subscriptionField(astField.Name)
+	if !ok {
+		return fmt.Errorf("%s has no such field: %q", subscriptionType, astField.Name)
+	}
+
+	// load the object to subscribe to, and select the stream field on it with
+	// the rest of the arguments
+	idArg := lowerTypeName(class.TypeName())
+	streamField := *astField
+	streamField.Name = spec.Name
+	streamField.Arguments = nil
+	var id *call.ID
+	for _, arg := range astField.Arguments {
+		if arg.Name != idArg {
+			streamField.Arguments = append(streamField.Arguments, arg)
+			continue
+		}
+		val, err := arg.Value.Value(gqlOp.Variables)
+		if err != nil {
+			return err
+		}
+		idType, _ := class.IDType()
+		input, err := idType.Decoder().DecodeInput(val)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", idArg, err)
+		}
+		id = input.(IDable).ID()
+	}
+	if id == nil {
+		return fmt.Errorf("%s: missing argument %q", astField.Name, idArg)
+	}
+	self, err := s.Load(ctx, id)
+	if err != nil {
+		return gqlErrs(err)
+	}
+	sels, err := s.parseASTSelections(ctx, gqlOp, class.Typed().Type(), ast.SelectionSet{&streamField})
+	if err != nil {
+		return fmt.Errorf("subscription:\n%s\n\nerror: parse selections: %w", gqlOp.RawQuery, err)
+	}
+	sel := sels[0]
+
+	streamRes, err := self.Select(ctx, s, sel.Selector)
+	if err != nil {
+		return gqlErrs(gqlErr(err, append(idToPath(self.ID()), ast.PathName(sel.Name()))))
+	}
+	stream, ok := streamRes.Unwrap().(AnyStream)
+	if !ok {
+		return fmt.Errorf("%s: not a stream", astField.Name)
+	}
+
+	nth := 0
+	for val, err := range stream.Values() {
+		if err != nil {
+			return gqlErrs(gqlErr(err, idToPath(streamRes.ID())))
+		}
+		nth++
+		res, err := s.resolveStreamValue(ctx, streamRes, nth, val, sel)
+		if err != nil {
+			return gqlErrs(err)
+		}
+		if err := fn(map[string]any{sel.Name(): res}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveStreamValue resolves the sub-selections of the nth value of a stream.
+func (s *Server) resolveStreamValue(ctx context.Context, stream AnyResult, nth int, val Typed, sel Selection) (any, error) {
+	if len(sel.Subselections) == 0 {
+		return val, nil
+	}
+	res, err := NewResultForID(val, stream.ID().SelectNth(nth))
+	if err != nil {
+		return nil, err
+	}
+	node, err := s.toSelectable(res)
+	if err != nil {
+		return nil, fmt.Errorf("instantiate %dth stream value: %w", nth, err)
+	}
+	return s.Resolve(ctx, node, sel.Subselections...)
+}
+
+// execSubscription returns a response handler yielding a response for each
+// result of the subscription, followed by a response for the error that ended
+// it, if any.
+func (s *Server) execSubscription(ctx context.Context, gqlOp *graphql.OperationContext) graphql.ResponseHandler {
+	responses := make(chan *graphql.Response)
+	go func() {
+		defer close(responses)
+		send := func(res *graphql.Response) error {
+			select {
+			case responses <- res:
+				return nil
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+		}
+		err := s.Subscribe(ctx, gqlOp, func(results map[string]any) error {
+			data, err := json.Marshal(results)
+			if err != nil {
+				return fmt.Errorf("marshal: %w", err)
+			}
+			return send(&graphql.Response{Data: json.RawMessage(data)})
+		})
+		if err != nil && ctx.Err() == nil {
+			send(&graphql.Response{Errors: gqlErrs(err)})
+		}
+	}()
+	return func(ctx context.Context) *graphql.Response {
+		select {
+		case res, ok := <-responses:
+			if !ok {
+				return nil
+			}
+			return res
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
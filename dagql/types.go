@@ -286,6 +286,20 @@ func (i *Int) UnmarshalJSON(p []byte) error {
 	return nil
 }
 
+var _ Persistable = Int(0)
+
+func (i Int) EncodePersisted() ([]byte, error) {
+	return json.Marshal(i)
+}
+
+func (Int) DecodePersisted(id *call.ID, data []byte) (AnyResult, error) {
+	var i Int
+	if err := json.Unmarshal(data, &i); err != nil {
+		return nil, err
+	}
+	return NewResultForID(i, id)
+}
+
 var _ Setter = Int(0)
 
 func (i Int) SetField(v reflect.Value) error {
@@ -377,6 +391,20 @@ func (f *Float) UnmarshalJSON(p []byte) error {
 	return nil
 }
 
+var _ Persistable = Float(0)
+
+func (f Float) EncodePersisted() ([]byte, error) {
+	return json.Marshal(f)
+}
+
+func (Float) DecodePersisted(id *call.ID, data []byte) (AnyResult, error) {
+	var f Float
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return NewResultForID(f, id)
+}
+
 var _ Setter = Float(0)
 
 func (f Float) SetField(v reflect.Value) error {
@@ -463,6 +491,20 @@ func (b *Boolean) UnmarshalJSON(p []byte) error {
 	return nil
 }
 
+var _ Persistable = Boolean(false)
+
+func (b Boolean) EncodePersisted() ([]byte, error) {
+	return json.Marshal(b)
+}
+
+func (Boolean) DecodePersisted(id *call.ID, data []byte) (AnyResult, error) {
+	var b Boolean
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return NewResultForID(b, id)
+}
+
 var _ Setter = Boolean(false)
 
 func (b Boolean) SetField(v reflect.Value) error {
@@ -542,6 +584,20 @@ func (s String) String() string {
 	return string(s)
 }
 
+var _ Persistable = String("")
+
+func (s String) EncodePersisted() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+func (String) DecodePersisted(id *call.ID, data []byte) (AnyResult, error) {
+	var s String
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return NewResultForID(s, id)
+}
+
 var _ Setter = String("")
 
 func (s String) SetField(v reflect.Value) error {
@@ -1055,6 +1111,51 @@ func (arr Array[T]) NthValue(i int, enumID *call.ID) (AnyResult, error) {
 	}, nil
 }
 
+var _ Persistable = Array[Typed]{}
+
+// EncodePersisted encodes the array as a JSON list of its encoded elements. It
+// fails if the element type isn't Persistable.
+func (arr Array[T]) EncodePersisted() ([]byte, error) {
+	elems := make([]json.RawMessage, len(arr))
+	for i, elem := range arr {
+		persistable, ok := any(elem).(Persistable)
+		if !ok {
+			return nil, fmt.Errorf("cannot persist element of type %T", elem)
+		}
+		data, err := persistable.EncodePersisted()
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = data
+	}
+	return json.Marshal(elems)
+}
+
+func (arr Array[T]) DecodePersisted(id *call.ID, data []byte) (AnyResult, error) {
+	var zero T
+	persistable, ok := any(zero).(Persistable)
+	if !ok {
+		return nil, fmt.Errorf("cannot decode persisted element of type %T", zero)
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return nil, err
+	}
+	decoded := make(Array[T], len(elems))
+	for i, elem := range elems {
+		res, err := persistable.DecodePersisted(id.SelectNth(i+1), elem)
+		if err != nil {
+			return nil, err
+		}
+		t, ok := res.Unwrap().(T)
+		if !ok {
+			return nil, fmt.Errorf("decoded %T, expected %T", res.Unwrap(), zero)
+		}
+		decoded[i] = t
+	}
+	return NewResultForID(decoded, id)
+}
+
 type ResultArray[T Typed] []Result[T]
 
 var _ Typed = ResultArray[Typed]{}
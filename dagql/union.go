@@ -0,0 +1,121 @@
+package dagql
+
+import (
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// typenameField is the meta field selecting the name of an object's concrete
+// type, which is available on every object and union.
+const typenameField = "__typename"
+
+// UnionSpec is a GraphQL union: a type whose values are objects of one of its
+// member types.
+//
+// The members of a union are selected with inline fragments, e.g.
+// `... on Container { id }`, and the concrete type of a value is selected with
+// `__typename`.
+type UnionSpec struct {
+	Name        string
+	Description string
+	// Members are the names of the object types in the union.
+	Members []string
+}
+
+var _ TypeDef = UnionSpec{}
+
+// Install installs the union into the server.
+func (spec UnionSpec) Install(srv *Server) {
+	srv.InstallUnion(spec)
+}
+
+func (spec UnionSpec) TypeName() string {
+	return spec.Name
+}
+
+func (spec UnionSpec) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: spec.Name,
+		NonNull:   true,
+	}
+}
+
+func (spec UnionSpec) TypeDescription() string {
+	return spec.Description
+}
+
+func (spec UnionSpec) TypeDefinition(view call.View) *ast.Definition {
+	return &ast.Definition{
+		Kind:        ast.Union,
+		Name:        spec.Name,
+		Description: spec.Description,
+		Types:       spec.Members,
+	}
+}
+
+// HasMember returns true if the given object type is a member of the union.
+func (spec UnionSpec) HasMember(typeName string) bool {
+	for _, member := range spec.Members {
+		if member == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// InstallUnion installs the given union type into the schema. Its members
+// must be installed as objects before the schema is served.
+func (s *Server) InstallUnion(spec UnionSpec) {
+	s.InstallTypeDef(spec)
+}
+
+// Union returns the union type with the given name, if it exists.
+func (s *Server) Union(name string) (UnionSpec, bool) {
+	def, ok := s.TypeDef(name)
+	if !ok {
+		return UnionSpec{}, false
+	}
+	spec, ok := def.(UnionSpec)
+	return spec, ok
+}
+
+// UnionValue is a value of a union type: an object of one of the union's
+// members.
+type UnionValue struct {
+	// Union is the name of the union type.
+	Union string
+	// Value is the object, which has its own ID.
+	Value AnyObjectResult
+}
+
+var _ InterfaceValue = UnionValue{}
+
+// NewUnionValue returns a value of the given union, checking that the object
+// is one of its members.
+func NewUnionValue(union UnionSpec, val AnyObjectResult) (UnionValue, error) {
+	if !union.HasMember(val.Type().Name()) {
+		return UnionValue{}, fmt.Errorf("%s is not a member of union %s", val.Type().Name(), union.Name)
+	}
+	return UnionValue{
+		Union: union.Name,
+		Value: val,
+	}, nil
+}
+
+func (u UnionValue) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: u.Union,
+		NonNull:   true,
+	}
+}
+
+// UnderlyingObject returns the object the union value holds.
+func (u UnionValue) UnderlyingObject() (Typed, error) {
+	if u.Value == nil {
+		return nil, fmt.Errorf("union %s has no value", u.Union)
+	}
+	return u.Value.Unwrap(), nil
+}
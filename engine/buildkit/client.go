@@ -266,6 +266,15 @@ func RunInNetNS[T any](
 	return runInNetNS(ctx, runState, fn)
 }
 
+// SubscribeExecStdout returns a subscription to the stdout of execs started by
+// the call with the given digest in the given session, as they run.
+func (c *Client) SubscribeExecStdout(sessionID string, callDigest digest.Digest) *ExecOutputSubscription {
+	return c.Worker.execStdout.subscribe(execOutputKey{
+		SessionID:  sessionID,
+		CallDigest: callDigest,
+	})
+}
+
 // CombinedResult returns a buildkit result with all the refs solved by this client so far.
 // This is useful for constructing a result for upstream remote caching.
 func (c *Client) CombinedResult(ctx context.Context) (*Result, error) {
@@ -0,0 +1,122 @@
+package buildkit
+
+import (
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// execOutputs fans out the stdout of running execs to subscribers, keyed by
+// the session and the digest of the call that started the exec.
+type execOutputs struct {
+	subs map[execOutputKey]map[*ExecOutputSubscription]struct{}
+	mu   sync.Mutex
+}
+
+// execOutputKey identifies the execs of a call within a session, so that
+// clients only see the output of execs started by their own session.
+type execOutputKey struct {
+	SessionID  string
+	CallDigest digest.Digest
+}
+
+func newExecOutputs() *execOutputs {
+	return &execOutputs{
+		subs: map[execOutputKey]map[*ExecOutputSubscription]struct{}{},
+	}
+}
+
+func (o *execOutputs) subscribe(key execOutputKey) *ExecOutputSubscription {
+	sub := &ExecOutputSubscription{
+		ready: make(chan struct{}, 1),
+	}
+	sub.close = func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		delete(o.subs[key], sub)
+		if len(o.subs[key]) == 0 {
+			delete(o.subs, key)
+		}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.subs[key] == nil {
+		o.subs[key] = map[*ExecOutputSubscription]struct{}{}
+	}
+	o.subs[key][sub] = struct{}{}
+	return sub
+}
+
+// writer returns a writer publishing to the subscribers of the given call at
+// the time of each write.
+func (o *execOutputs) writer(key execOutputKey) execOutputWriter {
+	return execOutputWriter{outputs: o, key: key}
+}
+
+type execOutputWriter struct {
+	outputs *execOutputs
+	key     execOutputKey
+}
+
+func (w execOutputWriter) Write(p []byte) (int, error) {
+	w.outputs.mu.Lock()
+	defer w.outputs.mu.Unlock()
+	for sub := range w.outputs.subs[w.key] {
+		sub.publish(p)
+	}
+	return len(p), nil
+}
+
+// maxExecOutputBuffer is the most output buffered for a subscriber that
+// hasn't taken it yet.
+const maxExecOutputBuffer = 1 << 20
+
+// ExecOutputSubscription receives the output of an exec as it runs.
+//
+// Slow subscribers never block the exec: output is buffered up to
+// maxExecOutputBuffer, and output received past that is dropped and counted
+// as lost until the subscriber takes the buffer.
+type ExecOutputSubscription struct {
+	buf   []byte
+	lost  int
+	ready chan struct{}
+	close func()
+	mu    sync.Mutex
+}
+
+func (sub *ExecOutputSubscription) publish(p []byte) {
+	sub.mu.Lock()
+	if sub.lost > 0 || len(sub.buf)+len(p) > maxExecOutputBuffer {
+		// once output is lost, drop everything until the buffer is taken, so
+		// that it never has a gap in the middle
+		sub.lost += len(p)
+	} else {
+		sub.buf = append(sub.buf, p...)
+	}
+	sub.mu.Unlock()
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready returns a channel that receives a value whenever there is new output to
+// Take.
+func (sub *ExecOutputSubscription) Ready() <-chan struct{} {
+	return sub.ready
+}
+
+// Take returns the output received since it was last called, followed by the
+// number of bytes of output dropped after it because the buffer was full.
+func (sub *ExecOutputSubscription) Take() (buf []byte, lost int) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	buf, lost = sub.buf, sub.lost
+	sub.buf, sub.lost = nil, 0
+	return buf, lost
+}
+
+// Close stops receiving output.
+func (sub *ExecOutputSubscription) Close() {
+	sub.close()
+}
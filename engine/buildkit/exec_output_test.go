@@ -0,0 +1,65 @@
+package buildkit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecOutputsScopedBySession(t *testing.T) {
+	outputs := newExecOutputs()
+	callDigest := digest.FromString("withExec")
+
+	key1 := execOutputKey{SessionID: "session1", CallDigest: callDigest}
+	sub1 := outputs.subscribe(key1)
+	sub2 := outputs.subscribe(execOutputKey{SessionID: "session2", CallDigest: callDigest})
+	defer sub2.Close()
+
+	w := outputs.writer(key1)
+	_, err := w.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	out, lost := sub1.Take()
+	require.Equal(t, "hello", string(out))
+	require.Zero(t, lost)
+	out, _ = sub2.Take()
+	require.Empty(t, out)
+
+	// closed subscriptions stop receiving output
+	sub1.Close()
+	_, err = w.Write([]byte("world"))
+	require.NoError(t, err)
+	out, _ = sub1.Take()
+	require.Empty(t, out)
+	require.NotContains(t, outputs.subs, key1)
+}
+
+func TestExecOutputsBufferLimit(t *testing.T) {
+	outputs := newExecOutputs()
+	key := execOutputKey{SessionID: "session", CallDigest: digest.FromString("withExec")}
+	sub := outputs.subscribe(key)
+	defer sub.Close()
+
+	w := outputs.writer(key)
+	chunk := bytes.Repeat([]byte("x"), maxExecOutputBuffer/2)
+	for range 3 {
+		_, err := w.Write(chunk)
+		require.NoError(t, err)
+	}
+	// output past the limit is dropped, and so is anything after it until
+	// the buffer is taken
+	_, err := w.Write([]byte("y"))
+	require.NoError(t, err)
+
+	out, lost := sub.Take()
+	require.Len(t, out, maxExecOutputBuffer)
+	require.Equal(t, len(chunk)+1, lost)
+
+	_, err = w.Write([]byte("z"))
+	require.NoError(t, err)
+	out, lost = sub.Take()
+	require.Equal(t, "z", string(out))
+	require.Zero(t, lost)
+}
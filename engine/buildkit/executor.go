@@ -100,6 +100,10 @@ type ExecutionMetadata struct {
 	// If true, skip injecting dagger-init into the container.
 	NoInit bool
 
+	// If true, run the container in a user namespace, mapping root in the
+	// container to an unprivileged user on the host.
+	UserNamespace bool
+
 	// list of remote modules allowed to access LLM APIs
 	// any value of "all" bypasses restrictions, a nil slice imposes them
 	AllowedLLMModules []string
@@ -169,6 +173,9 @@ func (w *Worker) Run(
 	if err := w.validateEntitlements(procInfo.Meta); err != nil {
 		return nil, err
 	}
+	if w.useUserNamespace() && procInfo.Meta.SecurityMode == pb.SecurityMode_INSECURE {
+		return nil, fmt.Errorf("insecureRootCapabilities cannot be used in a user namespace")
+	}
 
 	state := newExecState(id, &procInfo, rootMount, mounts, started)
 	return nil, w.run(ctx, state,
@@ -187,6 +194,7 @@ func (w *Worker) Run(
 		w.createCWD,
 		w.setupNestedClient,
 		w.installCACerts,
+		w.setupUserNamespace,
 		w.runContainer,
 	)
 }
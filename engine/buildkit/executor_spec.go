@@ -578,6 +578,12 @@ func (w *Worker) setupStdio(_ context.Context, state *execState) error {
 	state.cleanups.Add("close container stdout file", stdoutFile.Close)
 	stdoutWriters = append(stdoutWriters, stdoutFile)
 	stdoutWriters = append(stdoutWriters, combinedOutputFile)
+	if w.execMD != nil && w.execMD.CallID != nil {
+		stdoutWriters = append(stdoutWriters, w.execStdout.writer(execOutputKey{
+			SessionID:  w.execMD.SessionID,
+			CallDigest: w.execMD.CallID.Digest(),
+		}))
+	}
 
 	var stderrWriters []io.Writer
 	if state.procInfo.Stderr != nil {
@@ -1087,6 +1093,85 @@ func (w *Worker) installCACerts(ctx context.Context, state *execState) error {
 	return nil
 }
 
+// useUserNamespace returns whether the container should run in a user
+// namespace, either because the engine forces it or the exec requested it.
+func (w *Worker) useUserNamespace() bool {
+	return w.userNamespaces || (w.execMD != nil && w.execMD.UserNamespace)
+}
+
+// setupUserNamespace runs the container in a new user namespace, mapping
+// root in the container to an unprivileged user on the host.
+//
+// Rather than chowning the rootfs and mounts to the remapped IDs, they're
+// idmapped into the user namespace, so files keep the same ownership on disk
+// as they would without a user namespace and the cache can be shared between
+// both modes. This must run after everything has been mounted to the rootfs,
+// since the idmapped rootfs is a copy of the mount tree at this point.
+func (w *Worker) setupUserNamespace(_ context.Context, state *execState) error {
+	if !w.useUserNamespace() {
+		return nil
+	}
+
+	if state.spec.Linux == nil {
+		state.spec.Linux = &specs.Linux{}
+	}
+	state.spec.Linux.UIDMappings = specIDMappings(w.userNamespaceMapping.UIDMaps)
+	state.spec.Linux.GIDMappings = specIDMappings(w.userNamespaceMapping.GIDMaps)
+	state.spec.Linux.Namespaces = append(state.spec.Linux.Namespaces, specs.LinuxNamespace{
+		Type: specs.UserNamespace,
+	})
+
+	userns, err := w.userNamespace()
+	if err != nil {
+		return fmt.Errorf("create user namespace: %w", err)
+	}
+
+	idmappedRootfsPath, err := os.MkdirTemp("", "rootfs-idmapped")
+	if err != nil {
+		return fmt.Errorf("create idmapped rootfs temp dir: %w", err)
+	}
+	state.cleanups.Add("remove idmapped rootfs temp dir", func() error {
+		return os.RemoveAll(idmappedRootfsPath)
+	})
+	if err := idmapMount(state.rootfsPath, idmappedRootfsPath, userns); err != nil {
+		return fmt.Errorf("idmap rootfs: %w", err)
+	}
+	state.cleanups.Add("unmount idmapped rootfs", func() error {
+		return unmountRecursive(idmappedRootfsPath)
+	})
+	state.spec.Root.Path = idmappedRootfsPath
+
+	return nil
+}
+
+// userNamespace returns a user namespace with the worker's ID mapping, to
+// idmap mounts into. It's only used as a reference to the mapping, so the
+// same one is shared by every exec rather than created for each.
+func (w *Worker) userNamespace() (*os.File, error) {
+	w.userNamespaceMu.Lock()
+	defer w.userNamespaceMu.Unlock()
+	if w.userNamespaceFile == nil {
+		userns, err := newUserNamespace(w.userNamespaceMapping)
+		if err != nil {
+			return nil, err
+		}
+		w.userNamespaceFile = userns
+	}
+	return w.userNamespaceFile, nil
+}
+
+func specIDMappings(idmaps []idtools.IDMap) []specs.LinuxIDMapping {
+	mappings := make([]specs.LinuxIDMapping, 0, len(idmaps))
+	for _, idmap := range idmaps {
+		mappings = append(mappings, specs.LinuxIDMapping{
+			ContainerID: uint32(idmap.ContainerID),
+			HostID:      uint32(idmap.HostID),
+			Size:        uint32(idmap.Size),
+		})
+	}
+	return mappings
+}
+
 func (w *Worker) runContainer(ctx context.Context, state *execState) (rerr error) {
 	bundle := filepath.Join(w.executorRoot, state.id)
 	if err := os.Mkdir(bundle, 0o711); err != nil {
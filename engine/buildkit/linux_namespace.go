@@ -7,10 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/dagger/dagger/util/cleanups"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/sourcegraph/conc/pool"
@@ -208,3 +211,87 @@ func (nsw *namespaceWorker) run(ctx context.Context, jobQueue <-chan func()) err
 		}
 	}
 }
+
+// newUserNamespace creates a user namespace with the given ID mapping,
+// returning a file referring to it that can be used to idmap mounts.
+//
+// The namespace is created by starting a process in it that's stopped by
+// ptrace as soon as it execs, so it never actually runs; it's killed once the
+// namespace file has been opened, which keeps the namespace alive on its own.
+func newUserNamespace(idmap idtools.IdentityMapping) (*os.File, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// ptrace requests must come from the thread that started the tracee
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	cmd := exec.Command(self)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  unix.CLONE_NEWUSER,
+		UidMappings: sysProcIDMappings(idmap.UIDMaps),
+		GidMappings: sysProcIDMappings(idmap.GIDMaps),
+		Ptrace:      true,
+		Pdeathsig:   unix.SIGKILL,
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start user namespace process: %w", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	f, err := os.Open(fmt.Sprintf("/proc/%d/ns/user", cmd.Process.Pid))
+	if err != nil {
+		return nil, fmt.Errorf("open user namespace: %w", err)
+	}
+	return f, nil
+}
+
+func sysProcIDMappings(idmaps []idtools.IDMap) []syscall.SysProcIDMap {
+	mappings := make([]syscall.SysProcIDMap, 0, len(idmaps))
+	for _, idmap := range idmaps {
+		mappings = append(mappings, syscall.SysProcIDMap{
+			ContainerID: idmap.ContainerID,
+			HostID:      idmap.HostID,
+			Size:        idmap.Size,
+		})
+	}
+	return mappings
+}
+
+// idmapMount recursively bind mounts src to dst, idmapped into the given user
+// namespace: a file owned by ID N on disk is seen as owned by ID N from
+// inside the user namespace.
+func idmapMount(src, dst string, userns *os.File) error {
+	fd, err := unix.OpenTree(unix.AT_FDCWD, src, unix.OPEN_TREE_CLONE|unix.OPEN_TREE_CLOEXEC|unix.AT_RECURSIVE)
+	if err != nil {
+		return fmt.Errorf("open tree %s: %w", src, err)
+	}
+	defer unix.Close(fd)
+
+	err = unix.MountSetattr(fd, "", unix.AT_EMPTY_PATH|unix.AT_RECURSIVE, &unix.MountAttr{
+		Attr_set:  unix.MOUNT_ATTR_IDMAP,
+		Userns_fd: uint64(userns.Fd()),
+	})
+	if err != nil {
+		return fmt.Errorf("set idmap on %s (requires kernel support for idmapped mounts on all mounted filesystems): %w", src, err)
+	}
+
+	if err := unix.MoveMount(fd, "", unix.AT_FDCWD, dst, unix.MOVE_MOUNT_F_EMPTY_PATH); err != nil {
+		return fmt.Errorf("move mount to %s: %w", dst, err)
+	}
+	return nil
+}
+
+// unmountRecursive lazily unmounts the mount at the given path along with
+// every mount below it.
+func unmountRecursive(path string) error {
+	if err := unix.Unmount(path, unix.MNT_DETACH); err != nil && !errors.Is(err, unix.EINVAL) {
+		return fmt.Errorf("unmount %s: %w", path, err)
+	}
+	return nil
+}
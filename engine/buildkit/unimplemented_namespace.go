@@ -6,6 +6,8 @@ import (
 	"context"
 	"os"
 	"time"
+
+	"github.com/docker/docker/pkg/idtools"
 )
 
 const (
@@ -51,3 +53,15 @@ func (nsw *namespaceWorker) leaveContainer() error {
 func (nsw *namespaceWorker) run(ctx context.Context, jobQueue <-chan func()) error {
 	panic("implemented only on linux")
 }
+
+func newUserNamespace(idmap idtools.IdentityMapping) (*os.File, error) {
+	panic("implemented only on linux")
+}
+
+func idmapMount(src, dst string, userns *os.File) error {
+	panic("implemented only on linux")
+}
+
+func unmountRecursive(path string) error {
+	panic("implemented only on linux")
+}
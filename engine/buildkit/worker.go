@@ -3,6 +3,7 @@ package buildkit
 import (
 	"context"
 	"net/http"
+	"os"
 	"sync"
 
 	runc "github.com/containerd/go-runc"
@@ -61,8 +62,21 @@ type sharedWorkerState struct {
 	parallelismSem   *semaphore.Weighted
 	workerCache      bkcache.Manager
 
+	// userNamespaces forces every exec to run in a user namespace, rather
+	// than only the ones that request it
+	userNamespaces       bool
+	userNamespaceMapping idtools.IdentityMapping
+
+	// userNamespaceFile refers to a user namespace with userNamespaceMapping,
+	// created the first time it's needed and kept for the worker's lifetime
+	userNamespaceFile *os.File
+	userNamespaceMu   sync.Mutex
+
 	running map[string]*execState
 	mu      sync.RWMutex
+
+	// execStdout streams the stdout of running execs to subscribers
+	execStdout *execOutputs
 }
 
 type sessionHandler interface {
@@ -93,6 +107,11 @@ type NewWorkerOpts struct {
 	NetworkProviders    map[pb.NetMode]network.Provider
 	ParallelismSem      *semaphore.Weighted
 	WorkerCache         bkcache.Manager
+
+	// UserNamespaces forces every exec to run in a user namespace.
+	UserNamespaces bool
+	// UserNamespaceMapping maps IDs in user namespaces to host IDs.
+	UserNamespaceMapping idtools.IdentityMapping
 }
 
 func NewWorker(opts *NewWorkerOpts) *Worker {
@@ -117,7 +136,11 @@ func NewWorker(opts *NewWorkerOpts) *Worker {
 		parallelismSem:   opts.ParallelismSem,
 		workerCache:      opts.WorkerCache,
 
-		running: make(map[string]*execState),
+		userNamespaces:       opts.UserNamespaces,
+		userNamespaceMapping: opts.UserNamespaceMapping,
+
+		running:    make(map[string]*execState),
+		execStdout: newExecOutputs(),
 	}}
 }
 
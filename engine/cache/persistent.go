@@ -0,0 +1,345 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// persistentStoreVersion is bumped whenever the format of persisted
+	// entries changes; stores written with another version are wiped on open.
+	persistentStoreVersion = "2"
+
+	// MaxPersistedEntrySize is the maximum size of a single persisted entry;
+	// larger values are only cached in memory.
+	MaxPersistedEntrySize = 1 << 20
+
+	// usedFlushThreshold is the number of entries read since the last flush
+	// above which their access times are written to disk in the background.
+	usedFlushThreshold = 100
+)
+
+var (
+	metaBucket    = []byte("meta")
+	resultsBucket = []byte("results")
+	usedBucket    = []byte("used")
+	versionKey    = []byte("version")
+)
+
+// PersistentStore is an on-disk store of encoded results keyed by digest,
+// used to keep results across engine restarts.
+type PersistentStore struct {
+	db *bolt.DB
+
+	// maxSize is the total size of entries above which the least recently
+	// used entries are pruned on write, 0 for no limit
+	maxSize int64
+
+	now func() time.Time
+
+	// size is the current total size of entries
+	size int64
+	// used are the access times of entries read since they were last written
+	// to disk, so that reads don't need a write transaction
+	used     map[digest.Digest]time.Time
+	flushing sync.WaitGroup
+	mu       sync.Mutex
+}
+
+// persistedEntry is an entry of the results bucket. The time it was last
+// used is kept in the used bucket, so that it can be updated without
+// rewriting the entry.
+type persistedEntry struct {
+	TypeName  string    `json:"type"`
+	Data      []byte    `json:"data"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PersistentStoreUsage summarizes the entries of a PersistentStore.
+type PersistentStoreUsage struct {
+	Entries   int
+	SizeBytes int64
+}
+
+// PersistentStorePruneOpts configures which entries Prune removes.
+type PersistentStorePruneOpts struct {
+	// All removes every entry.
+	All bool
+	// KeepDuration removes entries that haven't been used for longer than
+	// this duration, if set.
+	KeepDuration time.Duration
+	// MaxSize removes the least recently used entries until the store is no
+	// larger than this size, if set.
+	MaxSize int64
+}
+
+// OpenPersistentStore opens (or creates) the store at the given path. If
+// maxSize is non-zero, the least recently used entries are pruned whenever
+// the store grows larger than it.
+func OpenPersistentStore(path string, maxSize int64) (*PersistentStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open persistent store %s: %w", path, err)
+	}
+	s := &PersistentStore{
+		db:      db,
+		maxSize: maxSize,
+		now:     time.Now,
+		used:    map[digest.Digest]time.Time{},
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		if version := meta.Get(versionKey); version != nil && string(version) != persistentStoreVersion {
+			// written by an incompatible engine, start over
+			for _, bucket := range [][]byte{resultsBucket, usedBucket} {
+				if err := tx.DeleteBucket(bucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+					return err
+				}
+			}
+		}
+		if err := meta.Put(versionKey, []byte(persistentStoreVersion)); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(usedBucket); err != nil {
+			return err
+		}
+		results, err := tx.CreateBucketIfNotExists(resultsBucket)
+		if err != nil {
+			return err
+		}
+		return results.ForEach(func(_, v []byte) error {
+			s.size += int64(len(v))
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize persistent store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the type name and encoded data of the entry with the given key,
+// marking it as used.
+func (s *PersistentStore) Get(_ context.Context, key digest.Digest) (string, []byte, bool, error) {
+	var ent *persistedEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(resultsBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		ent = &persistedEntry{}
+		if err := json.Unmarshal(v, ent); err != nil {
+			return fmt.Errorf("decode entry %s: %w", key, err)
+		}
+		return nil
+	})
+	if err != nil || ent == nil {
+		return "", nil, false, err
+	}
+	s.markUsed(key)
+	return ent.TypeName, ent.Data, true, nil
+}
+
+// markUsed records that the entry with the given key was just used, writing
+// the access times to disk in the background once enough have accumulated.
+func (s *PersistentStore) markUsed(key digest.Digest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used[key] = s.now()
+	if len(s.used) < usedFlushThreshold {
+		return
+	}
+	used := s.used
+	s.used = map[digest.Digest]time.Time{}
+	s.flushing.Add(1)
+	go func() {
+		defer s.flushing.Done()
+		// access times are only used to pick which entries to prune, so
+		// losing some isn't worth failing a read over
+		_ = s.writeUsed(used)
+	}()
+}
+
+// flushUsed writes the access times recorded since the last flush to disk.
+func (s *PersistentStore) flushUsed() error {
+	s.mu.Lock()
+	used := s.used
+	s.used = map[digest.Digest]time.Time{}
+	s.mu.Unlock()
+	err := s.writeUsed(used)
+	s.flushing.Wait()
+	return err
+}
+
+func (s *PersistentStore) writeUsed(used map[digest.Digest]time.Time) error {
+	if len(used) == 0 {
+		return nil
+	}
+	return s.db.Batch(func(tx *bolt.Tx) error {
+		results := tx.Bucket(resultsBucket)
+		usedTimes := tx.Bucket(usedBucket)
+		for key, t := range used {
+			if results.Get([]byte(key)) == nil {
+				// pruned in the meantime
+				continue
+			}
+			if err := usedTimes.Put([]byte(key), encodeTime(t)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Put stores the encoded data of a value of the given type under the given
+// key. Values larger than MaxPersistedEntrySize are ignored.
+func (s *PersistentStore) Put(_ context.Context, key digest.Digest, typeName string, data []byte) error {
+	if len(data) > MaxPersistedEntrySize {
+		return nil
+	}
+	now := s.now()
+	v, err := json.Marshal(persistedEntry{
+		TypeName:  typeName,
+		Data:      data,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	var delta int64
+	err = s.db.Batch(func(tx *bolt.Tx) error {
+		results := tx.Bucket(resultsBucket)
+		delta = int64(len(v) - len(results.Get([]byte(key))))
+		if err := results.Put([]byte(key), v); err != nil {
+			return err
+		}
+		return tx.Bucket(usedBucket).Put([]byte(key), encodeTime(now))
+	})
+	if err != nil {
+		return err
+	}
+	s.addSize(delta)
+
+	if s.maxSize > 0 && s.currentSize() > s.maxSize {
+		// prune a little more than needed so this doesn't happen on every write
+		_, err := s.Prune(context.Background(), PersistentStorePruneOpts{MaxSize: s.maxSize * 9 / 10})
+		return err
+	}
+	return nil
+}
+
+// Prune removes entries according to the given options, returning what was
+// removed.
+func (s *PersistentStore) Prune(_ context.Context, opts PersistentStorePruneOpts) (PersistentStoreUsage, error) {
+	var pruned PersistentStoreUsage
+	if err := s.flushUsed(); err != nil {
+		return pruned, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		results := tx.Bucket(resultsBucket)
+		usedTimes := tx.Bucket(usedBucket)
+
+		type entryInfo struct {
+			key      []byte
+			size     int64
+			lastUsed time.Time
+		}
+		var entries []entryInfo
+		var total int64
+		err := results.ForEach(func(k, v []byte) error {
+			entries = append(entries, entryInfo{
+				key:  slices.Clone(k),
+				size: int64(len(v)),
+				// entries without an access time are pruned first
+				lastUsed: decodeTime(usedTimes.Get(k)),
+			})
+			total += int64(len(v))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// least recently used first
+		slices.SortFunc(entries, func(a, b entryInfo) int {
+			return a.lastUsed.Compare(b.lastUsed)
+		})
+
+		now := s.now()
+		for _, ent := range entries {
+			expired := opts.KeepDuration > 0 && now.Sub(ent.lastUsed) > opts.KeepDuration
+			oversized := opts.MaxSize > 0 && total > opts.MaxSize
+			if !opts.All && !expired && !oversized {
+				continue
+			}
+			if err := results.Delete(ent.key); err != nil {
+				return err
+			}
+			if err := usedTimes.Delete(ent.key); err != nil {
+				return err
+			}
+			total -= ent.size
+			pruned.Entries++
+			pruned.SizeBytes += ent.size
+		}
+
+		s.mu.Lock()
+		s.size = total
+		s.mu.Unlock()
+		return nil
+	})
+	return pruned, err
+}
+
+// Usage returns the number of entries in the store and their total size.
+func (s *PersistentStore) Usage() (PersistentStoreUsage, error) {
+	var usage PersistentStoreUsage
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(resultsBucket).ForEach(func(_, v []byte) error {
+			usage.Entries++
+			usage.SizeBytes += int64(len(v))
+			return nil
+		})
+	})
+	return usage, err
+}
+
+func (s *PersistentStore) Close() error {
+	return errors.Join(s.flushUsed(), s.db.Close())
+}
+
+func encodeTime(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano()))
+}
+
+func decodeTime(b []byte) time.Time {
+	if len(b) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+}
+
+func (s *PersistentStore) addSize(delta int64) {
+	s.mu.Lock()
+	s.size += delta
+	s.mu.Unlock()
+}
+
+func (s *PersistentStore) currentSize() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
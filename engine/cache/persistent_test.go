@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestPersistentStoreReopen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "results.db")
+
+	s, err := OpenPersistentStore(path, 0)
+	assert.NilError(t, err)
+	assert.NilError(t, s.Put(ctx, digest.FromString("a"), "String!", []byte(`"hello"`)))
+	assert.NilError(t, s.Close())
+
+	s, err = OpenPersistentStore(path, 0)
+	assert.NilError(t, err)
+	defer s.Close()
+
+	typeName, data, ok, err := s.Get(ctx, digest.FromString("a"))
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+	assert.Equal(t, typeName, "String!")
+	assert.Equal(t, string(data), `"hello"`)
+
+	_, _, ok, err = s.Get(ctx, digest.FromString("b"))
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	usage, err := s.Usage()
+	assert.NilError(t, err)
+	assert.Equal(t, usage.Entries, 1)
+	assert.Equal(t, usage.SizeBytes, s.currentSize())
+}
+
+func TestPersistentStoreSkipsLargeEntries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	s, err := OpenPersistentStore(filepath.Join(t.TempDir(), "results.db"), 0)
+	assert.NilError(t, err)
+	defer s.Close()
+
+	large := []byte(`"` + strings.Repeat("x", MaxPersistedEntrySize) + `"`)
+	assert.NilError(t, s.Put(ctx, digest.FromString("large"), "String!", large))
+
+	_, _, ok, err := s.Get(ctx, digest.FromString("large"))
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+}
+
+func TestPersistentStorePrune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	s, err := OpenPersistentStore(filepath.Join(t.TempDir(), "results.db"), 0)
+	assert.NilError(t, err)
+	defer s.Close()
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NilError(t, s.Put(ctx, digest.FromString(key), "Int!", []byte("1")))
+		now = now.Add(time.Hour)
+	}
+	// using an entry makes it the most recently used
+	_, _, ok, err := s.Get(ctx, digest.FromString("a"))
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+
+	// b was last used 2h ago, c 1h ago, a just now
+	pruned, err := s.Prune(ctx, PersistentStorePruneOpts{KeepDuration: 90 * time.Minute})
+	assert.NilError(t, err)
+	assert.Equal(t, pruned.Entries, 1)
+	_, _, ok, err = s.Get(ctx, digest.FromString("b"))
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	usage, err := s.Usage()
+	assert.NilError(t, err)
+	pruned, err = s.Prune(ctx, PersistentStorePruneOpts{MaxSize: usage.SizeBytes - 1})
+	assert.NilError(t, err)
+	assert.Equal(t, pruned.Entries, 1)
+	_, _, ok, err = s.Get(ctx, digest.FromString("c"))
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	pruned, err = s.Prune(ctx, PersistentStorePruneOpts{All: true})
+	assert.NilError(t, err)
+	assert.Equal(t, pruned.Entries, 1)
+
+	usage, err = s.Usage()
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(usage.Entries, 0))
+	assert.Check(t, is.Equal(s.currentSize(), int64(0)))
+}
+
+func TestPersistentStoreMaxSize(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	s, err := OpenPersistentStore(filepath.Join(t.TempDir(), "results.db"), 1024)
+	assert.NilError(t, err)
+	defer s.Close()
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	value := []byte(`"` + strings.Repeat("x", 100) + `"`)
+	for i := range 100 {
+		assert.NilError(t, s.Put(ctx, digest.FromString(string(rune('a'+i))), "String!", value))
+		now = now.Add(time.Second)
+	}
+
+	usage, err := s.Usage()
+	assert.NilError(t, err)
+	assert.Assert(t, usage.SizeBytes <= 1024, "size %d", usage.SizeBytes)
+	assert.Assert(t, usage.Entries > 0)
+
+	// the most recent entry is kept
+	_, _, ok, err := s.Get(ctx, digest.FromString(string(rune('a'+99))))
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+}
+
+func TestPersistentStoreUsedAcrossReopen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "results.db")
+
+	s, err := OpenPersistentStore(path, 0)
+	assert.NilError(t, err)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	for _, key := range []string{"a", "b"} {
+		assert.NilError(t, s.Put(ctx, digest.FromString(key), "Int!", []byte("1")))
+		now = now.Add(time.Hour)
+	}
+	// reads only record the access time in memory until flushed
+	_, _, ok, err := s.Get(ctx, digest.FromString("a"))
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+	assert.Equal(t, len(s.used), 1)
+	assert.NilError(t, s.Close())
+
+	s, err = OpenPersistentStore(path, 0)
+	assert.NilError(t, err)
+	defer s.Close()
+
+	// a was used after b, so b is pruned first
+	usage, err := s.Usage()
+	assert.NilError(t, err)
+	pruned, err := s.Prune(ctx, PersistentStorePruneOpts{MaxSize: usage.SizeBytes - 1})
+	assert.NilError(t, err)
+	assert.Equal(t, pruned.Entries, 1)
+	_, _, ok, err = s.Get(ctx, digest.FromString("a"))
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+}
+
+func TestPersistentStoreFlushesUsedInBatches(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	s, err := OpenPersistentStore(filepath.Join(t.TempDir(), "results.db"), 0)
+	assert.NilError(t, err)
+	defer s.Close()
+
+	for i := range usedFlushThreshold {
+		key := digest.FromString(strconv.Itoa(i))
+		assert.NilError(t, s.Put(ctx, key, "Int!", []byte("1")))
+		_, _, ok, err := s.Get(ctx, key)
+		assert.NilError(t, err)
+		assert.Assert(t, ok)
+	}
+	s.flushing.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Equal(t, len(s.used), 0)
+}
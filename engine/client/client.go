@@ -26,6 +26,7 @@ import (
 	bkclient "github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/identity"
 	bksession "github.com/moby/buildkit/session"
+	bkauth "github.com/moby/buildkit/session/auth"
 	"github.com/moby/buildkit/session/auth/authprovider"
 	"github.com/moby/buildkit/util/grpcerrors"
 	"github.com/vito/go-sse/sse"
@@ -47,6 +48,7 @@ import (
 	"dagger.io/dagger"
 	"dagger.io/dagger/telemetry"
 	"github.com/dagger/dagger/analytics"
+	registryauth "github.com/dagger/dagger/auth"
 	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/client/drivers"
 	"github.com/dagger/dagger/engine/client/imageload"
@@ -97,12 +99,21 @@ type Params struct {
 
 	AllowedLLMModules []string
 
+	// MaxQueryDepth and MaxQueryCost limit the queries of the session, in
+	// addition to the engine's limits.
+	MaxQueryDepth int
+	MaxQueryCost  int
+
 	PromptHandler prompt.PromptHandler
 
 	Stdin  io.Reader
 	Stdout io.Writer
 
 	ImageLoaderBackend imageload.Backend
+
+	// OnLocalSync, if set, is called with each host path the engine reads
+	// from or writes to through this client, e.g. to watch them for changes.
+	OnLocalSync func(LocalSync)
 }
 
 type Client struct {
@@ -373,13 +384,16 @@ func (c *Client) startSession(ctx context.Context) (rerr error) {
 	clientMetadata := c.clientMetadata()
 	c.internalCtx = engine.ContextWithClientMetadata(c.internalCtx, &clientMetadata)
 
+	dockerCfg := config.LoadDefaultConfigFile(os.Stderr)
 	attachables := []bksession.Attachable{
 		// sockets
 		SocketProvider{EnableHostNetworkAccess: !c.DisableHostRW},
 		// secrets
 		secretprovider.NewSecretProvider(),
 		// registry auth
-		authprovider.NewDockerAuthProvider(config.LoadDefaultConfigFile(os.Stderr), nil),
+		registryauth.NewRegistryAuthProvider().
+			WithCredentialHelpers(registryauth.NewCredentialHelpers(dockerCfg, registryauth.DefaultCredentialHelperTTL)).
+			WithFallback(authprovider.NewDockerAuthProvider(registryauth.WithoutCredentialHelpers(dockerCfg), nil).(bkauth.AuthServer)),
 		// host=>container networking
 		h2c.NewTunnelListenerAttachable(ctx),
 		// terminal
@@ -399,6 +413,7 @@ func (c *Client) startSession(ctx context.Context) (rerr error) {
 		if err != nil {
 			return fmt.Errorf("new filesyncer: %w", err)
 		}
+		filesyncer.onSync = c.Params.OnLocalSync
 		attachables = append(attachables, filesyncer.AsSource(), filesyncer.AsTarget())
 	}
 	if c.Params.PromptHandler != nil {
@@ -1185,6 +1200,9 @@ func (c *Client) clientMetadata() engine.ClientMetadata {
 		InteractiveCommand:        c.InteractiveCommand,
 		SSHAuthSocketPath:         sshAuthSock,
 		AllowedLLMModules:         c.AllowedLLMModules,
+		MaxQueryDepth:             c.MaxQueryDepth,
+		MaxQueryCost:              c.MaxQueryCost,
+		AllowlistToken:            os.Getenv(engine.DaggerAllowlistTokenEnv),
 	}
 }
 
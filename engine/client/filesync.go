@@ -22,6 +22,30 @@ import (
 
 type Filesyncer struct {
 	uid, gid uint32
+
+	// onSync, if set, is called with each host path synced from or to the engine
+	onSync func(LocalSync)
+}
+
+// LocalSync describes a host path that was synced from or to the engine.
+type LocalSync struct {
+	// Path is the absolute path on the host.
+	Path string
+
+	// IncludePatterns, ExcludePatterns and FollowPaths filter a directory
+	// synced to the engine.
+	IncludePatterns []string
+	ExcludePatterns []string
+	FollowPaths     []string
+
+	// Export is true if the engine wrote to the path rather than reading it.
+	Export bool
+}
+
+func (f Filesyncer) observe(sync LocalSync) {
+	if f.onSync != nil {
+		f.onSync(sync)
+	}
 }
 
 func NewFilesyncer() (Filesyncer, error) {
@@ -84,6 +108,8 @@ func (s FilesyncSource) DiffCopy(stream filesync.FileSync_DiffCopyServer) error
 		return stream.SendMsg(stat)
 
 	case opts.ReadSingleFileOnly:
+		Filesyncer(s).observe(LocalSync{Path: absPath})
+
 		// just stream the file bytes to the caller
 		fileContents, err := os.ReadFile(absPath)
 		if err != nil {
@@ -100,6 +126,13 @@ func (s FilesyncSource) DiffCopy(stream filesync.FileSync_DiffCopyServer) error
 
 	default:
 		// otherwise, do the whole directory sync back to the caller
+		Filesyncer(s).observe(LocalSync{
+			Path:            absPath,
+			IncludePatterns: opts.IncludePatterns,
+			ExcludePatterns: opts.ExcludePatterns,
+			FollowPaths:     opts.FollowPaths,
+		})
+
 		fs, err := fsutil.NewFS(absPath)
 		if err != nil {
 			return err
@@ -137,6 +170,7 @@ func (t FilesyncTarget) DiffCopy(stream filesync.FileSend_DiffCopyServer) (rerr
 	if err != nil {
 		return fmt.Errorf("get full root path: %w", err)
 	}
+	Filesyncer(t).observe(LocalSync{Path: absPath, Export: true})
 
 	if !opts.IsFileStream {
 		// we're writing a full directory tree, normal fsutil.Receive is good
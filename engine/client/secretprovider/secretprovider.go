@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/moby/buildkit/session/secrets"
@@ -23,6 +24,16 @@ var resolvers = map[string]SecretResolver{
 	"libsecret": libsecretProvider,
 }
 
+// Schemes returns the schemes of the supported secret providers, sorted.
+func Schemes() []string {
+	schemes := make([]string, 0, len(resolvers))
+	for scheme := range resolvers {
+		schemes = append(schemes, scheme)
+	}
+	slices.Sort(schemes)
+	return schemes
+}
+
 func ResolverForID(id string) (SecretResolver, string, error) {
 	scheme, pathWithQuery, ok := strings.Cut(id, "://")
 	if !ok {
@@ -3,7 +3,9 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/invopop/jsonschema"
 	bkconfig "github.com/moby/buildkit/cmd/buildkitd/config"
@@ -29,6 +31,21 @@ type Config struct {
 
 	// Security allows configuring various security settings for the engine.
 	Security *Security `json:"security,omitempty"`
+
+	// Registries configures how the engine connects to container registries,
+	// keyed by registry host (e.g. "docker.io" or "localhost:5000").
+	Registries map[string]RegistryConfig `json:"registries,omitempty"`
+
+	// ResultCache configures the on-disk cache of API results, which is kept
+	// across engine restarts.
+	ResultCache ResultCacheConfig `json:"resultCache,omitempty"`
+
+	// Limits configures limits on the API queries the engine accepts.
+	Limits LimitsConfig `json:"limits,omitempty"`
+
+	// PersistedQueries registers operations ahead of time, and can restrict
+	// clients to them.
+	PersistedQueries PersistedQueriesConfig `json:"persistedQueries,omitempty"`
 }
 
 type LogLevel string
@@ -137,6 +154,65 @@ func (space *GCSpace) IsUnset() bool {
 		space.SweepSize == DiskSpace{}
 }
 
+type ResultCacheConfig struct {
+	// Enabled controls whether API results are persisted across engine
+	// restarts - it is switched on by default.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MaxSize is the maximum amount of disk space used by persisted results,
+	// above which the least recently used results are removed. Defaults to
+	// 1GB.
+	MaxSize DiskSpace `json:"maxSize,omitempty"`
+
+	// KeepDuration is how long to keep persisted results that haven't been
+	// used. Defaults to 7 days.
+	KeepDuration Duration `json:"keepDuration,omitempty"`
+}
+
+var (
+	DefaultResultCacheMaxSize      = DiskSpace{Bytes: 1e9}
+	DefaultResultCacheKeepDuration = Duration{Duration: 7 * 24 * time.Hour}
+)
+
+type LimitsConfig struct {
+	// MaxQueryDepth is the maximum nesting depth of the selections in a
+	// query. Queries that exceed it are rejected before being executed.
+	// Clients nested in the engine, such as module functions, are not limited.
+	MaxQueryDepth int `json:"maxQueryDepth,omitempty"`
+
+	// MaxQueryCost is the maximum estimated cost of a query, where each
+	// selected field costs 1 (or more for expensive fields), and selections on
+	// lists count 10 times. Queries that exceed it are rejected before being
+	// executed. Clients nested in the engine, such as module functions, are
+	// not limited.
+	MaxQueryCost int `json:"maxQueryCost,omitempty"`
+
+	// RejectExperimentalFields rejects queries from clients that use
+	// experimental fields or arguments, including in the IDs they pass as
+	// arguments, to guarantee only stable APIs are used. Clients nested in the
+	// engine, such as module functions, are not restricted.
+	RejectExperimentalFields bool `json:"rejectExperimentalFields,omitempty"`
+}
+
+type PersistedQueriesConfig struct {
+	// Operations maps the names of persisted operations to their query.
+	Operations map[string]string `json:"operations,omitempty"`
+
+	// Dir is a directory of persisted operations, one per .graphql file, named
+	// after the file.
+	Dir string `json:"dir,omitempty"`
+
+	// Enforce rejects any query from clients connecting to the engine that
+	// doesn't match the shape of a persisted operation. Clients nested in the
+	// engine, such as module functions, are not restricted.
+	Enforce bool `json:"enforce,omitempty"`
+
+	// TrustedTokens are the hex-encoded SHA-256 hashes of the tokens exempting
+	// the clients that present them, through _EXPERIMENTAL_DAGGER_ALLOWLIST_TOKEN,
+	// from the enforced allowlist.
+	TrustedTokens []string `json:"trustedTokens,omitempty"`
+}
+
 type DiskSpace bkconfig.DiskSpace
 
 func (space DiskSpace) MarshalJSON() ([]byte, error) {
@@ -216,4 +292,98 @@ type Security struct {
 	// Disabling this option ensures that dagger build containers do not run as
 	// privileged, and is a basic form of security hardening.
 	InsecureRootCapabilities *bool `json:"insecureRootCapabilities,omitempty"`
+
+	// UserNamespaces controls whether every exec runs in a user namespace, so
+	// that root in the container maps to an unprivileged user on the host -
+	// it is disabled by default, in which case it can still be enabled per
+	// exec with the userNamespace argument of Container.withExec.
+	// Execs in a user namespace cannot use insecureRootCapabilities.
+	UserNamespaces *bool `json:"userNamespaces,omitempty"`
+
+	// UserNamespaceRange is the range of host user and group IDs that IDs in
+	// user namespaces are mapped to - by default, 65536 IDs starting at
+	// 100000.
+	UserNamespaceRange *IDRange `json:"userNamespaceRange,omitempty"`
+}
+
+// DefaultUserNamespaceRange is the range of host IDs that user namespaces
+// are mapped to if not configured, following the /etc/subuid convention.
+var DefaultUserNamespaceRange = IDRange{Start: 100000, Size: 65536}
+
+type IDRange struct {
+	// Start is the first host ID of the range, which root in the container
+	// is mapped to.
+	Start uint32 `json:"start" jsonschema:"minimum=1"`
+
+	// Size is the number of IDs in the range.
+	Size uint32 `json:"size" jsonschema:"minimum=1"`
+}
+
+func (r IDRange) Validate() error {
+	if r.Start == 0 {
+		return fmt.Errorf("start must not be 0, since that would map root in the container to root on the host")
+	}
+	if r.Size == 0 {
+		return fmt.Errorf("size must be at least 1")
+	}
+	if uint64(r.Start)+uint64(r.Size) > math.MaxUint32 {
+		return fmt.Errorf("range %d+%d overflows the ID space", r.Start, r.Size)
+	}
+	return nil
+}
+
+type RegistryConfig struct {
+	// Mirrors are a list of registry hosts (optionally with a path prefix)
+	// to pull images from before falling back to the registry itself. Only
+	// pulls are redirected - pushes always go to the registry itself.
+	Mirrors []string `json:"mirrors,omitempty"`
+
+	// PlainHTTP controls whether the registry is accessed over plain HTTP
+	// instead of HTTPS.
+	PlainHTTP *bool `json:"plainHTTP,omitempty"`
+
+	// Insecure controls whether TLS certificate verification is skipped
+	// when connecting to the registry.
+	Insecure *bool `json:"insecure,omitempty"`
+
+	// CACerts are a list of paths (in the engine's filesystem) to PEM-encoded
+	// CA certificates used to verify the registry's TLS certificate, in
+	// addition to the system CA certificates.
+	CACerts []string `json:"caCerts,omitempty"`
+
+	// ClientCerts are a list of client certificates used to authenticate to
+	// the registry with mutual TLS.
+	ClientCerts []RegistryClientCert `json:"clientCerts,omitempty"`
+
+	// MaxConcurrentRequests limits the number of concurrent requests the
+	// engine makes to the registry, across all clients - if unset, requests
+	// are not limited.
+	MaxConcurrentRequests int `json:"maxConcurrentRequests,omitempty" jsonschema:"minimum=0"`
+}
+
+type RegistryClientCert struct {
+	// Cert is the path (in the engine's filesystem) to a PEM-encoded client
+	// certificate.
+	Cert string `json:"cert"`
+
+	// Key is the path (in the engine's filesystem) to the PEM-encoded private
+	// key of the client certificate.
+	Key string `json:"key"`
+}
+
+func (reg RegistryConfig) Validate() error {
+	if reg.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("maxConcurrentRequests must not be negative")
+	}
+	for _, mirror := range reg.Mirrors {
+		if strings.Contains(mirror, "://") {
+			return fmt.Errorf("mirror %q must be a host, not a URL", mirror)
+		}
+	}
+	for _, cert := range reg.ClientCerts {
+		if cert.Cert == "" || cert.Key == "" {
+			return fmt.Errorf("client certificates must specify both cert and key")
+		}
+	}
+	return nil
 }
@@ -18,9 +18,35 @@ func Load(r io.Reader) (Config, error) {
 	if err := dec.Decode(&cfg); err != nil {
 		return cfg, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config: %w", err)
+	}
 	return cfg, nil
 }
 
+func (cfg *Config) Validate() error {
+	if cfg.Security != nil && cfg.Security.UserNamespaceRange != nil {
+		if err := cfg.Security.UserNamespaceRange.Validate(); err != nil {
+			return fmt.Errorf("security.userNamespaceRange: %w", err)
+		}
+	}
+	if cfg.Limits.MaxQueryDepth < 0 {
+		return fmt.Errorf("limits.maxQueryDepth must not be negative")
+	}
+	if cfg.Limits.MaxQueryCost < 0 {
+		return fmt.Errorf("limits.maxQueryCost must not be negative")
+	}
+	if cfg.PersistedQueries.Enforce && len(cfg.PersistedQueries.Operations) == 0 && cfg.PersistedQueries.Dir == "" {
+		return fmt.Errorf("persistedQueries.enforce requires persisted operations")
+	}
+	for host, reg := range cfg.Registries {
+		if err := reg.Validate(); err != nil {
+			return fmt.Errorf("registry %q: %w", host, err)
+		}
+	}
+	return nil
+}
+
 func (cfg *Config) Save(w io.Writer) error {
 	end := json.NewEncoder(w)
 	if err := end.Encode(cfg); err != nil {
@@ -12,6 +12,10 @@ const (
 
 	DaggerVersionEnv        = "_EXPERIMENTAL_DAGGER_VERSION"
 	DaggerMinimumVersionEnv = "_EXPERIMENTAL_DAGGER_MIN_VERSION"
+
+	// DaggerAllowlistTokenEnv is the token a client presents to be exempted
+	// from the engine's persisted queries allowlist.
+	DaggerAllowlistTokenEnv = "_EXPERIMENTAL_DAGGER_ALLOWLIST_TOKEN"
 )
 
 const (
@@ -85,6 +85,15 @@ type ClientMetadata struct {
 
 	// Modules permitted to access LLM APIs or "all" to bypass restrictions for any loaded module.
 	AllowedLLMModules []string `json:"allowed_llm_modules"`
+
+	// (Optional) Limits on the queries of the session, in addition to the
+	// engine's. They can only lower the engine's limits.
+	MaxQueryDepth int `json:"max_query_depth,omitempty"`
+	MaxQueryCost  int `json:"max_query_cost,omitempty"`
+
+	// (Optional) Token exempting the client from the engine's persisted
+	// queries allowlist, if the engine trusts it.
+	AllowlistToken string `json:"allowlist_token,omitempty"`
 }
 
 type clientMetadataCtxKey struct{}
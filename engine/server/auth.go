@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
+	"github.com/dagger/dagger/dagql"
 	bksession "github.com/moby/buildkit/session"
 	bkauth "github.com/moby/buildkit/session/auth"
 	"google.golang.org/grpc"
@@ -98,3 +101,23 @@ func (p *authProxy) VerifyTokenAuthority(ctx context.Context, req *bkauth.Verify
 	}
 	return resp, nil
 }
+
+// clientOperationAllowlist returns the operation allowlist applied to the
+// queries of the given client. Clients nested in the engine, and clients
+// presenting a trusted allowlist token, are not restricted to persisted
+// queries.
+//
+// Nothing else the client reports about itself, such as its stable ID,
+// exempts it: the engine can't verify it.
+func (srv *Server) clientOperationAllowlist(client *daggerClient) *dagql.OperationAllowlist {
+	if len(client.parents) > 0 {
+		return srv.unrestrictedAllowlist
+	}
+	if token := client.clientMetadata.AllowlistToken; token != "" {
+		hash := sha256.Sum256([]byte(token))
+		if _, ok := srv.trustedAllowlistTokens[hex.EncodeToString(hash[:])]; ok {
+			return srv.unrestrictedAllowlist
+		}
+	}
+	return srv.operationAllowlist
+}
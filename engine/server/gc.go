@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/dagger/dagger/engine/cache"
 	"github.com/dagger/dagger/engine/config"
 	bkclient "github.com/moby/buildkit/client"
 	bkconfig "github.com/moby/buildkit/cmd/buildkitd/config"
@@ -41,11 +42,41 @@ func (srv *Server) EngineLocalCacheEntries(ctx context.Context) (*core.EngineCac
 		set.EntriesList = append(set.EntriesList, cacheEnt)
 		set.DiskSpaceBytes += int(r.Size)
 	}
+
+	if srv.persistedResults != nil {
+		usage, err := srv.persistedResults.Usage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get persisted result cache usage: %w", err)
+		}
+		if usage.Entries > 0 {
+			set.EntriesList = append(set.EntriesList, persistedResultsCacheEntry(usage))
+			set.DiskSpaceBytes += int(usage.SizeBytes)
+		}
+	}
 	set.EntryCount = len(set.EntriesList)
 
 	return set, nil
 }
 
+func persistedResultsCacheEntry(usage cache.PersistentStoreUsage) *core.EngineCacheEntry {
+	return &core.EngineCacheEntry{
+		Description:    fmt.Sprintf("persisted API results (%d entries)", usage.Entries),
+		DiskSpaceBytes: int(usage.SizeBytes),
+	}
+}
+
+// prunePersistedResults prunes the persisted dagql results, either all of
+// them or only those that are expired.
+func (srv *Server) prunePersistedResults(ctx context.Context, all bool) (cache.PersistentStoreUsage, error) {
+	if srv.persistedResults == nil {
+		return cache.PersistentStoreUsage{}, nil
+	}
+	return srv.persistedResults.Prune(ctx, cache.PersistentStorePruneOpts{
+		All:          all,
+		KeepDuration: srv.persistedResultsKeepDuration,
+	})
+}
+
 // Prune the local cache of releaseable entries. If useDefaultPolicy is true, use the engine-wide default pruning policy,
 // otherwise prune the whole cache of any releasable entries.
 func (srv *Server) PruneEngineLocalCacheEntries(ctx context.Context, useDefaultPolicy bool) (*core.EngineCacheEntrySet, error) {
@@ -81,7 +112,12 @@ func (srv *Server) PruneEngineLocalCacheEntries(ctx context.Context, useDefaultP
 	close(ch)
 	wg.Wait()
 
-	if len(pruned) == 0 {
+	prunedResults, err := srv.prunePersistedResults(ctx, !useDefaultPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune persisted result cache: %w", err)
+	}
+
+	if len(pruned) == 0 && prunedResults.Entries == 0 {
 		return &core.EngineCacheEntrySet{}, nil
 	}
 
@@ -108,6 +144,10 @@ func (srv *Server) PruneEngineLocalCacheEntries(ctx context.Context, useDefaultP
 		set.EntriesList = append(set.EntriesList, ent)
 		set.DiskSpaceBytes += int(r.Size)
 	}
+	if prunedResults.Entries > 0 {
+		set.EntriesList = append(set.EntriesList, persistedResultsCacheEntry(prunedResults))
+		set.DiskSpaceBytes += int(prunedResults.SizeBytes)
+	}
 	set.EntryCount = len(set.EntriesList)
 
 	return set, nil
@@ -140,6 +180,14 @@ func (srv *Server) gc() {
 	if err != nil {
 		bklog.G(ctx).Errorf("gc error: %+v", err)
 	}
+
+	prunedResults, err := srv.prunePersistedResults(context.TODO(), false)
+	if err != nil {
+		bklog.G(ctx).Errorf("gc error pruning persisted results: %+v", err)
+	}
+	if prunedResults.SizeBytes > 0 {
+		bklog.G(ctx).Debugf("gc cleaned up %d bytes of persisted results", prunedResults.SizeBytes)
+	}
 	if size > 0 {
 		bklog.G(ctx).Debugf("gc cleaned up %d bytes", size)
 		go srv.throttledReleaseUnreferenced()
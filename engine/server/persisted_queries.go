@@ -0,0 +1,63 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/engine/config"
+)
+
+// loadPersistedQueries returns the persisted queries configured inline and in
+// the configured directory, sorted by name.
+func loadPersistedQueries(cfg config.PersistedQueriesConfig) ([]dagql.PersistedQuery, error) {
+	queries := map[string]string{}
+	for name, query := range cfg.Operations {
+		queries[name] = query
+	}
+	if cfg.Dir != "" {
+		paths, err := filepath.Glob(filepath.Join(cfg.Dir, "*.graphql"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			name := strings.TrimSuffix(filepath.Base(path), ".graphql")
+			if _, ok := queries[name]; ok {
+				return nil, fmt.Errorf("duplicate persisted query %q", name)
+			}
+			query, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			queries[name] = string(query)
+		}
+	}
+
+	persisted := make([]dagql.PersistedQuery, 0, len(queries))
+	for name, query := range queries {
+		persisted = append(persisted, dagql.PersistedQuery{Name: name, Query: query})
+	}
+	sort.Slice(persisted, func(i, j int) bool {
+		return persisted[i].Name < persisted[j].Name
+	})
+	return persisted, nil
+}
+
+// loadTrustedTokens returns the set of the configured hashes of trusted
+// allowlist tokens, lowercased.
+func loadTrustedTokens(hashes []string) (map[string]struct{}, error) {
+	trusted := make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		hash = strings.ToLower(hash)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("trusted token %q is not a hex-encoded SHA-256 hash", hash)
+		}
+		trusted[hash] = struct{}{}
+	}
+	return trusted, nil
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/engine/config"
+)
+
+func TestClientOperationAllowlist(t *testing.T) {
+	t.Parallel()
+
+	persisted, err := loadPersistedQueries(config.PersistedQueriesConfig{
+		Operations: map[string]string{"version": "{ version }"},
+	})
+	require.NoError(t, err)
+	srv := &Server{}
+	srv.operationAllowlist, err = dagql.NewOperationAllowlist(persisted, true)
+	require.NoError(t, err)
+	srv.unrestrictedAllowlist, err = dagql.NewOperationAllowlist(persisted, false)
+	require.NoError(t, err)
+	tokenHash := sha256.Sum256([]byte("trusted-token"))
+	srv.trustedAllowlistTokens, err = loadTrustedTokens([]string{hex.EncodeToString(tokenHash[:])})
+	require.NoError(t, err)
+
+	const query = "{ container { id } }"
+
+	main := &daggerClient{
+		clientMetadata: &engine.ClientMetadata{
+			ClientID:       "main",
+			ClientStableID: "trusted-client",
+		},
+	}
+	require.Error(t, srv.clientOperationAllowlist(main).Check(query))
+	require.NoError(t, srv.clientOperationAllowlist(main).Check("{ version }"))
+
+	// a client can't exempt itself by reporting the stable ID of another one
+	forged := &daggerClient{
+		clientMetadata: &engine.ClientMetadata{
+			ClientID:       "forged",
+			ClientStableID: main.clientMetadata.ClientStableID,
+		},
+	}
+	require.Error(t, srv.clientOperationAllowlist(forged).Check(query))
+
+	nested := &daggerClient{
+		clientMetadata: &engine.ClientMetadata{ClientID: "nested"},
+		parents:        []*daggerClient{main},
+	}
+	require.NoError(t, srv.clientOperationAllowlist(nested).Check(query))
+
+	trusted := &daggerClient{
+		clientMetadata: &engine.ClientMetadata{
+			ClientID:       "trusted",
+			AllowlistToken: "trusted-token",
+		},
+	}
+	require.NoError(t, srv.clientOperationAllowlist(trusted).Check(query))
+
+	untrusted := &daggerClient{
+		clientMetadata: &engine.ClientMetadata{
+			ClientID:       "untrusted",
+			AllowlistToken: "other-token",
+		},
+	}
+	require.Error(t, srv.clientOperationAllowlist(untrusted).Check(query))
+}
+
+func TestLoadTrustedTokens(t *testing.T) {
+	t.Parallel()
+
+	hash := sha256.Sum256([]byte("token"))
+	trusted, err := loadTrustedTokens([]string{hex.EncodeToString(hash[:])})
+	require.NoError(t, err)
+	require.Len(t, trusted, 1)
+
+	_, err = loadTrustedTokens([]string{"token"})
+	require.ErrorContains(t, err, "not a hex-encoded SHA-256 hash")
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/containerd/containerd/remotes/docker"
+	bkconfig "github.com/moby/buildkit/cmd/buildkitd/config"
+	resolverconfig "github.com/moby/buildkit/util/resolver/config"
+
+	"github.com/dagger/dagger/engine/config"
+)
+
+// registryConfigs merges the registries from the engine config with the
+// ones from the buildkit config. For a registry configured in both, settings
+// from the engine config take priority, and lists are combined with the
+// engine's entries first.
+func registryConfigs(cfg *config.Config, bkcfg *bkconfig.Config) map[string]resolverconfig.RegistryConfig {
+	regs := make(map[string]resolverconfig.RegistryConfig, len(bkcfg.Registries)+len(cfg.Registries))
+	maps.Copy(regs, bkcfg.Registries)
+	for host, reg := range cfg.Registries {
+		bkreg := regs[host]
+		bkreg.Mirrors = mergeUnique(reg.Mirrors, bkreg.Mirrors)
+		if reg.PlainHTTP != nil {
+			bkreg.PlainHTTP = reg.PlainHTTP
+		}
+		if reg.Insecure != nil {
+			bkreg.Insecure = reg.Insecure
+		}
+		bkreg.RootCAs = mergeUnique(reg.CACerts, bkreg.RootCAs)
+		var keyPairs []resolverconfig.TLSKeyPair
+		for _, cert := range reg.ClientCerts {
+			keyPairs = append(keyPairs, resolverconfig.TLSKeyPair{
+				Certificate: cert.Cert,
+				Key:         cert.Key,
+			})
+		}
+		for _, pair := range bkreg.KeyPairs {
+			if !slices.Contains(keyPairs, pair) {
+				keyPairs = append(keyPairs, pair)
+			}
+		}
+		bkreg.KeyPairs = keyPairs
+		regs[host] = bkreg
+	}
+	return regs
+}
+
+// mergeUnique returns the values of a followed by the ones of b that aren't
+// in a.
+func mergeUnique(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	res := slices.Clone(a)
+	for _, v := range b {
+		if !slices.Contains(res, v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// withRegistryConcurrencyLimits wraps the given registry hosts so that the
+// number of concurrent requests to each configured registry (or any of its
+// mirrors) is limited.
+func withRegistryConcurrencyLimits(hosts docker.RegistryHosts, cfg *config.Config) docker.RegistryHosts {
+	limiters := map[string]chan struct{}{}
+	for host, reg := range cfg.Registries {
+		if reg.MaxConcurrentRequests > 0 {
+			limiters[host] = make(chan struct{}, reg.MaxConcurrentRequests)
+		}
+	}
+	if len(limiters) == 0 {
+		return hosts
+	}
+	return func(host string) ([]docker.RegistryHost, error) {
+		res, err := hosts(host)
+		if err != nil {
+			return nil, err
+		}
+		limiter, ok := limiters[host]
+		if !ok {
+			return res, nil
+		}
+		out := make([]docker.RegistryHost, len(res))
+		for i, h := range res {
+			if h.Client != nil {
+				client := *h.Client
+				transport := client.Transport
+				if transport == nil {
+					transport = http.DefaultTransport
+				}
+				client.Transport = &limitedTransport{
+					limiter: limiter,
+					inner:   transport,
+				}
+				h.Client = &client
+			}
+			out[i] = h
+		}
+		return out, nil
+	}
+}
+
+type limitedTransport struct {
+	limiter chan struct{}
+	inner   http.RoundTripper
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.limiter <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		<-t.limiter
+		return nil, err
+	}
+	// hold the slot until the body has been fully consumed, since that's
+	// where the bulk of the transfer happens for blobs
+	resp.Body = &limitedBody{ReadCloser: resp.Body, release: func() { <-t.limiter }}
+	return resp, nil
+}
+
+// limitedBody releases its concurrency slot once, when the body is closed or
+// has been read to the end or failed, so that a body that is dropped after
+// being consumed doesn't hold the slot.
+type limitedBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.once.Do(b.release)
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/remotes/docker"
+	bkconfig "github.com/moby/buildkit/cmd/buildkitd/config"
+	resolverconfig "github.com/moby/buildkit/util/resolver/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/engine/config"
+)
+
+func TestRegistryConfigs(t *testing.T) {
+	t.Parallel()
+
+	yes, no := true, false
+	cfg := &config.Config{
+		Registries: map[string]config.RegistryConfig{
+			"docker.io": {
+				Mirrors: []string{"mirror.example.com", "mirror.gcr.io"},
+			},
+			"registry.example.com": {
+				PlainHTTP: &no,
+				CACerts:   []string{"/etc/dagger/ca.pem"},
+				ClientCerts: []config.RegistryClientCert{
+					{Cert: "/etc/dagger/client.pem", Key: "/etc/dagger/client.key"},
+				},
+			},
+			"localhost:5000": {
+				PlainHTTP: &yes,
+			},
+		},
+	}
+	bkcfg := &bkconfig.Config{
+		Registries: map[string]resolverconfig.RegistryConfig{
+			"docker.io": {
+				Mirrors: []string{"mirror.gcr.io", "buildkit-mirror.example.com"},
+			},
+			"registry.example.com": {
+				PlainHTTP: &yes,
+				Insecure:  &yes,
+				RootCAs:   []string{"/etc/buildkit/ca.pem"},
+				KeyPairs: []resolverconfig.TLSKeyPair{
+					{Certificate: "/etc/buildkit/client.pem", Key: "/etc/buildkit/client.key"},
+				},
+			},
+			"ghcr.io": {
+				Mirrors: []string{"ghcr-mirror.example.com"},
+			},
+		},
+	}
+
+	require.Equal(t, map[string]resolverconfig.RegistryConfig{
+		"docker.io": {
+			Mirrors: []string{"mirror.example.com", "mirror.gcr.io", "buildkit-mirror.example.com"},
+		},
+		"registry.example.com": {
+			PlainHTTP: &no,
+			Insecure:  &yes,
+			RootCAs:   []string{"/etc/dagger/ca.pem", "/etc/buildkit/ca.pem"},
+			KeyPairs: []resolverconfig.TLSKeyPair{
+				{Certificate: "/etc/dagger/client.pem", Key: "/etc/dagger/client.key"},
+				{Certificate: "/etc/buildkit/client.pem", Key: "/etc/buildkit/client.key"},
+			},
+		},
+		"localhost:5000": {
+			PlainHTTP: &yes,
+		},
+		"ghcr.io": {
+			Mirrors: []string{"ghcr-mirror.example.com"},
+		},
+	}, registryConfigs(cfg, bkcfg))
+
+	// the buildkit config isn't modified
+	require.Equal(t, []string{"mirror.gcr.io", "buildkit-mirror.example.com"}, bkcfg.Registries["docker.io"].Mirrors)
+}
+
+// blockingTransport returns responses whose body blocks reads until unblock
+// is closed, and counts the requests in flight.
+type blockingTransport struct {
+	unblock  chan struct{}
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+}
+
+func (t *blockingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	n := t.inFlight.Add(1)
+	for {
+		seen := t.maxSeen.Load()
+		if n <= seen || t.maxSeen.CompareAndSwap(seen, n) {
+			break
+		}
+	}
+	<-t.unblock
+	t.inFlight.Add(-1)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("blob")),
+	}, nil
+}
+
+func limitedTestHosts(t *testing.T, transport http.RoundTripper, limit int) []docker.RegistryHost {
+	t.Helper()
+	cfg := &config.Config{
+		Registries: map[string]config.RegistryConfig{
+			"registry.example.com": {MaxConcurrentRequests: limit},
+		},
+	}
+	hosts := withRegistryConcurrencyLimits(func(host string) ([]docker.RegistryHost, error) {
+		return []docker.RegistryHost{
+			{Host: "mirror.example.com", Client: &http.Client{Transport: transport}},
+			{Host: host, Client: &http.Client{Transport: transport}},
+		}, nil
+	}, cfg)
+	res, err := hosts("registry.example.com")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	return res
+}
+
+func TestRegistryConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	transport := &blockingTransport{unblock: make(chan struct{})}
+	hosts := limitedTestHosts(t, transport, 2)
+
+	// the limit is shared between the registry and its mirrors
+	var wg sync.WaitGroup
+	for i := range 6 {
+		client := hosts[i%len(hosts)].Client
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get("http://registry.example.com/v2/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_, err = io.Copy(io.Discard, resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			resp.Body.Close()
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return transport.inFlight.Load() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	close(transport.unblock)
+	wg.Wait()
+	require.EqualValues(t, 2, transport.maxSeen.Load())
+
+	// hosts without a limit are left as is
+	unlimited := withRegistryConcurrencyLimits(docker.ConfigureDefaultRegistries(), &config.Config{})
+	res, err := unlimited("docker.io")
+	require.NoError(t, err)
+	for _, h := range res {
+		_, ok := h.Client.Transport.(*limitedTransport)
+		require.False(t, ok)
+	}
+}
+
+func TestRegistryConcurrencyLimitRelease(t *testing.T) {
+	t.Parallel()
+
+	transport := &blockingTransport{unblock: make(chan struct{})}
+	close(transport.unblock)
+	client := limitedTestHosts(t, transport, 1)[0].Client
+	limiter := client.Transport.(*limitedTransport).limiter
+
+	// reading the body to the end releases the slot, even without Close
+	resp, err := client.Get("http://registry.example.com/v2/")
+	require.NoError(t, err)
+	require.Len(t, limiter, 1)
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Empty(t, limiter)
+
+	// closing after EOF doesn't release it again
+	resp2, err := client.Get("http://registry.example.com/v2/")
+	require.NoError(t, err)
+	require.Len(t, limiter, 1)
+	require.NoError(t, resp.Body.Close())
+	require.Len(t, limiter, 1)
+
+	// closing an unread body releases the slot
+	require.NoError(t, resp2.Body.Close())
+	require.Empty(t, limiter)
+}
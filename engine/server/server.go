@@ -26,6 +26,7 @@ import (
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/engine/cache"
 	"github.com/dagger/dagger/engine/config"
+	"github.com/docker/docker/pkg/idtools"
 	controlapi "github.com/moby/buildkit/api/services/control"
 	apitypes "github.com/moby/buildkit/api/types"
 	bkcache "github.com/moby/buildkit/cache"
@@ -52,6 +53,7 @@ import (
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/source"
 	"github.com/moby/buildkit/util/archutil"
+	"github.com/moby/buildkit/util/disk"
 	"github.com/moby/buildkit/util/entitlements"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/network"
@@ -145,6 +147,8 @@ type Server struct {
 	selinux          bool
 	entitlements     entitlements.Set
 	parallelismSem   *semaphore.Weighted
+	userNamespaces   bool
+	userNamespaceMap idtools.IdentityMapping
 	enabledPlatforms []ocispecs.Platform
 	defaultPlatform  ocispecs.Platform
 	registryHosts    docker.RegistryHosts
@@ -168,6 +172,29 @@ type Server struct {
 	//
 	baseDagqlCache cache.Cache[digest.Digest, dagql.AnyResult]
 
+	// persistedResults stores persisted dagql results across engine restarts,
+	// nil if disabled
+	persistedResults             *cache.PersistentStore
+	persistedResultsKeepDuration time.Duration
+
+	// queryCostLimits bounds the queries clients may execute; sessions may
+	// lower them
+	queryCostLimits dagql.CostLimits
+
+	// rejectExperimentalFields rejects queries from clients using
+	// experimental fields
+	rejectExperimentalFields bool
+
+	// operationAllowlist holds the persisted queries, restricting clients to
+	// them if enforced, and unrestrictedAllowlist the same queries without
+	// enforcement, for clients nested in the engine
+	operationAllowlist    *dagql.OperationAllowlist
+	unrestrictedAllowlist *dagql.OperationAllowlist
+
+	// trustedAllowlistTokens are the SHA-256 hashes of the tokens exempting
+	// clients from the operation allowlist
+	trustedAllowlistTokens map[string]struct{}
+
 	//
 	// session+client state
 	//
@@ -276,6 +303,64 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 		srv.entitlements[entitlements.EntitlementSecurityInsecure] = struct{}{}
 	}
 
+	userNamespaceRange := config.DefaultUserNamespaceRange
+	if cfg.Security != nil {
+		srv.userNamespaces = cfg.Security.UserNamespaces != nil && *cfg.Security.UserNamespaces
+		if cfg.Security.UserNamespaceRange != nil {
+			userNamespaceRange = *cfg.Security.UserNamespaceRange
+		}
+	}
+	idMap := []idtools.IDMap{{
+		ContainerID: 0,
+		HostID:      int(userNamespaceRange.Start),
+		Size:        int(userNamespaceRange.Size),
+	}}
+	srv.userNamespaceMap = idtools.IdentityMapping{UIDMaps: idMap, GIDMaps: idMap}
+
+	srv.queryCostLimits = dagql.CostLimits{
+		MaxDepth: cfg.Limits.MaxQueryDepth,
+		MaxCost:  cfg.Limits.MaxQueryCost,
+	}
+	srv.rejectExperimentalFields = cfg.Limits.RejectExperimentalFields
+
+	persistedQueries, err := loadPersistedQueries(cfg.PersistedQueries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted queries: %w", err)
+	}
+	srv.operationAllowlist, err = dagql.NewOperationAllowlist(persistedQueries, cfg.PersistedQueries.Enforce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted queries: %w", err)
+	}
+	srv.unrestrictedAllowlist, err = dagql.NewOperationAllowlist(persistedQueries, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted queries: %w", err)
+	}
+	srv.trustedAllowlistTokens, err = loadTrustedTokens(cfg.PersistedQueries.TrustedTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted queries: %w", err)
+	}
+
+	if cfg.ResultCache.Enabled == nil || *cfg.ResultCache.Enabled {
+		maxSize := cfg.ResultCache.MaxSize
+		if maxSize == (config.DiskSpace{}) {
+			maxSize = config.DefaultResultCacheMaxSize
+		}
+		srv.persistedResultsKeepDuration = cfg.ResultCache.KeepDuration.Duration
+		if srv.persistedResultsKeepDuration == 0 {
+			srv.persistedResultsKeepDuration = config.DefaultResultCacheKeepDuration.Duration
+		}
+		dstat, _ := disk.GetDiskStat(srv.workerRootDir)
+		srv.persistedResults, err = cache.OpenPersistentStore(
+			filepath.Join(srv.workerRootDir, "dagql-results.db"),
+			maxSize.AsBytes(dstat),
+		)
+		if err != nil {
+			// not fatal, results just won't survive restarts
+			slog.Warn("failed to open persisted result cache", "error", err)
+			srv.persistedResults = nil
+		}
+	}
+
 	srv.defaultPlatform = platforms.Normalize(platforms.DefaultSpec())
 	if platformsStr := ociCfg.Platforms; len(platformsStr) != 0 {
 		var err error
@@ -288,7 +373,10 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 		srv.enabledPlatforms = []ocispecs.Platform{srv.defaultPlatform}
 	}
 
-	srv.registryHosts = resolver.NewRegistryConfig(bkcfg.Registries)
+	srv.registryHosts = withRegistryConcurrencyLimits(
+		resolver.NewRegistryConfig(registryConfigs(cfg, bkcfg)),
+		cfg,
+	)
 
 	if slog.Default().Enabled(ctx, slog.LevelExtraDebug) {
 		srv.buildkitLogSink = os.Stderr
@@ -463,6 +551,9 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 		NetworkProviders:    srv.networkProviders,
 		ParallelismSem:      srv.parallelismSem,
 		WorkerCache:         srv.workerCache,
+
+		UserNamespaces:       srv.userNamespaces,
+		UserNamespaceMapping: srv.userNamespaceMap,
 	})
 
 	//
@@ -567,6 +658,10 @@ func (srv *Server) Close() error {
 		err = errors.Join(err, srv.removeDaggerSession(context.Background(), s))
 		s.stateMu.Unlock()
 	}
+
+	if srv.persistedResults != nil {
+		err = errors.Join(err, srv.persistedResults.Close())
+	}
 	return err
 }
 
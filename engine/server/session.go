@@ -94,10 +94,18 @@ type daggerSession struct {
 
 	dagqlCache *dagql.SessionCache
 
+	// lifecycle reports the deprecated and experimental fields used by the
+	// session's clients, once per session
+	lifecycle *dagql.LifecycleTracker
+
 	interactive        bool
 	interactiveCommand []string
 
 	allowedLLMModules []string
+
+	// queryCostLimits bounds the queries of the session's clients: the
+	// engine's limits, lowered by any set by the main client
+	queryCostLimits dagql.CostLimits
 }
 
 type daggerSessionState string
@@ -252,10 +260,18 @@ func (srv *Server) initializeDaggerSession(
 	sess.refs = map[buildkit.Reference]struct{}{}
 	sess.containers = map[bkgw.Container]struct{}{}
 	sess.dagqlCache = dagql.NewSessionCache(srv.baseDagqlCache)
+	if srv.persistedResults != nil {
+		sess.dagqlCache = sess.dagqlCache.WithPersistentCache(srv.persistedResults)
+	}
+	sess.lifecycle = dagql.NewLifecycleTracker(emitLifecycleWarning)
 	sess.telemetryPubSub = srv.telemetryPubSub
 	sess.interactive = clientMetadata.Interactive
 	sess.interactiveCommand = clientMetadata.InteractiveCommand
 	sess.allowedLLMModules = clientMetadata.AllowedLLMModules
+	sess.queryCostLimits = srv.queryCostLimits.Min(dagql.CostLimits{
+		MaxDepth: clientMetadata.MaxQueryDepth,
+		MaxCost:  clientMetadata.MaxQueryCost,
+	})
 
 	sess.analytics = analytics.New(analytics.Config{
 		DoNotTrack: clientMetadata.DoNotTrack || analytics.DoNotTrack(),
@@ -1066,6 +1082,20 @@ func (srv *Server) serveSessionAttachables(w http.ResponseWriter, r *http.Reques
 	return nil
 }
 
+// emitLifecycleWarning reports the first use of a deprecated or experimental
+// field in a session as a revealed span, so it's visible in the TUI.
+func emitLifecycleWarning(ctx context.Context, w dagql.LifecycleWarning) {
+	_, span := core.Tracer(ctx).Start(ctx, w.String(),
+		trace.WithAttributes(
+			attribute.Bool(telemetry.UIRevealAttr, true),
+			attribute.String(telemetry.UIActorEmojiAttr, "⚠️"),
+			attribute.String(telemetry.LifecycleKindAttr, string(w.Kind)),
+			attribute.String(telemetry.LifecycleAPIAttr, w.API()),
+			attribute.String(telemetry.LifecycleReasonAttr, w.Reason),
+		))
+	span.End()
+}
+
 func (srv *Server) serveQuery(w http.ResponseWriter, r *http.Request, client *daggerClient) (rerr error) {
 	ctx := r.Context()
 
@@ -1091,6 +1121,20 @@ func (srv *Server) serveQuery(w http.ResponseWriter, r *http.Request, client *da
 	// make query available via context to all APIs
 	ctx = core.ContextWithQuery(ctx, client.dagqlRoot)
 
+	// apply the session's query limits; like the operation allowlist, only
+	// restrict clients outside the engine, since SDK runtimes and module
+	// functions build queries of their own
+	if len(client.parents) == 0 {
+		ctx = dagql.WithCostLimits(ctx, client.daggerSession.queryCostLimits)
+	}
+	ctx = dagql.WithOperationAllowlist(ctx, srv.clientOperationAllowlist(client))
+	ctx = dagql.WithLifecyclePolicy(ctx, dagql.LifecyclePolicy{
+		Tracker: client.daggerSession.lifecycle,
+		// like the operation allowlist, only restrict clients outside the
+		// engine; SDK runtimes may rely on experimental APIs
+		RejectExperimental: srv.rejectExperimentalFields && len(client.parents) == 0,
+	})
+
 	r = r.WithContext(ctx)
 
 	// get the schema we're gonna serve to this client based on which modules they have loaded, if any
@@ -1444,6 +1488,15 @@ func (srv *Server) Platform() core.Platform {
 	return core.Platform(srv.defaultPlatform)
 }
 
+// The platforms the engine can run containers for, natively or with emulation
+func (srv *Server) Platforms() []core.Platform {
+	platforms := make([]core.Platform, 0, len(srv.enabledPlatforms))
+	for _, p := range srv.enabledPlatforms {
+		platforms = append(platforms, core.Platform(p))
+	}
+	return platforms
+}
+
 // The content store for the engine as a whole
 func (srv *Server) OCIStore() content.Store {
 	return srv.contentStore
@@ -0,0 +1,250 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// CastHeader is the header of an asciicast v2 recording.
+//
+// See https://docs.asciinema.org/manual/asciicast/v2/
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// castEvent is an event of an asciicast v2 recording, encoded as a
+// [time, code, data] array.
+type castEvent struct {
+	Time float64
+	Code string
+	Data string
+}
+
+const (
+	castOutput = "o"
+	castResize = "r"
+	castMarker = "m"
+)
+
+// maxRecordingSize is the amount of output after which a recording stops,
+// since it's kept in memory until the session ends.
+const maxRecordingSize = 32 << 20
+
+func (e castEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Code, e.Data})
+}
+
+func (e *castEvent) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("expected 3 fields, got %d", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields[1], &e.Code); err != nil {
+		return err
+	}
+	return json.Unmarshal(fields[2], &e.Data)
+}
+
+// Recorder records the output of a terminal session in the asciicast v2
+// format.
+//
+// Output past maxRecordingSize isn't recorded, and a marker is added instead.
+type Recorder struct {
+	mu     sync.Mutex
+	start  time.Time
+	width  int
+	height int
+	events []castEvent
+
+	size      int
+	maxSize   int
+	truncated bool
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		start:   time.Now(),
+		width:   80,
+		height:  24,
+		maxSize: maxRecordingSize,
+	}
+}
+
+// output records data written to the terminal, which must not end with an
+// incomplete UTF-8 sequence.
+func (r *Recorder) output(data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.truncated {
+		return
+	}
+	if r.size+len(data) > r.maxSize {
+		r.truncated = true
+		r.events = append(r.events, castEvent{
+			Time: time.Since(r.start).Seconds(),
+			Code: castMarker,
+			Data: "recording truncated",
+		})
+		return
+	}
+	r.size += len(data)
+	r.events = append(r.events, castEvent{
+		Time: time.Since(r.start).Seconds(),
+		Code: castOutput,
+		Data: string(data),
+	})
+}
+
+// Resize records a change of the terminal size.
+//
+// The size before any output is the initial size in the header.
+func (r *Recorder) Resize(width, height int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.events) == 0 {
+		r.width, r.height = width, height
+		return
+	}
+	r.events = append(r.events, castEvent{
+		Time: time.Since(r.start).Seconds(),
+		Code: castResize,
+		Data: strconv.Itoa(width) + "x" + strconv.Itoa(height),
+	})
+}
+
+// Writer returns a writer that records everything written to w.
+func (r *Recorder) Writer(w io.WriteCloser) io.WriteCloser {
+	return &recordingWriter{WriteCloser: w, rec: r}
+}
+
+type recordingWriter struct {
+	io.WriteCloser
+	rec *Recorder
+
+	// pending holds an incomplete UTF-8 sequence from the last write, kept
+	// per writer so that the bytes of stdout and stderr aren't mixed up
+	pending []byte
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	// Keep a trailing incomplete UTF-8 sequence for the next write since
+	// events must be valid strings.
+	cut := len(data)
+	for k := 1; k < utf8.UTFMax && k <= len(data); k++ {
+		if utf8.RuneStart(data[len(data)-k]) {
+			if !utf8.FullRune(data[len(data)-k:]) {
+				cut = len(data) - k
+			}
+			break
+		}
+	}
+	w.pending = bytes.Clone(data[cut:])
+	if cut > 0 {
+		w.rec.output(data[:cut])
+	}
+	return w.WriteCloser.Write(p)
+}
+
+// WriteTo writes the recording in the asciicast v2 format.
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(CastHeader{
+		Version:   2,
+		Width:     r.width,
+		Height:    r.height,
+		Timestamp: r.start.Unix(),
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range r.events {
+		if err := enc.Encode(e); err != nil {
+			return 0, err
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// Replay plays back the output of an asciicast v2 recording to w, with the
+// original timing divided by speed. Pauses are shortened to maxIdle, if
+// non-zero.
+func Replay(ctx context.Context, r io.Reader, w io.Writer, speed float64, maxIdle time.Duration) error {
+	if speed <= 0 {
+		return fmt.Errorf("invalid speed %v", speed)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return errors.New("empty recording")
+	}
+	var header CastHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	if header.Version != 2 {
+		return fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	var last float64
+	for line := 2; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e castEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("line %d: invalid event: %w", line, err)
+		}
+		delay := time.Duration((e.Time - last) * float64(time.Second))
+		last = e.Time
+		if maxIdle > 0 && delay > maxIdle {
+			delay = maxIdle
+		}
+		delay = time.Duration(float64(delay) / speed)
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		if e.Code != castOutput {
+			continue
+		}
+		if _, err := io.WriteString(w, e.Data); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
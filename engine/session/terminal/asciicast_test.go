@@ -0,0 +1,107 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct{ *bytes.Buffer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	rec.Resize(120, 40)
+
+	var out bytes.Buffer
+	w := rec.Writer(nopWriteCloser{&out})
+	_, err := w.Write([]byte("hello \xe2\x9c"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("\x93\r\n"))
+	require.NoError(t, err)
+	rec.Resize(100, 30)
+	require.Equal(t, "hello ✓\r\n", out.String())
+
+	var cast bytes.Buffer
+	_, err = rec.WriteTo(&cast)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(cast.String()), "\n")
+	require.Len(t, lines, 4)
+
+	var header CastHeader
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	require.Equal(t, 2, header.Version)
+	require.Equal(t, 120, header.Width)
+	require.Equal(t, 40, header.Height)
+
+	var events []castEvent
+	for _, line := range lines[1:] {
+		var e castEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		events = append(events, e)
+	}
+	require.Equal(t, "hello ", events[0].Data)
+	require.Equal(t, "✓\r\n", events[1].Data)
+	require.Equal(t, castResize, events[2].Code)
+	require.Equal(t, "100x30", events[2].Data)
+
+	var replayed bytes.Buffer
+	require.NoError(t, Replay(context.Background(), &cast, &replayed, 100, time.Millisecond))
+	require.Equal(t, "hello ✓\r\n", replayed.String())
+}
+
+func TestRecorderWriters(t *testing.T) {
+	rec := NewRecorder()
+	stdout := rec.Writer(nopWriteCloser{&bytes.Buffer{}})
+	stderr := rec.Writer(nopWriteCloser{&bytes.Buffer{}})
+
+	// incomplete sequences are completed by the next write to the same writer
+	_, err := stdout.Write([]byte("\xe2\x9c"))
+	require.NoError(t, err)
+	_, err = stderr.Write([]byte("err\xc3"))
+	require.NoError(t, err)
+	_, err = stdout.Write([]byte("\x93"))
+	require.NoError(t, err)
+	_, err = stderr.Write([]byte("\xa9"))
+	require.NoError(t, err)
+
+	var data []string
+	for _, e := range rec.events {
+		data = append(data, e.Data)
+	}
+	require.Equal(t, []string{"err", "✓", "é"}, data)
+}
+
+func TestRecorderTruncated(t *testing.T) {
+	rec := NewRecorder()
+	rec.maxSize = 8
+	w := rec.Writer(nopWriteCloser{&bytes.Buffer{}})
+	for _, s := range []string{"hello", "world", "again"} {
+		_, err := w.Write([]byte(s))
+		require.NoError(t, err)
+	}
+
+	require.Len(t, rec.events, 2)
+	require.Equal(t, "hello", rec.events[0].Data)
+	require.Equal(t, castMarker, rec.events[1].Code)
+
+	var cast, replayed bytes.Buffer
+	_, err := rec.WriteTo(&cast)
+	require.NoError(t, err)
+	require.NoError(t, Replay(context.Background(), &cast, &replayed, 100, time.Millisecond))
+	require.Equal(t, "hello", replayed.String())
+}
+
+func TestReplayInvalid(t *testing.T) {
+	ctx := context.Background()
+	require.ErrorContains(t, Replay(ctx, strings.NewReader(""), &bytes.Buffer{}, 1, 0), "empty recording")
+	require.ErrorContains(t, Replay(ctx, strings.NewReader(`{"version":1}`), &bytes.Buffer{}, 1, 0), "unsupported asciicast version 1")
+	require.ErrorContains(t, Replay(ctx, strings.NewReader("{\"version\":2}\n[1,\"o\"]\n"), &bytes.Buffer{}, 1, 0), "line 2: invalid event")
+}
@@ -1,9 +1,13 @@
 package engine
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"os"
 	"slices"
 	"strings"
+	"sync"
 
 	"golang.org/x/mod/semver"
 )
@@ -81,6 +85,35 @@ func init() {
 	}
 }
 
+// BinaryDigest returns a digest identifying the running binary, for keying
+// data that must not outlive the code that produced it. Unlike Version, it
+// differs between dev builds that share the same version, or when the version
+// is overridden with an env var. If the binary can't be read, it falls back to
+// the version and tag.
+var BinaryDigest = sync.OnceValue(func() string {
+	if dgst, err := binaryDigest(); err == nil {
+		return dgst
+	}
+	return Version + "@" + Tag
+})
+
+func binaryDigest() (string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(self)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func cleanVersion(v string) string {
 	if semver.IsValid("v" + v) {
 		return "v" + v
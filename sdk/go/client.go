@@ -1,8 +1,14 @@
 package dagger
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+	"strings"
 
 	"github.com/Khan/genqlient/graphql"
 	"github.com/vektah/gqlparser/v2/gqlerror"
@@ -100,7 +106,12 @@ func Connect(ctx context.Context, opts ...ClientOpt) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	gql := errorWrappedClient{graphql.NewClient("http://"+conn.Host()+"/query", conn)}
+	endpoint := "http://" + conn.Host() + "/query"
+	gql := errorWrappedClient{
+		Client:   graphql.NewClient(endpoint, conn),
+		endpoint: endpoint,
+		doer:     conn,
+	}
 
 	c := &Client{
 		query:  querybuilder.Query().Client(gql),
@@ -181,6 +192,9 @@ type Response struct {
 
 type errorWrappedClient struct {
 	graphql.Client
+
+	endpoint string
+	doer     graphql.Doer
 }
 
 func (c errorWrappedClient) MakeRequest(ctx context.Context, req *graphql.Request, resp *graphql.Response) error {
@@ -193,3 +207,54 @@ func (c errorWrappedClient) MakeRequest(ctx context.Context, req *graphql.Reques
 	}
 	return nil
 }
+
+var _ querybuilder.Subscriber = errorWrappedClient{}
+
+// Subscribe executes a subscription over server-sent events.
+func (c errorWrappedClient) Subscribe(ctx context.Context, req *graphql.Request, fn func(data json.RawMessage) error) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.doer.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("subscription failed with status %s: %s", resp.Status, respBody)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	// events can hold large values, e.g. chunks of output
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			// comments, keep-alives and event types; the stream ends with EOF
+			continue
+		}
+		var res struct {
+			Data   json.RawMessage `json:"data"`
+			Errors gqlerror.List   `json:"errors"`
+		}
+		if err := json.Unmarshal([]byte(data), &res); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		if len(res.Errors) > 0 {
+			return res.Errors
+		}
+		if err := fn(res.Data); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -192,6 +192,12 @@ func LoadContainerFromID(id dagger.ContainerID) *dagger.Container {
 	return client.LoadContainerFromID(id)
 }
 
+// Load a ContainerSet from its ID.
+func LoadContainerSetFromID(id dagger.ContainerSetID) *dagger.ContainerSet {
+	client := initClient()
+	return client.LoadContainerSetFromID(id)
+}
+
 // Load a CurrentModule from its ID.
 func LoadCurrentModuleFromID(id dagger.CurrentModuleID) *dagger.CurrentModule {
 	client := initClient()
@@ -324,6 +330,18 @@ func LoadHostFromID(id dagger.HostID) *dagger.Host {
 	return client.LoadHostFromID(id)
 }
 
+// Load a IDDiff from its ID.
+func LoadIDDiffFromID(id dagger.IDDiffID) *dagger.IDDiff {
+	client := initClient()
+	return client.LoadIDDiffFromID(id)
+}
+
+// Load a ImageDescriptor from its ID.
+func LoadImageDescriptorFromID(id dagger.ImageDescriptorID) *dagger.ImageDescriptor {
+	client := initClient()
+	return client.LoadImageDescriptorFromID(id)
+}
+
 // Load a InputTypeDef from its ID.
 func LoadInputTypeDefFromID(id dagger.InputTypeDefID) *dagger.InputTypeDef {
 	client := initClient()
@@ -396,6 +414,12 @@ func LoadPortFromID(id dagger.PortID) *dagger.Port {
 	return client.LoadPortFromID(id)
 }
 
+// Load a QueryCost from its ID.
+func LoadQueryCostFromID(id dagger.QueryCostID) *dagger.QueryCost {
+	client := initClient()
+	return client.LoadQueryCostFromID(id)
+}
+
 // Load a SDKConfig from its ID.
 func LoadSDKConfigFromID(id dagger.SDKConfigID) *dagger.SDKConfig {
 	client := initClient()
@@ -456,6 +480,12 @@ func LoadTypeDefFromID(id dagger.TypeDefID) *dagger.TypeDef {
 	return client.LoadTypeDefFromID(id)
 }
 
+// Load a UnionTypeDef from its ID.
+func LoadUnionTypeDefFromID(id dagger.UnionTypeDefID) *dagger.UnionTypeDef {
+	client := initClient()
+	return client.LoadUnionTypeDefFromID(id)
+}
+
 // Create a new module.
 func Module() *dagger.Module {
 	client := initClient()
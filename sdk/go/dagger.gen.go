@@ -140,6 +140,9 @@ type CloudID string
 // The `ContainerID` scalar type represents an identifier for an object of type Container.
 type ContainerID string
 
+// The `ContainerSetID` scalar type represents an identifier for an object of type ContainerSet.
+type ContainerSetID string
+
 // The `CurrentModuleID` scalar type represents an identifier for an object of type CurrentModule.
 type CurrentModuleID string
 
@@ -206,6 +209,12 @@ type GitRepositoryID string
 // The `HostID` scalar type represents an identifier for an object of type Host.
 type HostID string
 
+// The `IDDiffID` scalar type represents an identifier for an object of type IDDiff.
+type IDDiffID string
+
+// The `ImageDescriptorID` scalar type represents an identifier for an object of type ImageDescriptor.
+type ImageDescriptorID string
+
 // The `InputTypeDefID` scalar type represents an identifier for an object of type InputTypeDef.
 type InputTypeDefID string
 
@@ -250,6 +259,9 @@ type Platform string
 // The `PortID` scalar type represents an identifier for an object of type Port.
 type PortID string
 
+// The `QueryCostID` scalar type represents an identifier for an object of type QueryCost.
+type QueryCostID string
+
 // The `SDKConfigID` scalar type represents an identifier for an object of type SDKConfig.
 type SDKConfigID string
 
@@ -280,6 +292,9 @@ type TerminalID string
 // The `TypeDefID` scalar type represents an identifier for an object of type TypeDef.
 type TypeDefID string
 
+// The `UnionTypeDefID` scalar type represents an identifier for an object of type UnionTypeDef.
+type UnionTypeDefID string
+
 // The absence of a value.
 //
 // A Null Void is used as a placeholder for resolvers that do not return anything.
@@ -294,6 +309,15 @@ type BuildArg struct {
 	Value string `json:"value"`
 }
 
+// Key value object that represents an OCI annotation used to select an image.
+type ImageAnnotation struct {
+	// The annotation name.
+	Name string `json:"name"`
+
+	// The annotation value.
+	Value string `json:"value"`
+}
+
 // Key value object that represents a pipeline label.
 type PipelineLabel struct {
 	// Label name.
@@ -359,6 +383,15 @@ func (r *Binding) AsContainer() *Container {
 	}
 }
 
+// Retrieve the binding value, as type ContainerSet
+func (r *Binding) AsContainerSet() *ContainerSet {
+	q := r.query.Select("asContainerSet")
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
 // Retrieve the binding value, as type Directory
 func (r *Binding) AsDirectory() *Directory {
 	q := r.query.Select("asDirectory")
@@ -507,6 +540,15 @@ func (r *Binding) AsString(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// Retrieve the binding value, as type UnionTypeDef
+func (r *Binding) AsUnionTypeDef() *UnionTypeDef {
+	q := r.query.Select("asUnionTypeDef")
+
+	return &UnionTypeDef{
+		query: q,
+	}
+}
+
 // The digest of the binding value
 func (r *Binding) Digest(ctx context.Context) (string, error) {
 	if r.digest != nil {
@@ -768,6 +810,8 @@ type ContainerAsServiceOpts struct {
 	ExperimentalPrivilegedNesting bool
 	// Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
 	InsecureRootCapabilities bool
+	// Run the command in a user namespace, so that root in the container maps to an unprivileged user on the host. Cannot be combined with insecureRootCapabilities.
+	UserNamespace bool
 	// Replace "${VAR}" or "$VAR" in the args according to the current environment variables defined in the container (e.g. "/$VAR/foo").
 	Expand bool
 	// If set, skip the automatic init process injected into containers by default.
@@ -798,6 +842,10 @@ func (r *Container) AsService(opts ...ContainerAsServiceOpts) *Service {
 		if !querybuilder.IsZeroValue(opts[i].InsecureRootCapabilities) {
 			q = q.Arg("insecureRootCapabilities", opts[i].InsecureRootCapabilities)
 		}
+		// `userNamespace` optional argument
+		if !querybuilder.IsZeroValue(opts[i].UserNamespace) {
+			q = q.Arg("userNamespace", opts[i].UserNamespace)
+		}
 		// `expand` optional argument
 		if !querybuilder.IsZeroValue(opts[i].Expand) {
 			q = q.Arg("expand", opts[i].Expand)
@@ -1247,6 +1295,18 @@ func (r *Container) File(path string, opts ...ContainerFileOpts) *File {
 	}
 }
 
+// Replay the pipeline that built this container for each of the given platforms, in parallel.
+//
+// The pipeline must start from "container"; its platform is replaced for each platform, and every subsequent operation is applied on top of it. Non-native platforms are executed using emulation.
+func (r *Container) ForPlatforms(platforms []Platform) *ContainerSet {
+	q := r.query.Select("forPlatforms")
+	q = q.Arg("platforms", platforms)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
 // Download a container image, and apply it to the container state. All previous state will be lost.
 func (r *Container) From(address string) *Container {
 	q := r.query.Select("from")
@@ -1314,9 +1374,15 @@ func (r *Container) ImageRef(ctx context.Context) (string, error) {
 type ContainerImportOpts struct {
 	// Identifies the tag to import from the archive, if the archive bundles multiple tags.
 	Tag string
+	// Identifies the image manifest or index to import from the archive by its digest.
+	Digest string
+	// Identifies the image to import from the archive by the annotations of its descriptor.
+	Annotations []ImageAnnotation
 }
 
-// Reads the container from an OCI tarball.
+// Reads the container from an OCI tarball or Docker archive.
+//
+// Use File.imageDescriptors to list the images available in the archive.
 func (r *Container) Import(source *File, opts ...ContainerImportOpts) *Container {
 	assertNotNil("source", source)
 	q := r.query.Select("import")
@@ -1325,6 +1391,51 @@ func (r *Container) Import(source *File, opts ...ContainerImportOpts) *Container
 		if !querybuilder.IsZeroValue(opts[i].Tag) {
 			q = q.Arg("tag", opts[i].Tag)
 		}
+		// `digest` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Digest) {
+			q = q.Arg("digest", opts[i].Digest)
+		}
+		// `annotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Annotations) {
+			q = q.Arg("annotations", opts[i].Annotations)
+		}
+	}
+	q = q.Arg("source", source)
+
+	return &Container{
+		query: q,
+	}
+}
+
+// ContainerImportLayoutOpts contains options for Container.ImportLayout
+type ContainerImportLayoutOpts struct {
+	// Identifies the tag to import from the layout, if the layout bundles multiple tags.
+	Tag string
+	// Identifies the image manifest or index to import from the layout by its digest.
+	Digest string
+	// Identifies the image to import from the layout by the annotations of its descriptor.
+	Annotations []ImageAnnotation
+}
+
+// Reads the container from an OCI image layout directory.
+//
+// Use Directory.imageDescriptors to list the images available in the layout.
+func (r *Container) ImportLayout(source *Directory, opts ...ContainerImportLayoutOpts) *Container {
+	assertNotNil("source", source)
+	q := r.query.Select("importLayout")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `tag` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Tag) {
+			q = q.Arg("tag", opts[i].Tag)
+		}
+		// `digest` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Digest) {
+			q = q.Arg("digest", opts[i].Digest)
+		}
+		// `annotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Annotations) {
+			q = q.Arg("annotations", opts[i].Annotations)
+		}
 	}
 	q = q.Arg("source", source)
 
@@ -1513,6 +1624,12 @@ type ContainerTerminalOpts struct {
 	ExperimentalPrivilegedNesting bool
 	// Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
 	InsecureRootCapabilities bool
+	// If set, record the session as an asciicast v2 file at "/terminal.cast" in the returned container.
+	//
+	// The returned container is identified by the contents of the recording, so loading it again doesn't open another terminal.
+	//
+	// It can be played back with "dagger terminal --replay".
+	Record bool
 }
 
 // Opens an interactive terminal for this container using its configured default terminal command if not overridden by args (or sh as a fallback default).
@@ -1531,6 +1648,10 @@ func (r *Container) Terminal(opts ...ContainerTerminalOpts) *Container {
 		if !querybuilder.IsZeroValue(opts[i].InsecureRootCapabilities) {
 			q = q.Arg("insecureRootCapabilities", opts[i].InsecureRootCapabilities)
 		}
+		// `record` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Record) {
+			q = q.Arg("record", opts[i].Record)
+		}
 	}
 
 	return &Container{
@@ -1556,6 +1677,8 @@ type ContainerUpOpts struct {
 	ExperimentalPrivilegedNesting bool
 	// Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
 	InsecureRootCapabilities bool
+	// Run the command in a user namespace, so that root in the container maps to an unprivileged user on the host. Cannot be combined with insecureRootCapabilities.
+	UserNamespace bool
 	// Replace "${VAR}" or "$VAR" in the args according to the current environment variables defined in the container (e.g. "/$VAR/foo").
 	Expand bool
 	// If set, skip the automatic init process injected into containers by default.
@@ -1597,6 +1720,10 @@ func (r *Container) Up(ctx context.Context, opts ...ContainerUpOpts) error {
 		if !querybuilder.IsZeroValue(opts[i].InsecureRootCapabilities) {
 			q = q.Arg("insecureRootCapabilities", opts[i].InsecureRootCapabilities)
 		}
+		// `userNamespace` optional argument
+		if !querybuilder.IsZeroValue(opts[i].UserNamespace) {
+			q = q.Arg("userNamespace", opts[i].UserNamespace)
+		}
 		// `expand` optional argument
 		if !querybuilder.IsZeroValue(opts[i].Expand) {
 			q = q.Arg("expand", opts[i].Expand)
@@ -1785,6 +1912,10 @@ type ContainerWithExecOpts struct {
 	//
 	// DANGER: this grants the command full access to the host system. Only use when 1) you trust the command being executed and 2) you specifically need this level of access.
 	InsecureRootCapabilities bool
+	// Execute the command in a user namespace, so that root in the container maps to an unprivileged user on the host.
+	//
+	// Files keep their ownership as seen from the container. Cannot be combined with insecureRootCapabilities.
+	UserNamespace bool
 	// Replace "${VAR}" or "$VAR" in the args according to the current environment variables defined in the container (e.g. "/$VAR/foo").
 	Expand bool
 	// Skip the automatic init process injected into containers by default.
@@ -1829,6 +1960,10 @@ func (r *Container) WithExec(args []string, opts ...ContainerWithExecOpts) *Cont
 		if !querybuilder.IsZeroValue(opts[i].InsecureRootCapabilities) {
 			q = q.Arg("insecureRootCapabilities", opts[i].InsecureRootCapabilities)
 		}
+		// `userNamespace` optional argument
+		if !querybuilder.IsZeroValue(opts[i].UserNamespace) {
+			q = q.Arg("userNamespace", opts[i].UserNamespace)
+		}
 		// `expand` optional argument
 		if !querybuilder.IsZeroValue(opts[i].Expand) {
 			q = q.Arg("expand", opts[i].Expand)
@@ -2600,45 +2735,99 @@ func (r *Container) Workdir(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
-// Reflective module API provided to functions at runtime.
-type CurrentModule struct {
+// A set of containers built from the same pipeline for multiple platforms.
+//
+// Operations applied to the set, such as withExec, are applied to each platform in parallel.
+type ContainerSet struct {
 	query *querybuilder.Selection
 
-	id   *CurrentModuleID
-	name *string
+	id      *ContainerSetID
+	publish *string
+	sync    *ContainerSetID
 }
+type WithContainerSetFunc func(r *ContainerSet) *ContainerSet
 
-func (r *CurrentModule) WithGraphQLQuery(q *querybuilder.Selection) *CurrentModule {
-	return &CurrentModule{
+// With calls the provided function with current ContainerSet.
+//
+// This is useful for reusability and readability by not breaking the calling chain.
+func (r *ContainerSet) With(f WithContainerSetFunc) *ContainerSet {
+	return f(r)
+}
+
+func (r *ContainerSet) WithGraphQLQuery(q *querybuilder.Selection) *ContainerSet {
+	return &ContainerSet{
 		query: q,
 	}
 }
 
-// A unique identifier for this CurrentModule.
-func (r *CurrentModule) ID(ctx context.Context) (CurrentModuleID, error) {
+// Retrieve the container built for the given platform.
+func (r *ContainerSet) Container(platform Platform) *Container {
+	q := r.query.Select("container")
+	q = q.Arg("platform", platform)
+
+	return &Container{
+		query: q,
+	}
+}
+
+// The containers in the set, one per platform, in the order the platforms were requested.
+func (r *ContainerSet) Containers(ctx context.Context) ([]Container, error) {
+	q := r.query.Select("containers")
+
+	q = q.Select("id")
+
+	type containers struct {
+		Id ContainerID
+	}
+
+	convert := func(fields []containers) []Container {
+		out := []Container{}
+
+		for i := range fields {
+			val := Container{id: &fields[i].Id}
+			val.query = q.Root().Select("loadContainerFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []containers
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
+// A unique identifier for this ContainerSet.
+func (r *ContainerSet) ID(ctx context.Context) (ContainerSetID, error) {
 	if r.id != nil {
 		return *r.id, nil
 	}
 	q := r.query.Select("id")
 
-	var response CurrentModuleID
+	var response ContainerSetID
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
 // XXX_GraphQLType is an internal function. It returns the native GraphQL type name
-func (r *CurrentModule) XXX_GraphQLType() string {
-	return "CurrentModule"
+func (r *ContainerSet) XXX_GraphQLType() string {
+	return "ContainerSet"
 }
 
 // XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
-func (r *CurrentModule) XXX_GraphQLIDType() string {
-	return "CurrentModuleID"
+func (r *ContainerSet) XXX_GraphQLIDType() string {
+	return "ContainerSetID"
 }
 
 // XXX_GraphQLID is an internal function. It returns the underlying type ID
-func (r *CurrentModule) XXX_GraphQLID(ctx context.Context) (string, error) {
+func (r *ContainerSet) XXX_GraphQLID(ctx context.Context) (string, error) {
 	id, err := r.ID(ctx)
 	if err != nil {
 		return "", err
@@ -2646,7 +2835,7 @@ func (r *CurrentModule) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
-func (r *CurrentModule) MarshalJSON() ([]byte, error) {
+func (r *ContainerSet) MarshalJSON() ([]byte, error) {
 	id, err := r.ID(marshalCtx)
 	if err != nil {
 		return nil, err
@@ -2654,12 +2843,49 @@ func (r *CurrentModule) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
-// The name of the module being executed in
-func (r *CurrentModule) Name(ctx context.Context) (string, error) {
-	if r.name != nil {
-		return *r.name, nil
+// The platforms of the containers in the set.
+func (r *ContainerSet) Platforms(ctx context.Context) ([]Platform, error) {
+	q := r.query.Select("platforms")
+
+	var response []Platform
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// ContainerSetPublishOpts contains options for ContainerSet.Publish
+type ContainerSetPublishOpts struct {
+	// Force each layer of the published image to use the specified compression algorithm.
+	//
+	// If this is unset, then if a layer already has a compressed blob in the engine's cache, that will be used (this can result in a mix of compression algorithms for different layers). If this is unset and a layer has no compressed blob in the engine's cache, then it will be compressed using Gzip.
+	ForcedCompression ImageLayerCompression
+	// Use the specified media types for the published image's layers.
+	//
+	// Defaults to "OCI", which is compatible with most recent registries, but "Docker" may be needed for older registries without OCI support.
+	//
+	// Default: OCIMediaTypes
+	MediaTypes ImageMediaTypes
+}
+
+// Package every container in the set as a single multi-platform OCI image, and publish it to a registry
+//
+// Returns the fully qualified address of the published image index, with digest
+func (r *ContainerSet) Publish(ctx context.Context, address string, opts ...ContainerSetPublishOpts) (string, error) {
+	if r.publish != nil {
+		return *r.publish, nil
 	}
-	q := r.query.Select("name")
+	q := r.query.Select("publish")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `forcedCompression` optional argument
+		if !querybuilder.IsZeroValue(opts[i].ForcedCompression) {
+			q = q.Arg("forcedCompression", opts[i].ForcedCompression)
+		}
+		// `mediaTypes` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MediaTypes) {
+			q = q.Arg("mediaTypes", opts[i].MediaTypes)
+		}
+	}
+	q = q.Arg("address", address)
 
 	var response string
 
@@ -2667,26 +2893,55 @@ func (r *CurrentModule) Name(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
-// The directory containing the module's source code loaded into the engine (plus any generated code that may have been created).
-func (r *CurrentModule) Source() *Directory {
-	q := r.query.Select("source")
+// Forces evaluation of every container in the set, in parallel.
+//
+// Failures are reported for every platform that failed, not only the first one.
+func (r *ContainerSet) Sync(ctx context.Context) (*ContainerSet, error) {
+	q := r.query.Select("sync")
 
-	return &Directory{
+	var id ContainerSetID
+	if err := q.Bind(&id).Execute(ctx); err != nil {
+		return nil, err
+	}
+	return &ContainerSet{
+		query: q.Root().Select("loadContainerSetFromID").Arg("id", id),
+	}, nil
+}
+
+// Apply Container.withDefaultArgs to every container in the set, in parallel.
+//
+// Configures default arguments for future commands. Like CMD in Dockerfile.
+func (r *ContainerSet) WithDefaultArgs(args []string) *ContainerSet {
+	q := r.query.Select("withDefaultArgs")
+	q = q.Arg("args", args)
+
+	return &ContainerSet{
 		query: q,
 	}
 }
 
-// CurrentModuleWorkdirOpts contains options for CurrentModule.Workdir
-type CurrentModuleWorkdirOpts struct {
-	// Exclude artifacts that match the given pattern (e.g., ["node_modules/", ".git*"]).
+// ContainerSetWithDirectoryOpts contains options for ContainerSet.WithDirectory
+type ContainerSetWithDirectoryOpts struct {
+	// Patterns to exclude in the written directory (e.g. ["node_modules/**", ".gitignore", ".git/"]).
 	Exclude []string
-	// Include only artifacts that match the given pattern (e.g., ["app/", "package.*"]).
+	// Patterns to include in the written directory (e.g. ["*.go", "go.mod", "go.sum"]).
 	Include []string
+	// A user:group to set for the directory and its contents.
+	//
+	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
+	//
+	// If the group is omitted, it defaults to the same as the user.
+	Owner string
+	// Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo").
+	Expand bool
 }
 
-// Load a directory from the module's scratch working directory, including any changes that may have been made to it during module function execution.
-func (r *CurrentModule) Workdir(path string, opts ...CurrentModuleWorkdirOpts) *Directory {
-	q := r.query.Select("workdir")
+// Apply Container.withDirectory to every container in the set, in parallel.
+//
+// Return a new container snapshot, with a directory added to its filesystem
+func (r *ContainerSet) WithDirectory(path string, directory *Directory, opts ...ContainerSetWithDirectoryOpts) *ContainerSet {
+	assertNotNil("directory", directory)
+	q := r.query.Select("withDirectory")
 	for i := len(opts) - 1; i >= 0; i-- {
 		// `exclude` optional argument
 		if !querybuilder.IsZeroValue(opts[i].Exclude) {
@@ -2696,66 +2951,554 @@ func (r *CurrentModule) Workdir(path string, opts ...CurrentModuleWorkdirOpts) *
 		if !querybuilder.IsZeroValue(opts[i].Include) {
 			q = q.Arg("include", opts[i].Include)
 		}
+		// `owner` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Owner) {
+			q = q.Arg("owner", opts[i].Owner)
+		}
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
 	}
 	q = q.Arg("path", path)
+	q = q.Arg("directory", directory)
 
-	return &Directory{
+	return &ContainerSet{
 		query: q,
 	}
 }
 
-// Load a file from the module's scratch working directory, including any changes that may have been made to it during module function execution.Load a file from the module's scratch working directory, including any changes that may have been made to it during module function execution.
-func (r *CurrentModule) WorkdirFile(path string) *File {
-	q := r.query.Select("workdirFile")
-	q = q.Arg("path", path)
+// ContainerSetWithEntrypointOpts contains options for ContainerSet.WithEntrypoint
+type ContainerSetWithEntrypointOpts struct {
+	// Don't reset the default arguments when setting the entrypoint. By default it is reset, since entrypoint and default args are often tightly coupled.
+	KeepDefaultArgs bool
+}
 
-	return &File{
+// Apply Container.withEntrypoint to every container in the set, in parallel.
+//
+// Set an OCI-style entrypoint. It will be included in the container's OCI configuration. Note, withExec ignores the entrypoint by default.
+func (r *ContainerSet) WithEntrypoint(args []string, opts ...ContainerSetWithEntrypointOpts) *ContainerSet {
+	q := r.query.Select("withEntrypoint")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `keepDefaultArgs` optional argument
+		if !querybuilder.IsZeroValue(opts[i].KeepDefaultArgs) {
+			q = q.Arg("keepDefaultArgs", opts[i].KeepDefaultArgs)
+		}
+	}
+	q = q.Arg("args", args)
+
+	return &ContainerSet{
 		query: q,
 	}
 }
 
-// A directory.
-type Directory struct {
-	query *querybuilder.Selection
-
-	digest *string
-	exists *bool
-	export *string
-	id     *DirectoryID
-	name   *string
-	sync   *DirectoryID
+// ContainerSetWithEnvVariableOpts contains options for ContainerSet.WithEnvVariable
+type ContainerSetWithEnvVariableOpts struct {
+	// Replace "${VAR}" or "$VAR" in the value according to the current environment variables defined in the container (e.g. "/opt/bin:$PATH").
+	Expand bool
 }
-type WithDirectoryFunc func(r *Directory) *Directory
 
-// With calls the provided function with current Directory.
+// Apply Container.withEnvVariable to every container in the set, in parallel.
 //
-// This is useful for reusability and readability by not breaking the calling chain.
-func (r *Directory) With(f WithDirectoryFunc) *Directory {
-	return f(r)
-}
-
-func (r *Directory) WithGraphQLQuery(q *querybuilder.Selection) *Directory {
-	return &Directory{
-		query: q,
+// Set a new environment variable in the container.
+func (r *ContainerSet) WithEnvVariable(name string, value string, opts ...ContainerSetWithEnvVariableOpts) *ContainerSet {
+	q := r.query.Select("withEnvVariable")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
 	}
-}
-
-// Converts this directory to a local git repository
-func (r *Directory) AsGit() *GitRepository {
-	q := r.query.Select("asGit")
+	q = q.Arg("name", name)
+	q = q.Arg("value", value)
 
-	return &GitRepository{
+	return &ContainerSet{
 		query: q,
 	}
 }
 
-// DirectoryAsModuleOpts contains options for Directory.AsModule
-type DirectoryAsModuleOpts struct {
-	// An optional subpath of the directory which contains the module's configuration file.
-	//
-	// If not set, the module source code is loaded from the root of the directory.
-	//
-	// Default: "."
+// ContainerSetWithExecOpts contains options for ContainerSet.WithExec
+type ContainerSetWithExecOpts struct {
+	// Apply the OCI entrypoint, if present, by prepending it to the args. Ignored by default.
+	UseEntrypoint bool
+	// Content to write to the command's standard input. Example: "Hello world")
+	Stdin string
+	// Redirect the command's standard input from a file in the container. Example: "./stdin.txt"
+	RedirectStdin string
+	// Redirect the command's standard output to a file in the container. Example: "./stdout.txt"
+	RedirectStdout string
+	// Redirect the command's standard error to a file in the container. Example: "./stderr.txt"
+	RedirectStderr string
+	// Exit codes this command is allowed to exit with without error
+	//
+	// Default: SUCCESS
+	Expect ReturnType
+	// Provides Dagger access to the executed command.
+	ExperimentalPrivilegedNesting bool
+	// Execute the command with all root capabilities. Like --privileged in Docker
+	//
+	// DANGER: this grants the command full access to the host system. Only use when 1) you trust the command being executed and 2) you specifically need this level of access.
+	InsecureRootCapabilities bool
+	// Execute the command in a user namespace, so that root in the container maps to an unprivileged user on the host.
+	//
+	// Files keep their ownership as seen from the container. Cannot be combined with insecureRootCapabilities.
+	UserNamespace bool
+	// Replace "${VAR}" or "$VAR" in the args according to the current environment variables defined in the container (e.g. "/$VAR/foo").
+	Expand bool
+	// Skip the automatic init process injected into containers by default.
+	//
+	// Only use this if you specifically need the command to be pid 1 in the container. Otherwise it may result in unexpected behavior. If you're not sure, you don't need this.
+	NoInit bool
+}
+
+// Apply Container.withExec to every container in the set, in parallel.
+//
+// Execute a command in the container, and return a new snapshot of the container state after execution.
+func (r *ContainerSet) WithExec(args []string, opts ...ContainerSetWithExecOpts) *ContainerSet {
+	q := r.query.Select("withExec")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `useEntrypoint` optional argument
+		if !querybuilder.IsZeroValue(opts[i].UseEntrypoint) {
+			q = q.Arg("useEntrypoint", opts[i].UseEntrypoint)
+		}
+		// `stdin` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Stdin) {
+			q = q.Arg("stdin", opts[i].Stdin)
+		}
+		// `redirectStdin` optional argument
+		if !querybuilder.IsZeroValue(opts[i].RedirectStdin) {
+			q = q.Arg("redirectStdin", opts[i].RedirectStdin)
+		}
+		// `redirectStdout` optional argument
+		if !querybuilder.IsZeroValue(opts[i].RedirectStdout) {
+			q = q.Arg("redirectStdout", opts[i].RedirectStdout)
+		}
+		// `redirectStderr` optional argument
+		if !querybuilder.IsZeroValue(opts[i].RedirectStderr) {
+			q = q.Arg("redirectStderr", opts[i].RedirectStderr)
+		}
+		// `expect` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expect) {
+			q = q.Arg("expect", opts[i].Expect)
+		}
+		// `experimentalPrivilegedNesting` optional argument
+		if !querybuilder.IsZeroValue(opts[i].ExperimentalPrivilegedNesting) {
+			q = q.Arg("experimentalPrivilegedNesting", opts[i].ExperimentalPrivilegedNesting)
+		}
+		// `insecureRootCapabilities` optional argument
+		if !querybuilder.IsZeroValue(opts[i].InsecureRootCapabilities) {
+			q = q.Arg("insecureRootCapabilities", opts[i].InsecureRootCapabilities)
+		}
+		// `userNamespace` optional argument
+		if !querybuilder.IsZeroValue(opts[i].UserNamespace) {
+			q = q.Arg("userNamespace", opts[i].UserNamespace)
+		}
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
+		// `noInit` optional argument
+		if !querybuilder.IsZeroValue(opts[i].NoInit) {
+			q = q.Arg("noInit", opts[i].NoInit)
+		}
+	}
+	q = q.Arg("args", args)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// ContainerSetWithFileOpts contains options for ContainerSet.WithFile
+type ContainerSetWithFileOpts struct {
+	// Permissions of the new file. Example: 0600
+	Permissions int
+	// A user:group to set for the file.
+	//
+	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
+	//
+	// If the group is omitted, it defaults to the same as the user.
+	Owner string
+	// Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo.txt").
+	Expand bool
+}
+
+// Apply Container.withFile to every container in the set, in parallel.
+//
+// Return a container snapshot with a file added
+func (r *ContainerSet) WithFile(path string, source *File, opts ...ContainerSetWithFileOpts) *ContainerSet {
+	assertNotNil("source", source)
+	q := r.query.Select("withFile")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `permissions` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Permissions) {
+			q = q.Arg("permissions", opts[i].Permissions)
+		}
+		// `owner` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Owner) {
+			q = q.Arg("owner", opts[i].Owner)
+		}
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
+	}
+	q = q.Arg("path", path)
+	q = q.Arg("source", source)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// Apply Container.withLabel to every container in the set, in parallel.
+//
+// Retrieves this container plus the given label.
+func (r *ContainerSet) WithLabel(name string, value string) *ContainerSet {
+	q := r.query.Select("withLabel")
+	q = q.Arg("name", name)
+	q = q.Arg("value", value)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// ContainerSetWithMountedCacheOpts contains options for ContainerSet.WithMountedCache
+type ContainerSetWithMountedCacheOpts struct {
+	// Identifier of the directory to use as the cache volume's root.
+	Source *Directory
+	// Sharing mode of the cache volume.
+	//
+	// Default: SHARED
+	Sharing CacheSharingMode
+	// A user:group to set for the mounted cache directory.
+	//
+	// Note that this changes the ownership of the specified mount along with the initial filesystem provided by source (if any). It does not have any effect if/when the cache has already been created.
+	//
+	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
+	//
+	// If the group is omitted, it defaults to the same as the user.
+	Owner string
+	// Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo").
+	Expand bool
+}
+
+// Apply Container.withMountedCache to every container in the set, in parallel.
+//
+// Retrieves this container plus a cache volume mounted at the given path.
+func (r *ContainerSet) WithMountedCache(path string, cache *CacheVolume, opts ...ContainerSetWithMountedCacheOpts) *ContainerSet {
+	assertNotNil("cache", cache)
+	q := r.query.Select("withMountedCache")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `source` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Source) {
+			q = q.Arg("source", opts[i].Source)
+		}
+		// `sharing` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Sharing) {
+			q = q.Arg("sharing", opts[i].Sharing)
+		}
+		// `owner` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Owner) {
+			q = q.Arg("owner", opts[i].Owner)
+		}
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
+	}
+	q = q.Arg("path", path)
+	q = q.Arg("cache", cache)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// ContainerSetWithMountedDirectoryOpts contains options for ContainerSet.WithMountedDirectory
+type ContainerSetWithMountedDirectoryOpts struct {
+	// A user:group to set for the mounted directory and its contents.
+	//
+	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
+	//
+	// If the group is omitted, it defaults to the same as the user.
+	Owner string
+	// Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo").
+	Expand bool
+}
+
+// Apply Container.withMountedDirectory to every container in the set, in parallel.
+//
+// Retrieves this container plus a directory mounted at the given path.
+func (r *ContainerSet) WithMountedDirectory(path string, source *Directory, opts ...ContainerSetWithMountedDirectoryOpts) *ContainerSet {
+	assertNotNil("source", source)
+	q := r.query.Select("withMountedDirectory")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `owner` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Owner) {
+			q = q.Arg("owner", opts[i].Owner)
+		}
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
+	}
+	q = q.Arg("path", path)
+	q = q.Arg("source", source)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// ContainerSetWithNewFileOpts contains options for ContainerSet.WithNewFile
+type ContainerSetWithNewFileOpts struct {
+	// Permissions of the new file. Example: 0600
+	//
+	// Default: 420
+	Permissions int
+	// A user:group to set for the file.
+	//
+	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
+	//
+	// If the group is omitted, it defaults to the same as the user.
+	Owner string
+	// Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo.txt").
+	Expand bool
+}
+
+// Apply Container.withNewFile to every container in the set, in parallel.
+//
+// Return a new container snapshot, with a file added to its filesystem with text content
+func (r *ContainerSet) WithNewFile(path string, contents string, opts ...ContainerSetWithNewFileOpts) *ContainerSet {
+	q := r.query.Select("withNewFile")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `permissions` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Permissions) {
+			q = q.Arg("permissions", opts[i].Permissions)
+		}
+		// `owner` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Owner) {
+			q = q.Arg("owner", opts[i].Owner)
+		}
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
+	}
+	q = q.Arg("path", path)
+	q = q.Arg("contents", contents)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// Apply Container.withUser to every container in the set, in parallel.
+//
+// Retrieves this container with a different command user.
+func (r *ContainerSet) WithUser(name string) *ContainerSet {
+	q := r.query.Select("withUser")
+	q = q.Arg("name", name)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// ContainerSetWithWorkdirOpts contains options for ContainerSet.WithWorkdir
+type ContainerSetWithWorkdirOpts struct {
+	// Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo").
+	Expand bool
+}
+
+// Apply Container.withWorkdir to every container in the set, in parallel.
+//
+// Change the container's working directory. Like WORKDIR in Dockerfile.
+func (r *ContainerSet) WithWorkdir(path string, opts ...ContainerSetWithWorkdirOpts) *ContainerSet {
+	q := r.query.Select("withWorkdir")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `expand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expand) {
+			q = q.Arg("expand", opts[i].Expand)
+		}
+	}
+	q = q.Arg("path", path)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// Apply Container.withoutEnvVariable to every container in the set, in parallel.
+//
+// Retrieves this container minus the given environment variable.
+func (r *ContainerSet) WithoutEnvVariable(name string) *ContainerSet {
+	q := r.query.Select("withoutEnvVariable")
+	q = q.Arg("name", name)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
+// Reflective module API provided to functions at runtime.
+type CurrentModule struct {
+	query *querybuilder.Selection
+
+	id   *CurrentModuleID
+	name *string
+}
+
+func (r *CurrentModule) WithGraphQLQuery(q *querybuilder.Selection) *CurrentModule {
+	return &CurrentModule{
+		query: q,
+	}
+}
+
+// A unique identifier for this CurrentModule.
+func (r *CurrentModule) ID(ctx context.Context) (CurrentModuleID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response CurrentModuleID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *CurrentModule) XXX_GraphQLType() string {
+	return "CurrentModule"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *CurrentModule) XXX_GraphQLIDType() string {
+	return "CurrentModuleID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *CurrentModule) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *CurrentModule) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The name of the module being executed in
+func (r *CurrentModule) Name(ctx context.Context) (string, error) {
+	if r.name != nil {
+		return *r.name, nil
+	}
+	q := r.query.Select("name")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The directory containing the module's source code loaded into the engine (plus any generated code that may have been created).
+func (r *CurrentModule) Source() *Directory {
+	q := r.query.Select("source")
+
+	return &Directory{
+		query: q,
+	}
+}
+
+// CurrentModuleWorkdirOpts contains options for CurrentModule.Workdir
+type CurrentModuleWorkdirOpts struct {
+	// Exclude artifacts that match the given pattern (e.g., ["node_modules/", ".git*"]).
+	Exclude []string
+	// Include only artifacts that match the given pattern (e.g., ["app/", "package.*"]).
+	Include []string
+}
+
+// Load a directory from the module's scratch working directory, including any changes that may have been made to it during module function execution.
+func (r *CurrentModule) Workdir(path string, opts ...CurrentModuleWorkdirOpts) *Directory {
+	q := r.query.Select("workdir")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `exclude` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Exclude) {
+			q = q.Arg("exclude", opts[i].Exclude)
+		}
+		// `include` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Include) {
+			q = q.Arg("include", opts[i].Include)
+		}
+	}
+	q = q.Arg("path", path)
+
+	return &Directory{
+		query: q,
+	}
+}
+
+// Load a file from the module's scratch working directory, including any changes that may have been made to it during module function execution.Load a file from the module's scratch working directory, including any changes that may have been made to it during module function execution.
+func (r *CurrentModule) WorkdirFile(path string) *File {
+	q := r.query.Select("workdirFile")
+	q = q.Arg("path", path)
+
+	return &File{
+		query: q,
+	}
+}
+
+// A directory.
+type Directory struct {
+	query *querybuilder.Selection
+
+	digest *string
+	exists *bool
+	export *string
+	id     *DirectoryID
+	name   *string
+	sync   *DirectoryID
+}
+type WithDirectoryFunc func(r *Directory) *Directory
+
+// With calls the provided function with current Directory.
+//
+// This is useful for reusability and readability by not breaking the calling chain.
+func (r *Directory) With(f WithDirectoryFunc) *Directory {
+	return f(r)
+}
+
+func (r *Directory) WithGraphQLQuery(q *querybuilder.Selection) *Directory {
+	return &Directory{
+		query: q,
+	}
+}
+
+// Converts this directory to a local git repository
+func (r *Directory) AsGit() *GitRepository {
+	q := r.query.Select("asGit")
+
+	return &GitRepository{
+		query: q,
+	}
+}
+
+// DirectoryAsModuleOpts contains options for Directory.AsModule
+type DirectoryAsModuleOpts struct {
+	// An optional subpath of the directory which contains the module's configuration file.
+	//
+	// If not set, the module source code is loaded from the root of the directory.
+	//
+	// Default: "."
 	SourceRootPath string
 }
 
@@ -3058,6 +3801,41 @@ func (r *Directory) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
+// Lists the images in an OCI image layout directory.
+//
+// Use with Container.importLayout to pick the image to import.
+func (r *Directory) ImageDescriptors(ctx context.Context) ([]ImageDescriptor, error) {
+	q := r.query.Select("imageDescriptors")
+
+	q = q.Select("id")
+
+	type imageDescriptors struct {
+		Id ImageDescriptorID
+	}
+
+	convert := func(fields []imageDescriptors) []ImageDescriptor {
+		out := []ImageDescriptor{}
+
+		for i := range fields {
+			val := ImageDescriptor{id: &fields[i].Id}
+			val.query = q.Root().Select("loadImageDescriptorFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []imageDescriptors
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
 // Returns the name of the directory.
 func (r *Directory) Name(ctx context.Context) (string, error) {
 	if r.name != nil {
@@ -3476,6 +4254,16 @@ func (r *Engine) LocalCache() *EngineCache {
 	}
 }
 
+// The platforms the engine can run containers for, natively or with emulation
+func (r *Engine) Platforms(ctx context.Context) ([]Platform, error) {
+	q := r.query.Select("platforms")
+
+	var response []Platform
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // A cache storage for the Dagger engine
 type EngineCache struct {
 	query *querybuilder.Selection
@@ -4373,6 +5161,30 @@ func (r *Env) WithContainerOutput(name string, description string) *Env {
 	}
 }
 
+// Create or update a binding of type ContainerSet in the environment
+func (r *Env) WithContainerSetInput(name string, value *ContainerSet, description string) *Env {
+	assertNotNil("value", value)
+	q := r.query.Select("withContainerSetInput")
+	q = q.Arg("name", name)
+	q = q.Arg("value", value)
+	q = q.Arg("description", description)
+
+	return &Env{
+		query: q,
+	}
+}
+
+// Declare a desired ContainerSet output to be assigned in the environment
+func (r *Env) WithContainerSetOutput(name string, description string) *Env {
+	q := r.query.Select("withContainerSetOutput")
+	q = q.Arg("name", name)
+	q = q.Arg("description", description)
+
+	return &Env{
+		query: q,
+	}
+}
+
 // Create or update a binding of type Directory in the environment
 func (r *Env) WithDirectoryInput(name string, value *Directory, description string) *Env {
 	assertNotNil("value", value)
@@ -4756,6 +5568,30 @@ func (r *Env) WithStringOutput(name string, description string) *Env {
 	}
 }
 
+// Create or update a binding of type UnionTypeDef in the environment
+func (r *Env) WithUnionTypeDefInput(name string, value *UnionTypeDef, description string) *Env {
+	assertNotNil("value", value)
+	q := r.query.Select("withUnionTypeDefInput")
+	q = q.Arg("name", name)
+	q = q.Arg("value", value)
+	q = q.Arg("description", description)
+
+	return &Env{
+		query: q,
+	}
+}
+
+// Declare a desired UnionTypeDef output to be assigned in the environment
+func (r *Env) WithUnionTypeDefOutput(name string, description string) *Env {
+	q := r.query.Select("withUnionTypeDefOutput")
+	q = q.Arg("name", name)
+	q = q.Arg("description", description)
+
+	return &Env{
+		query: q,
+	}
+}
+
 // An environment variable name and value.
 type EnvVariable struct {
 	query *querybuilder.Selection
@@ -5268,21 +6104,56 @@ func (r *File) XXX_GraphQLIDType() string {
 	return "FileID"
 }
 
-// XXX_GraphQLID is an internal function. It returns the underlying type ID
-func (r *File) XXX_GraphQLID(ctx context.Context) (string, error) {
-	id, err := r.ID(ctx)
-	if err != nil {
-		return "", err
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *File) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *File) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// Lists the images in an OCI tarball or Docker archive.
+//
+// Use with Container.import to pick the image to import.
+func (r *File) ImageDescriptors(ctx context.Context) ([]ImageDescriptor, error) {
+	q := r.query.Select("imageDescriptors")
+
+	q = q.Select("id")
+
+	type imageDescriptors struct {
+		Id ImageDescriptorID
+	}
+
+	convert := func(fields []imageDescriptors) []ImageDescriptor {
+		out := []ImageDescriptor{}
+
+		for i := range fields {
+			val := ImageDescriptor{id: &fields[i].Id}
+			val.query = q.Root().Select("loadImageDescriptorFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
+		}
+
+		return out
 	}
-	return string(id), nil
-}
+	var response []imageDescriptors
 
-func (r *File) MarshalJSON() ([]byte, error) {
-	id, err := r.ID(marshalCtx)
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return json.Marshal(id)
+
+	return convert(response), nil
 }
 
 // Retrieves the name of the file.
@@ -5489,6 +6360,7 @@ func (r *File) WithTimestamps(timestamp int) *File {
 type Function struct {
 	query *querybuilder.Selection
 
+	cacheTTL    *string
 	description *string
 	id          *FunctionID
 	name        *string
@@ -5541,6 +6413,29 @@ func (r *Function) Args(ctx context.Context) ([]FunctionArg, error) {
 	return convert(response), nil
 }
 
+// The arguments that identify the function's result in the cache, if not all of them.
+func (r *Function) CacheKeyArgs(ctx context.Context) ([]string, error) {
+	q := r.query.Select("cacheKeyArgs")
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// How long the function's result may be reused across sessions (e.g. "1h"), if set by its cache policy.
+func (r *Function) CacheTTL(ctx context.Context) (string, error) {
+	if r.cacheTTL != nil {
+		return *r.cacheTTL, nil
+	}
+	q := r.query.Select("cacheTTL")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // A doc string for the function, if any.
 func (r *Function) Description(ctx context.Context) (string, error) {
 	if r.description != nil {
@@ -5673,6 +6568,35 @@ func (r *Function) WithArg(name string, typeDef *TypeDef, opts ...FunctionWithAr
 	}
 }
 
+// FunctionWithCachePolicyOpts contains options for Function.WithCachePolicy
+type FunctionWithCachePolicyOpts struct {
+	// Reuse the result across sessions for at most this duration (e.g. "1h").
+	TTL string
+	// Identify the result by only these arguments, ignoring the others (e.g. a log level).
+	KeyArgs []string
+}
+
+// Returns the function with the given cache policy.
+//
+// By default, a function's result is cached for the rest of the session for the same receiver and arguments.
+func (r *Function) WithCachePolicy(opts ...FunctionWithCachePolicyOpts) *Function {
+	q := r.query.Select("withCachePolicy")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `ttl` optional argument
+		if !querybuilder.IsZeroValue(opts[i].TTL) {
+			q = q.Arg("ttl", opts[i].TTL)
+		}
+		// `keyArgs` optional argument
+		if !querybuilder.IsZeroValue(opts[i].KeyArgs) {
+			q = q.Arg("keyArgs", opts[i].KeyArgs)
+		}
+	}
+
+	return &Function{
+		query: q,
+	}
+}
+
 // Returns the function with the given doc string.
 func (r *Function) WithDescription(description string) *Function {
 	q := r.query.Select("withDescription")
@@ -5910,122 +6834,345 @@ func (r *FunctionCall) InputArgs(ctx context.Context) ([]FunctionCallArgValue, e
 			out = append(out, val)
 		}
 
-		return out
+		return out
+	}
+	var response []inputArgs
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
+// The name of the function being called.
+func (r *FunctionCall) Name(ctx context.Context) (string, error) {
+	if r.name != nil {
+		return *r.name, nil
+	}
+	q := r.query.Select("name")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The value of the parent object of the function being called. If the function is top-level to the module, this is always an empty object.
+func (r *FunctionCall) Parent(ctx context.Context) (JSON, error) {
+	if r.parent != nil {
+		return *r.parent, nil
+	}
+	q := r.query.Select("parent")
+
+	var response JSON
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The name of the parent object of the function being called. If the function is top-level to the module, this is the name of the module.
+func (r *FunctionCall) ParentName(ctx context.Context) (string, error) {
+	if r.parentName != nil {
+		return *r.parentName, nil
+	}
+	q := r.query.Select("parentName")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// Return an error from the function.
+func (r *FunctionCall) ReturnError(ctx context.Context, error *Error) error {
+	assertNotNil("error", error)
+	if r.returnError != nil {
+		return nil
+	}
+	q := r.query.Select("returnError")
+	q = q.Arg("error", error)
+
+	return q.Execute(ctx)
+}
+
+// Set the return value of the function call to the provided value.
+func (r *FunctionCall) ReturnValue(ctx context.Context, value JSON) error {
+	if r.returnValue != nil {
+		return nil
+	}
+	q := r.query.Select("returnValue")
+	q = q.Arg("value", value)
+
+	return q.Execute(ctx)
+}
+
+// A value passed as a named argument to a function call.
+type FunctionCallArgValue struct {
+	query *querybuilder.Selection
+
+	id    *FunctionCallArgValueID
+	name  *string
+	value *JSON
+}
+
+func (r *FunctionCallArgValue) WithGraphQLQuery(q *querybuilder.Selection) *FunctionCallArgValue {
+	return &FunctionCallArgValue{
+		query: q,
+	}
+}
+
+// A unique identifier for this FunctionCallArgValue.
+func (r *FunctionCallArgValue) ID(ctx context.Context) (FunctionCallArgValueID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response FunctionCallArgValueID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *FunctionCallArgValue) XXX_GraphQLType() string {
+	return "FunctionCallArgValue"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *FunctionCallArgValue) XXX_GraphQLIDType() string {
+	return "FunctionCallArgValueID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *FunctionCallArgValue) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *FunctionCallArgValue) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The name of the argument.
+func (r *FunctionCallArgValue) Name(ctx context.Context) (string, error) {
+	if r.name != nil {
+		return *r.name, nil
+	}
+	q := r.query.Select("name")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The value of the argument represented as a JSON serialized string.
+func (r *FunctionCallArgValue) Value(ctx context.Context) (JSON, error) {
+	if r.value != nil {
+		return *r.value, nil
+	}
+	q := r.query.Select("value")
+
+	var response JSON
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The result of running an SDK's codegen.
+type GeneratedCode struct {
+	query *querybuilder.Selection
+
+	id *GeneratedCodeID
+}
+type WithGeneratedCodeFunc func(r *GeneratedCode) *GeneratedCode
+
+// With calls the provided function with current GeneratedCode.
+//
+// This is useful for reusability and readability by not breaking the calling chain.
+func (r *GeneratedCode) With(f WithGeneratedCodeFunc) *GeneratedCode {
+	return f(r)
+}
+
+func (r *GeneratedCode) WithGraphQLQuery(q *querybuilder.Selection) *GeneratedCode {
+	return &GeneratedCode{
+		query: q,
+	}
+}
+
+// The directory containing the generated code.
+func (r *GeneratedCode) Code() *Directory {
+	q := r.query.Select("code")
+
+	return &Directory{
+		query: q,
+	}
+}
+
+// A unique identifier for this GeneratedCode.
+func (r *GeneratedCode) ID(ctx context.Context) (GeneratedCodeID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response GeneratedCodeID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *GeneratedCode) XXX_GraphQLType() string {
+	return "GeneratedCode"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *GeneratedCode) XXX_GraphQLIDType() string {
+	return "GeneratedCodeID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *GeneratedCode) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
 	}
-	var response []inputArgs
-
-	q = q.Bind(&response)
+	return string(id), nil
+}
 
-	err := q.Execute(ctx)
+func (r *GeneratedCode) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
 	if err != nil {
 		return nil, err
 	}
-
-	return convert(response), nil
+	return json.Marshal(id)
 }
 
-// The name of the function being called.
-func (r *FunctionCall) Name(ctx context.Context) (string, error) {
-	if r.name != nil {
-		return *r.name, nil
-	}
-	q := r.query.Select("name")
+// List of paths to mark generated in version control (i.e. .gitattributes).
+func (r *GeneratedCode) VcsGeneratedPaths(ctx context.Context) ([]string, error) {
+	q := r.query.Select("vcsGeneratedPaths")
 
-	var response string
+	var response []string
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
-// The value of the parent object of the function being called. If the function is top-level to the module, this is always an empty object.
-func (r *FunctionCall) Parent(ctx context.Context) (JSON, error) {
-	if r.parent != nil {
-		return *r.parent, nil
-	}
-	q := r.query.Select("parent")
+// List of paths to ignore in version control (i.e. .gitignore).
+func (r *GeneratedCode) VcsIgnoredPaths(ctx context.Context) ([]string, error) {
+	q := r.query.Select("vcsIgnoredPaths")
 
-	var response JSON
+	var response []string
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
-// The name of the parent object of the function being called. If the function is top-level to the module, this is the name of the module.
-func (r *FunctionCall) ParentName(ctx context.Context) (string, error) {
-	if r.parentName != nil {
-		return *r.parentName, nil
+// Set the list of paths to mark generated in version control.
+func (r *GeneratedCode) WithVCSGeneratedPaths(paths []string) *GeneratedCode {
+	q := r.query.Select("withVCSGeneratedPaths")
+	q = q.Arg("paths", paths)
+
+	return &GeneratedCode{
+		query: q,
 	}
-	q := r.query.Select("parentName")
+}
 
-	var response string
+// Set the list of paths to ignore in version control.
+func (r *GeneratedCode) WithVCSIgnoredPaths(paths []string) *GeneratedCode {
+	q := r.query.Select("withVCSIgnoredPaths")
+	q = q.Arg("paths", paths)
 
-	q = q.Bind(&response)
-	return response, q.Execute(ctx)
+	return &GeneratedCode{
+		query: q,
+	}
 }
 
-// Return an error from the function.
-func (r *FunctionCall) ReturnError(ctx context.Context, error *Error) error {
-	assertNotNil("error", error)
-	if r.returnError != nil {
-		return nil
-	}
-	q := r.query.Select("returnError")
-	q = q.Arg("error", error)
+// A git ref (tag, branch, or commit).
+type GitRef struct {
+	query *querybuilder.Selection
 
-	return q.Execute(ctx)
+	commit *string
+	id     *GitRefID
+	ref    *string
 }
+type WithGitRefFunc func(r *GitRef) *GitRef
 
-// Set the return value of the function call to the provided value.
-func (r *FunctionCall) ReturnValue(ctx context.Context, value JSON) error {
-	if r.returnValue != nil {
-		return nil
-	}
-	q := r.query.Select("returnValue")
-	q = q.Arg("value", value)
+// With calls the provided function with current GitRef.
+//
+// This is useful for reusability and readability by not breaking the calling chain.
+func (r *GitRef) With(f WithGitRefFunc) *GitRef {
+	return f(r)
+}
 
-	return q.Execute(ctx)
+func (r *GitRef) WithGraphQLQuery(q *querybuilder.Selection) *GitRef {
+	return &GitRef{
+		query: q,
+	}
 }
 
-// A value passed as a named argument to a function call.
-type FunctionCallArgValue struct {
-	query *querybuilder.Selection
+// The resolved commit id at this ref.
+func (r *GitRef) Commit(ctx context.Context) (string, error) {
+	if r.commit != nil {
+		return *r.commit, nil
+	}
+	q := r.query.Select("commit")
 
-	id    *FunctionCallArgValueID
-	name  *string
-	value *JSON
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-func (r *FunctionCallArgValue) WithGraphQLQuery(q *querybuilder.Selection) *FunctionCallArgValue {
-	return &FunctionCallArgValue{
+// Find the best common ancestor between this ref and another ref.
+func (r *GitRef) CommonAncestor(other *GitRef) *GitRef {
+	assertNotNil("other", other)
+	q := r.query.Select("commonAncestor")
+	q = q.Arg("other", other)
+
+	return &GitRef{
 		query: q,
 	}
 }
 
-// A unique identifier for this FunctionCallArgValue.
-func (r *FunctionCallArgValue) ID(ctx context.Context) (FunctionCallArgValueID, error) {
+// A unique identifier for this GitRef.
+func (r *GitRef) ID(ctx context.Context) (GitRefID, error) {
 	if r.id != nil {
 		return *r.id, nil
 	}
 	q := r.query.Select("id")
 
-	var response FunctionCallArgValueID
+	var response GitRefID
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
 // XXX_GraphQLType is an internal function. It returns the native GraphQL type name
-func (r *FunctionCallArgValue) XXX_GraphQLType() string {
-	return "FunctionCallArgValue"
+func (r *GitRef) XXX_GraphQLType() string {
+	return "GitRef"
 }
 
 // XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
-func (r *FunctionCallArgValue) XXX_GraphQLIDType() string {
-	return "FunctionCallArgValueID"
+func (r *GitRef) XXX_GraphQLIDType() string {
+	return "GitRefID"
 }
 
 // XXX_GraphQLID is an internal function. It returns the underlying type ID
-func (r *FunctionCallArgValue) XXX_GraphQLID(ctx context.Context) (string, error) {
+func (r *GitRef) XXX_GraphQLID(ctx context.Context) (string, error) {
 	id, err := r.ID(ctx)
 	if err != nil {
 		return "", err
@@ -6033,7 +7180,7 @@ func (r *FunctionCallArgValue) XXX_GraphQLID(ctx context.Context) (string, error
 	return string(id), nil
 }
 
-func (r *FunctionCallArgValue) MarshalJSON() ([]byte, error) {
+func (r *GitRef) MarshalJSON() ([]byte, error) {
 	id, err := r.ID(marshalCtx)
 	if err != nil {
 		return nil, err
@@ -6041,12 +7188,12 @@ func (r *FunctionCallArgValue) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
-// The name of the argument.
-func (r *FunctionCallArgValue) Name(ctx context.Context) (string, error) {
-	if r.name != nil {
-		return *r.name, nil
+// The resolved ref name at this ref.
+func (r *GitRef) Ref(ctx context.Context) (string, error) {
+	if r.ref != nil {
+		return *r.ref, nil
 	}
-	q := r.query.Select("name")
+	q := r.query.Select("ref")
 
 	var response string
 
@@ -6054,74 +7201,133 @@ func (r *FunctionCallArgValue) Name(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
-// The value of the argument represented as a JSON serialized string.
-func (r *FunctionCallArgValue) Value(ctx context.Context) (JSON, error) {
-	if r.value != nil {
-		return *r.value, nil
-	}
-	q := r.query.Select("value")
+// GitRefTreeOpts contains options for GitRef.Tree
+type GitRefTreeOpts struct {
+	// Set to true to discard .git directory.
+	DiscardGitDir bool
+	// The depth of the tree to fetch.
+	//
+	// Default: 1
+	Depth int
+}
 
-	var response JSON
+// The filesystem tree at this ref.
+func (r *GitRef) Tree(opts ...GitRefTreeOpts) *Directory {
+	q := r.query.Select("tree")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `discardGitDir` optional argument
+		if !querybuilder.IsZeroValue(opts[i].DiscardGitDir) {
+			q = q.Arg("discardGitDir", opts[i].DiscardGitDir)
+		}
+		// `depth` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Depth) {
+			q = q.Arg("depth", opts[i].Depth)
+		}
+	}
 
-	q = q.Bind(&response)
-	return response, q.Execute(ctx)
+	return &Directory{
+		query: q,
+	}
 }
 
-// The result of running an SDK's codegen.
-type GeneratedCode struct {
+// A git repository.
+type GitRepository struct {
 	query *querybuilder.Selection
 
-	id *GeneratedCodeID
+	id  *GitRepositoryID
+	url *string
 }
-type WithGeneratedCodeFunc func(r *GeneratedCode) *GeneratedCode
+type WithGitRepositoryFunc func(r *GitRepository) *GitRepository
 
-// With calls the provided function with current GeneratedCode.
+// With calls the provided function with current GitRepository.
 //
 // This is useful for reusability and readability by not breaking the calling chain.
-func (r *GeneratedCode) With(f WithGeneratedCodeFunc) *GeneratedCode {
+func (r *GitRepository) With(f WithGitRepositoryFunc) *GitRepository {
 	return f(r)
 }
 
-func (r *GeneratedCode) WithGraphQLQuery(q *querybuilder.Selection) *GeneratedCode {
-	return &GeneratedCode{
+func (r *GitRepository) WithGraphQLQuery(q *querybuilder.Selection) *GitRepository {
+	return &GitRepository{
 		query: q,
 	}
 }
 
-// The directory containing the generated code.
-func (r *GeneratedCode) Code() *Directory {
-	q := r.query.Select("code")
+// Returns details of a branch.
+func (r *GitRepository) Branch(name string) *GitRef {
+	q := r.query.Select("branch")
+	q = q.Arg("name", name)
 
-	return &Directory{
+	return &GitRef{
 		query: q,
 	}
 }
 
-// A unique identifier for this GeneratedCode.
-func (r *GeneratedCode) ID(ctx context.Context) (GeneratedCodeID, error) {
+// GitRepositoryBranchesOpts contains options for GitRepository.Branches
+type GitRepositoryBranchesOpts struct {
+	// Glob patterns (e.g., "refs/tags/v*").
+	Patterns []string
+}
+
+// branches that match any of the given glob patterns.
+func (r *GitRepository) Branches(ctx context.Context, opts ...GitRepositoryBranchesOpts) ([]string, error) {
+	q := r.query.Select("branches")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `patterns` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Patterns) {
+			q = q.Arg("patterns", opts[i].Patterns)
+		}
+	}
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// Returns details of a commit.
+func (r *GitRepository) Commit(id string) *GitRef {
+	q := r.query.Select("commit")
+	q = q.Arg("id", id)
+
+	return &GitRef{
+		query: q,
+	}
+}
+
+// Returns details for HEAD.
+func (r *GitRepository) Head() *GitRef {
+	q := r.query.Select("head")
+
+	return &GitRef{
+		query: q,
+	}
+}
+
+// A unique identifier for this GitRepository.
+func (r *GitRepository) ID(ctx context.Context) (GitRepositoryID, error) {
 	if r.id != nil {
 		return *r.id, nil
 	}
 	q := r.query.Select("id")
 
-	var response GeneratedCodeID
+	var response GitRepositoryID
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
 // XXX_GraphQLType is an internal function. It returns the native GraphQL type name
-func (r *GeneratedCode) XXX_GraphQLType() string {
-	return "GeneratedCode"
+func (r *GitRepository) XXX_GraphQLType() string {
+	return "GitRepository"
 }
 
 // XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
-func (r *GeneratedCode) XXX_GraphQLIDType() string {
-	return "GeneratedCodeID"
+func (r *GitRepository) XXX_GraphQLIDType() string {
+	return "GitRepositoryID"
 }
 
 // XXX_GraphQLID is an internal function. It returns the underlying type ID
-func (r *GeneratedCode) XXX_GraphQLID(ctx context.Context) (string, error) {
+func (r *GitRepository) XXX_GraphQLID(ctx context.Context) (string, error) {
 	id, err := r.ID(ctx)
 	if err != nil {
 		return "", err
@@ -6129,7 +7335,7 @@ func (r *GeneratedCode) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
-func (r *GeneratedCode) MarshalJSON() ([]byte, error) {
+func (r *GitRepository) MarshalJSON() ([]byte, error) {
 	id, err := r.ID(marshalCtx)
 	if err != nil {
 		return nil, err
@@ -6137,9 +7343,50 @@ func (r *GeneratedCode) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
-// List of paths to mark generated in version control (i.e. .gitattributes).
-func (r *GeneratedCode) VcsGeneratedPaths(ctx context.Context) ([]string, error) {
-	q := r.query.Select("vcsGeneratedPaths")
+// Returns details for the latest semver tag.
+func (r *GitRepository) LatestVersion() *GitRef {
+	q := r.query.Select("latestVersion")
+
+	return &GitRef{
+		query: q,
+	}
+}
+
+// Returns details of a ref.
+func (r *GitRepository) Ref(name string) *GitRef {
+	q := r.query.Select("ref")
+	q = q.Arg("name", name)
+
+	return &GitRef{
+		query: q,
+	}
+}
+
+// Returns details of a tag.
+func (r *GitRepository) Tag(name string) *GitRef {
+	q := r.query.Select("tag")
+	q = q.Arg("name", name)
+
+	return &GitRef{
+		query: q,
+	}
+}
+
+// GitRepositoryTagsOpts contains options for GitRepository.Tags
+type GitRepositoryTagsOpts struct {
+	// Glob patterns (e.g., "refs/tags/v*").
+	Patterns []string
+}
+
+// tags that match any of the given glob patterns.
+func (r *GitRepository) Tags(ctx context.Context, opts ...GitRepositoryTagsOpts) ([]string, error) {
+	q := r.query.Select("tags")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `patterns` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Patterns) {
+			q = q.Arg("patterns", opts[i].Patterns)
+		}
+	}
 
 	var response []string
 
@@ -6147,108 +7394,149 @@ func (r *GeneratedCode) VcsGeneratedPaths(ctx context.Context) ([]string, error)
 	return response, q.Execute(ctx)
 }
 
-// List of paths to ignore in version control (i.e. .gitignore).
-func (r *GeneratedCode) VcsIgnoredPaths(ctx context.Context) ([]string, error) {
-	q := r.query.Select("vcsIgnoredPaths")
+// The URL of the git repository.
+func (r *GitRepository) URL(ctx context.Context) (string, error) {
+	if r.url != nil {
+		return *r.url, nil
+	}
+	q := r.query.Select("url")
 
-	var response []string
+	var response string
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
-// Set the list of paths to mark generated in version control.
-func (r *GeneratedCode) WithVCSGeneratedPaths(paths []string) *GeneratedCode {
-	q := r.query.Select("withVCSGeneratedPaths")
-	q = q.Arg("paths", paths)
+// Header to authenticate the remote with.
+//
+// Deprecated: Use "httpAuthHeader" in the constructor instead.
+func (r *GitRepository) WithAuthHeader(header *Secret) *GitRepository {
+	assertNotNil("header", header)
+	q := r.query.Select("withAuthHeader")
+	q = q.Arg("header", header)
 
-	return &GeneratedCode{
+	return &GitRepository{
 		query: q,
 	}
 }
 
-// Set the list of paths to ignore in version control.
-func (r *GeneratedCode) WithVCSIgnoredPaths(paths []string) *GeneratedCode {
-	q := r.query.Select("withVCSIgnoredPaths")
-	q = q.Arg("paths", paths)
+// Token to authenticate the remote with.
+//
+// Deprecated: Use "httpAuthToken" in the constructor instead.
+func (r *GitRepository) WithAuthToken(token *Secret) *GitRepository {
+	assertNotNil("token", token)
+	q := r.query.Select("withAuthToken")
+	q = q.Arg("token", token)
 
-	return &GeneratedCode{
+	return &GitRepository{
 		query: q,
 	}
 }
 
-// A git ref (tag, branch, or commit).
-type GitRef struct {
+// Information about the host environment.
+type Host struct {
 	query *querybuilder.Selection
 
-	commit *string
-	id     *GitRefID
-	ref    *string
+	id *HostID
 }
-type WithGitRefFunc func(r *GitRef) *GitRef
 
-// With calls the provided function with current GitRef.
-//
-// This is useful for reusability and readability by not breaking the calling chain.
-func (r *GitRef) With(f WithGitRefFunc) *GitRef {
-	return f(r)
+func (r *Host) WithGraphQLQuery(q *querybuilder.Selection) *Host {
+	return &Host{
+		query: q,
+	}
 }
 
-func (r *GitRef) WithGraphQLQuery(q *querybuilder.Selection) *GitRef {
-	return &GitRef{
+// Accesses a container image on the host.
+func (r *Host) ContainerImage(name string) *Container {
+	q := r.query.Select("containerImage")
+	q = q.Arg("name", name)
+
+	return &Container{
 		query: q,
 	}
 }
 
-// The resolved commit id at this ref.
-func (r *GitRef) Commit(ctx context.Context) (string, error) {
-	if r.commit != nil {
-		return *r.commit, nil
+// HostDirectoryOpts contains options for Host.Directory
+type HostDirectoryOpts struct {
+	// Exclude artifacts that match the given pattern (e.g., ["node_modules/", ".git*"]).
+	Exclude []string
+	// Include only artifacts that match the given pattern (e.g., ["app/", "package.*"]).
+	Include []string
+	// If true, the directory will always be reloaded from the host.
+	NoCache bool
+}
+
+// Accesses a directory on the host.
+func (r *Host) Directory(path string, opts ...HostDirectoryOpts) *Directory {
+	q := r.query.Select("directory")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `exclude` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Exclude) {
+			q = q.Arg("exclude", opts[i].Exclude)
+		}
+		// `include` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Include) {
+			q = q.Arg("include", opts[i].Include)
+		}
+		// `noCache` optional argument
+		if !querybuilder.IsZeroValue(opts[i].NoCache) {
+			q = q.Arg("noCache", opts[i].NoCache)
+		}
 	}
-	q := r.query.Select("commit")
+	q = q.Arg("path", path)
 
-	var response string
+	return &Directory{
+		query: q,
+	}
+}
 
-	q = q.Bind(&response)
-	return response, q.Execute(ctx)
+// HostFileOpts contains options for Host.File
+type HostFileOpts struct {
+	// If true, the file will always be reloaded from the host.
+	NoCache bool
 }
 
-// Find the best common ancestor between this ref and another ref.
-func (r *GitRef) CommonAncestor(other *GitRef) *GitRef {
-	assertNotNil("other", other)
-	q := r.query.Select("commonAncestor")
-	q = q.Arg("other", other)
+// Accesses a file on the host.
+func (r *Host) File(path string, opts ...HostFileOpts) *File {
+	q := r.query.Select("file")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `noCache` optional argument
+		if !querybuilder.IsZeroValue(opts[i].NoCache) {
+			q = q.Arg("noCache", opts[i].NoCache)
+		}
+	}
+	q = q.Arg("path", path)
 
-	return &GitRef{
+	return &File{
 		query: q,
 	}
 }
 
-// A unique identifier for this GitRef.
-func (r *GitRef) ID(ctx context.Context) (GitRefID, error) {
+// A unique identifier for this Host.
+func (r *Host) ID(ctx context.Context) (HostID, error) {
 	if r.id != nil {
 		return *r.id, nil
 	}
 	q := r.query.Select("id")
 
-	var response GitRefID
+	var response HostID
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
 // XXX_GraphQLType is an internal function. It returns the native GraphQL type name
-func (r *GitRef) XXX_GraphQLType() string {
-	return "GitRef"
+func (r *Host) XXX_GraphQLType() string {
+	return "Host"
 }
 
 // XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
-func (r *GitRef) XXX_GraphQLIDType() string {
-	return "GitRefID"
+func (r *Host) XXX_GraphQLIDType() string {
+	return "HostID"
 }
 
 // XXX_GraphQLID is an internal function. It returns the underlying type ID
-func (r *GitRef) XXX_GraphQLID(ctx context.Context) (string, error) {
+func (r *Host) XXX_GraphQLID(ctx context.Context) (string, error) {
 	id, err := r.ID(ctx)
 	if err != nil {
 		return "", err
@@ -6256,154 +7544,158 @@ func (r *GitRef) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
-func (r *GitRef) MarshalJSON() ([]byte, error) {
+func (r *Host) MarshalJSON() ([]byte, error) {
 	id, err := r.ID(marshalCtx)
-	if err != nil {
-		return nil, err
-	}
-	return json.Marshal(id)
-}
-
-// The resolved ref name at this ref.
-func (r *GitRef) Ref(ctx context.Context) (string, error) {
-	if r.ref != nil {
-		return *r.ref, nil
-	}
-	q := r.query.Select("ref")
-
-	var response string
-
-	q = q.Bind(&response)
-	return response, q.Execute(ctx)
-}
-
-// GitRefTreeOpts contains options for GitRef.Tree
-type GitRefTreeOpts struct {
-	// Set to true to discard .git directory.
-	DiscardGitDir bool
-	// The depth of the tree to fetch.
-	//
-	// Default: 1
-	Depth int
-}
-
-// The filesystem tree at this ref.
-func (r *GitRef) Tree(opts ...GitRefTreeOpts) *Directory {
-	q := r.query.Select("tree")
-	for i := len(opts) - 1; i >= 0; i-- {
-		// `discardGitDir` optional argument
-		if !querybuilder.IsZeroValue(opts[i].DiscardGitDir) {
-			q = q.Arg("discardGitDir", opts[i].DiscardGitDir)
-		}
-		// `depth` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Depth) {
-			q = q.Arg("depth", opts[i].Depth)
-		}
-	}
-
-	return &Directory{
-		query: q,
-	}
-}
-
-// A git repository.
-type GitRepository struct {
-	query *querybuilder.Selection
-
-	id  *GitRepositoryID
-	url *string
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
 }
-type WithGitRepositoryFunc func(r *GitRepository) *GitRepository
 
-// With calls the provided function with current GitRepository.
-//
-// This is useful for reusability and readability by not breaking the calling chain.
-func (r *GitRepository) With(f WithGitRepositoryFunc) *GitRepository {
-	return f(r)
+// HostServiceOpts contains options for Host.Service
+type HostServiceOpts struct {
+	// Upstream host to forward traffic to.
+	//
+	// Default: "localhost"
+	Host string
 }
 
-func (r *GitRepository) WithGraphQLQuery(q *querybuilder.Selection) *GitRepository {
-	return &GitRepository{
+// Creates a service that forwards traffic to a specified address via the host.
+func (r *Host) Service(ports []PortForward, opts ...HostServiceOpts) *Service {
+	q := r.query.Select("service")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `host` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Host) {
+			q = q.Arg("host", opts[i].Host)
+		}
+	}
+	q = q.Arg("ports", ports)
+
+	return &Service{
 		query: q,
 	}
 }
 
-// Returns details of a branch.
-func (r *GitRepository) Branch(name string) *GitRef {
-	q := r.query.Select("branch")
+// Sets a secret given a user-defined name and the file path on the host, and returns the secret.
+//
+// The file is limited to a size of 512000 bytes.
+//
+// Deprecated: setSecretFile is superceded by use of the secret API with file:// URIs
+func (r *Host) SetSecretFile(name string, path string) *Secret {
+	q := r.query.Select("setSecretFile")
 	q = q.Arg("name", name)
+	q = q.Arg("path", path)
 
-	return &GitRef{
+	return &Secret{
 		query: q,
 	}
 }
 
-// GitRepositoryBranchesOpts contains options for GitRepository.Branches
-type GitRepositoryBranchesOpts struct {
-	// Glob patterns (e.g., "refs/tags/v*").
-	Patterns []string
+// HostTunnelOpts contains options for Host.Tunnel
+type HostTunnelOpts struct {
+	// Map each service port to the same port on the host, as if the service were running natively.
+	//
+	// Note: enabling may result in port conflicts.
+	Native bool
+	// Configure explicit port forwarding rules for the tunnel.
+	//
+	// If a port's frontend is unspecified or 0, a random port will be chosen by the host.
+	//
+	// If no ports are given, all of the service's ports are forwarded. If native is true, each port maps to the same port on the host. If native is false, each port maps to a random port chosen by the host.
+	//
+	// If ports are given and native is true, the ports are additive.
+	Ports []PortForward
 }
 
-// branches that match any of the given glob patterns.
-func (r *GitRepository) Branches(ctx context.Context, opts ...GitRepositoryBranchesOpts) ([]string, error) {
-	q := r.query.Select("branches")
+// Creates a tunnel that forwards traffic from the host to a service.
+func (r *Host) Tunnel(service *Service, opts ...HostTunnelOpts) *Service {
+	assertNotNil("service", service)
+	q := r.query.Select("tunnel")
 	for i := len(opts) - 1; i >= 0; i-- {
-		// `patterns` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Patterns) {
-			q = q.Arg("patterns", opts[i].Patterns)
+		// `native` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Native) {
+			q = q.Arg("native", opts[i].Native)
+		}
+		// `ports` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Ports) {
+			q = q.Arg("ports", opts[i].Ports)
 		}
 	}
+	q = q.Arg("service", service)
 
-	var response []string
-
-	q = q.Bind(&response)
-	return response, q.Execute(ctx)
+	return &Service{
+		query: q,
+	}
 }
 
-// Returns details of a commit.
-func (r *GitRepository) Commit(id string) *GitRef {
-	q := r.query.Select("commit")
-	q = q.Arg("id", id)
+// Accesses a Unix socket on the host.
+func (r *Host) UnixSocket(path string) *Socket {
+	q := r.query.Select("unixSocket")
+	q = q.Arg("path", path)
 
-	return &GitRef{
+	return &Socket{
 		query: q,
 	}
 }
 
-// Returns details for HEAD.
-func (r *GitRepository) Head() *GitRef {
-	q := r.query.Select("head")
+// The first difference found between two IDs.
+type IDDiff struct {
+	query *querybuilder.Selection
 
-	return &GitRef{
+	arg         *string
+	id          *IDDiffID
+	kind        *string
+	left        *string
+	leftDigest  *string
+	path        *string
+	right       *string
+	rightDigest *string
+}
+
+func (r *IDDiff) WithGraphQLQuery(q *querybuilder.Selection) *IDDiff {
+	return &IDDiff{
 		query: q,
 	}
 }
 
-// A unique identifier for this GitRepository.
-func (r *GitRepository) ID(ctx context.Context) (GitRepositoryID, error) {
+// The name of the diverging argument, if any.
+func (r *IDDiff) Arg(ctx context.Context) (string, error) {
+	if r.arg != nil {
+		return *r.arg, nil
+	}
+	q := r.query.Select("arg")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A unique identifier for this IDDiff.
+func (r *IDDiff) ID(ctx context.Context) (IDDiffID, error) {
 	if r.id != nil {
 		return *r.id, nil
 	}
 	q := r.query.Select("id")
 
-	var response GitRepositoryID
+	var response IDDiffID
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
 // XXX_GraphQLType is an internal function. It returns the native GraphQL type name
-func (r *GitRepository) XXX_GraphQLType() string {
-	return "GitRepository"
+func (r *IDDiff) XXX_GraphQLType() string {
+	return "IDDiff"
 }
 
 // XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
-func (r *GitRepository) XXX_GraphQLIDType() string {
-	return "GitRepositoryID"
+func (r *IDDiff) XXX_GraphQLIDType() string {
+	return "IDDiffID"
 }
 
 // XXX_GraphQLID is an internal function. It returns the underlying type ID
-func (r *GitRepository) XXX_GraphQLID(ctx context.Context) (string, error) {
+func (r *IDDiff) XXX_GraphQLID(ctx context.Context) (string, error) {
 	id, err := r.ID(ctx)
 	if err != nil {
 		return "", err
@@ -6411,7 +7703,7 @@ func (r *GitRepository) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
-func (r *GitRepository) MarshalJSON() ([]byte, error) {
+func (r *IDDiff) MarshalJSON() ([]byte, error) {
 	id, err := r.ID(marshalCtx)
 	if err != nil {
 		return nil, err
@@ -6419,63 +7711,51 @@ func (r *GitRepository) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
-// Returns details for the latest semver tag.
-func (r *GitRepository) LatestVersion() *GitRef {
-	q := r.query.Select("latestVersion")
-
-	return &GitRef{
-		query: q,
+// The kind of difference: field, type, nth, view, module, arg, or content.
+func (r *IDDiff) Kind(ctx context.Context) (string, error) {
+	if r.kind != nil {
+		return *r.kind, nil
 	}
-}
+	q := r.query.Select("kind")
 
-// Returns details of a ref.
-func (r *GitRepository) Ref(name string) *GitRef {
-	q := r.query.Select("ref")
-	q = q.Arg("name", name)
+	var response string
 
-	return &GitRef{
-		query: q,
-	}
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-// Returns details of a tag.
-func (r *GitRepository) Tag(name string) *GitRef {
-	q := r.query.Select("tag")
-	q = q.Arg("name", name)
-
-	return &GitRef{
-		query: q,
+// The diverging value in the first ID.
+func (r *IDDiff) Left(ctx context.Context) (string, error) {
+	if r.left != nil {
+		return *r.left, nil
 	}
-}
+	q := r.query.Select("left")
 
-// GitRepositoryTagsOpts contains options for GitRepository.Tags
-type GitRepositoryTagsOpts struct {
-	// Glob patterns (e.g., "refs/tags/v*").
-	Patterns []string
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-// tags that match any of the given glob patterns.
-func (r *GitRepository) Tags(ctx context.Context, opts ...GitRepositoryTagsOpts) ([]string, error) {
-	q := r.query.Select("tags")
-	for i := len(opts) - 1; i >= 0; i-- {
-		// `patterns` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Patterns) {
-			q = q.Arg("patterns", opts[i].Patterns)
-		}
+// The digest of the diverging call in the first ID.
+func (r *IDDiff) LeftDigest(ctx context.Context) (string, error) {
+	if r.leftDigest != nil {
+		return *r.leftDigest, nil
 	}
+	q := r.query.Select("leftDigest")
 
-	var response []string
+	var response string
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
-// The URL of the git repository.
-func (r *GitRepository) URL(ctx context.Context) (string, error) {
-	if r.url != nil {
-		return *r.url, nil
+// The path of the diverging call in the first ID.
+func (r *IDDiff) Path(ctx context.Context) (string, error) {
+	if r.path != nil {
+		return *r.path, nil
 	}
-	q := r.query.Select("url")
+	q := r.query.Select("path")
 
 	var response string
 
@@ -6483,136 +7763,121 @@ func (r *GitRepository) URL(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
-// Header to authenticate the remote with.
-//
-// Deprecated: Use "httpAuthHeader" in the constructor instead.
-func (r *GitRepository) WithAuthHeader(header *Secret) *GitRepository {
-	assertNotNil("header", header)
-	q := r.query.Select("withAuthHeader")
-	q = q.Arg("header", header)
-
-	return &GitRepository{
-		query: q,
+// The diverging value in the second ID.
+func (r *IDDiff) Right(ctx context.Context) (string, error) {
+	if r.right != nil {
+		return *r.right, nil
 	}
-}
+	q := r.query.Select("right")
 
-// Token to authenticate the remote with.
-//
-// Deprecated: Use "httpAuthToken" in the constructor instead.
-func (r *GitRepository) WithAuthToken(token *Secret) *GitRepository {
-	assertNotNil("token", token)
-	q := r.query.Select("withAuthToken")
-	q = q.Arg("token", token)
+	var response string
 
-	return &GitRepository{
-		query: q,
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The digest of the diverging call in the second ID.
+func (r *IDDiff) RightDigest(ctx context.Context) (string, error) {
+	if r.rightDigest != nil {
+		return *r.rightDigest, nil
 	}
+	q := r.query.Select("rightDigest")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-// Information about the host environment.
-type Host struct {
+// An image found in an OCI tarball, Docker archive or OCI layout directory.
+type ImageDescriptor struct {
 	query *querybuilder.Selection
 
-	id *HostID
+	digest      *string
+	id          *ImageDescriptorID
+	indexDigest *string
+	mediaType   *string
+	platform    *string
+	tag         *string
 }
 
-func (r *Host) WithGraphQLQuery(q *querybuilder.Selection) *Host {
-	return &Host{
+func (r *ImageDescriptor) WithGraphQLQuery(q *querybuilder.Selection) *ImageDescriptor {
+	return &ImageDescriptor{
 		query: q,
 	}
 }
 
-// Accesses a container image on the host.
-func (r *Host) ContainerImage(name string) *Container {
-	q := r.query.Select("containerImage")
-	q = q.Arg("name", name)
+// The annotations of the image descriptor, including those inherited from its multi-platform index.
+func (r *ImageDescriptor) Annotations(ctx context.Context) ([]Label, error) {
+	q := r.query.Select("annotations")
 
-	return &Container{
-		query: q,
+	q = q.Select("id")
+
+	type annotations struct {
+		Id LabelID
 	}
-}
 
-// HostDirectoryOpts contains options for Host.Directory
-type HostDirectoryOpts struct {
-	// Exclude artifacts that match the given pattern (e.g., ["node_modules/", ".git*"]).
-	Exclude []string
-	// Include only artifacts that match the given pattern (e.g., ["app/", "package.*"]).
-	Include []string
-	// If true, the directory will always be reloaded from the host.
-	NoCache bool
-}
+	convert := func(fields []annotations) []Label {
+		out := []Label{}
 
-// Accesses a directory on the host.
-func (r *Host) Directory(path string, opts ...HostDirectoryOpts) *Directory {
-	q := r.query.Select("directory")
-	for i := len(opts) - 1; i >= 0; i-- {
-		// `exclude` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Exclude) {
-			q = q.Arg("exclude", opts[i].Exclude)
-		}
-		// `include` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Include) {
-			q = q.Arg("include", opts[i].Include)
-		}
-		// `noCache` optional argument
-		if !querybuilder.IsZeroValue(opts[i].NoCache) {
-			q = q.Arg("noCache", opts[i].NoCache)
+		for i := range fields {
+			val := Label{id: &fields[i].Id}
+			val.query = q.Root().Select("loadLabelFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
 		}
+
+		return out
 	}
-	q = q.Arg("path", path)
+	var response []annotations
 
-	return &Directory{
-		query: q,
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// HostFileOpts contains options for Host.File
-type HostFileOpts struct {
-	// If true, the file will always be reloaded from the host.
-	NoCache bool
+	return convert(response), nil
 }
 
-// Accesses a file on the host.
-func (r *Host) File(path string, opts ...HostFileOpts) *File {
-	q := r.query.Select("file")
-	for i := len(opts) - 1; i >= 0; i-- {
-		// `noCache` optional argument
-		if !querybuilder.IsZeroValue(opts[i].NoCache) {
-			q = q.Arg("noCache", opts[i].NoCache)
-		}
+// The digest of the image manifest.
+func (r *ImageDescriptor) Digest(ctx context.Context) (string, error) {
+	if r.digest != nil {
+		return *r.digest, nil
 	}
-	q = q.Arg("path", path)
+	q := r.query.Select("digest")
 
-	return &File{
-		query: q,
-	}
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-// A unique identifier for this Host.
-func (r *Host) ID(ctx context.Context) (HostID, error) {
+// A unique identifier for this ImageDescriptor.
+func (r *ImageDescriptor) ID(ctx context.Context) (ImageDescriptorID, error) {
 	if r.id != nil {
 		return *r.id, nil
 	}
 	q := r.query.Select("id")
 
-	var response HostID
+	var response ImageDescriptorID
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
 }
 
 // XXX_GraphQLType is an internal function. It returns the native GraphQL type name
-func (r *Host) XXX_GraphQLType() string {
-	return "Host"
+func (r *ImageDescriptor) XXX_GraphQLType() string {
+	return "ImageDescriptor"
 }
 
 // XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
-func (r *Host) XXX_GraphQLIDType() string {
-	return "HostID"
+func (r *ImageDescriptor) XXX_GraphQLIDType() string {
+	return "ImageDescriptorID"
 }
 
 // XXX_GraphQLID is an internal function. It returns the underlying type ID
-func (r *Host) XXX_GraphQLID(ctx context.Context) (string, error) {
+func (r *ImageDescriptor) XXX_GraphQLID(ctx context.Context) (string, error) {
 	id, err := r.ID(ctx)
 	if err != nil {
 		return "", err
@@ -6620,7 +7885,7 @@ func (r *Host) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
-func (r *Host) MarshalJSON() ([]byte, error) {
+func (r *ImageDescriptor) MarshalJSON() ([]byte, error) {
 	id, err := r.ID(marshalCtx)
 	if err != nil {
 		return nil, err
@@ -6628,90 +7893,56 @@ func (r *Host) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
-// HostServiceOpts contains options for Host.Service
-type HostServiceOpts struct {
-	// Upstream host to forward traffic to.
-	//
-	// Default: "localhost"
-	Host string
-}
-
-// Creates a service that forwards traffic to a specified address via the host.
-func (r *Host) Service(ports []PortForward, opts ...HostServiceOpts) *Service {
-	q := r.query.Select("service")
-	for i := len(opts) - 1; i >= 0; i-- {
-		// `host` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Host) {
-			q = q.Arg("host", opts[i].Host)
-		}
+// The digest of the multi-platform index containing the image manifest, if any.
+func (r *ImageDescriptor) IndexDigest(ctx context.Context) (string, error) {
+	if r.indexDigest != nil {
+		return *r.indexDigest, nil
 	}
-	q = q.Arg("ports", ports)
+	q := r.query.Select("indexDigest")
 
-	return &Service{
-		query: q,
-	}
-}
+	var response string
 
-// Sets a secret given a user-defined name and the file path on the host, and returns the secret.
-//
-// The file is limited to a size of 512000 bytes.
-//
-// Deprecated: setSecretFile is superceded by use of the secret API with file:// URIs
-func (r *Host) SetSecretFile(name string, path string) *Secret {
-	q := r.query.Select("setSecretFile")
-	q = q.Arg("name", name)
-	q = q.Arg("path", path)
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
 
-	return &Secret{
-		query: q,
+// The media type of the image manifest.
+func (r *ImageDescriptor) MediaType(ctx context.Context) (string, error) {
+	if r.mediaType != nil {
+		return *r.mediaType, nil
 	}
-}
+	q := r.query.Select("mediaType")
 
-// HostTunnelOpts contains options for Host.Tunnel
-type HostTunnelOpts struct {
-	// Map each service port to the same port on the host, as if the service were running natively.
-	//
-	// Note: enabling may result in port conflicts.
-	Native bool
-	// Configure explicit port forwarding rules for the tunnel.
-	//
-	// If a port's frontend is unspecified or 0, a random port will be chosen by the host.
-	//
-	// If no ports are given, all of the service's ports are forwarded. If native is true, each port maps to the same port on the host. If native is false, each port maps to a random port chosen by the host.
-	//
-	// If ports are given and native is true, the ports are additive.
-	Ports []PortForward
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-// Creates a tunnel that forwards traffic from the host to a service.
-func (r *Host) Tunnel(service *Service, opts ...HostTunnelOpts) *Service {
-	assertNotNil("service", service)
-	q := r.query.Select("tunnel")
-	for i := len(opts) - 1; i >= 0; i-- {
-		// `native` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Native) {
-			q = q.Arg("native", opts[i].Native)
-		}
-		// `ports` optional argument
-		if !querybuilder.IsZeroValue(opts[i].Ports) {
-			q = q.Arg("ports", opts[i].Ports)
-		}
+// The platform of the image, if known.
+func (r *ImageDescriptor) Platform(ctx context.Context) (string, error) {
+	if r.platform != nil {
+		return *r.platform, nil
 	}
-	q = q.Arg("service", service)
+	q := r.query.Select("platform")
 
-	return &Service{
-		query: q,
-	}
-}
+	var response string
 
-// Accesses a Unix socket on the host.
-func (r *Host) UnixSocket(path string) *Socket {
-	q := r.query.Select("unixSocket")
-	q = q.Arg("path", path)
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
 
-	return &Socket{
-		query: q,
+// The tag of the image, if any.
+func (r *ImageDescriptor) Tag(ctx context.Context) (string, error) {
+	if r.tag != nil {
+		return *r.tag, nil
 	}
+	q := r.query.Select("tag")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
 // A graphql input type, which is essentially just a group of named args.
@@ -8013,6 +9244,39 @@ func (r *Module) Sync(ctx context.Context) (*Module, error) {
 	}, nil
 }
 
+// Unions served by this module.
+func (r *Module) Unions(ctx context.Context) ([]TypeDef, error) {
+	q := r.query.Select("unions")
+
+	q = q.Select("id")
+
+	type unions struct {
+		Id TypeDefID
+	}
+
+	convert := func(fields []unions) []TypeDef {
+		out := []TypeDef{}
+
+		for i := range fields {
+			val := TypeDef{id: &fields[i].Id}
+			val.query = q.Root().Select("loadTypeDefFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []unions
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
 // Retrieves the module with the given description
 func (r *Module) WithDescription(description string) *Module {
 	q := r.query.Select("withDescription")
@@ -8056,6 +9320,17 @@ func (r *Module) WithObject(object *TypeDef) *Module {
 	}
 }
 
+// This module plus the given Union type
+func (r *Module) WithUnion(union *TypeDef) *Module {
+	assertNotNil("union", union)
+	q := r.query.Select("withUnion")
+	q = q.Arg("union", union)
+
+	return &Module{
+		query: q,
+	}
+}
+
 // The client generated for the module.
 type ModuleConfigClient struct {
 	query *querybuilder.Selection
@@ -9407,6 +10682,16 @@ func (r *Client) LoadContainerFromID(id ContainerID) *Container {
 	}
 }
 
+// Load a ContainerSet from its ID.
+func (r *Client) LoadContainerSetFromID(id ContainerSetID) *ContainerSet {
+	q := r.query.Select("loadContainerSetFromID")
+	q = q.Arg("id", id)
+
+	return &ContainerSet{
+		query: q,
+	}
+}
+
 // Load a CurrentModule from its ID.
 func (r *Client) LoadCurrentModuleFromID(id CurrentModuleID) *CurrentModule {
 	q := r.query.Select("loadCurrentModuleFromID")
@@ -9627,6 +10912,26 @@ func (r *Client) LoadHostFromID(id HostID) *Host {
 	}
 }
 
+// Load a IDDiff from its ID.
+func (r *Client) LoadIDDiffFromID(id IDDiffID) *IDDiff {
+	q := r.query.Select("loadIDDiffFromID")
+	q = q.Arg("id", id)
+
+	return &IDDiff{
+		query: q,
+	}
+}
+
+// Load a ImageDescriptor from its ID.
+func (r *Client) LoadImageDescriptorFromID(id ImageDescriptorID) *ImageDescriptor {
+	q := r.query.Select("loadImageDescriptorFromID")
+	q = q.Arg("id", id)
+
+	return &ImageDescriptor{
+		query: q,
+	}
+}
+
 // Load a InputTypeDef from its ID.
 func (r *Client) LoadInputTypeDefFromID(id InputTypeDefID) *InputTypeDef {
 	q := r.query.Select("loadInputTypeDefFromID")
@@ -9747,6 +11052,16 @@ func (r *Client) LoadPortFromID(id PortID) *Port {
 	}
 }
 
+// Load a QueryCost from its ID.
+func (r *Client) LoadQueryCostFromID(id QueryCostID) *QueryCost {
+	q := r.query.Select("loadQueryCostFromID")
+	q = q.Arg("id", id)
+
+	return &QueryCost{
+		query: q,
+	}
+}
+
 // Load a SDKConfig from its ID.
 func (r *Client) LoadSDKConfigFromID(id SDKConfigID) *SDKConfig {
 	q := r.query.Select("loadSDKConfigFromID")
@@ -9847,6 +11162,16 @@ func (r *Client) LoadTypeDefFromID(id TypeDefID) *TypeDef {
 	}
 }
 
+// Load a UnionTypeDef from its ID.
+func (r *Client) LoadUnionTypeDefFromID(id UnionTypeDefID) *UnionTypeDef {
+	q := r.query.Select("loadUnionTypeDefFromID")
+	q = q.Arg("id", id)
+
+	return &UnionTypeDef{
+		query: q,
+	}
+}
+
 // Create a new module.
 func (r *Client) Module() *Module {
 	q := r.query.Select("module")
@@ -9866,6 +11191,8 @@ type ModuleSourceOpts struct {
 	AllowNotExists bool
 	// If set, error out if the ref string is not of the provided requireKind.
 	RequireKind ModuleSourceKind
+	// If true, a local module source and its local dependencies will always be reloaded from the host.
+	NoCache bool
 }
 
 // Create a new module source instance from a source ref string
@@ -9888,6 +11215,10 @@ func (r *Client) ModuleSource(refString string, opts ...ModuleSourceOpts) *Modul
 		if !querybuilder.IsZeroValue(opts[i].RequireKind) {
 			q = q.Arg("requireKind", opts[i].RequireKind)
 		}
+		// `noCache` optional argument
+		if !querybuilder.IsZeroValue(opts[i].NoCache) {
+			q = q.Arg("noCache", opts[i].NoCache)
+		}
 	}
 	q = q.Arg("refString", refString)
 
@@ -9917,50 +11248,159 @@ func (r *Client) Secret(uri string, opts ...SecretOpts) *Secret {
 	}
 	q = q.Arg("uri", uri)
 
-	return &Secret{
-		query: q,
+	return &Secret{
+		query: q,
+	}
+}
+
+// Sets a secret given a user defined name to its plaintext and returns the secret.
+//
+// The plaintext value is limited to a size of 128000 bytes.
+func (r *Client) SetSecret(name string, plaintext string) *Secret {
+	q := r.query.Select("setSecret")
+	q = q.Arg("name", name)
+	q = q.Arg("plaintext", plaintext)
+
+	return &Secret{
+		query: q,
+	}
+}
+
+// Creates source map metadata.
+func (r *Client) SourceMap(filename string, line int, column int) *SourceMap {
+	q := r.query.Select("sourceMap")
+	q = q.Arg("filename", filename)
+	q = q.Arg("line", line)
+	q = q.Arg("column", column)
+
+	return &SourceMap{
+		query: q,
+	}
+}
+
+// Create a new TypeDef.
+func (r *Client) TypeDef() *TypeDef {
+	q := r.query.Select("typeDef")
+
+	return &TypeDef{
+		query: q,
+	}
+}
+
+// Get the current Dagger Engine version.
+func (r *Client) Version(ctx context.Context) (string, error) {
+	q := r.query.Select("version")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The estimated cost of a query.
+type QueryCost struct {
+	query *querybuilder.Selection
+
+	cost     *int
+	depth    *int
+	id       *QueryCostID
+	maxCost  *int
+	maxDepth *int
+}
+
+func (r *QueryCost) WithGraphQLQuery(q *querybuilder.Selection) *QueryCost {
+	return &QueryCost{
+		query: q,
+	}
+}
+
+// The estimated cost of the query.
+func (r *QueryCost) Cost(ctx context.Context) (int, error) {
+	if r.cost != nil {
+		return *r.cost, nil
+	}
+	q := r.query.Select("cost")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The maximum nesting depth of the query's selections.
+func (r *QueryCost) Depth(ctx context.Context) (int, error) {
+	if r.depth != nil {
+		return *r.depth, nil
 	}
-}
+	q := r.query.Select("depth")
 
-// Sets a secret given a user defined name to its plaintext and returns the secret.
-//
-// The plaintext value is limited to a size of 128000 bytes.
-func (r *Client) SetSecret(name string, plaintext string) *Secret {
-	q := r.query.Select("setSecret")
-	q = q.Arg("name", name)
-	q = q.Arg("plaintext", plaintext)
+	var response int
 
-	return &Secret{
-		query: q,
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A unique identifier for this QueryCost.
+func (r *QueryCost) ID(ctx context.Context) (QueryCostID, error) {
+	if r.id != nil {
+		return *r.id, nil
 	}
+	q := r.query.Select("id")
+
+	var response QueryCostID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-// Creates source map metadata.
-func (r *Client) SourceMap(filename string, line int, column int) *SourceMap {
-	q := r.query.Select("sourceMap")
-	q = q.Arg("filename", filename)
-	q = q.Arg("line", line)
-	q = q.Arg("column", column)
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *QueryCost) XXX_GraphQLType() string {
+	return "QueryCost"
+}
 
-	return &SourceMap{
-		query: q,
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *QueryCost) XXX_GraphQLIDType() string {
+	return "QueryCostID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *QueryCost) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
 	}
+	return string(id), nil
 }
 
-// Create a new TypeDef.
-func (r *Client) TypeDef() *TypeDef {
-	q := r.query.Select("typeDef")
+func (r *QueryCost) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
 
-	return &TypeDef{
-		query: q,
+// The maximum cost allowed by the engine, or 0 if unlimited.
+func (r *QueryCost) MaxCost(ctx context.Context) (int, error) {
+	if r.maxCost != nil {
+		return *r.maxCost, nil
 	}
+	q := r.query.Select("maxCost")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
 }
 
-// Get the current Dagger Engine version.
-func (r *Client) Version(ctx context.Context) (string, error) {
-	q := r.query.Select("version")
+// The maximum depth allowed by the engine, or 0 if unlimited.
+func (r *QueryCost) MaxDepth(ctx context.Context) (int, error) {
+	if r.maxDepth != nil {
+		return *r.maxDepth, nil
+	}
+	q := r.query.Select("maxDepth")
 
-	var response string
+	var response int
 
 	q = q.Bind(&response)
 	return response, q.Execute(ctx)
@@ -11040,6 +12480,15 @@ func (r *TypeDef) AsScalar() *ScalarTypeDef {
 	}
 }
 
+// If kind is UNION, the union-specific type definition. If kind is not UNION, this will be null.
+func (r *TypeDef) AsUnion() *UnionTypeDef {
+	q := r.query.Select("asUnion")
+
+	return &UnionTypeDef{
+		query: q,
+	}
+}
+
 // A unique identifier for this TypeDef.
 func (r *TypeDef) ID(ctx context.Context) (TypeDefID, error) {
 	if r.id != nil {
@@ -11360,6 +12809,184 @@ func (r *TypeDef) WithScalar(name string, opts ...TypeDefWithScalarOpts) *TypeDe
 	}
 }
 
+// TypeDefWithUnionOpts contains options for TypeDef.WithUnion
+type TypeDefWithUnionOpts struct {
+	// A doc string for the union, if any
+	Description string
+	// The source map for the union definition.
+	SourceMap *SourceMap
+}
+
+// Returns a TypeDef of kind Union with the provided name.
+//
+// Note that a union's members may be omitted if the intent is only to refer to a union. This is how functions are able to return their own, or any other circular reference.
+func (r *TypeDef) WithUnion(name string, opts ...TypeDefWithUnionOpts) *TypeDef {
+	q := r.query.Select("withUnion")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `description` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Description) {
+			q = q.Arg("description", opts[i].Description)
+		}
+		// `sourceMap` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SourceMap) {
+			q = q.Arg("sourceMap", opts[i].SourceMap)
+		}
+	}
+	q = q.Arg("name", name)
+
+	return &TypeDef{
+		query: q,
+	}
+}
+
+// Adds an object type as a member of a Union TypeDef, failing if the type is not a union.
+func (r *TypeDef) WithUnionMember(typeDef *TypeDef) *TypeDef {
+	assertNotNil("typeDef", typeDef)
+	q := r.query.Select("withUnionMember")
+	q = q.Arg("typeDef", typeDef)
+
+	return &TypeDef{
+		query: q,
+	}
+}
+
+// A definition of a custom union of objects defined in a Module.
+type UnionTypeDef struct {
+	query *querybuilder.Selection
+
+	description      *string
+	id               *UnionTypeDefID
+	name             *string
+	sourceModuleName *string
+}
+
+func (r *UnionTypeDef) WithGraphQLQuery(q *querybuilder.Selection) *UnionTypeDef {
+	return &UnionTypeDef{
+		query: q,
+	}
+}
+
+// A doc string for the union, if any.
+func (r *UnionTypeDef) Description(ctx context.Context) (string, error) {
+	if r.description != nil {
+		return *r.description, nil
+	}
+	q := r.query.Select("description")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A unique identifier for this UnionTypeDef.
+func (r *UnionTypeDef) ID(ctx context.Context) (UnionTypeDefID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response UnionTypeDefID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *UnionTypeDef) XXX_GraphQLType() string {
+	return "UnionTypeDef"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *UnionTypeDef) XXX_GraphQLIDType() string {
+	return "UnionTypeDefID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *UnionTypeDef) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *UnionTypeDef) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The object types that are members of the union.
+func (r *UnionTypeDef) Members(ctx context.Context) ([]TypeDef, error) {
+	q := r.query.Select("members")
+
+	q = q.Select("id")
+
+	type members struct {
+		Id TypeDefID
+	}
+
+	convert := func(fields []members) []TypeDef {
+		out := []TypeDef{}
+
+		for i := range fields {
+			val := TypeDef{id: &fields[i].Id}
+			val.query = q.Root().Select("loadTypeDefFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []members
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
+// The name of the union.
+func (r *UnionTypeDef) Name(ctx context.Context) (string, error) {
+	if r.name != nil {
+		return *r.name, nil
+	}
+	q := r.query.Select("name")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The location of this union declaration.
+func (r *UnionTypeDef) SourceMap() *SourceMap {
+	q := r.query.Select("sourceMap")
+
+	return &SourceMap{
+		query: q,
+	}
+}
+
+// If this UnionTypeDef is associated with a Module, the name of the module. Unset otherwise.
+func (r *UnionTypeDef) SourceModuleName(ctx context.Context) (string, error) {
+	if r.sourceModuleName != nil {
+		return *r.sourceModuleName, nil
+	}
+	q := r.query.Select("sourceModuleName")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Sharing mode of the cache volume.
 type CacheSharingMode string
 
@@ -11808,6 +13435,77 @@ const (
 	ReturnTypeAny ReturnType = "ANY"
 )
 
+// A change in the state of a service.
+type ServiceEventType string
+
+func (ServiceEventType) IsEnum() {}
+
+func (v ServiceEventType) Name() string {
+	switch v {
+	case ServiceEventTypeStarting:
+		return "STARTING"
+	case ServiceEventTypeRunning:
+		return "RUNNING"
+	case ServiceEventTypeFailed:
+		return "FAILED"
+	case ServiceEventTypeStopped:
+		return "STOPPED"
+	default:
+		return ""
+	}
+}
+
+func (v ServiceEventType) Value() string {
+	return string(v)
+}
+
+func (v *ServiceEventType) MarshalJSON() ([]byte, error) {
+	if *v == "" {
+		return []byte(`""`), nil
+	}
+	name := v.Name()
+	if name == "" {
+		return nil, fmt.Errorf("invalid enum value %q", *v)
+	}
+	return json.Marshal(name)
+}
+
+func (v *ServiceEventType) UnmarshalJSON(dt []byte) error {
+	var s string
+	if err := json.Unmarshal(dt, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "":
+		*v = ""
+	case "FAILED":
+		*v = ServiceEventTypeFailed
+	case "RUNNING":
+		*v = ServiceEventTypeRunning
+	case "STARTING":
+		*v = ServiceEventTypeStarting
+	case "STOPPED":
+		*v = ServiceEventTypeStopped
+	default:
+		return fmt.Errorf("invalid enum value %q", s)
+	}
+	return nil
+}
+
+const (
+	// The service is starting.
+	ServiceEventTypeStarting ServiceEventType = "STARTING"
+
+	// The service started and is running.
+	ServiceEventTypeRunning ServiceEventType = "RUNNING"
+
+	// The service failed to start.
+	ServiceEventTypeFailed ServiceEventType = "FAILED"
+
+	// The service is not running.
+	ServiceEventTypeStopped ServiceEventType = "STOPPED"
+)
+
 // Distinguishes the different kinds of TypeDefs.
 type TypeDefKind string
 
@@ -11837,6 +13535,8 @@ func (v TypeDefKind) Name() string {
 		return "VOID_KIND"
 	case TypeDefKindEnumKind:
 		return "ENUM_KIND"
+	case TypeDefKindUnionKind:
+		return "UNION_KIND"
 	default:
 		return ""
 	}
@@ -11905,6 +13605,10 @@ func (v *TypeDefKind) UnmarshalJSON(dt []byte) error {
 		*v = TypeDefKindString
 	case "STRING_KIND":
 		*v = TypeDefKindStringKind
+	case "UNION":
+		*v = TypeDefKindUnion
+	case "UNION_KIND":
+		*v = TypeDefKindUnionKind
 	case "VOID":
 		*v = TypeDefKindVoid
 	case "VOID_KIND":
@@ -11990,4 +13694,13 @@ const (
 	//
 	// Always paired with an EnumTypeDef.
 	TypeDefKindEnum TypeDefKind = TypeDefKindEnumKind
+
+	// A GraphQL union of object types
+	//
+	// Always paired with a UnionTypeDef.
+	TypeDefKindUnionKind TypeDefKind = "UNION_KIND"
+	// A GraphQL union of object types
+	//
+	// Always paired with a UnionTypeDef.
+	TypeDefKindUnion TypeDefKind = TypeDefKindUnionKind
 )
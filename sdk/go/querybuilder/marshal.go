@@ -131,6 +131,24 @@ func marshalValue(ctx context.Context, v reflect.Value) (string, error) {
 			}
 		}
 		return fmt.Sprintf("{%s}", strings.Join(nonNullElems, ",")), nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return "", fmt.Errorf("unsupported map key of kind %s", t.Key().Kind())
+		}
+		// sort keys for a deterministic query
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		elems := make([]string, 0, len(keys))
+		for _, k := range keys {
+			m, err := marshalValue(ctx, v.MapIndex(k))
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, fmt.Sprintf("%s:%s", k.String(), m))
+		}
+		return fmt.Sprintf("{%s}", strings.Join(elems, ",")), nil
 	default:
 		return "", fmt.Errorf("unsupported argument of kind %s", t.Kind())
 	}
@@ -105,6 +105,17 @@ func TestMarshalGQLStruct(t *testing.T) {
 	require.Equal(t, `{a:"test",b:42,sub:{x:["1"]}}`, enc)
 }
 
+func TestMarshalGQLMap(t *testing.T) {
+	m := map[string]any{
+		"b":   42,
+		"a":   "test",
+		"sub": map[string]any{"x": []any{"1"}},
+	}
+	enc, err := MarshalGQL(context.TODO(), m)
+	require.NoError(t, err)
+	require.Equal(t, `{a:"test",b:42,sub:{x:["1"]}}`, enc)
+}
+
 type customMarshaller struct {
 	v     string
 	count int
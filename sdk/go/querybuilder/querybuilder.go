@@ -22,6 +22,7 @@ type Selection struct {
 	args     map[string]*argument
 	bind     any
 	multiple bool
+	fragment bool
 
 	prev *Selection
 
@@ -57,6 +58,14 @@ func (s *Selection) Select(name string) *Selection {
 	return s.SelectWithAlias("", name)
 }
 
+// InlineFragment selects the following fields only when the value is of the
+// given type, e.g. `... on Container`, as needed for the members of a union.
+func (s *Selection) InlineFragment(typeName string) *Selection {
+	sel := s.SelectWithAlias("", typeName)
+	sel.fragment = true
+	return sel
+}
+
 func (s *Selection) SelectMultiple(name ...string) *Selection {
 	sel := s.SelectWithAlias("", strings.Join(name, " "))
 	sel.multiple = true
@@ -96,12 +105,21 @@ func (s *Selection) marshalArguments(ctx context.Context) error {
 }
 
 func (s *Selection) Build(ctx context.Context) (string, error) {
+	return s.build(ctx, "query")
+}
+
+// BuildSubscription is like Build, but builds a subscription operation.
+func (s *Selection) BuildSubscription(ctx context.Context) (string, error) {
+	return s.build(ctx, "subscription")
+}
+
+func (s *Selection) build(ctx context.Context, operation string) (string, error) {
 	if err := s.marshalArguments(ctx); err != nil {
 		return "", err
 	}
 
 	var b strings.Builder
-	b.WriteString("query")
+	b.WriteString(operation)
 
 	path := s.path()
 
@@ -112,6 +130,12 @@ func (s *Selection) Build(ctx context.Context) (string, error) {
 
 		b.WriteRune('{')
 
+		if sel.fragment {
+			b.WriteString("... on ")
+			b.WriteString(sel.name)
+			continue
+		}
+
 		if sel.alias != "" {
 			b.WriteString(sel.alias)
 			b.WriteRune(':')
@@ -141,6 +165,10 @@ func (s *Selection) Build(ctx context.Context) (string, error) {
 
 func (s *Selection) unpack(data any) error {
 	for _, i := range s.path() {
+		if i.fragment {
+			// fragment fields are selected on the value itself
+			continue
+		}
 		k := i.name
 		if i.alias != "" {
 			k = i.alias
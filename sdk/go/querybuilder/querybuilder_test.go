@@ -3,8 +3,10 @@ package querybuilder
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/Khan/genqlient/graphql"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,6 +32,25 @@ func TestAlias(t *testing.T) {
 	require.Equal(t, `query{core{image(ref:"alpine"){foo:file(path:"/etc/alpine-release")}}}`, q)
 }
 
+func TestInlineFragment(t *testing.T) {
+	var contents string
+	root := Query().
+		Select("shape").
+		InlineFragment("Point").
+		Select("x").Bind(&contents)
+
+	q, err := root.Build(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, `query{shape{... on Point{x}}}`, q)
+
+	var response any
+	err = json.Unmarshal([]byte(`{"shape": {"x": "1"}}`), &response)
+	require.NoError(t, err)
+	require.NoError(t, root.unpack(response))
+	require.Equal(t, "1", contents)
+	require.Equal(t, "1", root.value(response))
+}
+
 func TestArgsCollision(t *testing.T) {
 	q, err := Query().
 		Select("a").Arg("arg", "one").
@@ -200,3 +221,40 @@ func TestUnpackSiblings(t *testing.T) {
 	require.NoError(t, root.unpack(response))
 	require.EqualValues(t, data{"TEST", 12, true}, contents)
 }
+
+type fakeSubscriber struct {
+	graphql.Client
+	query  string
+	events []string
+}
+
+func (c *fakeSubscriber) Subscribe(_ context.Context, req *graphql.Request, fn func(json.RawMessage) error) error {
+	c.query = req.Query
+	for _, ev := range c.events {
+		if err := fn(json.RawMessage(ev)); err != nil {
+			return err
+		}
+	}
+	return errors.New("done")
+}
+
+func TestSubscribe(t *testing.T) {
+	client := &fakeSubscriber{
+		events: []string{
+			`{"containerStdoutStream":"hello "}`,
+			`{"containerStdoutStream":"world"}`,
+		},
+	}
+	sel := Query().Client(client).
+		Select("containerStdoutStream").
+		Arg("container", "ctr")
+
+	values, errs := Subscribe[string](context.Background(), sel)
+	var got []string
+	for val := range values {
+		got = append(got, val)
+	}
+	require.Equal(t, []string{"hello ", "world"}, got)
+	require.EqualError(t, <-errs, "done")
+	require.Equal(t, `subscription{containerStdoutStream(container:"ctr")}`, client.query)
+}
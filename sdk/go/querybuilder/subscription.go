@@ -0,0 +1,93 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// Subscriber is implemented by clients that can execute subscriptions.
+type Subscriber interface {
+	// Subscribe executes the request as a subscription, calling fn with the
+	// data of each response until the subscription ends, fn returns an error,
+	// or ctx is canceled.
+	Subscribe(ctx context.Context, req *graphql.Request, fn func(data json.RawMessage) error) error
+}
+
+// Subscribe executes the selection as a subscription, sending the value of the
+// selected field to the returned channel each time the subscription produces
+// one.
+//
+// The values channel is closed once the subscription ends. The error channel
+// then receives the error that ended it, if any, and is closed too.
+func Subscribe[T any](ctx context.Context, s *Selection) (<-chan T, <-chan error) {
+	values := make(chan T)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		err := s.subscribe(ctx, func(data any) error {
+			var val T
+			marshalled, err := json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(marshalled, &val); err != nil {
+				return err
+			}
+			select {
+			case values <- val:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(values)
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return values, errs
+}
+
+func (s *Selection) subscribe(ctx context.Context, fn func(data any) error) error {
+	if s.client == nil {
+		return fmt.Errorf("no client configured for selection")
+	}
+	subscriber, ok := s.client.(Subscriber)
+	if !ok {
+		return fmt.Errorf("client does not support subscriptions")
+	}
+
+	query, err := s.BuildSubscription(ctx)
+	if err != nil {
+		return err
+	}
+
+	return subscriber.Subscribe(ctx, &graphql.Request{Query: query}, func(data json.RawMessage) error {
+		var response any
+		if err := json.Unmarshal(data, &response); err != nil {
+			return err
+		}
+		return fn(s.value(response))
+	})
+}
+
+// value returns the value of the selection in the given response data.
+func (s *Selection) value(data any) any {
+	for _, sel := range s.path() {
+		if sel.fragment {
+			// fragment fields are selected on the value itself
+			continue
+		}
+		k := sel.name
+		if sel.alias != "" {
+			k = sel.alias
+		}
+		if f, ok := data.(map[string]any); ok {
+			data = f[k]
+		}
+	}
+	return data
+}
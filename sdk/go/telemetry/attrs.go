@@ -159,4 +159,17 @@ const (
 
 	// The function name of the current module in the format of "type.functionName"
 	ModuleCallerFunctionCallNameAttr = "dagger.io/module.caller.function.name"
+
+	// The lifecycle stage of an API used by a client, on spans warning about
+	// its use.
+	//
+	// Example: "deprecated", "experimental"
+	LifecycleKindAttr = "dagger.io/lifecycle.kind"
+
+	// The API a lifecycle warning is about, e.g. "Container.withExec" or
+	// "Container.withExec(args:)".
+	LifecycleAPIAttr = "dagger.io/lifecycle.api"
+
+	// The reason given for the lifecycle stage of an API, if any.
+	LifecycleReasonAttr = "dagger.io/lifecycle.reason"
 )
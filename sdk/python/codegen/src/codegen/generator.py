@@ -44,10 +44,12 @@ from graphql import (
     GraphQLScalarType,
     GraphQLSchema,
     GraphQLType,
+    GraphQLUnionType,
     GraphQLWrappingType,
     Undefined,
     get_named_type,
     is_leaf_type,
+    is_union_type,
 )
 from graphql.pyutils import camel_to_snake
 from graphql.type.schema import TypeMap
@@ -212,10 +214,17 @@ def generate(schema: GraphQLSchema) -> Iterator[str]:
         Enum(ctx),
         Input(ctx),
         Object(ctx),
+        Union(ctx),
     )
 
     # Split into two iterators to update ctx.remaining.
-    types_n, types_g = itertools.tee(get_grouped_types(handlers, schema.type_map))
+    # Subscriptions are served over SSE, which the client doesn't support.
+    type_map = {
+        n: t
+        for n, t in schema.type_map.items()
+        if t is not schema.subscription_type
+    }
+    types_n, types_g = itertools.tee(get_grouped_types(handlers, type_map))
 
     # Track types that haven't been defined yet, to format as a forward reference.
     ctx.remaining.update(name for _, name, _ in types_n)
@@ -834,3 +843,44 @@ class Object(ObjectHandler[GraphQLObjectType]):
                     return cb(self)
                 '''  # noqa: E501
             )
+
+
+@dataclass
+class Union(Handler[GraphQLUnionType]):
+    predicate: ClassVar[Predicate] = staticmethod(is_union_type)
+
+    def supertype_name(self, t: GraphQLUnionType) -> str:
+        return "Type"
+
+    def render_head(self, t: GraphQLUnionType) -> str:
+        return f"@typecheck\n{super().render_head(t)}"
+
+    @joiner
+    def render_body(self, t: GraphQLUnionType) -> Iterator[str]:
+        members = ", ".join(f":py:class:`{m.name}`" for m in t.types)
+        lines = t.description.splitlines() if t.description else []
+        if lines:
+            lines.append("")
+        lines.append(f"The value is one of: {members}.")
+        yield doc("\n".join(textwrap.fill(line) for line in lines))
+
+        yield ""
+        yield "async def typename(self) -> str:"
+        yield indent(doc("The name of the concrete type of the value."))
+        yield indent('_ctx = self._select("__typename", [])')
+        yield indent("return await _ctx.execute(str)")
+
+        for member in t.types:
+            yield ""
+            yield self.ctx.render_types(
+                f"def as_{format_name(member.name)}(self) -> {member.name}:"
+            )
+            yield indent(
+                doc(
+                    textwrap.fill(
+                        f"The value as a :py:class:`{member.name}`, which it must"
+                        " be, as reported by :py:meth:`typename`."
+                    )
+                )
+            )
+            yield indent(f'return {member.name}(self._select_fragment("{member.name}"))')
@@ -19,7 +19,16 @@ import graphql
 import httpx
 from beartype.door import TypeHint
 from cattrs.preconf.json import make_converter as make_json_converter
-from gql.dsl import DSLField, DSLQuery, DSLSchema, DSLSelectable, DSLType, dsl_gql
+from gql.dsl import (
+    DSLField,
+    DSLInlineFragment,
+    DSLMetaField,
+    DSLQuery,
+    DSLSchema,
+    DSLSelectable,
+    DSLType,
+    dsl_gql,
+)
 from gql.transport.exceptions import (
     TransportClosed,
     TransportConnectionFailed,
@@ -62,13 +71,32 @@ class Field:
     name: str
     args: dict[str, Any]
     children: dict[str, "Field"] = dataclasses.field(default_factory=dict)
-
-    def to_dsl(self, schema: DSLSchema) -> DSLField:
-        type_: DSLType = getattr(schema, self.type_name)
-        field_ = getattr(type_, self.name)(**self.args)
+    fragment: bool = False
+    """Whether this is an inline fragment on the type named by `name`."""
+
+    @property
+    def unaliased(self) -> bool:
+        # Inline fragments can't be aliased, and neither can `__typename`
+        # as aliases can't start with `__`.
+        return self.fragment or self.name == "__typename"
+
+    def to_dsl(self, schema: DSLSchema) -> DSLSelectable:
+        field_: DSLField | DSLInlineFragment | DSLMetaField
+        if self.fragment:
+            field_ = DSLInlineFragment().on(getattr(schema, self.name))
+        elif self.name == "__typename":
+            field_ = DSLMetaField("__typename")
+        else:
+            type_: DSLType = getattr(schema, self.type_name)
+            field_ = getattr(type_, self.name)(**self.args)
         if self.children:
             field_ = field_.select(
-                **{name: child.to_dsl(schema) for name, child in self.children.items()}
+                *(c.to_dsl(schema) for c in self.children.values() if c.unaliased),
+                **{
+                    name: c.to_dsl(schema)
+                    for name, c in self.children.items()
+                    if not c.unaliased
+                },
             )
         return field_
 
@@ -107,6 +135,13 @@ class Context:
         selections.append(field_)
         return dataclasses.replace(self, selections=selections)
 
+    def select_fragment(self, type_name: str, member_name: str) -> "Context":
+        """Select the value as the given member type of a union."""
+        field_ = Field(type_name, member_name, {}, fragment=True)
+        selections = self.selections.copy()
+        selections.append(field_)
+        return dataclasses.replace(self, selections=selections)
+
     def select_multiple(self, type_name: str, **fields: str) -> "Context":
         selections = self.selections.copy()
         parent = selections.pop()
@@ -247,7 +282,9 @@ class Context:
         for f in self.selections:
             if not isinstance(value, dict):
                 break
-            value = value[f.name]
+            # the fields of inline fragments are merged into their parent's
+            if not f.fragment:
+                value = value[f.name]
 
         if value is None and not type_hint.is_bearable(value):
             msg = (
@@ -66,6 +66,9 @@ class Type(Object):
     def _select_multiple(self, **kwargs):
         return self._ctx.select_multiple(self._graphql_name(), **kwargs)
 
+    def _select_fragment(self, member_name: str):
+        return self._ctx.select_fragment(self._graphql_name(), member_name)
+
 
 class Interface(Type):
     """Dagger interface type."""
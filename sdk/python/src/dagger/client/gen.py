@@ -5965,6 +5965,33 @@ class Function(Type):
         _ctx = self._select("withArg", _args)
         return Function(_ctx)
 
+    def with_cache_policy(
+        self,
+        *,
+        ttl: str | None = "",
+        key_args: list[str] | None = None,
+    ) -> Self:
+        """Returns the function with the given cache policy.
+
+        By default, a function's result is cached for the rest of the session
+        for the same receiver and arguments.
+
+        Parameters
+        ----------
+        ttl:
+            Reuse the result across sessions for at most this duration (e.g.
+            "1h").
+        key_args:
+            Identify the result by only these arguments, ignoring the others
+            (e.g. a log level).
+        """
+        _args = [
+            Arg("ttl", ttl, ""),
+            Arg("keyArgs", [] if key_args is None else key_args, []),
+        ]
+        _ctx = self._select("withCachePolicy", _args)
+        return Function(_ctx)
+
     def with_description(self, description: str) -> Self:
         """Returns the function with the given doc string.
 
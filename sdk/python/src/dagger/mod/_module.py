@@ -6,7 +6,7 @@ import logging
 import os
 import textwrap
 import typing
-from collections.abc import Awaitable, Callable, Mapping
+from collections.abc import Awaitable, Callable, Mapping, Sequence
 from typing import Any, TypeVar, cast
 
 import cattrs
@@ -175,6 +175,12 @@ class Module:
                 if doc := func.doc:
                     func_def = func_def.with_description(doc)
 
+                if func.meta.cache or func.meta.cache_key_args:
+                    func_def = func_def.with_cache_policy(
+                        ttl=func.meta.cache or "",
+                        key_args=list(func.meta.cache_key_args),
+                    )
+
                 for param in func.parameters.values():
                     arg_def = to_typedef(
                         param.resolved_type,
@@ -568,6 +574,8 @@ class Module:
         *,
         name: APIName | None = None,
         doc: str | None = None,
+        cache: str | None = None,
+        cache_key_args: Sequence[str] | None = None,
     ) -> Func[P, R]: ...
 
     @overload
@@ -576,6 +584,8 @@ class Module:
         *,
         name: APIName | None = None,
         doc: str | None = None,
+        cache: str | None = None,
+        cache_key_args: Sequence[str] | None = None,
     ) -> Callable[[Func[P, R]], Func[P, R]]: ...
 
     def function(
@@ -584,6 +594,8 @@ class Module:
         *,
         name: APIName | None = None,
         doc: str | None = None,
+        cache: str | None = None,
+        cache_key_args: Sequence[str] | None = None,
     ) -> Func[P, R] | Callable[[Func[P, R]], Func[P, R]]:
         """Exposes a Python function as a :py:class:`dagger.Function`.
 
@@ -608,6 +620,13 @@ class Module:
         doc:
             An alternative description for the API. Useful to use the
             docstring for other purposes.
+        cache:
+            Reuse the function's result across sessions for at most this
+            duration (e.g., ``"1h"``). By default, it's only cached for the
+            rest of the session.
+        cache_key_args:
+            Identify the function's result in the cache by only these
+            arguments, ignoring the others (e.g., a log level).
         """
 
         # TODO: Wrap appropriately
@@ -615,7 +634,12 @@ class Module:
             # TODO: Use beartype to validate
             assert callable(func), f"Expected a callable, got {type(func)}."
 
-            meta = FunctionDefinition(name, doc)
+            meta = FunctionDefinition(
+                name,
+                doc,
+                cache=cache,
+                cache_key_args=tuple(cache_key_args or ()),
+            )
 
             if inspect.isclass(func):
                 return Constructor(func, meta)
@@ -19,6 +19,8 @@ class FieldDefinition:
 class FunctionDefinition:
     name: APIName | None = None
     doc: str | None = None
+    cache: str | None = None
+    cache_key_args: tuple[str, ...] = ()
 
 
 class Enum(str, base.Enum):
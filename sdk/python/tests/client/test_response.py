@@ -56,3 +56,16 @@ def test_list(ctx: Context):
     r = {"one": {"two": ["200", "201"]}}
     actual = ctx.get_value(r, list[SomeID])
     assert actual == [SomeID("200"), SomeID("201")]
+
+
+def test_inline_fragment(mocker):
+    selections = deque(
+        [
+            Field("Query", "pet", {}),
+            Field("Pet", "Cat", {}, fragment=True),
+            Field("Cat", "lives", {}),
+        ]
+    )
+    ctx = Context(mocker.MagicMock(), selections)
+    # the fields of the fragment are merged into the union's
+    assert ctx.get_value({"pet": {"lives": 9}}, int) == 9
@@ -14,6 +14,7 @@ from graphql import GraphQLNonNull as NonNull
 from graphql import GraphQLObjectType as Object
 from graphql import GraphQLScalarType as Scalar
 from graphql import GraphQLString as String
+from graphql import GraphQLUnionType as Union
 
 from codegen.generator import (
     Context,
@@ -26,6 +27,7 @@ from codegen.generator import (
 )
 from codegen.generator import Enum as EnumHandler
 from codegen.generator import Scalar as ScalarHandler
+from codegen.generator import Union as UnionHandler
 
 
 @pytest.fixture
@@ -334,6 +336,47 @@ def test_enum_render(type_, expected, ctx: Context):
     assert handler.render(type_) == expected
 
 
+def test_union_render(ctx: Context):
+    pet = Union(
+        "Pet",
+        [
+            Object("Cat", {"lives": Field(NonNull(Int))}),
+            Object("Dog", {"breed": Field(NonNull(String))}),
+        ],
+        description="A pet, either a cat or a dog.",
+    )
+    expected = dedent(
+        '''
+        @typecheck
+        class Pet(Type):
+            """A pet, either a cat or a dog.
+
+            The value is one of: :py:class:`Cat`, :py:class:`Dog`.
+            """
+
+            async def typename(self) -> str:
+                """The name of the concrete type of the value."""
+                _ctx = self._select("__typename", [])
+                return await _ctx.execute(str)
+
+            def as_cat(self) -> Cat:
+                """The value as a :py:class:`Cat`, which it must be, as reported by
+                :py:meth:`typename`.
+                """
+                return Cat(self._select_fragment("Cat"))
+
+            def as_dog(self) -> Dog:
+                """The value as a :py:class:`Dog`, which it must be, as reported by
+                :py:meth:`typename`.
+                """
+                return Dog(self._select_fragment("Dog"))
+        ''',
+    )
+    handler = UnionHandler(ctx)
+    assert handler.predicate(pet)
+    assert handler.render(pet) == expected
+
+
 @pytest.mark.parametrize(
     ("original", "expected"),
     [
@@ -59,6 +59,24 @@ def test_func_doc():
     assert mod.get_object("Foo").functions["fn_with_doc"].doc == "Foo."
 
 
+def test_func_cache_policy():
+    mod = Module()
+
+    @mod.object_type
+    class Foo:
+        @mod.function(cache="1h", cache_key_args=["repo"])
+        def fetch(self, repo: str, verbose: bool = False) -> str: ...
+
+        @mod.function
+        def build(self) -> str: ...
+
+    functions = mod.get_object("Foo").functions
+    assert functions["fetch"].meta.cache == "1h"
+    assert functions["fetch"].meta.cache_key_args == ("repo",)
+    assert functions["build"].meta.cache is None
+    assert functions["build"].meta.cache_key_args == ()
+
+
 def test_external_constructor_doc():
     mod = Module()
 
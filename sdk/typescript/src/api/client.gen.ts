@@ -1194,6 +1194,18 @@ export type FunctionWithArgOpts = {
   sourceMap?: SourceMap
 }
 
+export type FunctionWithCachePolicyOpts = {
+  /**
+   * Reuse the result across sessions for at most this duration (e.g. "1h").
+   */
+  ttl?: string
+
+  /**
+   * Identify the result by only these arguments, ignoring the others (e.g. a log level).
+   */
+  keyArgs?: string[]
+}
+
 /**
  * The `FunctionArgID` scalar type represents an identifier for an object of type FunctionArg.
  */
@@ -6199,6 +6211,18 @@ export class Function_ extends BaseClient {
     return new Function_(ctx)
   }
 
+  /**
+   * Returns the function with the given cache policy.
+   *
+   * By default, a function's result is cached for the rest of the session for the same receiver and arguments.
+   * @param opts.ttl Reuse the result across sessions for at most this duration (e.g. "1h").
+   * @param opts.keyArgs Identify the result by only these arguments, ignoring the others (e.g. a log level).
+   */
+  withCachePolicy = (opts?: FunctionWithCachePolicyOpts): Function_ => {
+    const ctx = this._ctx.select("withCachePolicy", { ...opts })
+    return new Function_(ctx)
+  }
+
   /**
    * Returns the function with the given doc string.
    * @param description The doc string to set.
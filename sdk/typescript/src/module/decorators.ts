@@ -14,7 +14,8 @@ export const object = registry.object
  * The definition of @func decorator that should be on top of any
  * class' method that must be exposed to the Dagger API.
  *
- * @param alias The alias to use for the field when exposed on the API.
+ * @param alias The alias to use for the field when exposed on the API, or
+ * the function's options, such as its cache policy.
  */
 export const func = registry.func
 
@@ -126,9 +126,25 @@ export class Register {
       .function_(fct.alias ?? fct.name, addTypeDef(fct.returnType!))
       .withDescription(fct.description)
       .withSourceMap(addSourceMap(fct))
+      .with(this.addCachePolicy(fct))
       .with(this.addArg(fct.arguments))
   }
 
+  /**
+   * Set the cache policy of the function, if it has one.
+   */
+  addCachePolicy(
+    fct: Method | DaggerInterfaceFunction,
+  ): (fct: Function_) => Function_ {
+    return (fn: Function_): Function_ => {
+      if (!(fct instanceof Method) || (!fct.cache && !fct.cacheKeyArgs)) {
+        return fn
+      }
+
+      return fn.withCachePolicy({ ttl: fct.cache, keyArgs: fct.cacheKeyArgs })
+    }
+  }
+
   /**
    * Register all arguments in the function.
    */
@@ -2,6 +2,7 @@ import ts from "typescript"
 
 import { TypeDefKind } from "../../../api/client.gen.js"
 import { IntrospectionError } from "../../../common/errors/index.js"
+import { FunctionOptions } from "../../registry.js"
 import { TypeDef } from "../typedef.js"
 import {
   AST,
@@ -21,6 +22,8 @@ export class DaggerFunction extends Locatable {
   private _returnTypeRef?: string
   public returnType?: TypeDef<TypeDefKind>
   public alias: string | undefined
+  public cache: string | undefined
+  public cacheKeyArgs: string[] | undefined
   public arguments: DaggerArguments = {}
 
   private signature: ts.Signature
@@ -44,7 +47,11 @@ export class DaggerFunction extends Locatable {
       )
     }
     this.returnType = this.getReturnType()
-    this.alias = this.getAlias()
+
+    const options = this.getOptions()
+    this.alias = options.alias
+    this.cache = options.cache
+    this.cacheKeyArgs = options.cacheKeyArgs
   }
 
   private getReturnType(): TypeDef<TypeDefKind> | undefined {
@@ -58,17 +65,31 @@ export class DaggerFunction extends Locatable {
     return typedef
   }
 
-  private getAlias(): string | undefined {
-    const alias = this.ast.getDecoratorArgument<string>(
+  /**
+   * Return the options of the function decorator, which takes either an
+   * alias or an options object.
+   */
+  private getOptions(): FunctionOptions {
+    const argument = this.ast.getDecoratorArgument<string>(
       this.node,
       FUNCTION_DECORATOR,
       "string",
     )
-    if (!alias) {
-      return
+    if (!argument) {
+      return {}
+    }
+
+    if (argument.trim().startsWith("{")) {
+      return (
+        this.ast.getDecoratorArgument<FunctionOptions>(
+          this.node,
+          FUNCTION_DECORATOR,
+          "object",
+        ) ?? {}
+      )
     }
 
-    return JSON.parse(alias.replace(/'/g, '"'))
+    return { alias: JSON.parse(argument.replace(/'/g, '"')) }
   }
 
   public getArgsOrder(): string[] {
@@ -123,6 +144,8 @@ export class DaggerFunction extends Locatable {
       name: this.name,
       description: this.description,
       alias: this.alias,
+      cache: this.cache,
+      cacheKeyArgs: this.cacheKeyArgs,
       arguments: this.arguments,
       returnType: this.returnType,
     }
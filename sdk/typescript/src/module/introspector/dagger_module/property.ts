@@ -2,6 +2,7 @@ import ts from "typescript"
 
 import { TypeDefKind } from "../../../api/client.gen.js"
 import { IntrospectionError } from "../../../common/errors/index.js"
+import { FunctionOptions } from "../../registry.js"
 import { TypeDef } from "../typedef.js"
 import {
   AST,
@@ -58,6 +59,15 @@ export class DaggerProperty
       "string",
     )
 
+    if (alias?.trim().startsWith("{")) {
+      // the options of a function only set the alias of a property
+      return this.ast.getDecoratorArgument<FunctionOptions>(
+        this.node,
+        FUNCTION_DECORATOR,
+        "object",
+      )?.alias
+    }
+
     if (alias) {
       return JSON.parse(alias.replace(/'/g, '"'))
     }
@@ -98,6 +98,10 @@ describe("scan by reference TypeScript", function () {
       name: "Should correctly scan interfaces",
       directory: "interface",
     },
+    {
+      name: "Should correctly scan cache policies",
+      directory: "cachePolicy",
+    },
   ]
 
   for (const test of testCases) {
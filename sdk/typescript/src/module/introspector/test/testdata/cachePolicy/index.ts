@@ -0,0 +1,22 @@
+import { func, object } from "../../../../decorators.js"
+
+@object()
+export class CachePolicy {
+  @func({ alias: "tag" })
+  version: string = "1.0"
+
+  @func({ cache: "1h", cacheKeyArgs: ["repo"] })
+  fetch(repo: string, verbose: boolean = false): string {
+    return verbose ? `fetching ${repo}` : repo
+  }
+
+  @func({ alias: "latest", cache: "10m" })
+  getLatest(): string {
+    return this.version
+  }
+
+  @func()
+  build(): string {
+    return "build"
+  }
+}
@@ -12,6 +12,25 @@ export type State = { [property: string]: any }
 
 export type Args = Record<string, unknown>
 
+export type FunctionOptions = {
+  /**
+   * The alias to use for the function when exposed on the API.
+   */
+  alias?: string
+
+  /**
+   * Reuse the function's result across sessions for at most this duration
+   * (e.g. "1h"). By default, it's only cached for the rest of the session.
+   */
+  cache?: string
+
+  /**
+   * Identify the function's result in the cache by only these arguments,
+   * ignoring the others (e.g. a log level).
+   */
+  cacheKeyArgs?: string[]
+}
+
 /**
  * Datastructures that store the class constructor to allow invoking it
  * from the registry and store method's name.
@@ -92,9 +111,12 @@ export class Registry {
   /**
    * The definition of @func decorator that should be on top of any
    * class' method that must be exposed to the Dagger API.
+   *
+   * @param alias The alias to use for the function when exposed on the API,
+   * or the function's options.
    */
   func = (
-    alias?: string,
+    alias?: string | FunctionOptions,
   ): ((
     target: object,
     propertyKey: string | symbol,